@@ -0,0 +1,88 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+	"bytes"
+	"strings"
+)
+
+// Comandos que resumen los registros de la tabla actual.
+
+// Scope es el alcance de un comando sobre los registros de la tabla:
+// All, Next n, Rest o Record n.
+type Scope struct {
+	Kind  string     // "all", "next", "rest" o "record"
+	Count Expression // Next n | Record n
+}
+
+func (s *Scope) String() string {
+	if s.Count != nil {
+		return s.Kind + " " + s.Count.String()
+	}
+	return s.Kind
+}
+
+// scopeClauses es el texto del alcance y las condiciones de un comando.
+func scopeClauses(scope *Scope, forCond Expression, whileCond Expression) string {
+	out := conditions(forCond, whileCond)
+	if scope != nil {
+		out = " " + scope.String() + out
+	}
+	return out
+}
+
+// AggregateStmt => Count | Sum [exprs] | Average [exprs] | Calculate exprs
+// [alcance] [For cond] [While cond] [To vars | To Array arr]
+type AggregateStmt struct {
+	Token   token.Token
+	Command string // "count", "sum", "average" o "calculate"
+	Exprs   []Expression
+	Scope   *Scope
+	For     Expression
+	While   Expression
+	To      []string
+	ToArray string
+}
+
+func (a *AggregateStmt) statementNode() {}
+func (a *AggregateStmt) String() string {
+	var out bytes.Buffer
+	out.WriteString(a.Command)
+	exprs := make([]string, len(a.Exprs))
+	for i, exp := range a.Exprs {
+		exprs[i] = exp.String()
+	}
+	if len(exprs) > 0 {
+		out.WriteString(" " + strings.Join(exprs, ", "))
+	}
+	out.WriteString(scopeClauses(a.Scope, a.For, a.While))
+	if a.ToArray != "" {
+		out.WriteString(" to array " + a.ToArray)
+	} else if len(a.To) > 0 {
+		out.WriteString(" to " + strings.Join(a.To, ", "))
+	}
+	return out.String()
+}
+
+// TotalStmt => Total On clave To tabla [Fields campos] [alcance] [For cond]
+// [While cond]
+type TotalStmt struct {
+	Token  token.Token
+	Key    Expression
+	File   Expression
+	Fields []string
+	Scope  *Scope
+	For    Expression
+	While  Expression
+}
+
+func (t *TotalStmt) statementNode() {}
+func (t *TotalStmt) String() string {
+	var out bytes.Buffer
+	out.WriteString("total on " + t.Key.String() + " to " + t.File.String())
+	if len(t.Fields) > 0 {
+		out.WriteString(" fields " + strings.Join(t.Fields, ", "))
+	}
+	out.WriteString(scopeClauses(t.Scope, t.For, t.While))
+	return out.String()
+}
@@ -0,0 +1,25 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+	"bytes"
+	"strings"
+)
+
+type ArrayLiteral struct {
+	Token    token.Token
+	Elements []Expression
+}
+
+func (a *ArrayLiteral) expressionNode() {}
+func (a *ArrayLiteral) String() string {
+	var out bytes.Buffer
+	var elements []string
+	for _, el := range a.Elements {
+		elements = append(elements, el.String())
+	}
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+	return out.String()
+}
@@ -0,0 +1,19 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+	"fmt"
+)
+
+// AssignStmt asigna un valor a un destino que no es una simple variable,
+// por ejemplo el elemento de un array: laFrutas[0] = "Manzana"
+type AssignStmt struct {
+	Token  token.Token
+	Target Expression
+	Value  Expression
+}
+
+func (a *AssignStmt) statementNode() {}
+func (a *AssignStmt) String() string {
+	return fmt.Sprintf("%s = %s", a.Target.String(), a.Value.String())
+}
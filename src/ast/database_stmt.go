@@ -0,0 +1,68 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+)
+
+// Comandos de las bases de datos (.dbc).
+
+// CreateDatabaseStmt => Create Database ventas
+type CreateDatabaseStmt struct {
+	Token token.Token
+	Name  Expression
+}
+
+func (c *CreateDatabaseStmt) statementNode() {}
+func (c *CreateDatabaseStmt) String() string {
+	return "create database " + c.Name.String()
+}
+
+// OpenDatabaseStmt => Open Database ventas [Exclusive | Shared]
+type OpenDatabaseStmt struct {
+	Token     token.Token
+	Name      Expression
+	Exclusive bool
+	Shared    bool
+}
+
+func (o *OpenDatabaseStmt) statementNode() {}
+func (o *OpenDatabaseStmt) String() string {
+	out := "open database " + o.Name.String()
+	if o.Exclusive {
+		out += " exclusive"
+	}
+	if o.Shared {
+		out += " shared"
+	}
+	return out
+}
+
+// SetDatabaseStmt => Set Database To [ventas]
+type SetDatabaseStmt struct {
+	Token token.Token
+	Name  Expression // nil: ninguna base de datos actual
+}
+
+func (s *SetDatabaseStmt) statementNode() {}
+func (s *SetDatabaseStmt) String() string {
+	if s.Name == nil {
+		return "set database to"
+	}
+	return "set database to " + s.Name.String()
+}
+
+// AddTableStmt => Add Table clientes [Name "clientes_activos"]
+type AddTableStmt struct {
+	Token token.Token
+	File  Expression
+	Name  Expression // nombre largo de la tabla en la base
+}
+
+func (a *AddTableStmt) statementNode() {}
+func (a *AddTableStmt) String() string {
+	out := "add table " + a.File.String()
+	if a.Name != nil {
+		out += " name " + a.Name.String()
+	}
+	return out
+}
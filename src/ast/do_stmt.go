@@ -0,0 +1,35 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+	"bytes"
+	"strings"
+)
+
+// DoStmt ejecuta una rutina descartando su valor: Do Calcular With lnTotal
+// o un programa: Do otro.flp With lnTotal (en ese caso Name es nil).
+// Los argumentos que son variables se pasan por referencia.
+type DoStmt struct {
+	Token token.Token
+	Name  *Literal
+	File  Expression
+	Args  []Expression
+}
+
+func (d *DoStmt) statementNode() {}
+func (d *DoStmt) String() string {
+	var out bytes.Buffer
+	if d.File != nil {
+		out.WriteString("do " + d.File.String())
+	} else {
+		out.WriteString("do " + d.Name.String())
+	}
+	if len(d.Args) > 0 {
+		var args []string
+		for _, arg := range d.Args {
+			args = append(args, arg.String())
+		}
+		out.WriteString(" with " + strings.Join(args, ", "))
+	}
+	return out.String()
+}
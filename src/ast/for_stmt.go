@@ -0,0 +1,24 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+	"fmt"
+)
+
+// For recorre un iterable: For v in laFrutas | For i, v in laFrutas
+// Key es opcional y recibe la posición de cada elemento.
+type For struct {
+	Token    token.Token
+	Key      *Literal
+	Value    *Literal
+	Iterable Expression
+	Body     *BlockStmt
+}
+
+func (f *For) statementNode() {}
+func (f *For) String() string {
+	if f.Key != nil {
+		return fmt.Sprintf("for %s, %s in %s", f.Key.String(), f.Value.String(), f.Iterable.String())
+	}
+	return fmt.Sprintf("for %s in %s", f.Value.String(), f.Iterable.String())
+}
@@ -3,22 +3,42 @@ package ast
 import (
 	"FoxLite/src/token"
 	"bytes"
+	"fmt"
 	"strings"
 )
 
 type FunctionLiteral struct {
 	Token      token.Token
 	Name       *Literal
-	Parameters []*Literal
+	Parameters []*Parameter
 	Body       *BlockStmt
 }
 
-func (f *FunctionLiteral) statementNode() {}
+// Parameter es un parámetro de la cabecera de una función con su valor
+// por defecto opcional: Func Saludar(tcNombre, tcSaludo = "Hola")
+type Parameter struct {
+	Name    *Literal
+	Default Expression
+}
+
+func (p *Parameter) String() string {
+	if p.Default != nil {
+		return fmt.Sprintf("%s = %s", p.Name.String(), p.Default.String())
+	}
+	return p.Name.String()
+}
+
+// Una función con nombre es una sentencia; sin nombre es una expresión
+// (función anónima) que se puede asignar, pasar como argumento o retornar.
+func (f *FunctionLiteral) statementNode()  {}
+func (f *FunctionLiteral) expressionNode() {}
 func (f *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
 	out.WriteString("Func")
-	out.WriteString(f.Name.String())
+	if f.Name != nil {
+		out.WriteString(" " + f.Name.String())
+	}
 	out.WriteString("(")
 
 	if len(f.Parameters) > 0 {
@@ -0,0 +1,20 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+	"fmt"
+)
+
+// IifExp es una expresión condicional que solo evalúa la rama elegida.
+// Se obtiene tanto de Iif(cond, a, b) como de la forma en línea: a if cond else b
+type IifExp struct {
+	Token       token.Token
+	Condition   Expression
+	Consequence Expression
+	Alternative Expression
+}
+
+func (i *IifExp) expressionNode() {}
+func (i *IifExp) String() string {
+	return fmt.Sprintf("iif(%s, %s, %s)", i.Condition.String(), i.Consequence.String(), i.Alternative.String())
+}
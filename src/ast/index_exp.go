@@ -0,0 +1,17 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+	"fmt"
+)
+
+type IndexExp struct {
+	Token token.Token
+	Left  Expression
+	Index Expression
+}
+
+func (i *IndexExp) expressionNode() {}
+func (i *IndexExp) String() string {
+	return fmt.Sprintf("%s[%s]", i.Left.String(), i.Index.String())
+}
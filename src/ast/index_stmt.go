@@ -0,0 +1,118 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+	"bytes"
+	"strings"
+)
+
+// Comandos para trabajar con índices.
+
+// IndexStmt => Index On Upper(nombre) Tag nombre [For cond] [Descending] [Unique | Candidate]
+// Source y ForSource guardan el texto de las expresiones, que se graba en
+// el archivo de índice.
+type IndexStmt struct {
+	Token      token.Token
+	Expr       Expression
+	Source     string
+	Tag        string
+	For        Expression
+	ForSource  string
+	Descending bool
+	Unique     bool
+	Candidate  bool
+}
+
+func (i *IndexStmt) statementNode() {}
+func (i *IndexStmt) String() string {
+	var out bytes.Buffer
+	out.WriteString("index on " + i.Source + " tag " + i.Tag)
+	if i.For != nil {
+		out.WriteString(" for " + i.ForSource)
+	}
+	if i.Descending {
+		out.WriteString(" descending")
+	}
+	if i.Unique {
+		out.WriteString(" unique")
+	}
+	if i.Candidate {
+		out.WriteString(" candidate")
+	}
+	return out.String()
+}
+
+// ReindexStmt => Reindex
+type ReindexStmt struct {
+	Token token.Token
+}
+
+func (r *ReindexStmt) statementNode() {}
+func (r *ReindexStmt) String() string {
+	return "reindex"
+}
+
+// DeleteTagStmt => Delete Tag nombre, ciudad | Delete Tag All
+type DeleteTagStmt struct {
+	Token token.Token
+	Names []string
+	All   bool
+}
+
+func (d *DeleteTagStmt) statementNode() {}
+func (d *DeleteTagStmt) String() string {
+	if d.All {
+		return "delete tag all"
+	}
+	return "delete tag " + strings.Join(d.Names, ", ")
+}
+
+// SetOrderStmt => Set Order To [Tag] nombre | 2 | 0 [In cli] [Ascending | Descending]
+// Order es nil cuando se vuelve al orden físico.
+type SetOrderStmt struct {
+	Token      token.Token
+	Order      Expression
+	In         Expression
+	Descending bool
+	Ascending  bool
+}
+
+func (s *SetOrderStmt) statementNode() {}
+func (s *SetOrderStmt) String() string {
+	var out bytes.Buffer
+	out.WriteString("set order to")
+	if s.Order != nil {
+		out.WriteString(" tag " + s.Order.String())
+	}
+	if s.In != nil {
+		out.WriteString(" in " + s.In.String())
+	}
+	if s.Descending {
+		out.WriteString(" descending")
+	}
+	if s.Ascending {
+		out.WriteString(" ascending")
+	}
+	return out.String()
+}
+
+// SeekStmt => Seek "GARCIA" [Order [Tag] nombre] [In cli]
+type SeekStmt struct {
+	Token token.Token
+	Value Expression
+	Order Expression
+	In    Expression
+}
+
+func (s *SeekStmt) statementNode() {}
+func (s *SeekStmt) String() string {
+	var out bytes.Buffer
+	out.WriteString("seek " + s.Value.String())
+	if s.Order != nil {
+		out.WriteString(" order tag " + s.Order.String())
+	}
+	if s.In != nil {
+		out.WriteString(" in " + s.In.String())
+	}
+	return out.String()
+}
@@ -0,0 +1,75 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+	"strings"
+)
+
+// Comandos para administrar las variables de memoria.
+
+// ReleaseStmt => Release lnTotal, lcNombre | Release All [Like l* | Except g*]
+type ReleaseStmt struct {
+	Token    token.Token
+	Names    []string
+	All      bool
+	Skeleton string
+	Except   bool
+}
+
+func (r *ReleaseStmt) statementNode() {}
+func (r *ReleaseStmt) String() string {
+	if r.All {
+		return "release all like " + r.Skeleton
+	}
+	return "release " + strings.Join(r.Names, ", ")
+}
+
+// ClearMemoryStmt => Clear Memory
+type ClearMemoryStmt struct {
+	Token token.Token
+}
+
+func (c *ClearMemoryStmt) statementNode() {}
+func (c *ClearMemoryStmt) String() string {
+	return "clear memory"
+}
+
+// ListMemoryStmt => List Memory [Like l* | Except g*] | Display Memory
+type ListMemoryStmt struct {
+	Token    token.Token
+	Skeleton string
+	Except   bool
+}
+
+func (l *ListMemoryStmt) statementNode() {}
+func (l *ListMemoryStmt) String() string {
+	return "list memory like " + l.Skeleton
+}
+
+// SaveStmt => Save To vars.mem [All Like l* | All Except g*]
+type SaveStmt struct {
+	Token    token.Token
+	File     Expression
+	Skeleton string
+	Except   bool
+}
+
+func (s *SaveStmt) statementNode() {}
+func (s *SaveStmt) String() string {
+	return "save to " + s.File.String()
+}
+
+// RestoreStmt => Restore From vars.mem [Additive]
+type RestoreStmt struct {
+	Token    token.Token
+	File     Expression
+	Additive bool
+}
+
+func (r *RestoreStmt) statementNode() {}
+func (r *RestoreStmt) String() string {
+	if r.Additive {
+		return "restore from " + r.File.String() + " additive"
+	}
+	return "restore from " + r.File.String()
+}
@@ -0,0 +1,107 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+	"bytes"
+	"strings"
+)
+
+// Comandos para recorrer y filtrar los registros.
+
+// ScanStmt => Scan [For cond] [While cond] ... [EndScan]
+type ScanStmt struct {
+	Token token.Token
+	For   Expression
+	While Expression
+	Body  *BlockStmt
+}
+
+func (s *ScanStmt) statementNode() {}
+func (s *ScanStmt) String() string {
+	return "scan" + conditions(s.For, s.While)
+}
+
+// LocateStmt => Locate [For cond] [While cond]
+type LocateStmt struct {
+	Token token.Token
+	For   Expression
+	While Expression
+}
+
+func (l *LocateStmt) statementNode() {}
+func (l *LocateStmt) String() string {
+	return "locate" + conditions(l.For, l.While)
+}
+
+// ContinueStmt => Continue
+type ContinueStmt struct {
+	Token token.Token
+}
+
+func (c *ContinueStmt) statementNode() {}
+func (c *ContinueStmt) String() string {
+	return "continue"
+}
+
+// SetFilterStmt => Set Filter To [cond] [In cli]
+// Cond es nil cuando se quita el filtro.
+type SetFilterStmt struct {
+	Token token.Token
+	Cond  Expression
+	In    Expression
+}
+
+func (s *SetFilterStmt) statementNode() {}
+func (s *SetFilterStmt) String() string {
+	var out bytes.Buffer
+	out.WriteString("set filter to")
+	if s.Cond != nil {
+		out.WriteString(" " + s.Cond.String())
+	}
+	if s.In != nil {
+		out.WriteString(" in " + s.In.String())
+	}
+	return out.String()
+}
+
+// RelationDef es cada relación de Set Relation: expr Into alias
+type RelationDef struct {
+	Expr Expression
+	Into Expression
+}
+
+// SetRelationStmt => Set Relation To [expr Into alias, ...] [Additive] | Set Relation Off Into alias
+type SetRelationStmt struct {
+	Token     token.Token
+	Relations []*RelationDef
+	Additive  bool
+	Off       bool // Set Relation Off Into alias: Relations tiene solo Into
+}
+
+func (s *SetRelationStmt) statementNode() {}
+func (s *SetRelationStmt) String() string {
+	if s.Off {
+		return "set relation off into " + s.Relations[0].Into.String()
+	}
+	items := make([]string, len(s.Relations))
+	for i, rel := range s.Relations {
+		items[i] = rel.Expr.String() + " into " + rel.Into.String()
+	}
+	out := "set relation to " + strings.Join(items, ", ")
+	if s.Additive {
+		out += " additive"
+	}
+	return out
+}
+
+// conditions devuelve las cláusulas For y While de un comando.
+func conditions(forCond Expression, whileCond Expression) string {
+	var out bytes.Buffer
+	if forCond != nil {
+		out.WriteString(" for " + forCond.String())
+	}
+	if whileCond != nil {
+		out.WriteString(" while " + whileCond.String())
+	}
+	return out.String()
+}
@@ -0,0 +1,27 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+	"bytes"
+	"strings"
+)
+
+// ParametersStmt declara los parámetros de una rutina dentro de su cuerpo:
+// LParameters tcNombre, tnEdad (locales) | Parameters tcNombre (privados)
+type ParametersStmt struct {
+	Token token.Token
+	Scope byte
+	Names []*Literal
+}
+
+func (p *ParametersStmt) statementNode() {}
+func (p *ParametersStmt) String() string {
+	var out bytes.Buffer
+	var names []string
+	for _, name := range p.Names {
+		names = append(names, name.String())
+	}
+	out.WriteString(p.Token.Literal + " ")
+	out.WriteString(strings.Join(names, ", "))
+	return out.String()
+}
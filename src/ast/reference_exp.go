@@ -0,0 +1,14 @@
+package ast
+
+import "FoxLite/src/token"
+
+// ReferenceExp es un argumento pasado por referencia: Calcular(@lnTotal)
+type ReferenceExp struct {
+	Token token.Token
+	Name  *Literal
+}
+
+func (r *ReferenceExp) expressionNode() {}
+func (r *ReferenceExp) String() string {
+	return "@" + r.Name.String()
+}
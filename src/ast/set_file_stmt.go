@@ -0,0 +1,30 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+	"bytes"
+	"strings"
+)
+
+// SetFileStmt representa los comandos SET que reciben una lista de archivos
+// o carpetas: SET PROCEDURE TO lib.flp, util.flp [ADDITIVE] | SET PATH TO datos
+type SetFileStmt struct {
+	Token    token.Token
+	Name     string
+	Files    []Expression
+	Additive bool
+}
+
+func (s *SetFileStmt) statementNode() {}
+func (s *SetFileStmt) String() string {
+	var out bytes.Buffer
+	var files []string
+	for _, file := range s.Files {
+		files = append(files, file.String())
+	}
+	out.WriteString("set " + s.Name + " to " + strings.Join(files, ", "))
+	if s.Additive {
+		out.WriteString(" additive")
+	}
+	return out.String()
+}
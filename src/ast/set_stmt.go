@@ -0,0 +1,42 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+	"fmt"
+)
+
+// SetStmt representa los comandos de configuración:
+// SET EXACT ON | SET EXACT OFF | SET COLLATE TO "GENERAL"
+// Value es nil cuando se restablece el valor por defecto: SET PATH TO
+type SetStmt struct {
+	Token   token.Token
+	Name    string
+	Value   Expression
+	Seconds bool // SET REPROCESS TO 5 SECONDS
+}
+
+func (s *SetStmt) statementNode() {}
+func (s *SetStmt) String() string {
+	if s.Value == nil {
+		return fmt.Sprintf("set %s to", s.Name)
+	}
+	if s.Seconds {
+		return fmt.Sprintf("set %s to %s seconds", s.Name, s.Value.String())
+	}
+	return fmt.Sprintf("set %s to %s", s.Name, s.Value.String())
+}
+
+// PushSetStmt guarda (PUSH SET) o restablece (POP SET) el valor de todos los
+// comandos SET.
+type PushSetStmt struct {
+	Token token.Token
+	Pop   bool
+}
+
+func (p *PushSetStmt) statementNode() {}
+func (p *PushSetStmt) String() string {
+	if p.Pop {
+		return "pop set"
+	}
+	return "push set"
+}
@@ -0,0 +1,411 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Consultas SQL sobre las tablas locales.
+
+// SqlSelectStmt => Select ... From ... [Into Cursor | Array | Table nombre]
+// Sin Into el resultado se muestra por pantalla.
+type SqlSelectStmt struct {
+	Token token.Token
+	Query *SqlQuery
+	Into  *SqlInto
+}
+
+func (s *SqlSelectStmt) statementNode() {}
+func (s *SqlSelectStmt) String() string {
+	if s.Into == nil {
+		return s.Query.String()
+	}
+	return s.Query.String() + " " + s.Into.String()
+}
+
+// SqlQuery es una consulta SELECT, ya sea la de un comando o una
+// subconsulta.
+type SqlQuery struct {
+	Token    token.Token
+	Distinct bool
+	Top      Expression // Top n [Percent]
+	Percent  bool
+	Columns  []*SqlColumn
+	From     []*SqlTable
+	Where    Expression
+	GroupBy  []Expression
+	Having   Expression
+	OrderBy  []*SqlOrder
+}
+
+func (q *SqlQuery) String() string {
+	var out bytes.Buffer
+	out.WriteString("select ")
+	if q.Distinct {
+		out.WriteString("distinct ")
+	}
+	if q.Top != nil {
+		out.WriteString("top " + q.Top.String() + " ")
+		if q.Percent {
+			out.WriteString("percent ")
+		}
+	}
+	columns := make([]string, len(q.Columns))
+	for i, c := range q.Columns {
+		columns[i] = c.String()
+	}
+	out.WriteString(strings.Join(columns, ", "))
+	out.WriteString(" from")
+	for i, t := range q.From {
+		if i > 0 && t.Join == "" {
+			out.WriteString(",")
+		}
+		out.WriteString(" " + t.String())
+	}
+	if q.Where != nil {
+		out.WriteString(" where " + q.Where.String())
+	}
+	if len(q.GroupBy) > 0 {
+		groups := make([]string, len(q.GroupBy))
+		for i, g := range q.GroupBy {
+			groups[i] = g.String()
+		}
+		out.WriteString(" group by " + strings.Join(groups, ", "))
+	}
+	if q.Having != nil {
+		out.WriteString(" having " + q.Having.String())
+	}
+	if len(q.OrderBy) > 0 {
+		orders := make([]string, len(q.OrderBy))
+		for i, o := range q.OrderBy {
+			orders[i] = o.Expr.String()
+			if o.Descending {
+				orders[i] += " desc"
+			}
+		}
+		out.WriteString(" order by " + strings.Join(orders, ", "))
+	}
+	return out.String()
+}
+
+// SqlColumn es una columna del resultado: expr [As nombre], * o alias.*
+type SqlColumn struct {
+	Expr   Expression // nil en * y alias.*
+	Alias  string     // nombre de la columna (As)
+	Star   bool
+	Source string // alias de alias.*
+}
+
+func (c *SqlColumn) String() string {
+	switch {
+	case c.Star && c.Source != "":
+		return c.Source + ".*"
+	case c.Star:
+		return "*"
+	case c.Alias != "":
+		return c.Expr.String() + " as " + c.Alias
+	}
+	return c.Expr.String()
+}
+
+// SqlTable es una tabla de la cláusula From. Join es "" para la primera
+// tabla y las separadas por comas, o "inner", "left", "right" o "full".
+type SqlTable struct {
+	Name  Expression
+	Alias string
+	Join  string
+	On    Expression
+}
+
+func (t *SqlTable) String() string {
+	var out bytes.Buffer
+	if t.Join != "" {
+		out.WriteString(t.Join + " join ")
+	}
+	out.WriteString(t.Name.String())
+	if t.Alias != "" {
+		out.WriteString(" " + t.Alias)
+	}
+	if t.On != nil {
+		out.WriteString(" on " + t.On.String())
+	}
+	return out.String()
+}
+
+// SqlOrder es un criterio de Order By: número o nombre de columna o
+// expresión, [Asc | Desc].
+type SqlOrder struct {
+	Expr       Expression
+	Descending bool
+}
+
+// SqlInto es el destino del resultado: Into Cursor nombre [ReadWrite],
+// Into Array nombre o Into Table nombre.
+type SqlInto struct {
+	Kind      string // "cursor", "array" o "table"
+	Name      Expression
+	ReadWrite bool
+}
+
+func (i *SqlInto) String() string {
+	out := fmt.Sprintf("into %s %s", i.Kind, i.Name.String())
+	if i.ReadWrite {
+		out += " readwrite"
+	}
+	return out
+}
+
+// Expresiones que solo se admiten dentro de una consulta SQL.
+
+// SqlAggregateExp => Count(*) | Count([Distinct] expr) | Sum(expr) |
+// Avg(expr) | Min(expr) | Max(expr)
+type SqlAggregateExp struct {
+	Token    token.Token
+	Func     string // nombre de la función en minúsculas
+	Arg      Expression
+	Distinct bool
+}
+
+func (a *SqlAggregateExp) expressionNode() {}
+func (a *SqlAggregateExp) String() string {
+	switch {
+	case a.Arg == nil && a.Func == "cnt":
+		return "cnt()"
+	case a.Arg == nil:
+		return a.Func + "(*)"
+	case a.Distinct:
+		return fmt.Sprintf("%s(distinct %s)", a.Func, a.Arg.String())
+	}
+	return fmt.Sprintf("%s(%s)", a.Func, a.Arg.String())
+}
+
+// SqlSubqueryExp => (Select ...) como valor: la primera columna de la
+// primera fila.
+type SqlSubqueryExp struct {
+	Token token.Token
+	Query *SqlQuery
+}
+
+func (s *SqlSubqueryExp) expressionNode() {}
+func (s *SqlSubqueryExp) String() string {
+	return "(" + s.Query.String() + ")"
+}
+
+// SqlInExp => expr [Not] In (a, b, c) | expr [Not] In (Select ...)
+type SqlInExp struct {
+	Token token.Token
+	Left  Expression
+	List  []Expression
+	Query *SqlQuery
+	Not   bool
+}
+
+func (i *SqlInExp) expressionNode() {}
+func (i *SqlInExp) String() string {
+	var list string
+	if i.Query != nil {
+		list = i.Query.String()
+	} else {
+		items := make([]string, len(i.List))
+		for idx, item := range i.List {
+			items[idx] = item.String()
+		}
+		list = strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s%s in (%s)", i.Left.String(), not(i.Not), list)
+}
+
+// SqlBetweenExp => expr [Not] Between a And b
+type SqlBetweenExp struct {
+	Token token.Token
+	Left  Expression
+	Low   Expression
+	High  Expression
+	Not   bool
+}
+
+func (b *SqlBetweenExp) expressionNode() {}
+func (b *SqlBetweenExp) String() string {
+	return fmt.Sprintf("%s%s between %s and %s", b.Left.String(), not(b.Not), b.Low.String(), b.High.String())
+}
+
+// SqlLikeExp => expr [Not] Like "patrón" con los comodines % y _
+type SqlLikeExp struct {
+	Token   token.Token
+	Left    Expression
+	Pattern Expression
+	Not     bool
+}
+
+func (l *SqlLikeExp) expressionNode() {}
+func (l *SqlLikeExp) String() string {
+	return fmt.Sprintf("%s%s like %s", l.Left.String(), not(l.Not), l.Pattern.String())
+}
+
+// SqlIsNullExp => expr Is [Not] Null
+type SqlIsNullExp struct {
+	Token token.Token
+	Left  Expression
+	Not   bool
+}
+
+func (i *SqlIsNullExp) expressionNode() {}
+func (i *SqlIsNullExp) String() string {
+	if i.Not {
+		return i.Left.String() + " is not null"
+	}
+	return i.Left.String() + " is null"
+}
+
+// SqlExistsExp => [Not] Exists (Select ...)
+type SqlExistsExp struct {
+	Token token.Token
+	Query *SqlQuery
+}
+
+func (e *SqlExistsExp) expressionNode() {}
+func (e *SqlExistsExp) String() string {
+	return "exists (" + e.Query.String() + ")"
+}
+
+// SqlNotExp => Not cond
+type SqlNotExp struct {
+	Token token.Token
+	Right Expression
+}
+
+func (n *SqlNotExp) expressionNode() {}
+func (n *SqlNotExp) String() string {
+	return "not " + n.Right.String()
+}
+
+func not(negated bool) string {
+	if negated {
+		return " not"
+	}
+	return ""
+}
+
+// Comandos SQL que modifican los datos y la estructura de las tablas.
+
+// SqlInsertStmt => Insert Into t [(campos)] Values (valores) |
+// Insert Into t From Array arr | From Memvar | From Name obj
+type SqlInsertStmt struct {
+	Token   token.Token
+	Table   Expression
+	Columns []string
+	Values  []Expression
+	From    string // "", "array", "memvar" o "name"
+	Source  Expression
+}
+
+func (i *SqlInsertStmt) statementNode() {}
+func (i *SqlInsertStmt) String() string {
+	var out bytes.Buffer
+	out.WriteString("insert into " + i.Table.String())
+	if len(i.Columns) > 0 {
+		out.WriteString(" (" + strings.Join(i.Columns, ", ") + ")")
+	}
+	switch i.From {
+	case "":
+		values := make([]string, len(i.Values))
+		for idx, val := range i.Values {
+			values[idx] = val.String()
+		}
+		out.WriteString(" values (" + strings.Join(values, ", ") + ")")
+	case "memvar":
+		out.WriteString(" from memvar")
+	default:
+		out.WriteString(" from " + i.From + " " + i.Source.String())
+	}
+	return out.String()
+}
+
+// SqlAssign es una asignación de Update: campo = expr
+type SqlAssign struct {
+	Column string
+	Value  Expression
+}
+
+// SqlUpdateStmt => Update t Set campo = expr [, ...] [Where cond]
+type SqlUpdateStmt struct {
+	Token token.Token
+	Table Expression
+	Set   []*SqlAssign
+	Where Expression
+}
+
+func (u *SqlUpdateStmt) statementNode() {}
+func (u *SqlUpdateStmt) String() string {
+	set := make([]string, len(u.Set))
+	for i, assign := range u.Set {
+		set[i] = assign.Column + " = " + assign.Value.String()
+	}
+	out := "update " + u.Table.String() + " set " + strings.Join(set, ", ")
+	if u.Where != nil {
+		out += " where " + u.Where.String()
+	}
+	return out
+}
+
+// SqlDeleteStmt => Delete From t [Where cond]
+type SqlDeleteStmt struct {
+	Token token.Token
+	Table Expression
+	Where Expression
+}
+
+func (d *SqlDeleteStmt) statementNode() {}
+func (d *SqlDeleteStmt) String() string {
+	out := "delete from " + d.Table.String()
+	if d.Where != nil {
+		out += " where " + d.Where.String()
+	}
+	return out
+}
+
+// CreateTableStmt => Create Table clientes [Free] (id I, nombre C(20) Null)
+type CreateTableStmt struct {
+	Token  token.Token
+	Name   Expression
+	Free   bool // no se agrega a la base de datos actual
+	Fields []*FieldDef
+}
+
+func (c *CreateTableStmt) statementNode() {}
+func (c *CreateTableStmt) String() string {
+	fields := make([]string, len(c.Fields))
+	for i, f := range c.Fields {
+		fields[i] = f.String()
+	}
+	free := ""
+	if c.Free {
+		free = " free"
+	}
+	return fmt.Sprintf("create table %s%s (%s)", c.Name.String(), free, strings.Join(fields, ", "))
+}
+
+// AlterTableStmt => Alter Table t Add [Column] def | Alter [Column] def |
+// Drop [Column] campo | Rename [Column] campo To nuevo
+type AlterTableStmt struct {
+	Token   token.Token
+	Table   Expression
+	Action  string    // "add", "alter", "drop" o "rename"
+	Field   *FieldDef // Add y Alter
+	Column  string    // Drop y Rename
+	NewName string    // Rename
+}
+
+func (a *AlterTableStmt) statementNode() {}
+func (a *AlterTableStmt) String() string {
+	out := "alter table " + a.Table.String() + " " + a.Action + " column "
+	switch a.Action {
+	case "add", "alter":
+		return out + a.Field.String()
+	case "rename":
+		return out + a.Column + " to " + a.NewName
+	}
+	return out + a.Column
+}
@@ -0,0 +1,369 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// Comandos para trabajar con tablas.
+
+// UseStmt => Use clientes [In 2 | In cli] [Alias cli] [Exclusive | Shared] | Use [In cli]
+// File es nil cuando se cierra la tabla del área actual (o la indicada con In).
+type UseStmt struct {
+	Token     token.Token
+	File      Expression
+	In        Expression // área de trabajo o alias donde se abre la tabla
+	Alias     string
+	Exclusive bool
+	Shared    bool
+}
+
+func (u *UseStmt) statementNode() {}
+func (u *UseStmt) String() string {
+	var out bytes.Buffer
+	out.WriteString("use")
+	if u.File != nil {
+		out.WriteString(" " + u.File.String())
+	}
+	if u.In != nil {
+		out.WriteString(" in " + u.In.String())
+	}
+	if u.Alias != "" {
+		out.WriteString(" alias " + u.Alias)
+	}
+	if u.Exclusive {
+		out.WriteString(" exclusive")
+	}
+	if u.Shared {
+		out.WriteString(" shared")
+	}
+	return out.String()
+}
+
+// AppendBlankStmt => Append Blank
+type AppendBlankStmt struct {
+	Token token.Token
+}
+
+func (a *AppendBlankStmt) statementNode() {}
+func (a *AppendBlankStmt) String() string {
+	return "append blank"
+}
+
+// Replacement es cada asignación de Replace: nombre With "Ana" [Additive]
+type Replacement struct {
+	Field    string // campo, opcionalmente con su alias: cli.nombre
+	Value    Expression
+	Additive bool
+}
+
+// AppendMemoStmt => Append Memo notas From notas.txt [Overwrite]
+type AppendMemoStmt struct {
+	Token     token.Token
+	Field     string
+	File      Expression
+	Overwrite bool
+}
+
+func (a *AppendMemoStmt) statementNode() {}
+func (a *AppendMemoStmt) String() string {
+	text := "append memo " + a.Field + " from " + a.File.String()
+	if a.Overwrite {
+		text += " overwrite"
+	}
+	return text
+}
+
+// CopyMemoStmt => Copy Memo notas To notas.txt [Additive]
+type CopyMemoStmt struct {
+	Token    token.Token
+	Field    string
+	File     Expression
+	Additive bool
+}
+
+func (c *CopyMemoStmt) statementNode() {}
+func (c *CopyMemoStmt) String() string {
+	text := "copy memo " + c.Field + " to " + c.File.String()
+	if c.Additive {
+		text += " additive"
+	}
+	return text
+}
+
+// TextFormat es el formato del archivo de texto de Copy To y Append From:
+// [Type] Csv | Sdf | Delimited [With delimitador | With Blank | With Tab |
+// With Character separador] [As nCodePage]
+type TextFormat struct {
+	Type      string // "csv", "sdf" o "delimited"
+	Delimiter *string
+	Separator string
+	CodePage  Expression
+}
+
+func (t *TextFormat) String() string {
+	var out bytes.Buffer
+	out.WriteString(" type " + t.Type)
+	if t.Delimiter != nil {
+		out.WriteString(" with " + strconv.Quote(*t.Delimiter))
+	}
+	switch t.Separator {
+	case "":
+	case " ":
+		out.WriteString(" with blank")
+	case "\t":
+		out.WriteString(" with tab")
+	default:
+		out.WriteString(" with character " + strconv.Quote(t.Separator))
+	}
+	if t.CodePage != nil {
+		out.WriteString(" as " + t.CodePage.String())
+	}
+	return out.String()
+}
+
+// CopyToStmt => Copy To clientes.csv [Fields campos | Fields Like patrón
+// | Fields Except patrón] [alcance] [For cond] [While cond] Type Csv
+type CopyToStmt struct {
+	Token  token.Token
+	File   Expression
+	Filter FieldFilter
+	Scope  *Scope
+	For    Expression
+	While  Expression
+	Format TextFormat
+}
+
+func (c *CopyToStmt) statementNode() {}
+func (c *CopyToStmt) String() string {
+	return "copy to " + c.File.String() + c.Filter.String() + scopeClauses(c.Scope, c.For, c.While) + c.Format.String()
+}
+
+// AppendFromStmt => Append From clientes.csv [Fields campos] [For cond]
+// Type Csv
+type AppendFromStmt struct {
+	Token  token.Token
+	File   Expression
+	Fields []string
+	For    Expression
+	Format TextFormat
+}
+
+func (a *AppendFromStmt) statementNode() {}
+func (a *AppendFromStmt) String() string {
+	text := "append from " + a.File.String()
+	if len(a.Fields) > 0 {
+		text += " fields " + strings.Join(a.Fields, ", ")
+	}
+	return text + conditions(a.For, nil) + a.Format.String()
+}
+
+// ReplaceStmt => Replace nombre With "Ana", saldo With saldo + 10
+// [alcance] [For cond] [While cond]
+type ReplaceStmt struct {
+	Token        token.Token
+	Replacements []*Replacement
+	Scope        *Scope
+	For          Expression
+	While        Expression
+}
+
+func (r *ReplaceStmt) statementNode() {}
+func (r *ReplaceStmt) String() string {
+	var items []string
+	for _, item := range r.Replacements {
+		text := item.Field + " with " + item.Value.String()
+		if item.Additive {
+			text += " additive"
+		}
+		items = append(items, text)
+	}
+	return "replace " + strings.Join(items, ", ") + scopeClauses(r.Scope, r.For, r.While)
+}
+
+// DeleteStmt => Delete | Recall [alcance] [For cond] [While cond]
+type DeleteStmt struct {
+	Token  token.Token
+	Recall bool
+	Scope  *Scope
+	For    Expression
+	While  Expression
+}
+
+func (d *DeleteStmt) statementNode() {}
+func (d *DeleteStmt) String() string {
+	command := "delete"
+	if d.Recall {
+		command = "recall"
+	}
+	return command + scopeClauses(d.Scope, d.For, d.While)
+}
+
+// PackStmt => Pack | Zap
+type PackStmt struct {
+	Token token.Token
+	Zap   bool
+}
+
+func (p *PackStmt) statementNode() {}
+func (p *PackStmt) String() string {
+	if p.Zap {
+		return "zap"
+	}
+	return "pack"
+}
+
+// GoStmt => Go Top | Go Bottom | Go 10 | Goto lnRegistro
+// Where es "top", "bottom" o "" cuando se indica el número de registro.
+type GoStmt struct {
+	Token  token.Token
+	Where  string
+	Record Expression
+}
+
+func (g *GoStmt) statementNode() {}
+func (g *GoStmt) String() string {
+	if g.Record != nil {
+		return "go " + g.Record.String()
+	}
+	return "go " + g.Where
+}
+
+// SkipStmt => Skip [n]
+type SkipStmt struct {
+	Token token.Token
+	Count Expression
+}
+
+func (s *SkipStmt) statementNode() {}
+func (s *SkipStmt) String() string {
+	if s.Count == nil {
+		return "skip"
+	}
+	return "skip " + s.Count.String()
+}
+
+// FieldFilter son las cláusulas que eligen los campos de Scatter y Gather:
+// [Fields campos | Fields [Like patrón] [Except patrón]] [Memo]
+type FieldFilter struct {
+	Fields []string
+	Like   string
+	Except string
+	Memo   bool
+}
+
+func (f *FieldFilter) String() string {
+	var out bytes.Buffer
+	if len(f.Fields) > 0 {
+		out.WriteString(" fields " + strings.Join(f.Fields, ", "))
+	}
+	if f.Like != "" {
+		out.WriteString(" fields like " + f.Like)
+	}
+	if f.Except != "" {
+		if f.Like == "" {
+			out.WriteString(" fields")
+		}
+		out.WriteString(" except " + f.Except)
+	}
+	if f.Memo {
+		out.WriteString(" memo")
+	}
+	return out.String()
+}
+
+// ScatterStmt => Scatter [filtro] [Blank] To arr | Memvar | Name obj [Additive]
+// Copia los campos del registro actual en un array, en variables de memoria
+// o en las propiedades de un objeto.
+type ScatterStmt struct {
+	Token    token.Token
+	Filter   FieldFilter
+	Blank    bool
+	Target   string // "array", "memvar" o "name"
+	Name     string
+	Additive bool
+}
+
+func (s *ScatterStmt) statementNode() {}
+func (s *ScatterStmt) String() string {
+	var out bytes.Buffer
+	out.WriteString("scatter" + s.Filter.String())
+	if s.Blank {
+		out.WriteString(" blank")
+	}
+	switch s.Target {
+	case "memvar":
+		out.WriteString(" memvar")
+	case "name":
+		out.WriteString(" name " + s.Name)
+		if s.Additive {
+			out.WriteString(" additive")
+		}
+	default:
+		out.WriteString(" to " + s.Name)
+	}
+	return out.String()
+}
+
+// GatherStmt => Gather From arr | Memvar | Name obj [filtro]
+// Copia los valores en los campos del registro actual.
+type GatherStmt struct {
+	Token  token.Token
+	Source string // "array", "memvar" o "name"
+	Name   string
+	Filter FieldFilter
+}
+
+func (g *GatherStmt) statementNode() {}
+func (g *GatherStmt) String() string {
+	switch g.Source {
+	case "memvar":
+		return "gather memvar" + g.Filter.String()
+	case "name":
+		return "gather name " + g.Name + g.Filter.String()
+	}
+	return "gather from " + g.Name + g.Filter.String()
+}
+
+// TransactionStmt => Begin Transaction | End Transaction | Rollback
+// Action es "begin", "end" o "rollback".
+type TransactionStmt struct {
+	Token  token.Token
+	Action string
+}
+
+func (t *TransactionStmt) statementNode() {}
+func (t *TransactionStmt) String() string {
+	if t.Action == "rollback" {
+		return "rollback"
+	}
+	return t.Action + " transaction"
+}
+
+// UnlockStmt => Unlock [Record n] [In cli] [All]
+// Sin Record libera todos los bloqueos de la tabla; con All los de todas
+// las tablas abiertas.
+type UnlockStmt struct {
+	Token  token.Token
+	Record Expression
+	In     Expression
+	All    bool
+}
+
+func (u *UnlockStmt) statementNode() {}
+func (u *UnlockStmt) String() string {
+	var out bytes.Buffer
+	out.WriteString("unlock")
+	if u.Record != nil {
+		out.WriteString(" record " + u.Record.String())
+	}
+	if u.In != nil {
+		out.WriteString(" in " + u.In.String())
+	}
+	if u.All {
+		out.WriteString(" all")
+	}
+	return out.String()
+}
@@ -16,5 +16,24 @@ type VarStmt struct {
 func (v *VarStmt) statementNode() {}
 
 func (v *VarStmt) String() string {
+	if v.Value == nil {
+		return v.Name
+	}
 	return fmt.Sprintf("%s = %s", v.Name, v.Value.String())
 }
+
+// PrivateAllStmt oculta las variables de las rutinas que llaman a la actual:
+// Private All | Private All Like l* | Private All Except g*
+type PrivateAllStmt struct {
+	Token    token.Token
+	Skeleton string
+	Except   bool
+}
+
+func (p *PrivateAllStmt) statementNode() {}
+func (p *PrivateAllStmt) String() string {
+	if p.Except {
+		return "private all except " + p.Skeleton
+	}
+	return "private all like " + p.Skeleton
+}
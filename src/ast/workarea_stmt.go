@@ -0,0 +1,81 @@
+package ast
+
+import (
+	"FoxLite/src/token"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Comandos para administrar las áreas de trabajo.
+
+// SelectStmt => Select 2 | Select cli | Select 0 | Select (lcAlias)
+type SelectStmt struct {
+	Token token.Token
+	Area  Expression // número de área o alias
+}
+
+func (s *SelectStmt) statementNode() {}
+func (s *SelectStmt) String() string {
+	return "select " + s.Area.String()
+}
+
+// FieldDef es la definición de un campo: nombre C(20) [Null | Not Null]
+type FieldDef struct {
+	Name     string
+	Type     byte
+	Length   int
+	Decimals int
+	Null     bool
+	NotNull  bool
+}
+
+func (f *FieldDef) String() string {
+	var out bytes.Buffer
+	out.WriteString(fmt.Sprintf("%s %c", f.Name, f.Type))
+	if f.Length > 0 {
+		out.WriteString(fmt.Sprintf("(%d", f.Length))
+		if f.Decimals > 0 {
+			out.WriteString(fmt.Sprintf(", %d", f.Decimals))
+		}
+		out.WriteString(")")
+	}
+	if f.Null {
+		out.WriteString(" null")
+	}
+	if f.NotNull {
+		out.WriteString(" not null")
+	}
+	return out.String()
+}
+
+// CreateCursorStmt => Create Cursor tmp (id I, nombre C(20), saldo N(10, 2))
+type CreateCursorStmt struct {
+	Token  token.Token
+	Name   Expression
+	Fields []*FieldDef
+}
+
+func (c *CreateCursorStmt) statementNode() {}
+func (c *CreateCursorStmt) String() string {
+	fields := make([]string, len(c.Fields))
+	for i, f := range c.Fields {
+		fields[i] = f.String()
+	}
+	return fmt.Sprintf("create cursor %s (%s)", c.Name.String(), strings.Join(fields, ", "))
+}
+
+// CloseStmt => Close Tables [All] | Close Databases [All] | Close All
+type CloseStmt struct {
+	Token token.Token
+	What  string // "tables", "databases" o "all"
+	All   bool
+}
+
+func (c *CloseStmt) statementNode() {}
+func (c *CloseStmt) String() string {
+	if c.All && c.What != "all" {
+		return "close " + c.What + " all"
+	}
+	return "close " + c.What
+}
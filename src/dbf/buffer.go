@@ -0,0 +1,287 @@
+package dbf
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+)
+
+// Buffer de registros (CURSORSETPROP("Buffering")).
+//
+// Con el buffer activo los registros modificados (WriteRecord) y los
+// agregados (AppendBlank) quedan en memoria hasta que se guardan con Flush
+// o se descartan con Revert. Por cada registro modificado se guarda además
+// una copia de cómo estaba en disco al modificarlo por primera vez, que es
+// lo que devuelve Original. Los registros agregados se numeran a
+// continuación de los del archivo. Los memos que se modifican con el buffer
+// activo se escriben siempre en bloques nuevos para no alterar los que usa
+// el registro en disco.
+
+// Estados de un campo o de la marca de borrado (GETFLDSTATE()).
+const (
+	FieldUnchanged        = 1
+	FieldModified         = 2
+	FieldAppended         = 3
+	FieldAppendedModified = 4
+)
+
+// ErrPendingChanges se devuelve al intentar una operación que necesita que
+// el buffer no tenga cambios sin guardar.
+var ErrPendingChanges = errors.New("table has uncommitted buffered changes")
+
+type buffer struct {
+	records map[int]*bufferedRecord
+	added   int // registros agregados que todavía no están en disco
+}
+
+type bufferedRecord struct {
+	orig []byte // nil en los registros agregados
+	cur  []byte
+}
+
+// SetBuffering activa o desactiva el buffer; no se puede desactivar con
+// cambios pendientes.
+func (t *Table) SetBuffering(on bool) error {
+	if !on {
+		if len(t.Modified()) > 0 {
+			return ErrPendingChanges
+		}
+		t.buffer = nil
+		return nil
+	}
+	if t.buffer == nil {
+		t.buffer = &buffer{records: map[int]*bufferedRecord{}}
+	}
+	return nil
+}
+
+// Buffered indica si el buffer está activo.
+func (t *Table) Buffered() bool {
+	return t.buffer != nil
+}
+
+// Modified devuelve en orden los números de los registros con cambios
+// pendientes.
+func (t *Table) Modified() []int {
+	if t.buffer == nil {
+		return nil
+	}
+	var recnos []int
+	for recno := range t.buffer.records {
+		recnos = append(recnos, recno)
+	}
+	sort.Ints(recnos)
+	return recnos
+}
+
+// Appended indica si recno es un registro agregado que todavía no está en
+// disco.
+func (t *Table) Appended(recno int) bool {
+	return recno > t.diskCount() && recno <= t.count
+}
+
+// diskCount devuelve la cantidad de registros guardados en el archivo.
+func (t *Table) diskCount() int {
+	if t.buffer == nil {
+		return t.count
+	}
+	return t.count - t.buffer.added
+}
+
+// bufferRecord guarda en el buffer una copia del registro.
+func (t *Table) bufferRecord(r *Record) error {
+	b := t.buffer.records[r.Recno]
+	if b == nil {
+		orig, err := t.readDisk(r.Recno)
+		if err != nil {
+			return err
+		}
+		b = &bufferedRecord{orig: orig}
+		t.buffer.records[r.Recno] = b
+	}
+	b.cur = append(b.cur[:0], r.buf...)
+	return nil
+}
+
+func (t *Table) readDisk(recno int) ([]byte, error) {
+	buf := make([]byte, t.recordLen)
+	if _, err := t.file.ReadAt(buf, t.recordOffset(recno)); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// Flush guarda en disco los cambios pendientes de los registros recnos (de
+// todos si no se indica ninguno). Los registros agregados se guardan en
+// orden: guardar uno guarda también los agregados anteriores. En una tabla
+// compartida los agregados pasan a continuación de los que hayan agregado
+// otros procesos.
+func (t *Table) Flush(recnos ...int) error {
+	if t.buffer == nil {
+		return nil
+	}
+	if len(recnos) == 0 {
+		recnos = t.Modified()
+	}
+	appending := false
+	for _, recno := range recnos {
+		appending = appending || t.Appended(recno)
+	}
+	disk := t.diskCount()
+	return t.update(appending, func() error {
+		if shift := t.diskCount() - disk; shift != 0 {
+			moved := make([]int, len(recnos))
+			for i, recno := range recnos {
+				if recno > disk {
+					recno += shift
+				}
+				moved[i] = recno
+			}
+			recnos = moved
+		}
+		return t.flush(recnos)
+	})
+}
+
+func (t *Table) flush(recnos []int) error {
+	disk := t.diskCount()
+	last := disk
+	for _, recno := range recnos {
+		if t.Appended(recno) && recno > last {
+			last = recno
+		}
+	}
+	for _, recno := range recnos {
+		b := t.buffer.records[recno]
+		if b == nil || recno > disk {
+			continue
+		}
+		if err := t.writeAt(b.cur, t.recordOffset(recno)); err != nil {
+			return err
+		}
+		delete(t.buffer.records, recno)
+	}
+	for recno := disk + 1; recno <= last; recno++ {
+		data := append(append([]byte{}, t.buffer.records[recno].cur...), eofMarker)
+		if err := t.writeAt(data, t.recordOffset(recno)); err != nil {
+			return err
+		}
+		delete(t.buffer.records, recno)
+		t.buffer.added--
+	}
+	return t.writeHeader(false)
+}
+
+// Revert descarta los cambios pendientes de los registros recnos (de todos
+// si no se indica ninguno) y devuelve los números de los registros
+// afectados. Descartar un registro agregado descarta también los agregados
+// posteriores.
+func (t *Table) Revert(recnos ...int) []int {
+	if t.buffer == nil {
+		return nil
+	}
+	if len(recnos) == 0 {
+		recnos = t.Modified()
+	}
+	first := t.count + 1
+	var reverted []int
+	for _, recno := range recnos {
+		if t.Appended(recno) {
+			if recno < first {
+				first = recno
+			}
+			continue
+		}
+		if _, ok := t.buffer.records[recno]; ok {
+			delete(t.buffer.records, recno)
+			reverted = append(reverted, recno)
+		}
+	}
+	for recno := first; recno <= t.count; recno++ {
+		delete(t.buffer.records, recno)
+		reverted = append(reverted, recno)
+	}
+	if first <= t.count {
+		t.buffer.added -= t.count - first + 1
+		t.count = first - 1
+	}
+	return reverted
+}
+
+// Conflict indica si el registro recno cambió en disco (por ejemplo, desde
+// otro programa) después de modificarlo en el buffer.
+func (t *Table) Conflict(recno int) (bool, error) {
+	if t.buffer == nil {
+		return false, nil
+	}
+	b := t.buffer.records[recno]
+	if b == nil || b.orig == nil {
+		return false, nil
+	}
+	disk, err := t.readDisk(recno)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(disk, b.orig), nil
+}
+
+// Original devuelve el registro tal como estaba en disco antes de
+// modificarlo en el buffer; nil si es un registro agregado.
+func (t *Table) Original(recno int) (*Record, error) {
+	if t.buffer != nil {
+		if b := t.buffer.records[recno]; b != nil && b.orig != nil {
+			return &Record{Recno: recno, table: t, buf: append([]byte{}, b.orig...)}, nil
+		}
+	}
+	return t.DiskRecord(recno)
+}
+
+// DiskRecord lee el registro recno del archivo sin tener en cuenta el
+// buffer; nil si el registro todavía no está en disco.
+func (t *Table) DiskRecord(recno int) (*Record, error) {
+	if recno > t.diskCount() {
+		return nil, nil
+	}
+	if recno < 1 {
+		return nil, errors.New("record is out of range")
+	}
+	buf, err := t.readDisk(recno)
+	if err != nil {
+		return nil, err
+	}
+	return &Record{Recno: recno, table: t, buf: buf}, nil
+}
+
+// FieldState devuelve el estado del campo idx de Fields del registro recno
+// en el buffer; con idx -1 el de la marca de borrado.
+func (t *Table) FieldState(recno int, idx int) int {
+	var b *bufferedRecord
+	if t.buffer != nil {
+		b = t.buffer.records[recno]
+	}
+	if b == nil {
+		return FieldUnchanged
+	}
+	orig := &Record{Recno: recno, table: t, buf: b.orig}
+	if b.orig == nil {
+		orig.buf = t.blankRecord()
+	}
+	cur := &Record{Recno: recno, table: t, buf: b.cur}
+	var changed bool
+	if idx < 0 {
+		changed = orig.buf[0] != cur.buf[0]
+	} else {
+		f := t.Fields[idx]
+		changed = orig.isNull(f) != cur.isNull(f) ||
+			!bytes.Equal(orig.buf[f.offset:f.offset+f.Length], cur.buf[f.offset:f.offset+f.Length])
+	}
+	switch {
+	case b.orig == nil && changed:
+		return FieldAppendedModified
+	case b.orig == nil:
+		return FieldAppended
+	case changed:
+		return FieldModified
+	}
+	return FieldUnchanged
+}
@@ -0,0 +1,157 @@
+package dbf
+
+import "unicode/utf8"
+
+// codePage traduce el texto de los campos entre la página de códigos de la
+// tabla y UTF-8. La marca de página de códigos está en el byte 29 de la
+// cabecera; las tablas sin marca se tratan como Windows-1252, que es la
+// página por defecto de Visual FoxPro.
+type codePage struct {
+	mark     byte
+	toUTF8   *[128]rune // caracteres 0x80-0xFF; nil si el texto se guarda tal cual
+	fromRune map[rune]byte
+}
+
+// Marcas de página de códigos más comunes.
+const (
+	CodePageDOS437  = 0x01
+	CodePageDOS850  = 0x02
+	CodePageWin1252 = 0x03
+)
+
+var win1252High = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+var win1252 = func() *[128]rune {
+	var table [128]rune
+	for i := range table {
+		if i < 32 {
+			table[i] = win1252High[i]
+		} else {
+			table[i] = rune(0x80 + i) // el resto coincide con Latin-1
+		}
+	}
+	return &table
+}()
+
+var dos437 = &[128]rune{
+	'Ç', 'ü', 'é', 'â', 'ä', 'à', 'å', 'ç', 'ê', 'ë', 'è', 'ï', 'î', 'ì', 'Ä', 'Å',
+	'É', 'æ', 'Æ', 'ô', 'ö', 'ò', 'û', 'ù', 'ÿ', 'Ö', 'Ü', '¢', '£', '¥', '₧', 'ƒ',
+	'á', 'í', 'ó', 'ú', 'ñ', 'Ñ', 'ª', 'º', '¿', '⌐', '¬', '½', '¼', '¡', '«', '»',
+	'░', '▒', '▓', '│', '┤', '╡', '╢', '╖', '╕', '╣', '║', '╗', '╝', '╜', '╛', '┐',
+	'└', '┴', '┬', '├', '─', '┼', '╞', '╟', '╚', '╔', '╩', '╦', '╠', '═', '╬', '╧',
+	'╨', '╤', '╥', '╙', '╘', '╒', '╓', '╫', '╪', '┘', '┌', '█', '▄', '▌', '▐', '▀',
+	'α', 'ß', 'Γ', 'π', 'Σ', 'σ', 'µ', 'τ', 'Φ', 'Θ', 'Ω', 'δ', '∞', 'φ', 'ε', '∩',
+	'≡', '±', '≥', '≤', '⌠', '⌡', '÷', '≈', '°', '∙', '·', '√', 'ⁿ', '²', '■', '\u00a0',
+}
+
+var dos850 = &[128]rune{
+	'Ç', 'ü', 'é', 'â', 'ä', 'à', 'å', 'ç', 'ê', 'ë', 'è', 'ï', 'î', 'ì', 'Ä', 'Å',
+	'É', 'æ', 'Æ', 'ô', 'ö', 'ò', 'û', 'ù', 'ÿ', 'Ö', 'Ü', 'ø', '£', 'Ø', '×', 'ƒ',
+	'á', 'í', 'ó', 'ú', 'ñ', 'Ñ', 'ª', 'º', '¿', '®', '¬', '½', '¼', '¡', '«', '»',
+	'░', '▒', '▓', '│', '┤', 'Á', 'Â', 'À', '©', '╣', '║', '╗', '╝', '¢', '¥', '┐',
+	'└', '┴', '┬', '├', '─', '┼', 'ã', 'Ã', '╚', '╔', '╩', '╦', '╠', '═', '╬', '¤',
+	'ð', 'Ð', 'Ê', 'Ë', 'È', 'ı', 'Í', 'Î', 'Ï', '┘', '┌', '█', '▄', '¦', 'Ì', '▀',
+	'Ó', 'ß', 'Ô', 'Ò', 'õ', 'Õ', 'µ', 'þ', 'Þ', 'Ú', 'Û', 'Ù', 'ý', 'Ý', '¯', '´',
+	'\u00ad', '±', '‗', '¾', '¶', '§', '÷', '¸', '°', '¨', '·', '¹', '³', '²', '■', '\u00a0',
+}
+
+var latin1 = func() *[128]rune {
+	var table [128]rune
+	for i := range table {
+		table[i] = rune(0x80 + i)
+	}
+	return &table
+}()
+
+func newCodePage(mark byte) *codePage {
+	if mark == 0 || mark == CodePageWin1252 {
+		return tableCodePage(mark, win1252)
+	}
+	return &codePage{mark: mark}
+}
+
+func tableCodePage(mark byte, table *[128]rune) *codePage {
+	cp := &codePage{mark: mark, toUTF8: table, fromRune: map[rune]byte{}}
+	for i, r := range table {
+		cp.fromRune[r] = byte(0x80 + i)
+	}
+	return cp
+}
+
+// TextCodePage traduce entre UTF-8 y la página de códigos de un archivo de
+// texto (COPY TO y APPEND FROM con TYPE CSV, DELIMITED o SDF).
+type TextCodePage struct {
+	cp *codePage
+}
+
+// textCodePages son las páginas de códigos de los archivos de texto por su
+// número de Windows; 65001 es UTF-8.
+var textCodePages = map[int]*[128]rune{
+	437:   dos437,
+	850:   dos850,
+	1252:  win1252,
+	28591: latin1,
+	65001: nil,
+}
+
+// NewTextCodePage devuelve la página de códigos con el número indicado;
+// ok es false si no se admite.
+func NewTextCodePage(number int) (cp *TextCodePage, ok bool) {
+	table, ok := textCodePages[number]
+	if !ok {
+		return nil, false
+	}
+	if table == nil {
+		return &TextCodePage{cp: &codePage{}}, true
+	}
+	return &TextCodePage{cp: tableCodePage(0, table)}, true
+}
+
+// Decode convierte el texto del archivo a UTF-8.
+func (t *TextCodePage) Decode(data []byte) string {
+	return t.cp.decode(data)
+}
+
+// Encode convierte el texto a la página de códigos; los caracteres que no
+// existen en ella se escriben como '?'.
+func (t *TextCodePage) Encode(text string) []byte {
+	return t.cp.encode(text)
+}
+
+func (cp *codePage) decode(data []byte) string {
+	if cp.toUTF8 == nil {
+		return string(data)
+	}
+	runes := make([]rune, 0, len(data))
+	for _, b := range data {
+		if b < 0x80 {
+			runes = append(runes, rune(b))
+		} else {
+			runes = append(runes, cp.toUTF8[b-0x80])
+		}
+	}
+	return string(runes)
+}
+
+func (cp *codePage) encode(text string) []byte {
+	if cp.toUTF8 == nil {
+		return []byte(text)
+	}
+	data := make([]byte, 0, len(text))
+	for len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		text = text[size:]
+		switch b, ok := cp.fromRune[r]; {
+		case r < 0x80:
+			data = append(data, byte(r))
+		case ok:
+			data = append(data, b)
+		default:
+			data = append(data, '?')
+		}
+	}
+	return data
+}
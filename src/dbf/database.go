@@ -0,0 +1,712 @@
+package dbf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Bases de datos de Visual FoxPro (.dbc).
+//
+// Una base de datos es una tabla (.dbc, con sus memos en .dct) con un
+// registro por objeto: OBJECTID, PARENTID, OBJECTTYPE ("Database", "Table",
+// "Field"...), OBJECTNAME y las propiedades del objeto en el memo binario
+// PROPERTY. Cada propiedad ocupa su longitud total (4 bytes), un tipo (2
+// bytes), su identificador (1 byte) y el valor: los textos terminan en 0,
+// los números ocupan 4 bytes y los lógicos 1 (todo en little-endian). Las
+// propiedades que FoxLite no conoce se conservan tal como están.
+//
+// Las tablas de la base guardan en el enlace de su cabecera la ruta del .dbc
+// relativa a la carpeta de la tabla, y la base guarda en la propiedad Path
+// la ruta de cada tabla relativa a la suya (ambas con separadores '\', como
+// Visual FoxPro). Los campos de la tabla son los objetos "Field" hijos del
+// objeto "Table", en el orden de los campos del .dbf; su nombre es el
+// nombre largo del campo, que en el .dbf se guarda truncado a 10
+// caracteres.
+//
+// Las propiedades se leen al abrir la base: los cambios que hagan después
+// otros procesos no se ven hasta volver a abrirla.
+
+// Tipos de objeto de la base de datos (OBJECTTYPE).
+const (
+	ObjectDatabase = "Database"
+	ObjectTable    = "Table"
+	ObjectField    = "Field"
+)
+
+// dbcVersion es la versión que se guarda en la propiedad Version de las
+// bases nuevas.
+const dbcVersion = 10
+
+// dbcFields es la estructura de la tabla de una base de datos.
+var dbcFields = []Field{
+	{Name: "OBJECTID", Type: Integer},
+	{Name: "PARENTID", Type: Integer},
+	{Name: "OBJECTTYPE", Type: Character, Length: 10},
+	{Name: "OBJECTNAME", Type: Character, Length: 128},
+	{Name: "PROPERTY", Type: Memo, Flags: FlagBinary},
+	{Name: "CODE", Type: Memo, Flags: FlagBinary},
+	{Name: "RIINFO", Type: Character, Length: 6},
+	{Name: "USER", Type: Memo, Flags: FlagBinary},
+}
+
+// Posiciones de los campos de dbcFields.
+const (
+	dbcObjectID = iota
+	dbcParentID
+	dbcObjectType
+	dbcObjectName
+	dbcProperty
+)
+
+// Tipos de valor de las propiedades.
+const (
+	propText = iota
+	propNumber
+	propLogical
+)
+
+type propertyDef struct {
+	id       byte
+	name     string
+	kind     int
+	readOnly bool
+	objects  []string // tipos de objeto que tienen la propiedad
+}
+
+// propertyDefs son las propiedades que entienden DBGETPROP() y DBSETPROP().
+// Path y Version las mantiene la propia base de datos.
+var propertyDefs = []propertyDef{
+	{id: 1, name: "Path", kind: propText, readOnly: true, objects: []string{ObjectTable}},
+	{id: 7, name: "Comment", kind: propText, objects: []string{ObjectDatabase, ObjectTable, ObjectField}},
+	{id: 9, name: "RuleExpression", kind: propText, objects: []string{ObjectTable, ObjectField}},
+	{id: 10, name: "RuleText", kind: propText, objects: []string{ObjectTable, ObjectField}},
+	{id: 11, name: "DefaultValue", kind: propText, objects: []string{ObjectField}},
+	{id: 14, name: "InsertTrigger", kind: propText, objects: []string{ObjectTable}},
+	{id: 15, name: "UpdateTrigger", kind: propText, objects: []string{ObjectTable}},
+	{id: 16, name: "DeleteTrigger", kind: propText, objects: []string{ObjectTable}},
+	{id: 20, name: "PrimaryKey", kind: propText, objects: []string{ObjectTable}},
+	{id: 24, name: "Version", kind: propNumber, readOnly: true, objects: []string{ObjectDatabase}},
+	{id: 65, name: "Caption", kind: propText, objects: []string{ObjectField}},
+}
+
+func findProperty(name string) *propertyDef {
+	for i := range propertyDefs {
+		if strings.EqualFold(propertyDefs[i].name, name) {
+			return &propertyDefs[i]
+		}
+	}
+	return nil
+}
+
+// Database es una base de datos abierta.
+type Database struct {
+	Path    string
+	table   *Table
+	objects []*DBObject
+	nextID  int
+}
+
+// DBObject es un objeto de la base de datos: la propia base, una tabla o un
+// campo.
+type DBObject struct {
+	ID     int
+	Parent int
+	Type   string
+	Name   string
+
+	props []property
+	recno int
+	db    *Database
+}
+
+type property struct {
+	id   byte
+	kind uint16
+	data []byte
+}
+
+// CreateDatabase crea una base de datos vacía y la deja abierta. Si existe
+// un archivo con ese nombre se reemplaza.
+func CreateDatabase(path string) (*Database, error) {
+	t, err := Create(path, dbcFields)
+	if err != nil {
+		return nil, err
+	}
+	t.Flags |= TableIsDBC
+	if err := t.writeHeader(false); err != nil {
+		t.Close()
+		return nil, err
+	}
+	db := &Database{Path: path, table: t, nextID: 1}
+	// los objetos de sistema que crea Visual FoxPro en una base vacía
+	names := []string{"Database", "TransactionLog", "StoredProceduresSource", "StoredProceduresObject", "StoredProceduresDependencies"}
+	for _, name := range names {
+		var props []property
+		if name == "Database" {
+			props = []property{db.encodeProperty(findProperty("Version"), dbcVersion)}
+		}
+		if _, err := db.addObject(1, ObjectDatabase, name, props); err != nil {
+			t.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// OpenDatabase abre una base de datos y lee sus objetos.
+func OpenDatabase(path string, readOnly bool) (*Database, error) {
+	t, err := Open(path, readOnly)
+	if err != nil {
+		return nil, err
+	}
+	db := &Database{Path: path, table: t, nextID: 1}
+	if err := db.load(); err != nil {
+		t.Close()
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return db, nil
+}
+
+func (db *Database) load() error {
+	t := db.table
+	for i, f := range dbcFields[:dbcProperty+1] {
+		if i >= len(t.Fields) || t.Fields[i].Name != f.Name || t.Fields[i].Type != f.Type {
+			return errors.New("not a database")
+		}
+	}
+	for recno := 1; recno <= t.RecordCount(); recno++ {
+		rec, err := t.Record(recno)
+		if err != nil {
+			return err
+		}
+		if rec.Deleted() {
+			continue
+		}
+		var values [dbcProperty + 1]interface{}
+		for i := range values {
+			if values[i], err = rec.Value(i); err != nil {
+				return err
+			}
+		}
+		o := &DBObject{
+			ID:     int(values[dbcObjectID].(float64)),
+			Parent: int(values[dbcParentID].(float64)),
+			Type:   strings.TrimSpace(values[dbcObjectType].(string)),
+			Name:   strings.TrimSpace(values[dbcObjectName].(string)),
+			recno:  recno,
+			db:     db,
+		}
+		if data, ok := values[dbcProperty].(string); ok {
+			o.props = parseProperties([]byte(data))
+		}
+		if o.ID >= db.nextID {
+			db.nextID = o.ID + 1
+		}
+		db.objects = append(db.objects, o)
+	}
+	sort.SliceStable(db.objects, func(i, j int) bool {
+		return db.objects[i].ID < db.objects[j].ID
+	})
+	return nil
+}
+
+// parseProperties separa las propiedades de un memo PROPERTY; una entrada
+// incompleta termina la lista.
+func parseProperties(data []byte) []property {
+	var props []property
+	for len(data) >= 7 {
+		size := int(binary.LittleEndian.Uint32(data[0:4]))
+		if size < 7 || size > len(data) {
+			break
+		}
+		props = append(props, property{
+			id:   data[6],
+			kind: binary.LittleEndian.Uint16(data[4:6]),
+			data: append([]byte{}, data[7:size]...),
+		})
+		data = data[size:]
+	}
+	return props
+}
+
+func encodeProperties(props []property) []byte {
+	var out []byte
+	for _, p := range props {
+		var head [7]byte
+		binary.LittleEndian.PutUint32(head[0:4], uint32(7+len(p.data)))
+		binary.LittleEndian.PutUint16(head[4:6], p.kind)
+		head[6] = p.id
+		out = append(append(out, head[:]...), p.data...)
+	}
+	return out
+}
+
+// Share registra la apertura de la base de datos, compartida o en
+// exclusiva, como Table.Share.
+func (db *Database) Share(exclusive bool) error {
+	return db.table.Share(exclusive)
+}
+
+// Close cierra la base de datos.
+func (db *Database) Close() error {
+	return db.table.Close()
+}
+
+// Name devuelve el nombre de la base de datos (el del archivo sin la
+// extensión) en mayúsculas.
+func (db *Database) Name() string {
+	return strings.ToUpper(strings.TrimSuffix(filepath.Base(db.Path), filepath.Ext(db.Path)))
+}
+
+// Root devuelve el objeto de la propia base de datos.
+func (db *Database) Root() *DBObject {
+	for _, o := range db.objects {
+		if o.Type == ObjectDatabase && strings.EqualFold(o.Name, "Database") {
+			return o
+		}
+	}
+	return &DBObject{ID: 1, Parent: 1, Type: ObjectDatabase, Name: "Database", db: db}
+}
+
+// Tables devuelve los objetos de las tablas de la base.
+func (db *Database) Tables() []*DBObject {
+	var tables []*DBObject
+	for _, o := range db.objects {
+		if o.Type == ObjectTable {
+			tables = append(tables, o)
+		}
+	}
+	return tables
+}
+
+// Table busca una tabla por su nombre (sin distinguir mayúsculas); devuelve
+// nil si no está en la base.
+func (db *Database) Table(name string) *DBObject {
+	for _, o := range db.Tables() {
+		if strings.EqualFold(o.Name, name) {
+			return o
+		}
+	}
+	return nil
+}
+
+// TableByPath busca la tabla de la base que está en el archivo path.
+func (db *Database) TableByPath(path string) *DBObject {
+	key := tableFileKey(path)
+	for _, o := range db.Tables() {
+		if tableFileKey(db.TablePath(o)) == key {
+			return o
+		}
+	}
+	return nil
+}
+
+// TablePath devuelve la ruta del archivo de una tabla de la base.
+func (db *Database) TablePath(o *DBObject) string {
+	path, _ := o.Prop("Path")
+	return resolveLink(filepath.Dir(db.Path), path.(string))
+}
+
+// Attach prepara una tabla de la base recién abierta: sus campos toman los
+// nombres largos de la base. Devuelve el objeto de la tabla.
+func (db *Database) Attach(t *Table) (*DBObject, error) {
+	o := db.TableByPath(t.Path)
+	if o == nil {
+		return nil, fmt.Errorf("database `%s` does not contain the table", db.Name())
+	}
+	for i, field := range o.Fields() {
+		if i < len(t.Fields) && field.Name != "" {
+			t.SetLongName(i, field.Name)
+		}
+	}
+	return o, nil
+}
+
+// AddTable agrega a la base una tabla libre, con el nombre largo name y los
+// nombres largos de sus campos (nil para usar los del .dbf), y guarda en la
+// tabla el enlace a la base.
+func (db *Database) AddTable(t *Table, name string, fieldNames []string) (*DBObject, error) {
+	if link := t.Backlink(); link != "" {
+		return nil, fmt.Errorf("table already belongs to database `%s`", link)
+	}
+	if !t.hasBacklink() {
+		return nil, errors.New("table is not a Visual FoxPro table")
+	}
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(t.Path), filepath.Ext(t.Path))
+	}
+	name = strings.ToLower(name)
+	if len(name) > maxLongName {
+		return nil, fmt.Errorf("table name `%s` is too long", name)
+	}
+	if db.Table(name) != nil {
+		return nil, fmt.Errorf("table `%s` already exists in database `%s`", name, db.Name())
+	}
+	path, err := relativeLink(filepath.Dir(db.Path), t.Path)
+	if err != nil {
+		return nil, err
+	}
+	link, err := relativeLink(filepath.Dir(t.Path), db.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.SetBacklink(link); err != nil {
+		return nil, err
+	}
+	props := []property{db.encodeProperty(findProperty("Path"), path)}
+	o, err := db.addObject(1, ObjectTable, name, props)
+	if err != nil {
+		return nil, err
+	}
+	for i, f := range t.Fields {
+		fieldName := f.Name
+		if i < len(fieldNames) && fieldNames[i] != "" {
+			fieldName = fieldNames[i]
+		}
+		if _, err := db.addObject(o.ID, ObjectField, strings.ToLower(fieldName), nil); err != nil {
+			return nil, err
+		}
+		t.SetLongName(i, fieldName)
+	}
+	return o, nil
+}
+
+// SetFields actualiza los campos de una tabla de la base después de
+// cambiar su estructura: names son los nombres de los campos nuevos y
+// sources la posición que tenía cada uno antes (-1 si es nuevo). Los
+// campos que siguen conservan sus propiedades.
+func (db *Database) SetFields(o *DBObject, names []string, sources []int) error {
+	old := o.Fields()
+	kept := map[*DBObject]bool{}
+	for i, name := range names {
+		name = strings.ToLower(name)
+		if sources[i] >= 0 && sources[i] < len(old) {
+			field := old[sources[i]]
+			kept[field] = true
+			if field.Name != name {
+				field.Name = name
+				if err := db.writeObject(field); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if _, err := db.addObject(o.ID, ObjectField, name, nil); err != nil {
+			return err
+		}
+	}
+	for _, field := range old {
+		if !kept[field] {
+			if err := db.removeObject(field); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addObject agrega un objeto con el próximo OBJECTID libre.
+func (db *Database) addObject(parent int, kind string, name string, props []property) (*DBObject, error) {
+	o := &DBObject{ID: db.nextID, Parent: parent, Type: kind, Name: name, props: props, db: db}
+	rec, err := db.table.AppendBlank()
+	if err != nil {
+		return nil, err
+	}
+	o.recno = rec.Recno
+	db.nextID++
+	db.objects = append(db.objects, o)
+	return o, db.writeObject(o)
+}
+
+// writeObject guarda el registro de un objeto.
+func (db *Database) writeObject(o *DBObject) error {
+	rec, err := db.table.Record(o.recno)
+	if err != nil {
+		return err
+	}
+	values := []interface{}{float64(o.ID), float64(o.Parent), o.Type, o.Name, string(encodeProperties(o.props))}
+	for i, value := range values {
+		if err := rec.SetValue(i, value); err != nil {
+			return err
+		}
+	}
+	return db.table.WriteRecord(rec)
+}
+
+// removeObject borra un objeto y los que dependen de él.
+func (db *Database) removeObject(o *DBObject) error {
+	for _, child := range db.children(o) {
+		if err := db.removeObject(child); err != nil {
+			return err
+		}
+	}
+	rec, err := db.table.Record(o.recno)
+	if err != nil {
+		return err
+	}
+	rec.SetDeleted(true)
+	if err := db.table.WriteRecord(rec); err != nil {
+		return err
+	}
+	for i, other := range db.objects {
+		if other == o {
+			db.objects = append(db.objects[:i], db.objects[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (db *Database) children(o *DBObject) []*DBObject {
+	var children []*DBObject
+	for _, other := range db.objects {
+		if other.Parent == o.ID && other != o {
+			children = append(children, other)
+		}
+	}
+	return children
+}
+
+// Fields devuelve los campos de un objeto tabla en el orden de la tabla.
+func (o *DBObject) Fields() []*DBObject {
+	var fields []*DBObject
+	for _, child := range o.db.children(o) {
+		if child.Type == ObjectField {
+			fields = append(fields, child)
+		}
+	}
+	return fields
+}
+
+// Field busca un campo de un objeto tabla por su nombre largo.
+func (o *DBObject) Field(name string) *DBObject {
+	for _, field := range o.Fields() {
+		if strings.EqualFold(field.Name, name) {
+			return field
+		}
+	}
+	return nil
+}
+
+// Database devuelve la base de datos del objeto.
+func (o *DBObject) Database() *Database {
+	return o.db
+}
+
+// Prop devuelve el valor de una propiedad: string, int o bool según la
+// propiedad; si el objeto no la tiene asignada devuelve el valor vacío.
+func (o *DBObject) Prop(name string) (interface{}, error) {
+	def, err := o.propertyDef(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range o.props {
+		if p.id == def.id {
+			return o.db.decodeProperty(def, p.data), nil
+		}
+	}
+	return o.db.decodeProperty(def, nil), nil
+}
+
+// Text devuelve el valor de una propiedad de texto ("" si no la tiene).
+func (o *DBObject) Text(name string) string {
+	value, err := o.Prop(name)
+	if err != nil {
+		return ""
+	}
+	text, _ := value.(string)
+	return text
+}
+
+// SetProp cambia el valor de una propiedad y lo guarda en la base; un
+// texto vacío elimina la propiedad.
+func (o *DBObject) SetProp(name string, value interface{}) error {
+	def, err := o.propertyDef(name)
+	if err != nil {
+		return err
+	}
+	if def.readOnly {
+		return fmt.Errorf("property `%s` is read-only", def.name)
+	}
+	switch def.kind {
+	case propText:
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("property `%s` must be a character value", def.name)
+		}
+	case propNumber:
+		if _, ok := value.(int); !ok {
+			return fmt.Errorf("property `%s` must be a numeric value", def.name)
+		}
+	case propLogical:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("property `%s` must be a logical value", def.name)
+		}
+	}
+	props := o.props[:0:0]
+	for _, p := range o.props {
+		if p.id != def.id {
+			props = append(props, p)
+		}
+	}
+	if text, ok := value.(string); !ok || text != "" {
+		props = append(props, o.db.encodeProperty(def, value))
+	}
+	o.props = props
+	return o.db.writeObject(o)
+}
+
+func (o *DBObject) propertyDef(name string) (*propertyDef, error) {
+	def := findProperty(name)
+	if def == nil {
+		return nil, fmt.Errorf("property `%s` is invalid", name)
+	}
+	for _, kind := range def.objects {
+		if kind == o.Type {
+			return def, nil
+		}
+	}
+	return nil, fmt.Errorf("property `%s` is invalid for %s objects", def.name, strings.ToLower(o.Type))
+}
+
+func (db *Database) encodeProperty(def *propertyDef, value interface{}) property {
+	p := property{id: def.id, kind: 1}
+	switch value := value.(type) {
+	case string:
+		p.data = append(db.table.cp.encode(value), 0)
+	case int:
+		p.data = make([]byte, 4)
+		binary.LittleEndian.PutUint32(p.data, uint32(int32(value)))
+	case bool:
+		p.data = []byte{0}
+		if value {
+			p.data[0] = 1
+		}
+	}
+	return p
+}
+
+func (db *Database) decodeProperty(def *propertyDef, data []byte) interface{} {
+	switch def.kind {
+	case propNumber:
+		if len(data) < 4 {
+			return 0
+		}
+		return int(int32(binary.LittleEndian.Uint32(data)))
+	case propLogical:
+		return len(data) > 0 && data[0] != 0
+	}
+	if idx := strings.IndexByte(string(data), 0); idx >= 0 {
+		data = data[:idx]
+	}
+	return db.table.cp.decode(data)
+}
+
+// relativeLink devuelve la ruta de path relativa a la carpeta dir, con
+// separadores '\'.
+func relativeLink(dir string, path string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		rel = absPath
+	}
+	return strings.ReplaceAll(filepath.ToSlash(rel), "/", `\`), nil
+}
+
+// resolveLink convierte una ruta guardada por relativeLink (o por Visual
+// FoxPro) en una ruta del sistema.
+func resolveLink(dir string, link string) string {
+	path := filepath.FromSlash(strings.ReplaceAll(link, `\`, "/"))
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// Enlace de las tablas de Visual FoxPro a su base de datos: los 263 bytes
+// que siguen a los descriptores de los campos.
+
+func (t *Table) backlinkOffset() int64 {
+	return int64(headerSize + len(t.fields)*fieldSize + 1)
+}
+
+func (t *Table) hasBacklink() bool {
+	return int64(t.headerLen) >= t.backlinkOffset()+backlinkSize
+}
+
+// Backlink devuelve la ruta de la base de datos de la tabla tal como está
+// guardada ("" en las tablas libres).
+func (t *Table) Backlink() string {
+	if !t.hasBacklink() {
+		return ""
+	}
+	data := make([]byte, backlinkSize)
+	if _, err := t.file.ReadAt(data, t.backlinkOffset()); err != nil {
+		return ""
+	}
+	if idx := strings.IndexByte(string(data), 0); idx >= 0 {
+		data = data[:idx]
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// DatabasePath devuelve la ruta del .dbc de la tabla ("" en las tablas
+// libres).
+func (t *Table) DatabasePath() string {
+	link := t.Backlink()
+	if link == "" {
+		return ""
+	}
+	return resolveLink(filepath.Dir(t.Path), link)
+}
+
+// SetBacklink guarda la ruta de la base de datos de la tabla ("" la
+// convierte en una tabla libre).
+func (t *Table) SetBacklink(link string) error {
+	if t.readOnly {
+		return ErrReadOnly
+	}
+	if !t.hasBacklink() {
+		return errors.New("table is not a Visual FoxPro table")
+	}
+	if len(link) >= backlinkSize {
+		return fmt.Errorf("database path `%s` is too long", link)
+	}
+	data := make([]byte, backlinkSize)
+	copy(data, link)
+	return t.update(false, func() error {
+		if err := t.writeAt(data, t.backlinkOffset()); err != nil {
+			return err
+		}
+		return t.writeHeader(false)
+	})
+}
+
+// SetLongName le da al campo idx un nombre largo (el de la base de datos o
+// el de la tabla recién creada); el nombre del .dbf sigue sirviendo para
+// buscarlo.
+func (t *Table) SetLongName(idx int, name string) {
+	f := t.Fields[idx]
+	name = strings.ToUpper(name)
+	if f.Name == name {
+		return
+	}
+	if f.short == "" {
+		f.short = f.Name
+	}
+	if t.names[f.Name] == idx && f.Name != f.short {
+		delete(t.names, f.Name)
+	}
+	f.Name = name
+	t.names[name] = idx
+}
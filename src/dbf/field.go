@@ -0,0 +1,307 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tipos de campo soportados.
+const (
+	Character = 'C'
+	Numeric   = 'N'
+	Float     = 'F'
+	Logical   = 'L'
+	Date      = 'D'
+	DateTime  = 'T'
+	Integer   = 'I'
+	Currency  = 'Y'
+	Double    = 'B'
+	Memo      = 'M'
+	NullFlags = '0' // campo de sistema _NullFlags
+)
+
+// Flags de los campos de Visual FoxPro.
+const (
+	FlagSystem   = 0x01
+	FlagNullable = 0x02
+	FlagBinary   = 0x04
+)
+
+// Field describe una columna de la tabla.
+type Field struct {
+	Name     string
+	Type     byte
+	Length   int
+	Decimals int
+	Flags    byte
+
+	offset  int    // posición dentro del registro (el byte 0 es la marca de borrado)
+	nullBit int    // bit en _NullFlags si el campo admite null, -1 si no
+	short   string // nombre en el .dbf si Name es el nombre largo de la base de datos
+}
+
+// Nullable indica si el campo admite valores null.
+func (f *Field) Nullable() bool {
+	return f.Flags&FlagNullable != 0
+}
+
+// diskName devuelve el nombre del campo en el descriptor del .dbf.
+func (f *Field) diskName() string {
+	if f.short != "" {
+		return f.short
+	}
+	return f.Name
+}
+
+// maxLongName es la longitud máxima de los nombres largos de los campos.
+const maxLongName = 128
+
+// shortName abrevia un nombre largo a los 10 caracteres del .dbf; si la
+// abreviatura ya está en used le agrega un número al final.
+func shortName(long string, used map[string]bool) string {
+	name := long[:10]
+	for n := 1; used[name]; n++ {
+		suffix := strconv.Itoa(n)
+		name = long[:10-len(suffix)] + suffix
+	}
+	used[name] = true
+	return name
+}
+
+// Binary indica si el campo guarda datos binarios (NOCPTRANS).
+func (f *Field) Binary() bool {
+	return f.Flags&FlagBinary != 0
+}
+
+// normalize completa la longitud de los tipos de longitud fija y valida la
+// definición del campo.
+func (f *Field) normalize() error {
+	f.Name = strings.ToUpper(strings.TrimSpace(f.Name))
+	if f.Name == "" || len(f.Name) > 10 {
+		return fmt.Errorf("invalid field name `%s`", f.Name)
+	}
+	switch f.Type {
+	case Character:
+		if f.Length < 1 || f.Length > 254 {
+			return fmt.Errorf("field `%s`: invalid width %d", f.Name, f.Length)
+		}
+		f.Decimals = 0
+	case Numeric, Float:
+		if f.Length < 1 || f.Length > 20 || f.Decimals < 0 || (f.Decimals > 0 && f.Decimals > f.Length-2) {
+			return fmt.Errorf("field `%s`: invalid width %d,%d", f.Name, f.Length, f.Decimals)
+		}
+	case Logical:
+		f.Length, f.Decimals = 1, 0
+	case Date, DateTime, Currency, Double:
+		f.Length = 8
+		if f.Type != Double {
+			f.Decimals = 0
+		}
+	case Integer, Memo:
+		f.Length, f.Decimals = 4, 0
+	default:
+		return fmt.Errorf("field `%s`: unsupported type `%c`", f.Name, f.Type)
+	}
+	return nil
+}
+
+// blank devuelve el contenido de un campo vacío.
+func (f *Field) blank() []byte {
+	switch f.Type {
+	case Integer, Currency, Double, DateTime, NullFlags:
+		return make([]byte, f.Length)
+	case Memo:
+		if f.Length == 4 {
+			return make([]byte, 4)
+		}
+	}
+	return bytes.Repeat([]byte{' '}, f.Length)
+}
+
+// decode convierte el contenido de un campo en un valor de Go:
+// string (C), float64 (N, F, I, Y, B), bool (L), time.Time (D, T) o
+// uint32 con el número de bloque (M).
+func (f *Field) decode(data []byte, cp *codePage) (interface{}, error) {
+	switch f.Type {
+	case Character:
+		if f.Binary() {
+			return string(data), nil
+		}
+		return cp.decode(data), nil
+	case Numeric, Float:
+		text := strings.TrimSpace(string(data))
+		// los asteriscos son un desbordamiento guardado por FoxPro
+		if text == "" || strings.Trim(text, "*") == "" {
+			return float64(0), nil
+		}
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("field `%s`: invalid number `%s`", f.Name, text)
+		}
+		return value, nil
+	case Logical:
+		switch data[0] {
+		case 'T', 't', 'Y', 'y':
+			return true, nil
+		}
+		return false, nil
+	case Date:
+		text := strings.TrimSpace(string(data))
+		if text == "" {
+			return time.Time{}, nil
+		}
+		value, err := time.Parse("20060102", text)
+		if err != nil {
+			return nil, fmt.Errorf("field `%s`: invalid date `%s`", f.Name, text)
+		}
+		return value, nil
+	case DateTime:
+		day := int32(binary.LittleEndian.Uint32(data[0:4]))
+		ms := int32(binary.LittleEndian.Uint32(data[4:8]))
+		if day == 0 && ms == 0 {
+			return time.Time{}, nil
+		}
+		return julianToTime(int(day)).Add(time.Duration(ms) * time.Millisecond), nil
+	case Integer:
+		return float64(int32(binary.LittleEndian.Uint32(data))), nil
+	case Currency:
+		return float64(int64(binary.LittleEndian.Uint64(data))) / 10000, nil
+	case Double:
+		return math.Float64frombits(binary.LittleEndian.Uint64(data)), nil
+	case Memo:
+		if f.Length == 4 {
+			return binary.LittleEndian.Uint32(data), nil
+		}
+		text := strings.TrimSpace(string(data))
+		if text == "" {
+			return uint32(0), nil
+		}
+		block, err := strconv.ParseUint(text, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("field `%s`: invalid memo block `%s`", f.Name, text)
+		}
+		return uint32(block), nil
+	}
+	return string(data), nil
+}
+
+// encode escribe un valor de Go en el contenido del campo.
+func (f *Field) encode(dst []byte, value interface{}, cp *codePage) error {
+	switch f.Type {
+	case Character:
+		text, ok := value.(string)
+		if !ok {
+			return f.typeError(value)
+		}
+		data := []byte(text)
+		if !f.Binary() {
+			data = cp.encode(text)
+		}
+		copy(dst, bytes.Repeat([]byte{' '}, f.Length))
+		copy(dst, data)
+	case Numeric, Float:
+		num, ok := value.(float64)
+		if !ok {
+			return f.typeError(value)
+		}
+		text := strconv.FormatFloat(num, 'f', f.Decimals, 64)
+		if len(text) > f.Length {
+			return fmt.Errorf("field `%s`: numeric overflow", f.Name)
+		}
+		copy(dst, fmt.Sprintf("%*s", f.Length, text))
+	case Logical:
+		b, ok := value.(bool)
+		if !ok {
+			return f.typeError(value)
+		}
+		dst[0] = 'F'
+		if b {
+			dst[0] = 'T'
+		}
+	case Date:
+		t, ok := value.(time.Time)
+		if !ok {
+			return f.typeError(value)
+		}
+		if t.IsZero() {
+			copy(dst, "        ")
+		} else {
+			copy(dst, t.Format("20060102"))
+		}
+	case DateTime:
+		t, ok := value.(time.Time)
+		if !ok {
+			return f.typeError(value)
+		}
+		var day, ms uint32
+		if !t.IsZero() {
+			day = uint32(timeToJulian(t))
+			midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+			ms = uint32(t.Sub(midnight) / time.Millisecond)
+		}
+		binary.LittleEndian.PutUint32(dst[0:4], day)
+		binary.LittleEndian.PutUint32(dst[4:8], ms)
+	case Integer:
+		num, ok := value.(float64)
+		if !ok {
+			return f.typeError(value)
+		}
+		if num < math.MinInt32 || num > math.MaxInt32 {
+			return fmt.Errorf("field `%s`: numeric overflow", f.Name)
+		}
+		binary.LittleEndian.PutUint32(dst, uint32(int32(math.Round(num))))
+	case Currency:
+		num, ok := value.(float64)
+		if !ok {
+			return f.typeError(value)
+		}
+		// se guarda en un int64 con 4 decimales: float64(MaxInt64) ya es 2^63
+		scaled := math.Round(num * 10000)
+		if !(scaled >= math.MinInt64 && scaled < math.MaxInt64) {
+			return fmt.Errorf("field `%s`: numeric overflow", f.Name)
+		}
+		binary.LittleEndian.PutUint64(dst, uint64(int64(scaled)))
+	case Double:
+		num, ok := value.(float64)
+		if !ok {
+			return f.typeError(value)
+		}
+		binary.LittleEndian.PutUint64(dst, math.Float64bits(num))
+	case Memo:
+		block, ok := value.(uint32)
+		if !ok {
+			return f.typeError(value)
+		}
+		if f.Length == 4 {
+			binary.LittleEndian.PutUint32(dst, block)
+		} else if block == 0 {
+			copy(dst, bytes.Repeat([]byte{' '}, f.Length))
+		} else {
+			copy(dst, fmt.Sprintf("%*d", f.Length, block))
+		}
+	default:
+		return fmt.Errorf("field `%s`: cannot write type `%c`", f.Name, f.Type)
+	}
+	return nil
+}
+
+func (f *Field) typeError(value interface{}) error {
+	return fmt.Errorf("data type mismatch for field `%s` (%c): got %T", f.Name, f.Type, value)
+}
+
+// Los campos DateTime guardan el número de día juliano.
+const julianUnixEpoch = 2440588 // 1970-01-01
+
+func julianToTime(day int) time.Time {
+	return time.Unix(int64(day-julianUnixEpoch)*86400, 0).UTC()
+}
+
+func timeToJulian(t time.Time) int {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return int(date.Unix()/86400) + julianUnixEpoch
+}
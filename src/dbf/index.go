@@ -0,0 +1,633 @@
+package dbf
+
+// Índices compuestos estructurales de FoxLite (.fdx).
+//
+// FoxLite no lee ni escribe los .cdx de Visual FoxPro: usa su propio archivo
+// de índice compuesto con el mismo nombre base que la tabla y extensión
+// .fdx, que se abre automáticamente con ella. Cada tag guarda el texto de su
+// expresión y de su condición FOR, que el intérprete evalúa para calcular las
+// claves. Las entradas se mantienen en memoria ordenadas por clave y número
+// de registro, y el archivo se reescribe completo al cerrar la tabla.
+//
+// Formato (enteros en little-endian):
+//
+//	cabecera   "FLDX", versión (uint16), cantidad de tags (uint16),
+//	           registros de la tabla al guardar (uint32), contador de
+//	           escrituras de la tabla al guardar (uint32)
+//	cada tag   nombre, expresión y condición FOR (uint16 longitud + UTF-8),
+//	           flags (byte: 1 descendente, 2 único, 4 candidato),
+//	           tipo de clave (byte), cantidad de entradas (uint32)
+//	entradas   longitud de la clave (uint16), clave, registro (uint32)
+//
+// Las claves se codifican para que su orden de bytes sea el orden del
+// índice: un byte 0x00 para null (que ordena primero) o 0x01 seguido del
+// valor: los caracteres sin los espacios finales, los números, fechas y
+// fechas y horas como float64 de 8 bytes con el signo invertido, y los
+// lógicos como 'F' o 'T'. Si la cantidad de registros o el contador de
+// escrituras guardados no coinciden con los de la tabla (la tabla se
+// modificó sin FoxLite o desde otro proceso) el índice se marca como
+// desactualizado y debe reconstruirse.
+//
+// Limitación con varios procesos: el índice de cada proceso vive en
+// memoria y la tabla no registra qué registros cambió otro proceso, solo
+// que hubo escrituras (el contador de la cabecera). Por eso cada vez que
+// Refresh ve una escritura ajena el índice se reconstruye completo en el
+// siguiente uso, recorriendo toda la tabla. Con una tabla compartida por
+// dos o más procesos que escriben, el costo de cada búsqueda o
+// actualización de índice tras una escritura ajena es el de reconstruirlo,
+// no el de la búsqueda binaria.
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	indexMagic   = "FLDX"
+	indexVersion = 1
+)
+
+// Flags de los tags en el archivo de índice.
+const (
+	tagDescending = 0x01
+	tagUnique     = 0x02
+	tagCandidate  = 0x04
+)
+
+// UniquenessError se devuelve al repetir una clave de un tag candidato.
+type UniquenessError struct {
+	Tag string
+}
+
+func (e *UniquenessError) Error() string {
+	return fmt.Sprintf("uniqueness of index `%s` is violated", e.Tag)
+}
+
+// Index es el archivo de índice compuesto de una tabla.
+type Index struct {
+	path    string
+	tags    []*Tag
+	count   int    // registros de la tabla cuando se guardó
+	changes uint32 // contador de escrituras de la tabla cuando se guardó
+	stale   bool
+	dirty   bool
+}
+
+// Tag es un índice dentro del archivo compuesto. Las entradas están
+// ordenadas de forma ascendente; Descending invierte el recorrido y Unique
+// oculta las entradas con la misma clave que la anterior.
+type Tag struct {
+	Name       string
+	Expr       string // expresión de la clave
+	For        string // condición FOR ("" si no tiene)
+	Descending bool
+	Unique     bool
+	Candidate  bool
+
+	keyType byte // tipo de las claves indexadas (0 mientras no haya ninguna)
+	entries []entry
+	keys    map[int]string // clave de cada registro indexado
+	index   *Index
+}
+
+type entry struct {
+	key   string
+	recno int
+}
+
+func indexPath(tablePath string) string {
+	return strings.TrimSuffix(tablePath, filepath.Ext(tablePath)) + ".fdx"
+}
+
+// openIndex lee el índice estructural de la tabla si existe.
+func (t *Table) openIndex() error {
+	path := indexPath(t.Path)
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	idx, err := readIndex(bufio.NewReader(file))
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	idx.path = path
+	idx.stale = idx.count != t.count || idx.changes != t.changes
+	t.index = idx
+	return nil
+}
+
+func readIndex(r io.Reader) (*Index, error) {
+	var header struct {
+		Magic   [4]byte
+		Version uint16
+		Tags    uint16
+		Count   uint32
+		Changes uint32
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if string(header.Magic[:]) != indexMagic || header.Version != indexVersion {
+		return nil, errors.New("not a FoxLite index file")
+	}
+	idx := &Index{count: int(header.Count), changes: header.Changes}
+	for i := 0; i < int(header.Tags); i++ {
+		tag := &Tag{index: idx, keys: map[int]string{}}
+		var err error
+		if tag.Name, err = readIndexString(r); err != nil {
+			return nil, err
+		}
+		if tag.Expr, err = readIndexString(r); err != nil {
+			return nil, err
+		}
+		if tag.For, err = readIndexString(r); err != nil {
+			return nil, err
+		}
+		var info struct {
+			Flags   byte
+			KeyType byte
+			Entries uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &info); err != nil {
+			return nil, err
+		}
+		tag.Descending = info.Flags&tagDescending != 0
+		tag.Unique = info.Flags&tagUnique != 0
+		tag.Candidate = info.Flags&tagCandidate != 0
+		tag.keyType = info.KeyType
+		tag.entries = make([]entry, info.Entries)
+		for j := range tag.entries {
+			key, err := readIndexString(r)
+			if err != nil {
+				return nil, err
+			}
+			var recno uint32
+			if err := binary.Read(r, binary.LittleEndian, &recno); err != nil {
+				return nil, err
+			}
+			tag.entries[j] = entry{key: key, recno: int(recno)}
+			tag.keys[int(recno)] = key
+		}
+		idx.tags = append(idx.tags, tag)
+	}
+	return idx, nil
+}
+
+func readIndexString(r io.Reader) (string, error) {
+	var size uint16
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return "", err
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// saveIndex escribe el índice en un archivo temporal que luego reemplaza
+// al anterior; sin tags se borra el archivo.
+func (t *Table) saveIndex() error {
+	idx := t.index
+	if idx == nil || !idx.dirty {
+		return nil
+	}
+	if len(idx.tags) == 0 {
+		err := os.Remove(idx.path)
+		if errors.Is(err, os.ErrNotExist) {
+			err = nil
+		}
+		idx.dirty = false
+		return err
+	}
+	tmp := idx.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	count := t.count
+	if idx.stale {
+		// no coincide con la tabla: se reconstruye al volver a abrirla
+		count = -1
+	}
+	w := bufio.NewWriter(file)
+	err = writeIndex(w, idx, count, t.changes)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, idx.path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	idx.count = t.count
+	idx.changes = t.changes
+	idx.dirty = false
+	return nil
+}
+
+func writeIndex(w io.Writer, idx *Index, count int, changes uint32) error {
+	var buf bytes.Buffer
+	buf.WriteString(indexMagic)
+	binary.Write(&buf, binary.LittleEndian, uint16(indexVersion))
+	binary.Write(&buf, binary.LittleEndian, uint16(len(idx.tags)))
+	binary.Write(&buf, binary.LittleEndian, uint32(count))
+	binary.Write(&buf, binary.LittleEndian, changes)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	for _, tag := range idx.tags {
+		buf.Reset()
+		writeIndexString(&buf, tag.Name)
+		writeIndexString(&buf, tag.Expr)
+		writeIndexString(&buf, tag.For)
+		var flags byte
+		if tag.Descending {
+			flags |= tagDescending
+		}
+		if tag.Unique {
+			flags |= tagUnique
+		}
+		if tag.Candidate {
+			flags |= tagCandidate
+		}
+		buf.WriteByte(flags)
+		buf.WriteByte(tag.keyType)
+		binary.Write(&buf, binary.LittleEndian, uint32(len(tag.entries)))
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		for _, e := range tag.entries {
+			buf.Reset()
+			writeIndexString(&buf, e.key)
+			binary.Write(&buf, binary.LittleEndian, uint32(e.recno))
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeIndexString(buf *bytes.Buffer, s string) {
+	binary.Write(buf, binary.LittleEndian, uint16(len(s)))
+	buf.WriteString(s)
+}
+
+// Tags devuelve los tags del índice estructural de la tabla.
+func (t *Table) Tags() []*Tag {
+	if t.index == nil {
+		return nil
+	}
+	return t.index.tags
+}
+
+// Tag busca un tag por su nombre (sin distinguir mayúsculas).
+func (t *Table) Tag(name string) *Tag {
+	for _, tag := range t.Tags() {
+		if strings.EqualFold(tag.Name, name) {
+			return tag
+		}
+	}
+	return nil
+}
+
+// AddTag agrega un tag vacío al índice estructural (reemplaza al que tenga
+// el mismo nombre); las claves se cargan con Tag.Set.
+func (t *Table) AddTag(tag *Tag) *Tag {
+	if t.index == nil {
+		t.index = &Index{path: indexPath(t.Path)}
+	}
+	t.DeleteTag(tag.Name)
+	tag.index = t.index
+	tag.Reset()
+	t.index.tags = append(t.index.tags, tag)
+	t.index.dirty = true
+	return tag
+}
+
+// DeleteTag elimina un tag del índice estructural.
+func (t *Table) DeleteTag(name string) bool {
+	if t.index == nil {
+		return false
+	}
+	for i, tag := range t.index.tags {
+		if strings.EqualFold(tag.Name, name) {
+			t.index.tags = append(t.index.tags[:i], t.index.tags[i+1:]...)
+			t.index.dirty = true
+			return true
+		}
+	}
+	return false
+}
+
+// IndexStale indica si el índice no corresponde al contenido de la tabla y
+// hay que reconstruir sus tags.
+func (t *Table) IndexStale() bool {
+	return t.index != nil && t.index.stale
+}
+
+// ReindexDone marca el índice como actualizado tras reconstruir los tags.
+func (t *Table) ReindexDone() {
+	if t.index != nil {
+		t.index.stale = false
+		t.index.dirty = true
+	}
+}
+
+// Reset vacía el tag.
+func (tag *Tag) Reset() {
+	tag.keyType = 0
+	tag.entries = nil
+	tag.keys = map[int]string{}
+	if tag.index != nil {
+		tag.index.dirty = true
+	}
+}
+
+// Len devuelve la cantidad de registros indexados.
+func (tag *Tag) Len() int {
+	return len(tag.entries)
+}
+
+// KeyType devuelve el tipo de las claves del tag: Character, Numeric,
+// Logical o Date (también para DateTime); 0 si todavía no tiene ninguna.
+func (tag *Tag) KeyType() byte {
+	return tag.keyType
+}
+
+// Set actualiza la clave del registro recno; si indexed es false (no cumple
+// la condición FOR) el registro sale del tag.
+func (tag *Tag) Set(recno int, value interface{}, indexed bool) error {
+	if !indexed {
+		tag.Remove(recno)
+		return nil
+	}
+	key, keyType, err := encodeKey(value)
+	if err != nil {
+		return err
+	}
+	if keyType != 0 && tag.keyType != 0 && keyType != tag.keyType {
+		return fmt.Errorf("index key type mismatch in tag `%s`", tag.Name)
+	}
+	if old, ok := tag.keys[recno]; ok && old == key {
+		return nil
+	}
+	i := tag.search(key)
+	if tag.Candidate && i < len(tag.entries) && tag.entries[i].key == key && tag.entries[i].recno != recno {
+		return &UniquenessError{Tag: tag.Name}
+	}
+	tag.Remove(recno)
+	if keyType != 0 {
+		tag.keyType = keyType
+	}
+	i = sort.Search(len(tag.entries), func(i int) bool {
+		e := tag.entries[i]
+		return e.key > key || (e.key == key && e.recno > recno)
+	})
+	tag.entries = append(tag.entries, entry{})
+	copy(tag.entries[i+1:], tag.entries[i:])
+	tag.entries[i] = entry{key: key, recno: recno}
+	tag.keys[recno] = key
+	tag.index.dirty = true
+	return nil
+}
+
+// Remove quita el registro recno del tag.
+func (tag *Tag) Remove(recno int) {
+	i := tag.position(recno)
+	if i < 0 {
+		return
+	}
+	tag.entries = append(tag.entries[:i], tag.entries[i+1:]...)
+	delete(tag.keys, recno)
+	tag.index.dirty = true
+}
+
+// search devuelve la posición de la primera entrada con una clave mayor o
+// igual que key.
+func (tag *Tag) search(key string) int {
+	return sort.Search(len(tag.entries), func(i int) bool {
+		return tag.entries[i].key >= key
+	})
+}
+
+// position devuelve la posición del registro recno o -1 si no está indexado.
+func (tag *Tag) position(recno int) int {
+	key, ok := tag.keys[recno]
+	if !ok {
+		return -1
+	}
+	i := sort.Search(len(tag.entries), func(i int) bool {
+		e := tag.entries[i]
+		return e.key > key || (e.key == key && e.recno >= recno)
+	})
+	if i < len(tag.entries) && tag.entries[i].recno == recno {
+		return i
+	}
+	return -1
+}
+
+// visible indica si la entrada i forma parte del recorrido: en un tag
+// Unique solo la primera de cada clave.
+func (tag *Tag) visible(i int) bool {
+	return !tag.Unique || i == 0 || tag.entries[i-1].key != tag.entries[i].key
+}
+
+// step avanza desde la posición i en el sentido del recorrido (dir 1 o -1)
+// hasta la siguiente entrada visible; devuelve -1 al salir del tag.
+func (tag *Tag) step(i int, dir int) int {
+	if tag.Descending {
+		dir = -dir
+	}
+	for i += dir; i >= 0 && i < len(tag.entries); i += dir {
+		if tag.visible(i) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (tag *Tag) recnoAt(i int) int {
+	if i < 0 {
+		return 0
+	}
+	return tag.entries[i].recno
+}
+
+// First devuelve el primer registro en el orden del tag (0 si está vacío).
+func (tag *Tag) First() int {
+	start := -1
+	if tag.Descending {
+		start = len(tag.entries)
+	}
+	return tag.recnoAt(tag.step(start, 1))
+}
+
+// Last devuelve el último registro en el orden del tag (0 si está vacío).
+func (tag *Tag) Last() int {
+	start := len(tag.entries)
+	if tag.Descending {
+		start = -1
+	}
+	return tag.recnoAt(tag.step(start, -1))
+}
+
+// Next devuelve el registro siguiente (dir 1) o anterior (dir -1) a recno
+// en el orden del tag; 0 si no hay más o recno no está indexado.
+func (tag *Tag) Next(recno int, dir int) int {
+	i := tag.position(recno)
+	if i < 0 {
+		return 0
+	}
+	return tag.recnoAt(tag.step(i, dir))
+}
+
+// Seek busca, en el orden del tag, el primer registro cuya clave es value
+// y que acepta accept (nil acepta todos). Con partial una clave de
+// caracteres también coincide si empieza por value (SET EXACT OFF).
+// Devuelve 0 si no lo encuentra.
+func (tag *Tag) Seek(value interface{}, partial bool, accept func(recno int) bool) (int, error) {
+	key, keyType, err := encodeKey(value)
+	if err != nil {
+		return 0, err
+	}
+	if keyType != 0 && tag.keyType != 0 && keyType != tag.keyType {
+		return 0, fmt.Errorf("data type mismatch in SEEK on tag `%s`", tag.Name)
+	}
+	lo := tag.search(key)
+	hi := lo
+	for hi < len(tag.entries) {
+		e := tag.entries[hi].key
+		if e != key && !(partial && keyType == Character && strings.HasPrefix(e, key)) {
+			break
+		}
+		hi++
+	}
+	i, dir := lo, 1
+	if tag.Descending {
+		i, dir = hi-1, -1
+	}
+	for ; i >= lo && i < hi; i += dir {
+		if !tag.visible(i) {
+			continue
+		}
+		if recno := tag.entries[i].recno; accept == nil || accept(recno) {
+			return recno, nil
+		}
+	}
+	return 0, nil
+}
+
+// Build reconstruye el tag con las claves de los registros 1 a count que
+// devuelve keyOf (indexed es false si el registro no cumple la condición
+// FOR). Es mucho más rápido que llamar a Set para cada registro.
+func (tag *Tag) Build(count int, keyOf func(recno int) (value interface{}, indexed bool, err error)) error {
+	tag.Reset()
+	entries := make([]entry, 0, count)
+	for recno := 1; recno <= count; recno++ {
+		value, indexed, err := keyOf(recno)
+		if err != nil {
+			return err
+		}
+		if !indexed {
+			continue
+		}
+		key, keyType, err := encodeKey(value)
+		if err != nil {
+			return err
+		}
+		if keyType != 0 {
+			if tag.keyType != 0 && keyType != tag.keyType {
+				return fmt.Errorf("index key type mismatch in tag `%s`", tag.Name)
+			}
+			tag.keyType = keyType
+		}
+		entries = append(entries, entry{key: key, recno: recno})
+	}
+	// los registros ya están en orden, así que basta un orden estable por clave
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+	for i := range entries {
+		if tag.Candidate && i > 0 && entries[i].key == entries[i-1].key {
+			tag.Reset()
+			return &UniquenessError{Tag: tag.Name}
+		}
+		tag.keys[entries[i].recno] = entries[i].key
+	}
+	tag.entries = entries
+	return nil
+}
+
+// Contains indica si el registro recno está en el tag.
+func (tag *Tag) Contains(recno int) bool {
+	_, ok := tag.keys[recno]
+	return ok
+}
+
+// encodeKey codifica un valor de campo como clave de índice y devuelve
+// también su tipo (0 para null).
+func encodeKey(value interface{}) (string, byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return "\x00", 0, nil
+	case string:
+		return "\x01" + strings.TrimRight(v, " "), Character, nil
+	case float64:
+		return "\x01" + encodeNumberKey(v), Numeric, nil
+	case bool:
+		if v {
+			return "\x01T", Logical, nil
+		}
+		return "\x01F", Logical, nil
+	case time.Time:
+		seconds := float64(v.Unix()) + float64(v.Nanosecond())/1e9
+		return "\x01" + encodeNumberKey(seconds), Date, nil
+	}
+	return "", 0, fmt.Errorf("invalid index key type %T", value)
+}
+
+// KeyTypeOf devuelve el tipo de clave de un valor como lo guarda un tag; 0
+// para null o un valor que no puede ser clave.
+func KeyTypeOf(value interface{}) byte {
+	_, keyType, _ := encodeKey(value)
+	return keyType
+}
+
+// encodeNumberKey codifica un float64 en 8 bytes que se ordenan como el
+// número: se invierte el bit de signo de los positivos y todos los bits de
+// los negativos.
+func encodeNumberKey(v float64) string {
+	if v == 0 {
+		v = 0 // -0 y 0 son la misma clave
+	}
+	bits := math.Float64bits(v)
+	if bits&(1<<63) == 0 {
+		bits ^= 1 << 63
+	} else {
+		bits = ^bits
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], bits)
+	return string(buf[:])
+}
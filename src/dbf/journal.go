@@ -0,0 +1,593 @@
+package dbf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Diario de transacciones (.tjl).
+//
+// Mientras una tabla participa en una transacción, antes de modificar una
+// zona del .dbf o del archivo de memos se guarda en el diario su contenido
+// original. ROLLBACK, o la apertura de la tabla después de que el programa
+// se interrumpiera en medio de una transacción, restaura esas zonas en
+// orden inverso y recorta los archivos al tamaño que tenían al comenzar;
+// END TRANSACTION guarda los archivos en disco y borra el diario.
+//
+// Cada entrada comienza con un byte de tipo: 'L' marca el comienzo de un
+// nivel de transacción y guarda el tamaño del .dbf y el del archivo de
+// memos (-1 si no tiene); 'D' y 'M' guardan una zona del .dbf o del
+// archivo de memos: posición, longitud y contenido; 'C' guarda el nombre
+// del registro de confirmación. Los números son little-endian. El diario
+// se crea con la primera escritura y cada entrada llega al disco antes que
+// la escritura que protege, así que una entrada incompleta al final
+// corresponde a una escritura que no se hizo y se ignora.
+//
+// Cuando la transacción modificó varias tablas, borrar los diarios uno a
+// uno no es atómico. EndTransactions agrega a cada diario la entrada 'C'
+// y después escribe el registro de confirmación (.tjc), que lista los
+// diarios; recién entonces los borra y al final borra el registro. Un
+// diario cuyo registro de confirmación existe pertenece a una transacción
+// confirmada y se descarta sin deshacer nada.
+
+const (
+	journalLevel  = 'L'
+	journalTable  = 'D'
+	journalMemo   = 'M'
+	journalCommit = 'C'
+
+	journalLevelSize = 17
+	journalEntrySize = 13
+)
+
+// ErrTransaction se devuelve al intentar una operación que no se puede
+// deshacer dentro de una transacción.
+var ErrTransaction = errors.New("operation is not allowed during a transaction")
+
+type journal struct {
+	path   string
+	file   *os.File // nil hasta la primera escritura
+	levels []*transactionLevel
+}
+
+type transactionLevel struct {
+	tableSize int64
+	memoSize  int64
+	start     int64 // posición del diario donde comienza (-1 si no se escribió)
+	saved     map[journalKey]bool
+}
+
+type journalKey struct {
+	kind   byte
+	offset int64
+	size   int
+}
+
+type journalEntry struct {
+	kind      byte
+	offset    int64
+	data      []byte
+	tableSize int64
+	memoSize  int64
+}
+
+// activeJournals guarda los diarios de las transacciones en curso para no
+// confundirlos con los que quedaron de una interrupción.
+var activeJournals = map[string]bool{}
+
+func journalPath(tablePath string) string {
+	if abs, err := filepath.Abs(tablePath); err == nil {
+		tablePath = abs
+	}
+	return strings.TrimSuffix(tablePath, filepath.Ext(tablePath)) + ".tjl"
+}
+
+// BeginTransaction comienza un nivel de transacción en la tabla.
+func (t *Table) BeginTransaction() error {
+	level := &transactionLevel{memoSize: -1, start: -1, saved: map[journalKey]bool{}}
+	info, err := t.file.Stat()
+	if err != nil {
+		return err
+	}
+	level.tableSize = info.Size()
+	if t.memo != nil && !t.shared {
+		if info, err = t.memo.file.Stat(); err != nil {
+			return err
+		}
+		level.memoSize = info.Size()
+	}
+	if t.journal == nil {
+		t.journal = &journal{path: journalPath(t.Path)}
+	}
+	t.journal.levels = append(t.journal.levels, level)
+	if t.memo != nil {
+		t.memo.journal = t.journal
+	}
+	return nil
+}
+
+// TransactionLevel devuelve la cantidad de niveles de transacción en curso.
+func (t *Table) TransactionLevel() int {
+	if t.journal == nil {
+		return 0
+	}
+	return len(t.journal.levels)
+}
+
+// EndTransaction confirma el nivel de transacción actual. Al confirmar el
+// último nivel los cambios se guardan en disco y se borra el diario.
+func (t *Table) EndTransaction() error {
+	j := t.journal
+	if j == nil {
+		return nil
+	}
+	if n := len(j.levels); n > 1 {
+		// el nivel anterior ya tiene guardado el contenido original de
+		// las zonas que modificó este
+		for key := range j.levels[n-1].saved {
+			j.levels[n-2].saved[key] = true
+		}
+		j.levels = j.levels[:n-1]
+		return nil
+	}
+	if j.file != nil {
+		if err := t.file.Sync(); err != nil {
+			return err
+		}
+		if t.memo != nil {
+			if err := t.memo.file.Sync(); err != nil {
+				return err
+			}
+		}
+	}
+	err := t.leaveTransaction()
+	if removeErr := j.remove(); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// EndTransactions confirma el nivel de transacción actual de varias tablas
+// a la vez. Si es el último nivel y más de una tabla tiene cambios, se
+// confirman con un registro de confirmación; si no se puede escribir, los
+// cambios de todas se deshacen y se devuelve el error.
+func EndTransactions(tables []*Table) error {
+	var pending []*Table
+	var err error
+	for _, t := range tables {
+		if t.journal != nil && len(t.journal.levels) == 1 && t.journal.file != nil {
+			pending = append(pending, t)
+		} else if endErr := t.EndTransaction(); err == nil {
+			err = endErr
+		}
+	}
+	if len(pending) == 1 {
+		if endErr := pending[0].EndTransaction(); err == nil {
+			err = endErr
+		}
+	}
+	if len(pending) < 2 {
+		return err
+	}
+	commit, commitErr := writeCommit(pending)
+	if commitErr != nil {
+		for _, t := range pending {
+			t.RollbackTransaction()
+		}
+		return commitErr
+	}
+	for _, t := range pending {
+		if endErr := t.EndTransaction(); err == nil {
+			err = endErr
+		}
+	}
+	if removeErr := os.Remove(commit); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// writeCommit guarda en disco los cambios de las tablas, agrega a sus
+// diarios el nombre del registro de confirmación y lo escribe. Devuelve su
+// nombre.
+func writeCommit(tables []*Table) (string, error) {
+	// el nombre es único para no confundirlo con el de otra transacción
+	base := strings.TrimSuffix(tables[0].journal.path, ".tjl")
+	commit := fmt.Sprintf("%s-%d.tjc", base, time.Now().UnixNano())
+	var list strings.Builder
+	for _, t := range tables {
+		if err := t.file.Sync(); err != nil {
+			return "", err
+		}
+		if t.memo != nil {
+			if err := t.memo.file.Sync(); err != nil {
+				return "", err
+			}
+		}
+		entry := make([]byte, 5+len(commit))
+		entry[0] = journalCommit
+		binary.LittleEndian.PutUint32(entry[1:5], uint32(len(commit)))
+		copy(entry[5:], commit)
+		if err := t.journal.append(entry); err != nil {
+			return "", err
+		}
+		list.WriteString(t.journal.path + "\n")
+	}
+	file, err := os.OpenFile(commit, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", err
+	}
+	_, err = file.WriteString(list.String())
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(commit)
+		return "", err
+	}
+	syncDir(filepath.Dir(commit))
+	return commit, nil
+}
+
+// syncDir guarda en disco el directorio, para que no se pierda un archivo
+// recién creado. No todos los sistemas lo permiten.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// RollbackTransaction deshace los cambios del nivel de transacción actual y
+// descarta los cambios pendientes del buffer.
+func (t *Table) RollbackTransaction() error {
+	j := t.journal
+	if j == nil {
+		return nil
+	}
+	level := j.levels[len(j.levels)-1]
+	j.levels = j.levels[:len(j.levels)-1]
+	var err error
+	if level.start >= 0 {
+		var memo *os.File
+		if t.memo != nil {
+			memo = t.memo.file
+		}
+		err = j.undo(level.start, t.file, memo)
+		if t.index != nil {
+			t.index.stale = true
+		}
+	}
+	if len(j.levels) == 0 {
+		if leaveErr := t.leaveTransaction(); err == nil {
+			err = leaveErr
+		}
+		if removeErr := j.remove(); err == nil {
+			err = removeErr
+		}
+	}
+	if len(t.Revert()) > 0 && t.index != nil {
+		t.index.stale = true
+	}
+	if reloadErr := t.reload(); err == nil {
+		err = reloadErr
+	}
+	return err
+}
+
+func (t *Table) leaveTransaction() error {
+	t.journal = nil
+	if t.memo != nil {
+		t.memo.journal = nil
+	}
+	return t.unlockTransaction()
+}
+
+// reload vuelve a leer la cantidad de registros y el próximo bloque libre
+// de memos después de restaurar los archivos.
+func (t *Table) reload() error {
+	var header [headerSize]byte
+	if _, err := t.file.ReadAt(header[:], 0); err != nil {
+		return err
+	}
+	t.count = int(binary.LittleEndian.Uint32(header[4:8]))
+	t.changes = binary.LittleEndian.Uint32(header[16:20])
+	if t.buffer != nil {
+		t.count += t.buffer.added
+	}
+	if t.memo != nil {
+		var next [4]byte
+		if _, err := t.memo.file.ReadAt(next[:], 0); err != nil {
+			return err
+		}
+		t.memo.next = binary.BigEndian.Uint32(next[:])
+	}
+	return nil
+}
+
+// writeAt escribe en el .dbf guardando antes en el diario lo que se
+// reemplaza.
+func (t *Table) writeAt(data []byte, offset int64) error {
+	if t.journal != nil {
+		if err := t.journal.save(journalTable, t.file, offset, len(data)); err != nil {
+			return err
+		}
+	}
+	_, err := t.file.WriteAt(data, offset)
+	return err
+}
+
+// save guarda en el diario el contenido de size bytes de file a partir de
+// offset antes de que se modifiquen. Cada zona se guarda una vez por nivel.
+func (j *journal) save(kind byte, file *os.File, offset int64, size int) error {
+	level := j.levels[len(j.levels)-1]
+	key := journalKey{kind: kind, offset: offset, size: size}
+	if level.saved[key] {
+		return nil
+	}
+	if err := j.open(); err != nil {
+		return err
+	}
+	data := make([]byte, size)
+	n, err := file.ReadAt(data, offset)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	entry := make([]byte, journalEntrySize+n)
+	entry[0] = kind
+	binary.LittleEndian.PutUint64(entry[1:9], uint64(offset))
+	binary.LittleEndian.PutUint32(entry[9:13], uint32(n))
+	copy(entry[journalEntrySize:], data[:n])
+	if err := j.append(entry); err != nil {
+		return err
+	}
+	level.saved[key] = true
+	return nil
+}
+
+// open crea el diario si hace falta y escribe las marcas de los niveles
+// que todavía no tienen.
+func (j *journal) open() error {
+	if j.file == nil {
+		file, err := os.OpenFile(j.path, os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		j.file = file
+		activeJournals[j.path] = true
+	}
+	for _, level := range j.levels {
+		if level.start >= 0 {
+			continue
+		}
+		info, err := j.file.Stat()
+		if err != nil {
+			return err
+		}
+		var entry [journalLevelSize]byte
+		entry[0] = journalLevel
+		binary.LittleEndian.PutUint64(entry[1:9], uint64(level.tableSize))
+		binary.LittleEndian.PutUint64(entry[9:17], uint64(level.memoSize))
+		if err := j.append(entry[:]); err != nil {
+			return err
+		}
+		level.start = info.Size()
+	}
+	return nil
+}
+
+func (j *journal) append(entry []byte) error {
+	if _, err := j.file.Write(entry); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// undo restaura las zonas guardadas desde la posición start del diario y
+// lo recorta en esa posición.
+func (j *journal) undo(start int64, table *os.File, memo *os.File) error {
+	info, err := j.file.Stat()
+	if err != nil {
+		return err
+	}
+	data := make([]byte, info.Size()-start)
+	if _, err := j.file.ReadAt(data, start); err != nil && err != io.EOF {
+		return err
+	}
+	if err := undoEntries(parseJournal(data), table, memo); err != nil {
+		return err
+	}
+	return j.file.Truncate(start)
+}
+
+// remove cierra y borra el diario.
+func (j *journal) remove() error {
+	if j.file == nil {
+		return nil
+	}
+	delete(activeJournals, j.path)
+	err := j.file.Close()
+	j.file = nil
+	if removeErr := os.Remove(j.path); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// parseJournal lee las entradas del diario hasta la primera incompleta.
+func parseJournal(data []byte) []journalEntry {
+	var entries []journalEntry
+	for len(data) > 0 {
+		switch data[0] {
+		case journalLevel:
+			if len(data) < journalLevelSize {
+				return entries
+			}
+			entries = append(entries, journalEntry{
+				kind:      journalLevel,
+				tableSize: int64(binary.LittleEndian.Uint64(data[1:9])),
+				memoSize:  int64(binary.LittleEndian.Uint64(data[9:17])),
+			})
+			data = data[journalLevelSize:]
+		case journalCommit:
+			if len(data) < 5 {
+				return entries
+			}
+			end := 5 + int(binary.LittleEndian.Uint32(data[1:5]))
+			if len(data) < end {
+				return entries
+			}
+			entries = append(entries, journalEntry{kind: journalCommit, data: data[5:end]})
+			data = data[end:]
+		case journalTable, journalMemo:
+			if len(data) < journalEntrySize {
+				return entries
+			}
+			end := journalEntrySize + int(binary.LittleEndian.Uint32(data[9:13]))
+			if len(data) < end {
+				return entries
+			}
+			entries = append(entries, journalEntry{
+				kind:   data[0],
+				offset: int64(binary.LittleEndian.Uint64(data[1:9])),
+				data:   data[journalEntrySize:end],
+			})
+			data = data[end:]
+		default:
+			return entries
+		}
+	}
+	return entries
+}
+
+// undoEntries aplica las entradas en orden inverso.
+func undoEntries(entries []journalEntry, table *os.File, memo *os.File) error {
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		var err error
+		switch e.kind {
+		case journalLevel:
+			err = table.Truncate(e.tableSize)
+			if err == nil && memo != nil && e.memoSize >= 0 {
+				err = memo.Truncate(e.memoSize)
+			}
+		case journalTable:
+			_, err = table.WriteAt(e.data, e.offset)
+		case journalMemo:
+			if memo == nil {
+				return errors.New("memo file is missing")
+			}
+			_, err = memo.WriteAt(e.data, e.offset)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recoverJournal deshace la transacción que quedó sin terminar en una
+// tabla cerrada (por ejemplo, porque el programa se interrumpió) e indica
+// si hubo que hacerlo. También termina o descarta un PACK interrumpido: el
+// archivo de memos nuevo reemplaza al anterior si ya no queda diario.
+func recoverJournal(path string, readOnly bool) (bool, error) {
+	jpath := journalPath(path)
+	if activeJournals[jpath] {
+		return false, nil
+	}
+	data, err := os.ReadFile(jpath)
+	pending := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	packed := packedMemoPath(path)
+	if !pending && !fileExists(packed) {
+		return false, nil
+	}
+	table, err := openTableFile(path, os.O_RDWR, 0)
+	if err != nil && readOnly {
+		return false, errors.New("table has an unfinished transaction")
+	}
+	if err != nil {
+		return false, err
+	}
+	defer closeTableFile(path, table)
+	// si otro proceso tiene abierta la tabla el diario es de su transacción
+	ok, err := lockRange(table, lockWrite, openLockOffset, 1, false)
+	if err == ErrNoLocking {
+		ok, err = true, nil
+	}
+	if err != nil || !ok {
+		return false, err
+	}
+	defer lockRange(table, lockRelease, openLockOffset, 1, false)
+	if !pending {
+		return true, os.Rename(packed, memoPath(path))
+	}
+	entries := parseJournal(data)
+	if commit := committed(entries); commit != "" {
+		// la transacción se confirmó pero no se llegó a borrar el diario
+		if err := os.Remove(jpath); err != nil {
+			return false, err
+		}
+		removeCommit(commit)
+		return true, nil
+	}
+	var memo *os.File
+	if memoFile := memoPath(path); fileExists(memoFile) {
+		if memo, err = os.OpenFile(memoFile, os.O_RDWR, 0); err != nil {
+			return false, err
+		}
+		defer memo.Close()
+	}
+	if err := undoEntries(entries, table, memo); err != nil {
+		return false, err
+	}
+	if err := table.Sync(); err != nil {
+		return false, err
+	}
+	if memo != nil {
+		if err := memo.Sync(); err != nil {
+			return false, err
+		}
+	}
+	if err := os.Remove(packed); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	return true, os.Remove(jpath)
+}
+
+// committed devuelve el nombre del registro de confirmación del diario si
+// existe, es decir, si la transacción se confirmó.
+func committed(entries []journalEntry) string {
+	for _, e := range entries {
+		if e.kind == journalCommit && fileExists(string(e.data)) {
+			return string(e.data)
+		}
+	}
+	return ""
+}
+
+// removeCommit borra el registro de confirmación cuando ya no queda
+// ninguno de los diarios que lista.
+func removeCommit(commit string) {
+	data, err := os.ReadFile(commit)
+	if err != nil {
+		return
+	}
+	for _, jpath := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if fileExists(jpath) {
+			return
+		}
+	}
+	os.Remove(commit)
+}
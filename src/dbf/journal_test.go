@@ -0,0 +1,300 @@
+package dbf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestTable crea una tabla con un campo numérico y un memo y los
+// registros indicados.
+func newTestTable(t *testing.T, dir string, ids ...int) *Table {
+	t.Helper()
+	table, err := Create(filepath.Join(dir, "data.dbf"), []Field{
+		{Name: "ID", Type: Integer, Length: 4},
+		{Name: "NOTES", Type: Memo, Length: 4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { table.Close() })
+	for _, id := range ids {
+		r, err := table.AppendBlank()
+		if err != nil {
+			t.Fatal(err)
+		}
+		setRecord(t, table, r, id, "original")
+	}
+	return table
+}
+
+func setRecord(t *testing.T, table *Table, r *Record, id int, notes string) {
+	t.Helper()
+	if err := r.SetValue(0, float64(id)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetValue(1, notes); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.WriteRecord(r); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func updateRecord(t *testing.T, table *Table, recno, id int, notes string) {
+	t.Helper()
+	r, err := table.Record(recno)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setRecord(t, table, r, id, notes)
+}
+
+func appendRecord(t *testing.T, table *Table, id int, notes string) {
+	t.Helper()
+	r, err := table.AppendBlank()
+	if err != nil {
+		t.Fatal(err)
+	}
+	setRecord(t, table, r, id, notes)
+}
+
+// expectRecords comprueba la cantidad de registros y el contenido de cada
+// uno (id y memo).
+func expectRecords(t *testing.T, table *Table, want ...interface{}) {
+	t.Helper()
+	if table.RecordCount() != len(want)/2 {
+		t.Fatalf("RecordCount = %d, want %d", table.RecordCount(), len(want)/2)
+	}
+	for i := 0; i < len(want); i += 2 {
+		r, err := table.Record(i/2 + 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := r.Value(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		notes, err := r.Value(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != float64(want[i].(int)) || notes != want[i+1] {
+			t.Fatalf("record %d = %v, %q; want %v, %q", i/2+1, id, notes, want[i], want[i+1])
+		}
+	}
+}
+
+func expectNoJournal(t *testing.T, path string) {
+	t.Helper()
+	if _, err := os.Stat(journalPath(path)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("journal of %s was not removed (%v)", path, err)
+	}
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+// copyFiles copia los archivos de la tabla, incluido el diario, a otro
+// directorio, como quedarían si el programa se interrumpiera en ese punto.
+func copyFiles(t *testing.T, table *Table, dir string) string {
+	t.Helper()
+	for _, name := range []string{table.Path, memoPath(table.Path), journalPath(table.Path)} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(name)), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, filepath.Base(table.Path))
+}
+
+func TestRollbackTransaction(t *testing.T) {
+	table := newTestTable(t, t.TempDir(), 1, 2)
+	size := fileSize(t, table.Path)
+	memoSize := fileSize(t, memoPath(table.Path))
+
+	if err := table.BeginTransaction(); err != nil {
+		t.Fatal(err)
+	}
+	updateRecord(t, table, 1, 10, "changed in the transaction")
+	appendRecord(t, table, 3, "appended")
+	if err := table.RollbackTransaction(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectRecords(t, table, 1, "original", 2, "original")
+	if table.TransactionLevel() != 0 {
+		t.Fatalf("TransactionLevel = %d, want 0", table.TransactionLevel())
+	}
+	if got := fileSize(t, table.Path); got != size {
+		t.Fatalf("table size after ROLLBACK = %d, want %d", got, size)
+	}
+	if got := fileSize(t, memoPath(table.Path)); got != memoSize {
+		t.Fatalf("memo size after ROLLBACK = %d, want %d", got, memoSize)
+	}
+	expectNoJournal(t, table.Path)
+}
+
+func TestNestedTransactions(t *testing.T) {
+	table := newTestTable(t, t.TempDir(), 1, 2)
+
+	if err := table.BeginTransaction(); err != nil {
+		t.Fatal(err)
+	}
+	updateRecord(t, table, 1, 10, "outer")
+	if err := table.BeginTransaction(); err != nil {
+		t.Fatal(err)
+	}
+	updateRecord(t, table, 1, 20, "inner")
+	appendRecord(t, table, 3, "inner")
+	if err := table.RollbackTransaction(); err != nil {
+		t.Fatal(err)
+	}
+	expectRecords(t, table, 10, "outer", 2, "original")
+
+	// lo que confirma el nivel interior se deshace con el exterior
+	if err := table.BeginTransaction(); err != nil {
+		t.Fatal(err)
+	}
+	updateRecord(t, table, 2, 30, "inner")
+	if err := table.EndTransaction(); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.RollbackTransaction(); err != nil {
+		t.Fatal(err)
+	}
+	expectRecords(t, table, 1, "original", 2, "original")
+	expectNoJournal(t, table.Path)
+}
+
+func TestEndTransaction(t *testing.T) {
+	table := newTestTable(t, t.TempDir(), 1)
+
+	if err := table.BeginTransaction(); err != nil {
+		t.Fatal(err)
+	}
+	updateRecord(t, table, 1, 10, "committed")
+	appendRecord(t, table, 2, "committed")
+	if err := table.EndTransaction(); err != nil {
+		t.Fatal(err)
+	}
+	expectNoJournal(t, table.Path)
+	if err := table.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	table, err := Open(table.Path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer table.Close()
+	expectRecords(t, table, 10, "committed", 2, "committed")
+}
+
+func TestRecoverInterruptedTransaction(t *testing.T) {
+	table := newTestTable(t, t.TempDir(), 1, 2)
+	size := fileSize(t, table.Path)
+	if err := table.BeginTransaction(); err != nil {
+		t.Fatal(err)
+	}
+	updateRecord(t, table, 2, 20, "changed in the transaction")
+	appendRecord(t, table, 3, "appended")
+	path := copyFiles(t, table, t.TempDir())
+
+	recovered, err := Open(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recovered.Close()
+	expectRecords(t, recovered, 1, "original", 2, "original")
+	expectNoJournal(t, path)
+	if got := fileSize(t, path); got != size {
+		t.Fatalf("table size after recovery = %d, want %d", got, size)
+	}
+
+	// la transacción en curso no se toca al abrir otra vez la tabla
+	if err := table.RollbackTransaction(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecoverCommittedTransaction(t *testing.T) {
+	first := newTestTable(t, t.TempDir(), 1)
+	second := newTestTable(t, t.TempDir(), 2)
+	tables := []*Table{first, second}
+	for _, table := range tables {
+		if err := table.BeginTransaction(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	updateRecord(t, first, 1, 10, "committed")
+	updateRecord(t, second, 1, 20, "committed")
+
+	// interrupción después de escribir el registro de confirmación y antes
+	// de borrar los diarios
+	commit, err := writeCommit(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(commit)
+	path := copyFiles(t, first, t.TempDir())
+
+	recovered, err := Open(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recovered.Close()
+	expectRecords(t, recovered, 10, "committed")
+	expectNoJournal(t, path)
+
+	for _, table := range tables {
+		if err := table.EndTransaction(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRecoverIgnoresIncompleteEntry(t *testing.T) {
+	table := newTestTable(t, t.TempDir(), 1)
+	if err := table.BeginTransaction(); err != nil {
+		t.Fatal(err)
+	}
+	updateRecord(t, table, 1, 10, "changed in the transaction")
+	path := copyFiles(t, table, t.TempDir())
+	if err := table.RollbackTransaction(); err != nil {
+		t.Fatal(err)
+	}
+
+	// una entrada cortada al final corresponde a una escritura que no se
+	// llegó a hacer
+	jpath := journalPath(path)
+	file, err := os.OpenFile(jpath, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = file.Write([]byte{journalTable, 1, 2, 3})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recovered, err := Open(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recovered.Close()
+	expectRecords(t, recovered, 1, "original")
+	expectNoJournal(t, path)
+}
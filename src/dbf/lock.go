@@ -0,0 +1,403 @@
+package dbf
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Tablas compartidas entre procesos.
+//
+// Los bloqueos son bloqueos de rango de bytes (fcntl en los sistemas Unix;
+// en el resto no hay tablas compartidas) sobre zonas del .dbf que están
+// fuera de su contenido, como en Visual FoxPro: el registro n se bloquea en
+// el byte lockOffset - n, la cabecera (que se bloquea para agregar
+// registros) en lockOffset y FLOCK() bloquea todo el rango desde el byte 1
+// hasta la cabecera. Además cada proceso que abre la
+// tabla mantiene un bloqueo de lectura en openLockOffset, que es de
+// escritura si la abre en exclusiva.
+//
+// Las escrituras en una tabla compartida se hacen con un bloqueo breve en
+// updateLockOffset, durante el que se vuelve a leer la cabecera. Los bytes
+// 16-19 de la cabecera (reservados en el formato) cuentan las escrituras:
+// si cambiaron desde la última lectura otro proceso modificó la tabla y se
+// actualiza la cantidad de registros y se marca el índice como
+// desactualizado.
+//
+// Dentro de una transacción la tabla mantiene bloqueada la cabecera desde
+// la primera escritura hasta el final, para que ningún otro proceso agregue
+// registros que ROLLBACK recortaría. Los memos no se recortan ni se
+// restaura la cabecera del archivo de memos, porque otros procesos pueden
+// haber agregado memos mientras tanto.
+
+const (
+	lockOffset       = 0x7FFFFFFE
+	maxLockRecno     = lockOffset - 1
+	openLockOffset   = lockOffset + 1
+	updateLockOffset = lockOffset + 2
+)
+
+// ErrInUse se devuelve al abrir una tabla que otro proceso tiene abierta de
+// forma incompatible.
+var ErrInUse = errors.New("file is in use by another program")
+
+// ErrNoLocking se devuelve al abrir una tabla compartida en un sistema sin
+// bloqueos de archivos.
+var ErrNoLocking = errors.New("shared tables are not supported on this platform")
+
+// Los bloqueos de fcntl pertenecen al proceso y se pierden todos al cerrar
+// cualquier descriptor del archivo, aunque no sea el que se usó para
+// bloquear. Por eso los .dbf se abren con openTableFile y se cierran con
+// closeTableFile, que cierra los descriptores de un archivo recién cuando
+// no queda ninguno en uso.
+type tableFile struct {
+	refs   int
+	closed []*os.File
+}
+
+var tableFiles = map[string]*tableFile{}
+
+func tableFileKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func openTableFile(path string, flag int, perm os.FileMode) (*os.File, error) {
+	file, err := os.OpenFile(path, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	key := tableFileKey(path)
+	tf := tableFiles[key]
+	if tf == nil {
+		tf = &tableFile{}
+		tableFiles[key] = tf
+	}
+	tf.refs++
+	return file, nil
+}
+
+func closeTableFile(path string, file *os.File) error {
+	key := tableFileKey(path)
+	tf := tableFiles[key]
+	if tf == nil {
+		return file.Close()
+	}
+	tf.closed = append(tf.closed, file)
+	if tf.refs--; tf.refs > 0 {
+		return nil
+	}
+	delete(tableFiles, key)
+	var err error
+	for _, f := range tf.closed {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// Share registra la apertura de la tabla: en exclusiva falla si otro
+// proceso la tiene abierta y compartida falla si otro la tiene abierta en
+// exclusiva.
+func (t *Table) Share(exclusive bool) error {
+	mode := lockRead
+	if exclusive && !t.readOnly {
+		mode = lockWrite
+	}
+	ok, err := lockRange(t.file, mode, openLockOffset, 1, false)
+	if err == ErrNoLocking && exclusive {
+		// sin bloqueos no se puede comprobar que otro proceso no use la
+		// tabla: se supone que no
+		ok, err = true, nil
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInUse
+	}
+	t.shared = !exclusive
+	if t.memo != nil {
+		t.memo.shared = t.shared
+	}
+	return nil
+}
+
+// Shared indica si la tabla está abierta en modo compartido.
+func (t *Table) Shared() bool {
+	return t.shared
+}
+
+// LockRecord intenta bloquear el registro recno sin esperar; devuelve
+// false si lo tiene bloqueado otro proceso. En una tabla exclusiva siempre
+// lo consigue.
+func (t *Table) LockRecord(recno int) (bool, error) {
+	if recno < 1 || recno > maxLockRecno {
+		return false, errors.New("record is out of range")
+	}
+	if !t.shared || t.fileLocked || t.locks[recno] {
+		return true, nil
+	}
+	ok, err := lockRange(t.file, lockWrite, lockOffset-int64(recno), 1, false)
+	if ok {
+		if t.locks == nil {
+			t.locks = map[int]bool{}
+		}
+		t.locks[recno] = true
+	}
+	return ok, err
+}
+
+// UnlockRecord libera el bloqueo del registro recno.
+func (t *Table) UnlockRecord(recno int) error {
+	if !t.locks[recno] {
+		return nil
+	}
+	delete(t.locks, recno)
+	if t.fileLocked {
+		// está dentro del rango de FLOCK(), que se libera aparte
+		return nil
+	}
+	_, err := lockRange(t.file, lockRelease, lockOffset-int64(recno), 1, false)
+	return err
+}
+
+// LockFile intenta bloquear toda la tabla sin esperar; devuelve false si
+// otro proceso tiene bloqueado algún registro o la tabla.
+func (t *Table) LockFile() (bool, error) {
+	if !t.shared || t.fileLocked {
+		return true, nil
+	}
+	ok, err := lockRange(t.file, lockWrite, 1, lockOffset, false)
+	if ok {
+		t.fileLocked = true
+	}
+	return ok, err
+}
+
+// UnlockFile libera el bloqueo de la tabla, conservando los de los
+// registros y la cabecera que estaban bloqueados aparte.
+func (t *Table) UnlockFile() error {
+	if !t.fileLocked {
+		return nil
+	}
+	t.fileLocked = false
+	if _, err := lockRange(t.file, lockRelease, 1, lockOffset, false); err != nil {
+		return err
+	}
+	return t.relock()
+}
+
+// LockHeader intenta bloquear la cabecera sin esperar, para agregar
+// registros.
+func (t *Table) LockHeader() (bool, error) {
+	if !t.shared {
+		return true, nil
+	}
+	if !t.headerHeld() {
+		if ok, err := lockRange(t.file, lockWrite, lockOffset, 1, false); !ok {
+			return false, err
+		}
+	}
+	t.headerLocked = true
+	return true, nil
+}
+
+// UnlockHeader libera el bloqueo de la cabecera.
+func (t *Table) UnlockHeader() error {
+	if !t.headerLocked {
+		return nil
+	}
+	t.headerLocked = false
+	if t.headerHeld() {
+		return nil
+	}
+	_, err := lockRange(t.file, lockRelease, lockOffset, 1, false)
+	return err
+}
+
+// UnlockAll libera los bloqueos de la tabla, salvo el de la cabecera
+// durante una transacción.
+func (t *Table) UnlockAll() error {
+	if !t.shared {
+		return nil
+	}
+	t.locks = nil
+	t.fileLocked = false
+	t.headerLocked = false
+	if _, err := lockRange(t.file, lockRelease, 1, lockOffset, false); err != nil {
+		return err
+	}
+	return t.relock()
+}
+
+// relock vuelve a bloquear los registros y la cabecera que se conservan
+// después de liberar un rango que los incluía.
+func (t *Table) relock() error {
+	for recno := range t.locks {
+		ok, err := lockRange(t.file, lockWrite, lockOffset-int64(recno), 1, false)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			delete(t.locks, recno)
+		}
+	}
+	if t.headerLocked || t.txnHeader {
+		_, err := lockRange(t.file, lockWrite, lockOffset, 1, true)
+		return err
+	}
+	return nil
+}
+
+// RecordLocked indica si el proceso tiene bloqueado el registro recno
+// (directamente o con FLOCK()).
+func (t *Table) RecordLocked(recno int) bool {
+	return t.fileLocked || t.locks[recno]
+}
+
+// FileLocked indica si el proceso tiene bloqueada toda la tabla.
+func (t *Table) FileLocked() bool {
+	return t.fileLocked
+}
+
+// LockedRecords devuelve en orden los registros bloqueados uno a uno.
+func (t *Table) LockedRecords() []int {
+	var recnos []int
+	for recno := range t.locks {
+		recnos = append(recnos, recno)
+	}
+	sort.Ints(recnos)
+	return recnos
+}
+
+func (t *Table) headerHeld() bool {
+	return t.fileLocked || t.headerLocked || t.txnHeader
+}
+
+// Refresh vuelve a leer la cabecera de una tabla compartida. Si otro
+// proceso la modificó actualiza la cantidad de registros, renumera los
+// registros agregados que están en el buffer y marca el índice como
+// desactualizado. Devuelve cuánto se corrieron los registros agregados.
+func (t *Table) Refresh() (int, error) {
+	if !t.shared {
+		return 0, nil
+	}
+	var header [headerSize]byte
+	if _, err := t.file.ReadAt(header[:], 0); err != nil {
+		return 0, err
+	}
+	changes := binary.LittleEndian.Uint32(header[16:20])
+	if changes == t.changes {
+		return 0, nil
+	}
+	t.changes = changes
+	disk := int(binary.LittleEndian.Uint32(header[4:8]))
+	shift := disk - t.diskCount()
+	if t.buffer != nil && shift != 0 && t.buffer.added > 0 {
+		records := map[int]*bufferedRecord{}
+		for recno, b := range t.buffer.records {
+			if b.orig == nil {
+				recno += shift
+			}
+			records[recno] = b
+		}
+		t.buffer.records = records
+	}
+	t.count = disk
+	if t.buffer != nil {
+		t.count += t.buffer.added
+	}
+	// no se sabe qué registros cambió el otro proceso: el índice se
+	// reconstruye completo (ver la limitación descrita en index.go)
+	if t.index != nil {
+		t.index.stale = true
+	}
+	return shift, nil
+}
+
+// update ejecuta fn, que escribe en la tabla, con el bloqueo de
+// actualización y después de leer los cambios de otros procesos; con
+// appending también con la cabecera bloqueada. En una tabla exclusiva solo
+// ejecuta fn.
+func (t *Table) update(appending bool, fn func() error) error {
+	if !t.shared {
+		return fn()
+	}
+	// la cabecera siempre se bloquea antes que la zona de actualización,
+	// para que dos procesos no se esperen mutuamente
+	if err := t.lockTransaction(); err != nil {
+		return err
+	}
+	release := false
+	if appending && !t.headerHeld() {
+		if _, err := lockRange(t.file, lockWrite, lockOffset, 1, true); err != nil {
+			return err
+		}
+		release = true
+	}
+	err := t.lockedUpdate(fn)
+	if release {
+		if _, unlockErr := lockRange(t.file, lockRelease, lockOffset, 1, false); err == nil {
+			err = unlockErr
+		}
+	}
+	return err
+}
+
+func (t *Table) lockedUpdate(fn func() error) error {
+	if _, err := lockRange(t.file, lockWrite, updateLockOffset, 1, true); err != nil {
+		return err
+	}
+	_, err := t.Refresh()
+	if err == nil {
+		err = fn()
+	}
+	if _, unlockErr := lockRange(t.file, lockRelease, updateLockOffset, 1, false); err == nil {
+		err = unlockErr
+	}
+	return err
+}
+
+// lockTransaction bloquea la cabecera de una tabla compartida en la
+// primera escritura de una transacción y toma como tamaño inicial del .dbf
+// el que tiene en ese momento.
+func (t *Table) lockTransaction() error {
+	if t.journal == nil || t.txnHeader {
+		return nil
+	}
+	if !t.fileLocked && !t.headerLocked {
+		if _, err := lockRange(t.file, lockWrite, lockOffset, 1, true); err != nil {
+			return err
+		}
+	}
+	t.txnHeader = true
+	info, err := t.file.Stat()
+	if err != nil {
+		return err
+	}
+	for _, level := range t.journal.levels {
+		if level.start < 0 {
+			level.tableSize = info.Size()
+		}
+	}
+	return nil
+}
+
+// unlockTransaction libera la cabecera bloqueada por la transacción.
+func (t *Table) unlockTransaction() error {
+	if !t.txnHeader {
+		return nil
+	}
+	t.txnHeader = false
+	if t.headerHeld() {
+		return nil
+	}
+	_, err := lockRange(t.file, lockRelease, lockOffset, 1, false)
+	return err
+}
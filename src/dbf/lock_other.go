@@ -0,0 +1,17 @@
+//go:build !unix
+
+package dbf
+
+import "os"
+
+const (
+	lockRead    = 0
+	lockWrite   = 1
+	lockRelease = 2
+)
+
+// lockRange falla siempre fuera de los sistemas Unix, que no tienen
+// bloqueos de fcntl: las tablas solo se pueden abrir en exclusiva.
+func lockRange(file *os.File, mode int, start int64, length int64, wait bool) (bool, error) {
+	return false, ErrNoLocking
+}
@@ -0,0 +1,313 @@
+//go:build unix
+
+package dbf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Los bloqueos de fcntl pertenecen al proceso: dos tablas abiertas en el
+// mismo proceso no se bloquean entre sí. Por eso estos tests usan el propio
+// binario de los tests como segundo proceso (ver TestMain), que abre la
+// tabla compartida y ejecuta las órdenes que recibe por stdin.
+
+const helperEnv = "FOXLITE_DBF_HELPER"
+
+func TestMain(m *testing.M) {
+	if path := os.Getenv(helperEnv); path != "" {
+		os.Exit(runHelper(path, os.Stdin, os.Stdout))
+	}
+	os.Exit(m.Run())
+}
+
+// runHelper abre la tabla compartida y contesta una línea por orden:
+//
+//	rlock n | unlock n | flock | unlockall | header | unheader
+//	append n (agrega un registro con n en el primer campo) | quit
+func runHelper(path string, in io.Reader, out io.Writer) int {
+	t, err := Open(path, false)
+	if err == nil {
+		err = t.Share(false)
+	}
+	if err != nil {
+		fmt.Fprintln(out, "error:", err)
+		return 1
+	}
+	defer t.Close()
+	fmt.Fprintln(out, "ready")
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		words := strings.Fields(scanner.Text())
+		if len(words) == 0 {
+			continue
+		}
+		n := 0
+		if len(words) > 1 {
+			n, _ = strconv.Atoi(words[1])
+		}
+		var reply interface{}
+		switch words[0] {
+		case "rlock":
+			reply, err = t.LockRecord(n)
+		case "unlock":
+			err = t.UnlockRecord(n)
+			reply = "ok"
+		case "flock":
+			reply, err = t.LockFile()
+		case "unlockall":
+			err = t.UnlockAll()
+			reply = "ok"
+		case "header":
+			reply, err = t.LockHeader()
+		case "unheader":
+			err = t.UnlockHeader()
+			reply = "ok"
+		case "append":
+			var r *Record
+			if r, err = t.AppendBlank(); err == nil {
+				if err = r.SetValue(0, float64(n)); err == nil {
+					err = t.WriteRecord(r)
+				}
+				reply = r.Recno
+			}
+		case "quit":
+			return 0
+		default:
+			err = fmt.Errorf("unknown command `%s`", words[0])
+		}
+		if err != nil {
+			fmt.Fprintln(out, "error:", err)
+			continue
+		}
+		fmt.Fprintln(out, reply)
+	}
+	return 0
+}
+
+// otherProcess es el segundo proceso que usa la tabla.
+type otherProcess struct {
+	cmd *exec.Cmd
+	in  io.WriteCloser
+	out *bufio.Scanner
+}
+
+func startOther(t *testing.T, path string) *otherProcess {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	cmd.Env = append(os.Environ(), helperEnv+"="+path)
+	cmd.Stderr = os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	p := &otherProcess{cmd: cmd, in: in, out: bufio.NewScanner(stdout)}
+	t.Cleanup(p.stop)
+	if reply := p.read(t); reply != "ready" {
+		t.Fatalf("other process: %s", reply)
+	}
+	return p
+}
+
+func (p *otherProcess) read(t *testing.T) string {
+	t.Helper()
+	if !p.out.Scan() {
+		t.Fatal("other process exited")
+	}
+	return p.out.Text()
+}
+
+// do envía una orden y devuelve la respuesta.
+func (p *otherProcess) do(t *testing.T, command string) string {
+	t.Helper()
+	if _, err := fmt.Fprintln(p.in, command); err != nil {
+		t.Fatal(err)
+	}
+	return p.read(t)
+}
+
+func (p *otherProcess) stop() {
+	fmt.Fprintln(p.in, "quit")
+	p.in.Close()
+	p.cmd.Wait()
+}
+
+// sharedTable crea una tabla con count registros y la abre compartida.
+func sharedTable(t *testing.T, count int) (*Table, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "shared.dbf")
+	table, err := Create(path, []Field{{Name: "ID", Type: Integer}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= count; i++ {
+		r, err := table.AppendBlank()
+		if err == nil {
+			err = r.SetValue(0, float64(i))
+		}
+		if err == nil {
+			err = table.WriteRecord(r)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := table.Close(); err != nil {
+		t.Fatal(err)
+	}
+	table, err = Open(path, false)
+	if err == nil {
+		err = table.Share(false)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { table.Close() })
+	return table, path
+}
+
+func expectLock(t *testing.T, what string, ok bool, err error, want bool) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", what, err)
+	}
+	if ok != want {
+		t.Fatalf("%s = %v, want %v", what, ok, want)
+	}
+}
+
+func TestRecordLockContention(t *testing.T) {
+	table, path := sharedTable(t, 2)
+	other := startOther(t, path)
+
+	if reply := other.do(t, "rlock 1"); reply != "true" {
+		t.Fatalf("other rlock 1 = %s", reply)
+	}
+	ok, err := table.LockRecord(1)
+	expectLock(t, "LockRecord(1) while the other process holds it", ok, err, false)
+	ok, err = table.LockRecord(2)
+	expectLock(t, "LockRecord(2)", ok, err, true)
+	if reply := other.do(t, "rlock 2"); reply != "false" {
+		t.Fatalf("other rlock 2 = %s, want false", reply)
+	}
+
+	other.do(t, "unlock 1")
+	ok, err = table.LockRecord(1)
+	expectLock(t, "LockRecord(1) after the other process released it", ok, err, true)
+}
+
+func TestFileLockContention(t *testing.T) {
+	table, path := sharedTable(t, 2)
+	other := startOther(t, path)
+
+	if reply := other.do(t, "rlock 2"); reply != "true" {
+		t.Fatalf("other rlock 2 = %s", reply)
+	}
+	ok, err := table.LockFile()
+	expectLock(t, "LockFile() with a record locked by the other process", ok, err, false)
+
+	other.do(t, "unlockall")
+	ok, err = table.LockFile()
+	expectLock(t, "LockFile()", ok, err, true)
+	if reply := other.do(t, "rlock 1"); reply != "false" {
+		t.Fatalf("other rlock 1 under FLOCK = %s, want false", reply)
+	}
+	if reply := other.do(t, "flock"); reply != "false" {
+		t.Fatalf("other flock under FLOCK = %s, want false", reply)
+	}
+
+	if err := table.UnlockFile(); err != nil {
+		t.Fatal(err)
+	}
+	if reply := other.do(t, "rlock 1"); reply != "true" {
+		t.Fatalf("other rlock 1 after UnlockFile = %s, want true", reply)
+	}
+}
+
+func TestAppendWaitsForHeaderLock(t *testing.T) {
+	table, path := sharedTable(t, 1)
+	other := startOther(t, path)
+
+	if reply := other.do(t, "header"); reply != "true" {
+		t.Fatalf("other header = %s", reply)
+	}
+	ok, err := table.LockHeader()
+	expectLock(t, "LockHeader() while the other process holds it", ok, err, false)
+
+	type result struct {
+		recno int
+		err   error
+	}
+	done := make(chan result)
+	go func() {
+		r, err := table.AppendBlank()
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		done <- result{recno: r.Recno}
+	}()
+	select {
+	case res := <-done:
+		t.Fatalf("AppendBlank did not wait for the header lock: %+v", res)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// el otro proceso agrega con la cabecera bloqueada y la libera: el
+	// registro que estaba esperando queda después del suyo
+	if reply := other.do(t, "append 20"); reply != "2" {
+		t.Fatalf("other append = %s, want 2", reply)
+	}
+	other.do(t, "unheader")
+	res := <-done
+	if res.err != nil {
+		t.Fatal(res.err)
+	}
+	if res.recno != 3 || table.RecordCount() != 3 {
+		t.Fatalf("AppendBlank recno %d, RecordCount %d; want 3 and 3", res.recno, table.RecordCount())
+	}
+}
+
+func TestRefreshSeesOtherWriter(t *testing.T) {
+	table, path := sharedTable(t, 1)
+	other := startOther(t, path)
+
+	if reply := other.do(t, "append 42"); reply != "2" {
+		t.Fatalf("other append = %s, want 2", reply)
+	}
+	if table.RecordCount() != 1 {
+		t.Fatalf("RecordCount before Refresh = %d, want 1", table.RecordCount())
+	}
+	if _, err := table.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if table.RecordCount() != 2 {
+		t.Fatalf("RecordCount after Refresh = %d, want 2", table.RecordCount())
+	}
+	r, err := table.Record(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := r.Value(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != float64(42) {
+		t.Fatalf("record 2 = %v, want 42", value)
+	}
+}
@@ -0,0 +1,37 @@
+//go:build unix
+
+package dbf
+
+import (
+	"os"
+	"syscall"
+)
+
+const (
+	lockRead    = syscall.F_RDLCK
+	lockWrite   = syscall.F_WRLCK
+	lockRelease = syscall.F_UNLCK
+)
+
+// lockRange bloquea (o libera con lockRelease) length bytes de file a
+// partir de start con fcntl. Sin wait devuelve false si otro proceso tiene
+// un bloqueo incompatible; con wait espera a que lo libere.
+func lockRange(file *os.File, mode int, start int64, length int64, wait bool) (bool, error) {
+	lk := syscall.Flock_t{Type: int16(mode), Whence: 0, Start: start, Len: length}
+	cmd := syscall.F_SETLK
+	if wait {
+		cmd = syscall.F_SETLKW
+	}
+	for {
+		err := syscall.FcntlFlock(file.Fd(), cmd, &lk)
+		switch err {
+		case nil:
+			return true, nil
+		case syscall.EINTR:
+			continue
+		case syscall.EAGAIN, syscall.EACCES:
+			return false, nil
+		}
+		return false, err
+	}
+}
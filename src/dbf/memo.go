@@ -0,0 +1,323 @@
+package dbf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Archivo de memos de FoxPro (.fpt).
+//
+// La cabecera ocupa 512 bytes: los bytes 0-3 guardan el próximo bloque
+// libre y los bytes 6-7 el tamaño de bloque (ambos big-endian). Cada memo
+// ocupa uno o más bloques consecutivos y comienza con 8 bytes: el tipo
+// (0 binario, 1 texto) y la longitud de los datos, también big-endian.
+// Los campos memo de la tabla guardan el número del primer bloque (0 si el
+// memo está vacío).
+
+// Tipos de memo.
+const (
+	MemoBinary = 0
+	MemoText   = 1
+)
+
+const (
+	memoHeaderSize       = 512
+	memoBlockHeader      = 8
+	DefaultMemoBlockSize = 64
+)
+
+type memoFile struct {
+	path      string
+	file      *os.File
+	blockSize int
+	next      uint32   // próximo bloque libre
+	journal   *journal // transacción en curso de la tabla
+	shared    bool     // la tabla está abierta en modo compartido
+}
+
+// memoPath devuelve el nombre del archivo de memos de una tabla: el mismo
+// nombre con extensión .fpt (o .FPT si existe así); el de una base de datos
+// (.dbc) tiene extensión .dct.
+func memoPath(tablePath string) string {
+	ext := ".fpt"
+	if strings.EqualFold(filepath.Ext(tablePath), ".dbc") {
+		ext = ".dct"
+	}
+	base := strings.TrimSuffix(tablePath, filepath.Ext(tablePath))
+	path := base + ext
+	if _, err := os.Stat(path); err != nil {
+		if _, err := os.Stat(base + strings.ToUpper(ext)); err == nil {
+			return base + strings.ToUpper(ext)
+		}
+	}
+	return path
+}
+
+func openMemo(path string, readOnly bool) (*memoFile, error) {
+	flag := os.O_RDWR
+	if readOnly {
+		flag = os.O_RDONLY
+	}
+	file, err := os.OpenFile(path, flag, 0)
+	if err != nil {
+		return nil, fmt.Errorf("memo file is missing or invalid: %v", err)
+	}
+	var header [8]byte
+	if _, err := file.ReadAt(header[:], 0); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: invalid memo file", path)
+	}
+	m := &memoFile{
+		path:      path,
+		file:      file,
+		next:      binary.BigEndian.Uint32(header[0:4]),
+		blockSize: int(binary.BigEndian.Uint16(header[6:8])),
+	}
+	if m.blockSize == 0 {
+		// FoxPro guarda 0 cuando el tamaño de bloque es 1 byte (SET BLOCKSIZE TO 0)
+		m.blockSize = 1
+	}
+	return m, nil
+}
+
+func createMemo(path string, blockSize int) (*memoFile, error) {
+	if blockSize < 1 || blockSize > 0xFFFF {
+		return nil, fmt.Errorf("invalid memo block size %d", blockSize)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	m := &memoFile{path: path, file: file, blockSize: blockSize}
+	if err := m.reset(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// reset deja el archivo solo con la cabecera.
+func (m *memoFile) reset() error {
+	m.next = uint32((memoHeaderSize + m.blockSize - 1) / m.blockSize)
+	if err := m.file.Truncate(0); err != nil {
+		return err
+	}
+	if err := m.file.Truncate(int64(m.next) * int64(m.blockSize)); err != nil {
+		return err
+	}
+	return m.writeHeader()
+}
+
+func (m *memoFile) writeHeader() error {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[0:4], m.next)
+	binary.BigEndian.PutUint16(header[6:8], uint16(m.blockSize))
+	return m.writeAt(header[:], 0)
+}
+
+// writeAt escribe en el archivo guardando antes en el diario lo que se
+// reemplaza.
+func (m *memoFile) writeAt(data []byte, offset int64) error {
+	// la cabecera de una tabla compartida no se restaura: otros procesos
+	// pueden haber agregado memos después
+	if m.journal != nil && !(m.shared && offset == 0) {
+		if err := m.journal.save(journalMemo, m.file, offset, len(data)); err != nil {
+			return err
+		}
+	}
+	_, err := m.file.WriteAt(data, offset)
+	return err
+}
+
+func (m *memoFile) close() error {
+	return m.file.Close()
+}
+
+// blocks devuelve la cantidad de bloques que ocupa un memo de size bytes.
+func (m *memoFile) blocks(size int) uint32 {
+	return uint32((memoBlockHeader + size + m.blockSize - 1) / m.blockSize)
+}
+
+// read devuelve el tipo y el contenido del memo que comienza en block.
+func (m *memoFile) read(block uint32) (uint32, []byte, error) {
+	if block == 0 {
+		return MemoText, nil, nil
+	}
+	offset := int64(block) * int64(m.blockSize)
+	var header [memoBlockHeader]byte
+	if _, err := m.file.ReadAt(header[:], offset); err != nil {
+		return 0, nil, fmt.Errorf("memo block %d is out of range", block)
+	}
+	kind := binary.BigEndian.Uint32(header[0:4])
+	size := binary.BigEndian.Uint32(header[4:8])
+	// una longitud que excede el archivo es de un bloque dañado: no se
+	// reserva memoria para ella
+	info, err := m.file.Stat()
+	if err != nil {
+		return 0, nil, err
+	}
+	if int64(size) > info.Size()-offset-memoBlockHeader {
+		return 0, nil, fmt.Errorf("memo block %d is corrupted", block)
+	}
+	data := make([]byte, size)
+	if _, err := m.file.ReadAt(data, offset+memoBlockHeader); err != nil {
+		return 0, nil, fmt.Errorf("memo block %d is corrupted", block)
+	}
+	return kind, data, nil
+}
+
+// write guarda un memo y devuelve su primer bloque. Si el memo anterior
+// (old) tiene espacio suficiente, o es el último del archivo y puede crecer,
+// se reutilizan sus bloques; si no, se agrega al final. Un memo vacío no
+// ocupa bloques.
+func (m *memoFile) write(old uint32, kind uint32, data []byte) (uint32, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+	block := m.next
+	if old != 0 {
+		var header [memoBlockHeader]byte
+		if _, err := m.file.ReadAt(header[:], int64(old)*int64(m.blockSize)); err == nil {
+			used := m.blocks(int(binary.BigEndian.Uint32(header[4:8])))
+			if m.blocks(len(data)) <= used || old+used == m.next {
+				block = old
+			}
+		}
+	}
+	buf := make([]byte, int(m.blocks(len(data)))*m.blockSize)
+	binary.BigEndian.PutUint32(buf[0:4], kind)
+	binary.BigEndian.PutUint32(buf[4:8], uint32(len(data)))
+	copy(buf[memoBlockHeader:], data)
+	if err := m.writeAt(buf, int64(block)*int64(m.blockSize)); err != nil {
+		return 0, err
+	}
+	if end := block + m.blocks(len(data)); end > m.next {
+		m.next = end
+		if err := m.writeHeader(); err != nil {
+			return 0, err
+		}
+	}
+	return block, nil
+}
+
+// MemoBlockSize devuelve el tamaño de bloque del archivo de memos (0 si la
+// tabla no tiene memos).
+func (t *Table) MemoBlockSize() int {
+	if t.memo == nil {
+		return 0
+	}
+	return t.memo.blockSize
+}
+
+func (t *Table) hasMemoFields() bool {
+	for _, f := range t.Fields {
+		if f.Type == Memo {
+			return true
+		}
+	}
+	return false
+}
+
+// readMemo devuelve el contenido del memo del campo f.
+func (t *Table) readMemo(f *Field, block uint32) (string, error) {
+	if block == 0 {
+		return "", nil
+	}
+	if t.memo == nil {
+		return "", errors.New("memo file is missing")
+	}
+	kind, data, err := t.memo.read(block)
+	if err != nil {
+		return "", err
+	}
+	if kind != MemoText || f.Binary() {
+		return string(data), nil
+	}
+	return t.cp.decode(data), nil
+}
+
+// writeMemo guarda el contenido del memo del campo f y devuelve su bloque.
+func (t *Table) writeMemo(f *Field, old uint32, text string) (uint32, error) {
+	if t.memo == nil {
+		return 0, errors.New("memo file is missing")
+	}
+	if t.readOnly {
+		return 0, ErrReadOnly
+	}
+	kind, data := uint32(MemoText), t.cp.encode(text)
+	if f.Binary() {
+		kind, data = MemoBinary, []byte(text)
+	}
+	var block uint32
+	err := t.update(false, func() error {
+		if t.shared {
+			// otros procesos pueden haber agregado memos
+			var next [4]byte
+			if _, err := t.memo.file.ReadAt(next[:], 0); err != nil {
+				return err
+			}
+			t.memo.next = binary.BigEndian.Uint32(next[:])
+		}
+		var err error
+		block, err = t.memo.write(old, kind, data)
+		return err
+	})
+	return block, err
+}
+
+// packedMemoPath devuelve el nombre del archivo de memos nuevo que escribe
+// PACK.
+func packedMemoPath(tablePath string) string {
+	return memoPath(tablePath) + ".tmp"
+}
+
+// copyMemos copia los memos del registro r al final de dst y guarda en r
+// sus nuevos números de bloque. Indica si alguno cambió.
+func (t *Table) copyMemos(r *Record, dst *memoFile) (bool, error) {
+	changed := false
+	for _, f := range t.Fields {
+		if f.Type != Memo {
+			continue
+		}
+		value, err := f.decode(r.buf[f.offset:f.offset+f.Length], t.cp)
+		if err != nil {
+			return false, err
+		}
+		old := value.(uint32)
+		kind, data, err := t.memo.read(old)
+		if err != nil {
+			return false, err
+		}
+		block, err := dst.write(0, kind, data)
+		if err != nil {
+			return false, err
+		}
+		if block != old {
+			f.encode(r.buf[f.offset:f.offset+f.Length], block, t.cp)
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// replaceMemo reemplaza el archivo de memos por el que escribió PACK.
+func (t *Table) replaceMemo(packed *memoFile) error {
+	if err := packed.close(); err != nil {
+		return err
+	}
+	path := t.memo.path
+	shared := t.memo.shared
+	t.memo.close()
+	err := os.Rename(packed.path, path)
+	memo, openErr := openMemo(path, false)
+	if openErr != nil {
+		return openErr
+	}
+	memo.shared = shared
+	t.memo = memo
+	return err
+}
@@ -0,0 +1,788 @@
+// Package dbf lee y escribe tablas de dBASE y Visual FoxPro (.dbf).
+//
+// Una tabla se compone de una cabecera de 32 bytes, un descriptor de 32
+// bytes por cada campo terminado en 0x0D (más 263 bytes de enlace a la base
+// de datos en las tablas de Visual FoxPro) y a continuación los registros
+// de longitud fija. Cada registro comienza con la marca de borrado (' ' o
+// '*') seguida del contenido de los campos. El archivo termina con 0x1A.
+package dbf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Versiones de tabla (byte 0 de la cabecera).
+const (
+	VersionDBase3     = 0x03
+	VersionDBase3Memo = 0x83
+	VersionFoxPro     = 0x30
+	VersionFoxProInc  = 0x31
+	VersionFoxPro2    = 0xF5
+)
+
+// Flags de la tabla (byte 28 de la cabecera).
+const (
+	TableHasCDX  = 0x01
+	TableHasMemo = 0x02
+	TableIsDBC   = 0x04
+)
+
+const (
+	headerSize    = 32
+	fieldSize     = 32
+	backlinkSize  = 263
+	fieldTerm     = 0x0D
+	eofMarker     = 0x1A
+	deletedMarker = '*'
+)
+
+// ErrReadOnly se devuelve al intentar modificar una tabla abierta en modo
+// de solo lectura.
+var ErrReadOnly = errors.New("table is read-only")
+
+// Table es una tabla abierta.
+type Table struct {
+	Path    string
+	Fields  []*Field // campos visibles, sin los campos de sistema
+	Version byte
+	Flags   byte
+
+	fields    []*Field // todos los campos, incluido _NullFlags
+	nullFlags *Field
+	names     map[string]int
+	cp        *codePage
+	headerLen int
+	recordLen int
+	count     int
+	file      *os.File
+	memo      *memoFile
+	index     *Index
+	readOnly  bool
+	buffer    *buffer  // cambios sin guardar (nil sin buffer)
+	journal   *journal // transacción en curso (nil fuera de una transacción)
+
+	shared       bool         // abierta en modo compartido
+	changes      uint32       // contador de escrituras de la cabecera
+	locks        map[int]bool // registros bloqueados
+	fileLocked   bool
+	headerLocked bool
+	txnHeader    bool // cabecera bloqueada por la transacción en curso
+}
+
+// Open abre una tabla existente.
+func Open(path string, readOnly bool) (*Table, error) {
+	flag := os.O_RDWR
+	if readOnly {
+		flag = os.O_RDONLY
+	}
+	recovered, err := recoverJournal(path, readOnly)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	file, err := openTableFile(path, flag, 0)
+	if err != nil && !readOnly && errors.Is(err, os.ErrPermission) {
+		// archivos de solo lectura
+		file, err = openTableFile(path, os.O_RDONLY, 0)
+		readOnly = true
+	}
+	if err != nil {
+		return nil, err
+	}
+	t := &Table{Path: path, file: file, readOnly: readOnly}
+	if err := t.readHeader(); err != nil {
+		closeTableFile(path, file)
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if t.hasMemoFields() {
+		if t.memo, err = openMemo(memoPath(path), readOnly); err != nil {
+			closeTableFile(path, file)
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	if err := t.openIndex(); err != nil {
+		t.Close()
+		return nil, err
+	}
+	if recovered && t.index != nil {
+		t.index.stale = true
+	}
+	return t, nil
+}
+
+// Create crea una tabla de Visual FoxPro vacía con los campos indicados y
+// la deja abierta. Si existe un archivo con ese nombre se reemplaza. Los
+// nombres de más de 10 caracteres se guardan en el .dbf abreviados; el
+// nombre largo dura mientras la tabla esté abierta, salvo que la tabla se
+// agregue a una base de datos.
+func Create(path string, fields []Field) (*Table, error) {
+	if len(fields) == 0 {
+		return nil, errors.New("a table must have at least one field")
+	}
+	t := &Table{Path: path, Version: VersionFoxPro, cp: newCodePage(CodePageWin1252)}
+	used := map[string]bool{}
+	for _, f := range fields {
+		used[strings.ToUpper(strings.TrimSpace(f.Name))] = true
+	}
+	nullable := 0
+	offset := 1
+	for i := range fields {
+		f := fields[i]
+		long := strings.ToUpper(strings.TrimSpace(f.Name))
+		if len(long) > 10 && len(long) <= maxLongName {
+			f.Name = shortName(long, used)
+		}
+		if err := f.normalize(); err != nil {
+			return nil, err
+		}
+		if _, dup := t.fieldByName(long); dup {
+			return nil, fmt.Errorf("field `%s` is duplicated", long)
+		}
+		if long != f.Name {
+			f.short, f.Name = f.Name, long
+		}
+		f.offset = offset
+		f.nullBit = -1
+		if f.Nullable() {
+			f.nullBit = nullable
+			nullable++
+		}
+		if f.Type == Memo {
+			t.Flags |= TableHasMemo
+		}
+		offset += f.Length
+		t.fields = append(t.fields, &f)
+	}
+	if nullable > 0 {
+		t.nullFlags = &Field{
+			Name:    "_NullFlags",
+			Type:    NullFlags,
+			Length:  (nullable + 7) / 8,
+			Flags:   FlagSystem | FlagBinary,
+			offset:  offset,
+			nullBit: -1,
+		}
+		offset += t.nullFlags.Length
+		t.fields = append(t.fields, t.nullFlags)
+	}
+	t.recordLen = offset
+	t.headerLen = headerSize + len(t.fields)*fieldSize + 1 + backlinkSize
+	t.indexFields()
+
+	file, err := openTableFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	t.file = file
+	if err := t.writeHeader(true); err != nil {
+		closeTableFile(path, file)
+		return nil, err
+	}
+	if _, err := file.WriteAt([]byte{eofMarker}, int64(t.headerLen)); err != nil {
+		closeTableFile(path, file)
+		return nil, err
+	}
+	if t.hasMemoFields() {
+		if t.memo, err = createMemo(memoPath(path), DefaultMemoBlockSize); err != nil {
+			closeTableFile(path, file)
+			return nil, err
+		}
+	}
+	// el índice de una tabla anterior con el mismo nombre ya no sirve
+	os.Remove(indexPath(path))
+	return t, nil
+}
+
+// Close cierra la tabla. Una transacción sin terminar se deshace y los
+// cambios pendientes del buffer se descartan.
+func (t *Table) Close() error {
+	if t.file == nil {
+		return nil
+	}
+	var err error
+	for t.journal != nil && err == nil {
+		err = t.RollbackTransaction()
+	}
+	if len(t.Revert()) > 0 && t.index != nil {
+		t.index.stale = true
+	}
+	if t.shared {
+		// el descriptor puede seguir abierto para otra tabla del proceso
+		if unlockErr := t.UnlockAll(); err == nil {
+			err = unlockErr
+		}
+		// el índice solo vale si no hay cambios de otros procesos sin ver
+		if _, refreshErr := t.Refresh(); err == nil {
+			err = refreshErr
+		}
+	}
+	if indexErr := t.saveIndex(); err == nil {
+		err = indexErr
+	}
+	if closeErr := closeTableFile(t.Path, t.file); err == nil {
+		err = closeErr
+	}
+	t.file = nil
+	if t.memo != nil {
+		if memoErr := t.memo.close(); err == nil {
+			err = memoErr
+		}
+		t.memo = nil
+	}
+	return err
+}
+
+// Drop cierra la tabla y borra sus archivos (tablas temporales).
+func (t *Table) Drop() error {
+	files := []string{t.Path}
+	if t.memo != nil {
+		files = append(files, t.memo.path)
+	}
+	if t.index != nil {
+		t.index.tags = nil // no hace falta guardarlo
+		t.index.dirty = false
+		if _, err := os.Stat(t.index.path); err == nil {
+			files = append(files, t.index.path)
+		}
+	}
+	err := t.Close()
+	for _, file := range files {
+		if removeErr := os.Remove(file); err == nil {
+			err = removeErr
+		}
+	}
+	return err
+}
+
+// Rename reemplaza la tabla to por la tabla from, ambas cerradas: el .dbf y
+// el archivo de memos de from pasan a llamarse como los de to y se borran
+// los archivos de to que no tienen reemplazo, incluido su índice.
+func Rename(from string, to string) error {
+	toBase := strings.TrimSuffix(to, filepath.Ext(to))
+	for _, path := range []string{memoPath(to), indexPath(to)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if memo := memoPath(from); fileExists(memo) {
+		if err := os.Rename(memo, toBase+".fpt"); err != nil {
+			return err
+		}
+	}
+	if index := indexPath(from); fileExists(index) {
+		if err := os.Rename(index, indexPath(to)); err != nil {
+			return err
+		}
+	}
+	return os.Rename(from, to)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// ReadOnly indica si la tabla se abrió en modo de solo lectura.
+func (t *Table) ReadOnly() bool {
+	return t.readOnly
+}
+
+// RecordCount devuelve la cantidad de registros, incluidos los borrados.
+func (t *Table) RecordCount() int {
+	return t.count
+}
+
+// RecordLength devuelve la longitud de cada registro en bytes.
+func (t *Table) RecordLength() int {
+	return t.recordLen
+}
+
+// FieldIndex devuelve la posición del campo en Fields o -1 si no existe.
+// El nombre no distingue mayúsculas.
+func (t *Table) FieldIndex(name string) int {
+	if idx, ok := t.names[strings.ToUpper(name)]; ok {
+		return idx
+	}
+	return -1
+}
+
+func (t *Table) fieldByName(name string) (*Field, bool) {
+	for _, f := range t.fields {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+func (t *Table) indexFields() {
+	t.Fields = nil
+	t.names = map[string]int{}
+	for _, f := range t.fields {
+		if f.Flags&FlagSystem != 0 || f.Type == NullFlags {
+			continue
+		}
+		t.names[f.Name] = len(t.Fields)
+		t.Fields = append(t.Fields, f)
+	}
+}
+
+func (t *Table) readHeader() error {
+	var header [headerSize]byte
+	if _, err := t.file.ReadAt(header[:], 0); err != nil {
+		return errors.New("not a table")
+	}
+	t.Version = header[0]
+	t.count = int(binary.LittleEndian.Uint32(header[4:8]))
+	t.changes = binary.LittleEndian.Uint32(header[16:20])
+	t.headerLen = int(binary.LittleEndian.Uint16(header[8:10]))
+	t.recordLen = int(binary.LittleEndian.Uint16(header[10:12]))
+	t.Flags = header[28]
+	t.cp = newCodePage(header[29])
+	if t.headerLen < headerSize+1 || t.recordLen < 1 {
+		return errors.New("not a table")
+	}
+
+	descriptors := make([]byte, t.headerLen-headerSize)
+	if _, err := t.file.ReadAt(descriptors, headerSize); err != nil && err != io.EOF {
+		return errors.New("table header is corrupted")
+	}
+	offset := 1
+	nullable := 0
+	for pos := 0; pos+fieldSize <= len(descriptors) && descriptors[pos] != fieldTerm; pos += fieldSize {
+		d := descriptors[pos : pos+fieldSize]
+		name := string(d[0:11])
+		if idx := strings.IndexByte(name, 0); idx >= 0 {
+			name = name[:idx]
+		}
+		f := &Field{
+			Name:     strings.ToUpper(strings.TrimSpace(name)),
+			Type:     d[11],
+			Length:   int(d[16]),
+			Decimals: int(d[17]),
+			Flags:    d[18],
+			offset:   offset,
+			nullBit:  -1,
+		}
+		if f.Type == Character && t.Version != VersionFoxPro && t.Version != VersionFoxProInc {
+			// dBASE y FoxPro 2 usan el byte de decimales para campos de más de 255
+			f.Length += int(d[17]) << 8
+			f.Decimals = 0
+		}
+		if f.Type == NullFlags {
+			t.nullFlags = f
+		} else if f.Nullable() {
+			f.nullBit = nullable
+			nullable++
+		}
+		offset += f.Length
+		t.fields = append(t.fields, f)
+	}
+	if offset != t.recordLen {
+		return errors.New("table header is corrupted")
+	}
+	t.indexFields()
+	return nil
+}
+
+// writeHeader escribe la cabecera; con descriptors también los campos.
+// Cada escritura incrementa el contador de escrituras.
+func (t *Table) writeHeader(descriptors bool) error {
+	var header [headerSize]byte
+	now := time.Now()
+	t.changes++
+	header[0] = t.Version
+	header[1] = byte(now.Year() - 1900)
+	header[2] = byte(now.Month())
+	header[3] = byte(now.Day())
+	binary.LittleEndian.PutUint32(header[4:8], uint32(t.diskCount()))
+	binary.LittleEndian.PutUint16(header[8:10], uint16(t.headerLen))
+	binary.LittleEndian.PutUint16(header[10:12], uint16(t.recordLen))
+	binary.LittleEndian.PutUint32(header[16:20], t.changes)
+	header[28] = t.Flags
+	header[29] = t.cp.mark
+	if err := t.writeAt(header[:], 0); err != nil {
+		return err
+	}
+	if !descriptors {
+		return nil
+	}
+	data := make([]byte, t.headerLen-headerSize)
+	for i, f := range t.fields {
+		d := data[i*fieldSize : (i+1)*fieldSize]
+		copy(d[0:11], f.diskName())
+		d[11] = f.Type
+		binary.LittleEndian.PutUint32(d[12:16], uint32(f.offset))
+		d[16] = byte(f.Length)
+		d[17] = byte(f.Decimals)
+		d[18] = f.Flags
+	}
+	data[len(t.fields)*fieldSize] = fieldTerm
+	return t.writeAt(data, headerSize)
+}
+
+func (t *Table) recordOffset(recno int) int64 {
+	return int64(t.headerLen) + int64(recno-1)*int64(t.recordLen)
+}
+
+// Record lee el registro número recno (desde 1); con el buffer activo
+// devuelve la versión del buffer.
+func (t *Table) Record(recno int) (*Record, error) {
+	if recno < 1 || recno > t.count {
+		return nil, fmt.Errorf("record %d is out of range", recno)
+	}
+	if t.buffer != nil {
+		if b := t.buffer.records[recno]; b != nil {
+			return &Record{Recno: recno, table: t, buf: append([]byte{}, b.cur...)}, nil
+		}
+	}
+	buf := make([]byte, t.recordLen)
+	if _, err := t.file.ReadAt(buf, t.recordOffset(recno)); err != nil {
+		return nil, err
+	}
+	return &Record{Recno: recno, table: t, buf: buf}, nil
+}
+
+// WriteRecord guarda el registro en el archivo o, con el buffer activo, en
+// el buffer.
+func (t *Table) WriteRecord(r *Record) error {
+	if t.readOnly {
+		return ErrReadOnly
+	}
+	if t.buffer != nil {
+		return t.bufferRecord(r)
+	}
+	return t.update(false, func() error {
+		if r.Recno > t.count {
+			return fmt.Errorf("record %d is out of range", r.Recno)
+		}
+		if err := t.writeAt(r.buf, t.recordOffset(r.Recno)); err != nil {
+			return err
+		}
+		return t.writeHeader(false)
+	})
+}
+
+// AppendBlank agrega un registro vacío al final de la tabla. En una tabla
+// compartida el número del registro se decide con la cabecera bloqueada,
+// después de ver los registros que agregaron otros procesos.
+func (t *Table) AppendBlank() (*Record, error) {
+	if t.readOnly {
+		return nil, ErrReadOnly
+	}
+	r := &Record{Recno: t.count + 1, table: t, buf: t.blankRecord()}
+	if t.buffer != nil {
+		t.buffer.records[r.Recno] = &bufferedRecord{cur: append([]byte{}, r.buf...)}
+		t.buffer.added++
+		t.count++
+		return r, nil
+	}
+	err := t.update(true, func() error {
+		r.Recno = t.count + 1
+		data := append(append([]byte{}, r.buf...), eofMarker)
+		if err := t.writeAt(data, t.recordOffset(r.Recno)); err != nil {
+			return err
+		}
+		t.count++
+		return t.writeHeader(false)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Unappend descarta el último registro (recno), recién agregado con
+// AppendBlank, por ejemplo porque no cumple las reglas de la base de datos.
+// En una tabla compartida la cabecera debe seguir bloqueada desde que se
+// agregó, para que ningún otro proceso haya agregado registros después.
+func (t *Table) Unappend(recno int) error {
+	if t.readOnly {
+		return ErrReadOnly
+	}
+	if recno != t.count {
+		return fmt.Errorf("record %d is not the last record", recno)
+	}
+	if t.buffer != nil && t.Appended(recno) {
+		t.Revert(recno)
+		return nil
+	}
+	return t.update(true, func() error {
+		if recno != t.count {
+			return fmt.Errorf("record %d is not the last record", recno)
+		}
+		offset := t.recordOffset(recno)
+		if err := t.file.Truncate(offset); err != nil {
+			return err
+		}
+		if err := t.writeAt([]byte{eofMarker}, offset); err != nil {
+			return err
+		}
+		t.count--
+		return t.writeHeader(false)
+	})
+}
+
+// Blank devuelve un registro vacío que no pertenece a la tabla: es el que
+// se ve cuando el puntero está en el fin de archivo.
+func (t *Table) Blank() *Record {
+	return &Record{Recno: t.count + 1, table: t, buf: t.blankRecord()}
+}
+
+func (t *Table) blankRecord() []byte {
+	buf := make([]byte, t.recordLen)
+	buf[0] = ' '
+	for _, f := range t.fields {
+		copy(buf[f.offset:], f.blank())
+	}
+	return buf
+}
+
+// Zap elimina todos los registros.
+func (t *Table) Zap() error {
+	if t.readOnly {
+		return ErrReadOnly
+	}
+	if err := t.checkRewrite(); err != nil {
+		return err
+	}
+	t.count = 0
+	if err := t.file.Truncate(int64(t.headerLen)); err != nil {
+		return err
+	}
+	if _, err := t.file.WriteAt([]byte{eofMarker}, int64(t.headerLen)); err != nil {
+		return err
+	}
+	if t.memo != nil {
+		if err := t.memo.reset(); err != nil {
+			return err
+		}
+	}
+	for _, tag := range t.Tags() {
+		tag.Reset()
+	}
+	return t.writeHeader(false)
+}
+
+// Pack elimina definitivamente los registros marcados como borrados y
+// compacta el archivo de memos. Los cambios del .dbf se guardan en el
+// diario como en una transacción y los memos se copian en un archivo nuevo
+// que reemplaza al anterior después de borrar el diario: si el programa se
+// interrumpe antes, al abrir la tabla se deshacen los cambios y se descarta
+// el archivo nuevo; si se interrumpe después, se termina el reemplazo.
+func (t *Table) Pack() error {
+	if t.readOnly {
+		return ErrReadOnly
+	}
+	if err := t.checkRewrite(); err != nil {
+		return err
+	}
+	if err := t.BeginTransaction(); err != nil {
+		return err
+	}
+	// el diario tiene que existir antes que el archivo de memos nuevo
+	err := t.journal.open()
+	var memo *memoFile
+	if err == nil {
+		memo, err = t.pack()
+	}
+	if err == nil && memo != nil {
+		err = memo.file.Sync()
+	}
+	if err != nil {
+		if memo != nil {
+			memo.close()
+			os.Remove(memo.path)
+		}
+		t.RollbackTransaction()
+		return err
+	}
+	if err := t.EndTransaction(); err != nil {
+		return err
+	}
+	// los números de registro cambiaron: hay que reconstruir los tags
+	if t.index != nil {
+		for _, tag := range t.index.tags {
+			tag.Reset()
+		}
+		t.index.stale = true
+	}
+	if memo == nil {
+		return nil
+	}
+	return t.replaceMemo(memo)
+}
+
+// pack mueve los registros que quedan al comienzo de la tabla y la recorta.
+// Si la tabla tiene memos, los copia en un archivo nuevo que devuelve.
+func (t *Table) pack() (*memoFile, error) {
+	var memo *memoFile
+	if t.memo != nil {
+		var err error
+		if memo, err = createMemo(packedMemoPath(t.Path), t.memo.blockSize); err != nil {
+			return nil, err
+		}
+	}
+	kept := 0
+	for recno := 1; recno <= t.count; recno++ {
+		r, err := t.Record(recno)
+		if err != nil {
+			return memo, err
+		}
+		if r.Deleted() {
+			continue
+		}
+		kept++
+		changed := kept != recno
+		if memo != nil {
+			copied, err := t.copyMemos(r, memo)
+			if err != nil {
+				return memo, err
+			}
+			changed = changed || copied
+		}
+		if changed {
+			if err := t.writeAt(r.buf, t.recordOffset(kept)); err != nil {
+				return memo, err
+			}
+		}
+	}
+	end := t.recordOffset(kept + 1)
+	info, err := t.file.Stat()
+	if err != nil {
+		return memo, err
+	}
+	// lo que se recorta también se guarda en el diario
+	if info.Size() > end {
+		if err := t.journal.save(journalTable, t.file, end, int(info.Size()-end)); err != nil {
+			return memo, err
+		}
+	}
+	t.count = kept
+	if err := t.file.Truncate(end); err != nil {
+		return memo, err
+	}
+	if _, err := t.file.WriteAt([]byte{eofMarker}, end); err != nil {
+		return memo, err
+	}
+	return memo, t.writeHeader(false)
+}
+
+// checkRewrite comprueba que se pueda reescribir la tabla completa: no se
+// puede con cambios pendientes en el buffer ni dentro de una transacción.
+func (t *Table) checkRewrite() error {
+	if len(t.Modified()) > 0 {
+		return ErrPendingChanges
+	}
+	if t.journal != nil {
+		return ErrTransaction
+	}
+	return nil
+}
+
+// Record es una copia en memoria de un registro de la tabla. Los cambios se
+// guardan con Table.WriteRecord.
+type Record struct {
+	Recno int
+	table *Table
+	buf   []byte
+}
+
+// Deleted indica si el registro está marcado como borrado.
+func (r *Record) Deleted() bool {
+	return r.buf[0] == deletedMarker
+}
+
+// SetDeleted marca o desmarca el registro como borrado.
+func (r *Record) SetDeleted(deleted bool) {
+	r.buf[0] = ' '
+	if deleted {
+		r.buf[0] = deletedMarker
+	}
+}
+
+// Value devuelve el valor del campo idx de Fields: string (C y M), float64,
+// bool, time.Time o nil si el campo es null.
+func (r *Record) Value(idx int) (interface{}, error) {
+	f := r.table.Fields[idx]
+	if r.isNull(f) {
+		return nil, nil
+	}
+	value, err := f.decode(r.buf[f.offset:f.offset+f.Length], r.table.cp)
+	if err != nil || f.Type != Memo {
+		return value, err
+	}
+	return r.table.readMemo(f, value.(uint32))
+}
+
+// SetValue asigna el valor del campo idx de Fields; nil asigna null.
+func (r *Record) SetValue(idx int, value interface{}) error {
+	f := r.table.Fields[idx]
+	if value == nil {
+		if f.nullBit < 0 || r.table.nullFlags == nil {
+			return fmt.Errorf("field `%s` does not accept null values", f.Name)
+		}
+		copy(r.buf[f.offset:f.offset+f.Length], f.blank())
+		r.setNull(f, true)
+		return nil
+	}
+	if text, ok := value.(string); ok && f.Type == Memo {
+		// el contenido se guarda en el archivo de memos y el campo guarda
+		// el número de bloque
+		old, err := f.decode(r.buf[f.offset:f.offset+f.Length], r.table.cp)
+		if err != nil {
+			return err
+		}
+		if r.table.buffer != nil {
+			// el bloque anterior puede ser el del registro en disco
+			old = uint32(0)
+		}
+		if value, err = r.table.writeMemo(f, old.(uint32), text); err != nil {
+			return err
+		}
+	}
+	if err := f.encode(r.buf[f.offset:f.offset+f.Length], value, r.table.cp); err != nil {
+		return err
+	}
+	r.setNull(f, false)
+	return nil
+}
+
+// Bytes devuelve el contenido del registro tal como se guarda en el archivo.
+func (r *Record) Bytes() []byte {
+	return r.buf
+}
+
+func (r *Record) isNull(f *Field) bool {
+	nf := r.table.nullFlags
+	if f.nullBit < 0 || nf == nil {
+		return false
+	}
+	return getBit(r.buf[nf.offset:nf.offset+nf.Length], f.nullBit)
+}
+
+func (r *Record) setNull(f *Field, null bool) {
+	nf := r.table.nullFlags
+	if f.nullBit < 0 || nf == nil {
+		return
+	}
+	setBit(r.buf[nf.offset:nf.offset+nf.Length], f.nullBit, null)
+}
+
+func getBit(data []byte, bit int) bool {
+	return data[bit/8]&(1<<uint(bit%8)) != 0
+}
+
+func setBit(data []byte, bit int, on bool) {
+	if on {
+		data[bit/8] |= 1 << uint(bit%8)
+	} else {
+		data[bit/8] &^= 1 << uint(bit%8)
+	}
+}
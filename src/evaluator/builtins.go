@@ -0,0 +1,60 @@
+package evaluator
+
+import (
+	"FoxLite/src/object"
+	"fmt"
+	"strings"
+)
+
+// builtins contiene las funciones nativas del lenguaje indexadas por su
+// nombre en minúsculas, ya que al igual que en FoxPro se pueden invocar
+// sin distinguir mayúsculas: Round(), ROUND(), round().
+var builtins = map[string]*object.Builtin{}
+
+// registerBuiltins añade un grupo de funciones nativas a la tabla.
+func registerBuiltins(fns map[string]object.BuiltinFunction) {
+	for name, fn := range fns {
+		builtins[name] = &object.Builtin{Name: strings.ToUpper(name), Fn: fn}
+	}
+}
+
+func lookupBuiltin(name string) (*object.Builtin, bool) {
+	fn, ok := builtins[strings.ToLower(name)]
+	return fn, ok
+}
+
+// checkArgs valida que la cantidad de argumentos esté entre min y max.
+// Un max negativo indica que no hay límite superior.
+func checkArgs(name string, args []object.Object, min int, max int) *object.Error {
+	if len(args) >= min && (max < 0 || len(args) <= max) {
+		return nil
+	}
+	want := fmt.Sprintf("%d", min)
+	switch {
+	case max < 0:
+		want = fmt.Sprintf("at least %d", min)
+	case max != min:
+		want = fmt.Sprintf("%d to %d", min, max)
+	}
+	return object.NewError(fmt.Sprintf("%s(): wrong number of arguments, got %d, want %s", name, len(args), want))
+}
+
+// numberArg devuelve el valor numérico del argumento idx.
+func numberArg(name string, args []object.Object, idx int) (float64, *object.Error) {
+	if num, ok := args[idx].(*object.Integer); ok {
+		return num.Value, nil
+	}
+	return 0, argTypeError(name, idx, "number", args[idx])
+}
+
+// stringArg devuelve el valor del argumento idx si es un string.
+func stringArg(name string, args []object.Object, idx int) (string, *object.Error) {
+	if str, ok := args[idx].(*object.String); ok {
+		return str.Value, nil
+	}
+	return "", argTypeError(name, idx, "string", args[idx])
+}
+
+func argTypeError(name string, idx int, want string, got object.Object) *object.Error {
+	return object.NewError(fmt.Sprintf("%s(): argument %d must be a %s, got `%s`", name, idx+1, want, object.TypeToStr(got.Type())))
+}
@@ -0,0 +1,422 @@
+package evaluator
+
+import (
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"fmt"
+	"strings"
+)
+
+// Buffer de registros: con CURSORSETPROP("Buffering", 2 | 3) los cambios
+// del registro actual se guardan al mover el puntero (buffer de filas) y
+// con 4 | 5 quedan pendientes hasta TABLEUPDATE() (buffer de tabla). En
+// una tabla compartida los modos pesimistas (3 y 5) bloquean cada registro
+// al modificarlo y los optimistas (2 y 4) recién al guardarlo.
+
+const (
+	bufferingOff       = 1
+	bufferingRow       = 2
+	bufferingRowLock   = 3
+	bufferingTable     = 4
+	bufferingTableLock = 5
+)
+
+func init() {
+	registerBuiltins(map[string]object.BuiltinFunction{
+		"cursorsetprop": builtinCursorSetProp,
+		"cursorgetprop": builtinCursorGetProp,
+		"getfldstate":   builtinGetFldState,
+		"oldval":        builtinOldVal,
+		"curval":        builtinCurVal,
+		"tableupdate":   builtinTableUpdate,
+		"tablerevert":   builtinTableRevert,
+		"txnlevel":      builtinTxnLevel,
+	})
+}
+
+// CURSORSETPROP(cProperty, eValue [, nWorkArea | cAlias])
+// Cambia una propiedad del área de trabajo; por ahora solo "Buffering".
+func builtinCursorSetProp(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("CURSORSETPROP", args, 2, 3); err != nil {
+		return err
+	}
+	prop, err := stringArg("CURSORSETPROP", args, 0)
+	if err != nil {
+		return err
+	}
+	wa, err := bufferArea("CURSORSETPROP", env, args, 2)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(strings.TrimSpace(prop), "buffering") {
+		return object.NewError(fmt.Sprintf("CURSORSETPROP(): unknown property `%s`", prop))
+	}
+	n, err := numberArg("CURSORSETPROP", args, 1)
+	if err != nil {
+		return err
+	}
+	mode := int(n)
+	if mode < bufferingOff || mode > bufferingTableLock {
+		return object.NewError(fmt.Sprintf("CURSORSETPROP(): invalid buffering mode %d", mode))
+	}
+	if len(wa.Table.Modified()) > 0 {
+		return object.NewError(fmt.Sprintf("CURSORSETPROP(): %s: %v", wa.Alias, dbf.ErrPendingChanges))
+	}
+	if e := wa.Table.SetBuffering(mode != bufferingOff); e != nil {
+		return tableError(wa, e)
+	}
+	wa.Buffering = mode
+	return True
+}
+
+// CURSORGETPROP(cProperty [, nWorkArea | cAlias])
+// Devuelve una propiedad del área de trabajo; por ahora solo "Buffering".
+func builtinCursorGetProp(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("CURSORGETPROP", args, 1, 2); err != nil {
+		return err
+	}
+	prop, err := stringArg("CURSORGETPROP", args, 0)
+	if err != nil {
+		return err
+	}
+	wa, err := bufferArea("CURSORGETPROP", env, args, 1)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(strings.TrimSpace(prop), "buffering") {
+		return object.NewError(fmt.Sprintf("CURSORGETPROP(): unknown property `%s`", prop))
+	}
+	return &object.Integer{Value: float64(bufferingMode(wa))}
+}
+
+// GETFLDSTATE(cFieldName | nFieldNumber [, nWorkArea | cAlias])
+// Devuelve el estado de un campo del registro actual en el buffer: 1 sin
+// cambios, 2 modificado, 3 agregado sin cambios y 4 agregado y modificado.
+// Con 0 devuelve el estado de la marca de borrado y con -1 una cadena con
+// el de la marca de borrado seguido del de cada campo.
+func builtinGetFldState(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("GETFLDSTATE", args, 1, 2); err != nil {
+		return err
+	}
+	wa, err := bufferedArea("GETFLDSTATE", env, args, 1)
+	if err != nil {
+		return err
+	}
+	if n, ok := args[0].(*object.Integer); ok && n.Value == -1 {
+		states := []byte{stateDigit(wa.Table.FieldState(wa.Recno, -1))}
+		for idx := range wa.Table.Fields {
+			states = append(states, stateDigit(wa.Table.FieldState(wa.Recno, idx)))
+		}
+		return &object.String{Value: string(states)}
+	}
+	idx, err := fieldArg("GETFLDSTATE", wa, args[0])
+	if err != nil {
+		return err
+	}
+	return &object.Integer{Value: float64(wa.Table.FieldState(wa.Recno, idx))}
+}
+
+func stateDigit(state int) byte {
+	return byte('0' + state)
+}
+
+// OLDVAL(cFieldName [, nWorkArea | cAlias])
+// Devuelve el valor que tenía el campo en disco antes de modificarlo en el
+// buffer; null en los registros agregados.
+func builtinOldVal(env *object.Environment, args ...object.Object) object.Object {
+	return diskValue("OLDVAL", env, args, (*dbf.Table).Original)
+}
+
+// CURVAL(cFieldName [, nWorkArea | cAlias])
+// Devuelve el valor que tiene el campo en disco, que puede haber cambiado
+// desde otro programa; null en los registros agregados.
+func builtinCurVal(env *object.Environment, args ...object.Object) object.Object {
+	return diskValue("CURVAL", env, args, (*dbf.Table).DiskRecord)
+}
+
+func diskValue(name string, env *object.Environment, args []object.Object, read func(*dbf.Table, int) (*dbf.Record, error)) object.Object {
+	if err := checkArgs(name, args, 1, 2); err != nil {
+		return err
+	}
+	wa, errObj := bufferArea(name, env, args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	idx, errObj := fieldArg(name, wa, args[0])
+	if errObj != nil {
+		return errObj
+	}
+	if wa.Eof() {
+		return Null
+	}
+	rec, err := read(wa.Table, wa.Recno)
+	if err != nil {
+		return tableError(wa, err)
+	}
+	if rec == nil {
+		return Null
+	}
+	value, err := rec.Value(idx)
+	if err != nil {
+		return tableError(wa, err)
+	}
+	return fromFieldValue(wa.Table.Fields[idx], value)
+}
+
+// TABLEUPDATE([nRows | lAllRows [, lForce [, nWorkArea | cAlias]]])
+// Guarda en disco los cambios del buffer: con 0 (o False, el valor por
+// omisión) los del registro actual, con 1 (o True) los de todos los
+// registros y con 2 los de todos los registros sin detenerse en el primero
+// que falla. Sin lForce un registro que cambió en disco desde que se
+// modificó en el buffer no se guarda y la función devuelve False.
+func builtinTableUpdate(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("TABLEUPDATE", args, 0, 3); err != nil {
+		return err
+	}
+	rows := 0
+	if len(args) > 0 {
+		switch arg := args[0].(type) {
+		case *object.Boolean:
+			if arg.Value {
+				rows = 1
+			}
+		case *object.Integer:
+			rows = int(arg.Value)
+		default:
+			return argTypeError("TABLEUPDATE", 0, "number or logical", args[0])
+		}
+	}
+	force := false
+	if len(args) > 1 {
+		lForce, ok := args[1].(*object.Boolean)
+		if !ok {
+			return argTypeError("TABLEUPDATE", 1, "logical", args[1])
+		}
+		force = lForce.Value
+	}
+	wa, err := bufferedArea("TABLEUPDATE", env, args, 2)
+	if err != nil {
+		return err
+	}
+	recnos := []int{wa.Recno}
+	if rows != 0 {
+		recnos = wa.Table.Modified()
+	}
+	updated, err := updateRows(wa, recnos, force, rows == 2, env)
+	if err != nil {
+		return err
+	}
+	return toBoolean(updated)
+}
+
+// TABLEREVERT([lAllRows [, nWorkArea | cAlias]])
+// Descarta los cambios del buffer del registro actual (o de todos con
+// lAllRows) y devuelve la cantidad de registros descartados.
+func builtinTableRevert(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("TABLEREVERT", args, 0, 2); err != nil {
+		return err
+	}
+	all := false
+	if len(args) > 0 {
+		lAll, ok := args[0].(*object.Boolean)
+		if !ok {
+			return argTypeError("TABLEREVERT", 0, "logical", args[0])
+		}
+		all = lAll.Value
+	}
+	wa, err := bufferedArea("TABLEREVERT", env, args, 1)
+	if err != nil {
+		return err
+	}
+	var reverted []int
+	if all {
+		reverted = wa.Table.Revert()
+	} else if containsInt(wa.Table.Modified(), wa.Recno) {
+		reverted = wa.Table.Revert(wa.Recno)
+	}
+	if err := refreshIndexes(wa, reverted, env); err != nil {
+		return err
+	}
+	if env.TransactionLevel() == 0 {
+		// los bloqueos del buffer pesimista se liberan al descartar
+		for _, recno := range reverted {
+			if e := wa.Table.UnlockRecord(recno); e != nil {
+				return tableError(wa, e)
+			}
+		}
+	}
+	return &object.Integer{Value: float64(len(reverted))}
+}
+
+// TXNLEVEL()
+// Devuelve la cantidad de niveles de BEGIN TRANSACTION en curso.
+func builtinTxnLevel(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("TXNLEVEL", args, 0, 0); err != nil {
+		return err
+	}
+	return &object.Integer{Value: float64(env.TransactionLevel())}
+}
+
+// bufferArea devuelve el área del argumento idx, que debe estar en uso.
+func bufferArea(name string, env *object.Environment, args []object.Object, idx int) (*object.WorkArea, *object.Error) {
+	wa, err := areaArg(name, env, args, idx)
+	if err != nil {
+		return nil, err
+	}
+	if wa == nil {
+		return nil, object.NewError(fmt.Sprintf("%s(): no table is open in the work area", name))
+	}
+	return wa, nil
+}
+
+// bufferedArea devuelve el área del argumento idx, que debe tener activo
+// el buffer de filas o de tabla.
+func bufferedArea(name string, env *object.Environment, args []object.Object, idx int) (*object.WorkArea, *object.Error) {
+	wa, err := bufferArea(name, env, args, idx)
+	if err != nil {
+		return nil, err
+	}
+	if bufferingMode(wa) == bufferingOff {
+		return nil, object.NewError(fmt.Sprintf("%s(): `%s` requires row or table buffering", name, wa.Alias))
+	}
+	return wa, nil
+}
+
+func bufferingMode(wa *object.WorkArea) int {
+	if wa.Buffering == 0 {
+		return bufferingOff
+	}
+	return wa.Buffering
+}
+
+// fieldArg devuelve la posición del campo indicado por nombre (o
+// alias.nombre) o por número (desde 1); 0 es la marca de borrado (-1).
+func fieldArg(name string, wa *object.WorkArea, arg object.Object) (int, *object.Error) {
+	switch arg := arg.(type) {
+	case *object.Integer:
+		n := int(arg.Value)
+		if n < 0 || n > len(wa.Table.Fields) {
+			return 0, object.NewError(fmt.Sprintf("%s(): field number %d is out of range", name, n))
+		}
+		return n - 1, nil
+	case *object.String:
+		field := strings.TrimSpace(arg.Value)
+		if dot := strings.IndexByte(field, '.'); dot >= 0 && strings.EqualFold(field[:dot], wa.Alias) {
+			field = field[dot+1:]
+		}
+		idx := wa.Table.FieldIndex(field)
+		if idx < 0 {
+			return 0, object.NewError(fmt.Sprintf("%s(): field `%s` is not found", name, arg.Value))
+		}
+		return idx, nil
+	}
+	return 0, argTypeError(name, 0, "field name or number", arg)
+}
+
+// updateRows guarda en disco los cambios del buffer de los registros
+// recnos. Sin force los registros que cambiaron en disco desde que se
+// modificaron no se guardan: si keepGoing es false no se guarda ninguno y
+// si es true se guardan los demás. Devuelve false si hubo conflictos. En
+// una tabla compartida los registros se bloquean antes de compararlos con
+// el disco.
+func updateRows(wa *object.WorkArea, recnos []int, force bool, keepGoing bool, env *object.Environment) (bool, *object.Error) {
+	pending := map[int]bool{}
+	for _, recno := range wa.Table.Modified() {
+		pending[recno] = true
+	}
+	var candidates []int
+	for _, recno := range recnos {
+		if pending[recno] {
+			candidates = append(candidates, recno)
+		}
+	}
+	candidates, unlock, errObj := lockRows(wa, candidates, env)
+	if errObj != nil {
+		return false, errObj
+	}
+	defer unlock()
+	var rows []int
+	ok := true
+	for _, recno := range candidates {
+		if !force {
+			conflict, err := wa.Table.Conflict(recno)
+			if err != nil {
+				return false, tableError(wa, err)
+			}
+			if conflict {
+				ok = false
+				continue
+			}
+		}
+		rows = append(rows, recno)
+	}
+	if !ok && !keepGoing {
+		return false, nil
+	}
+	if len(rows) > 0 {
+		if err := wa.Table.Flush(rows...); err != nil {
+			return false, tableError(wa, err)
+		}
+	}
+	return ok, nil
+}
+
+// commitRow guarda, con buffer de filas, los cambios del registro que se
+// acaba de dejar.
+func commitRow(wa *object.WorkArea, env *object.Environment) *object.Error {
+	if wa.Buffering != bufferingRow && wa.Buffering != bufferingRowLock {
+		return nil
+	}
+	var left []int
+	for _, recno := range wa.Table.Modified() {
+		if recno != wa.Recno {
+			left = append(left, recno)
+		}
+	}
+	if len(left) == 0 {
+		return nil
+	}
+	updated, errObj := updateRows(wa, left, false, false, env)
+	if errObj != nil {
+		return errObj
+	}
+	if !updated {
+		return object.NewError(fmt.Sprintf("%s: record was modified by another program", wa.Alias))
+	}
+	return nil
+}
+
+// refreshIndexes vuelve a calcular las claves de los registros recnos
+// después de descartar sus cambios; los que ya no existen salen de los
+// tags. Si el puntero quedó fuera de la tabla pasa al fin de archivo.
+func refreshIndexes(wa *object.WorkArea, recnos []int, env *object.Environment) *object.Error {
+	recno, bof := wa.Recno, wa.Bof
+	defer func() {
+		wa.Recno, wa.Bof = recno, bof
+		if wa.Recno > wa.Table.RecordCount()+1 {
+			wa.Recno = wa.Table.RecordCount() + 1
+		}
+	}()
+	for _, r := range recnos {
+		if r > wa.Table.RecordCount() {
+			for _, tag := range wa.Table.Tags() {
+				tag.Remove(r)
+			}
+			continue
+		}
+		wa.Recno = r
+		if errObj := updateIndexes(wa, env); errObj != nil {
+			return errObj
+		}
+	}
+	return nil
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
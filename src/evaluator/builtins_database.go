@@ -0,0 +1,217 @@
+package evaluator
+
+import (
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Propiedades de las bases de datos (.dbc): DBGETPROP() y DBSETPROP() leen
+// y cambian las propiedades de la base, de sus tablas y de sus campos. Los
+// objetos se nombran como en VFP: "tabla" o "base!tabla" para las tablas y
+// "tabla.campo" para los campos; sin "base!" se busca en la base de datos
+// actual.
+
+func init() {
+	registerBuiltins(map[string]object.BuiltinFunction{
+		"dbgetprop": builtinDBGetProp,
+		"dbsetprop": builtinDBSetProp,
+		"dbc":       builtinDBC,
+		"dbused":    builtinDBUsed,
+	})
+}
+
+// DBGETPROP(cName, cType, cProperty)
+// cType es "DATABASE", "TABLE" o "FIELD".
+func builtinDBGetProp(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("DBGETPROP", args, 3, 3); err != nil {
+		return err
+	}
+	o, errObj := dbObjectArg("DBGETPROP", args, env)
+	if errObj != nil {
+		return errObj
+	}
+	prop, errObj := stringArg("DBGETPROP", args, 2)
+	if errObj != nil {
+		return errObj
+	}
+	value, err := o.Prop(prop)
+	if err != nil {
+		return object.NewError(fmt.Sprintf("DBGETPROP(): %v", err))
+	}
+	switch v := value.(type) {
+	case int:
+		return &object.Integer{Value: float64(v)}
+	case bool:
+		return toBoolean(v)
+	default:
+		return &object.String{Value: fmt.Sprint(v)}
+	}
+}
+
+// DBSETPROP(cName, cType, cProperty, ePropertyValue)
+// Devuelve True si se cambió la propiedad. Las reglas y triggers nuevos se
+// aplican desde el siguiente cambio en la tabla.
+func builtinDBSetProp(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("DBSETPROP", args, 4, 4); err != nil {
+		return err
+	}
+	o, errObj := dbObjectArg("DBSETPROP", args, env)
+	if errObj != nil {
+		return errObj
+	}
+	prop, errObj := stringArg("DBSETPROP", args, 2)
+	if errObj != nil {
+		return errObj
+	}
+	var value interface{}
+	switch v := args[3].(type) {
+	case *object.String:
+		value = strings.TrimSpace(v.Value)
+	case *object.Integer:
+		value = int(v.Value)
+	case *object.Boolean:
+		value = v.Value
+	default:
+		return argTypeError("DBSETPROP", 3, "string, number or logical", args[3])
+	}
+	if strings.EqualFold(prop, "PrimaryKey") {
+		if errObj := checkPrimaryKey(o, value, env); errObj != nil {
+			return errObj
+		}
+	}
+	// la regla se compila antes de guardarla para no dejar una que falle
+	if text, ok := value.(string); ok && text != "" && isExpressionProp(prop) {
+		if _, errObj := dbcExpression(text); errObj != nil {
+			return object.NewError(fmt.Sprintf("DBSETPROP(): %s: %s", prop, errObj.Message))
+		}
+	}
+	if err := o.SetProp(prop, value); err != nil {
+		return object.NewError(fmt.Sprintf("DBSETPROP(): %v", err))
+	}
+	return True
+}
+
+// isExpressionProp indica si la propiedad guarda una expresión.
+func isExpressionProp(prop string) bool {
+	switch strings.ToLower(prop) {
+	case "ruleexpression", "ruletext", "defaultvalue", "inserttrigger", "updatetrigger", "deletetrigger":
+		return true
+	}
+	return false
+}
+
+// checkPrimaryKey comprueba que la clave primaria de una tabla sea un tag
+// candidato de su índice.
+func checkPrimaryKey(o *dbf.DBObject, value interface{}, env *object.Environment) *object.Error {
+	name, ok := value.(string)
+	if !ok || name == "" {
+		return nil
+	}
+	var table *dbf.Table
+	for _, wa := range env.WorkAreas() {
+		if wa.DBTable == o {
+			table = wa.Table
+			break
+		}
+	}
+	if table == nil {
+		opened, err := dbf.Open(o.Database().TablePath(o), true)
+		if err != nil {
+			return object.NewError(fmt.Sprintf("DBSETPROP(): cannot open table: %v", err))
+		}
+		defer opened.Close()
+		table = opened
+	}
+	tag := table.Tag(name)
+	if tag == nil {
+		return object.NewError(fmt.Sprintf("DBSETPROP(): tag `%s` is not found", name))
+	}
+	if !tag.Candidate {
+		return object.NewError(fmt.Sprintf("DBSETPROP(): tag `%s` is not a candidate index", name))
+	}
+	return nil
+}
+
+// dbObjectArg busca el objeto de la base de datos que nombran los dos
+// primeros argumentos.
+func dbObjectArg(fn string, args []object.Object, env *object.Environment) (*dbf.DBObject, *object.Error) {
+	name, errObj := stringArg(fn, args, 0)
+	if errObj != nil {
+		return nil, errObj
+	}
+	kind, errObj := stringArg(fn, args, 1)
+	if errObj != nil {
+		return nil, errObj
+	}
+	name = strings.TrimSpace(name)
+	db := env.CurrentDatabase()
+	if bang := strings.IndexByte(name, '!'); bang > 0 {
+		db = env.DatabaseByName(name[:bang])
+		if db == nil {
+			return nil, object.NewError(fmt.Sprintf("%s(): database `%s` is not open", fn, name[:bang]))
+		}
+		name = name[bang+1:]
+	}
+	if strings.EqualFold(kind, "database") && name != "" {
+		if named := env.DatabaseByName(name); named != nil {
+			db = named
+		}
+	}
+	if db == nil {
+		return nil, object.NewError(fmt.Sprintf("%s(): no database is open", fn))
+	}
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "database":
+		return db.Root(), nil
+	case "table":
+		if table := db.Table(name); table != nil {
+			return table, nil
+		}
+		return nil, object.NewError(fmt.Sprintf("%s(): table `%s` is not found in database `%s`", fn, name, db.Name()))
+	case "field":
+		dot := strings.IndexByte(name, '.')
+		if dot < 0 {
+			return nil, object.NewError(fmt.Sprintf("%s(): expecting a field name as table.field, got `%s`", fn, name))
+		}
+		table := db.Table(name[:dot])
+		if table == nil {
+			return nil, object.NewError(fmt.Sprintf("%s(): table `%s` is not found in database `%s`", fn, name[:dot], db.Name()))
+		}
+		if field := table.Field(name[dot+1:]); field != nil {
+			return field, nil
+		}
+		return nil, object.NewError(fmt.Sprintf("%s(): field `%s` is not found", fn, name))
+	}
+	return nil, object.NewError(fmt.Sprintf("%s(): invalid object type `%s`", fn, kind))
+}
+
+// DBC() devuelve la ruta de la base de datos actual ("" si no hay ninguna).
+func builtinDBC(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("DBC", args, 0, 0); err != nil {
+		return err
+	}
+	db := env.CurrentDatabase()
+	if db == nil {
+		return &object.String{Value: ""}
+	}
+	path, err := filepath.Abs(db.Path)
+	if err != nil {
+		path = db.Path
+	}
+	return &object.String{Value: strings.ToUpper(path)}
+}
+
+// DBUSED(cDatabaseName) indica si la base de datos está abierta.
+func builtinDBUsed(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("DBUSED", args, 1, 1); err != nil {
+		return err
+	}
+	name, errObj := stringArg("DBUSED", args, 0)
+	if errObj != nil {
+		return errObj
+	}
+	return toBoolean(env.DatabaseByName(strings.TrimSpace(name)) != nil)
+}
@@ -0,0 +1,127 @@
+package evaluator
+
+import (
+	"FoxLite/src/object"
+	"fmt"
+	"time"
+)
+
+func init() {
+	registerBuiltins(map[string]object.BuiltinFunction{
+		"date":     builtinDate,
+		"datetime": builtinDateTime,
+		"dtos":     builtinDtos,
+		"year":     builtinYear,
+		"month":    builtinMonth,
+		"day":      builtinDay,
+	})
+}
+
+// DATE([nYear, nMonth, nDay])
+// Devuelve la fecha actual o la fecha indicada.
+func builtinDate(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) == 0 {
+		now := time.Now()
+		return &object.Date{Value: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)}
+	}
+	if err := checkArgs("DATE", args, 3, 3); err != nil {
+		return err
+	}
+	parts, err := numberArgs("DATE", args)
+	if err != nil {
+		return err
+	}
+	return makeDate("DATE", parts, false)
+}
+
+// DATETIME([nYear, nMonth, nDay [, nHours [, nMinutes [, nSeconds]]]])
+// Devuelve la fecha y hora actual o la indicada.
+func builtinDateTime(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) == 0 {
+		now := time.Now()
+		return &object.Date{Value: time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second(), 0, time.UTC), DateTime: true}
+	}
+	if err := checkArgs("DATETIME", args, 3, 6); err != nil {
+		return err
+	}
+	parts, err := numberArgs("DATETIME", args)
+	if err != nil {
+		return err
+	}
+	return makeDate("DATETIME", parts, true)
+}
+
+func numberArgs(name string, args []object.Object) ([]int, *object.Error) {
+	parts := make([]int, 6)
+	for idx := range args {
+		num, err := numberArg(name, args, idx)
+		if err != nil {
+			return nil, err
+		}
+		parts[idx] = int(num)
+	}
+	return parts, nil
+}
+
+func makeDate(name string, parts []int, dateTime bool) object.Object {
+	t := time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5], 0, time.UTC)
+	// time.Date normaliza los valores fuera de rango: 31/02 -> 03/03
+	if t.Year() != parts[0] || int(t.Month()) != parts[1] || t.Day() != parts[2] || t.Hour() != parts[3] || t.Minute() != parts[4] || t.Second() != parts[5] {
+		return object.NewError(fmt.Sprintf("%s(): invalid date", name))
+	}
+	return &object.Date{Value: t, DateTime: dateTime}
+}
+
+// dateArg devuelve el valor del argumento idx si es una fecha.
+func dateArg(name string, args []object.Object, idx int) (*object.Date, *object.Error) {
+	if d, ok := args[idx].(*object.Date); ok {
+		return d, nil
+	}
+	return nil, argTypeError(name, idx, "date", args[idx])
+}
+
+// DTOS(dDate | tDateTime)
+// Devuelve la fecha con el formato AAAAMMDD (útil en las claves de índice).
+func builtinDtos(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("DTOS", args, 1, 1); err != nil {
+		return err
+	}
+	d, err := dateArg("DTOS", args, 0)
+	if err != nil {
+		return err
+	}
+	if d.Value.IsZero() {
+		return &object.String{Value: "        "}
+	}
+	return &object.String{Value: d.Value.Format("20060102")}
+}
+
+// YEAR(dDate | tDateTime)
+func builtinYear(env *object.Environment, args ...object.Object) object.Object {
+	return datePart("YEAR", args, func(t time.Time) int { return t.Year() })
+}
+
+// MONTH(dDate | tDateTime)
+func builtinMonth(env *object.Environment, args ...object.Object) object.Object {
+	return datePart("MONTH", args, func(t time.Time) int { return int(t.Month()) })
+}
+
+// DAY(dDate | tDateTime)
+func builtinDay(env *object.Environment, args ...object.Object) object.Object {
+	return datePart("DAY", args, func(t time.Time) int { return t.Day() })
+}
+
+// datePart devuelve una parte de la fecha; 0 si la fecha está vacía.
+func datePart(name string, args []object.Object, part func(time.Time) int) object.Object {
+	if err := checkArgs(name, args, 1, 1); err != nil {
+		return err
+	}
+	d, err := dateArg(name, args, 0)
+	if err != nil {
+		return err
+	}
+	if d.Value.IsZero() {
+		return &object.Integer{Value: 0}
+	}
+	return &object.Integer{Value: float64(part(d.Value))}
+}
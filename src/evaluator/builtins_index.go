@@ -0,0 +1,127 @@
+package evaluator
+
+import (
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"fmt"
+)
+
+func init() {
+	registerBuiltins(map[string]object.BuiltinFunction{
+		"seek":      builtinSeek,
+		"indexseek": builtinIndexSeek,
+		"order":     builtinOrder,
+		"tag":       builtinTag,
+	})
+}
+
+// SEEK(eExpression [, nWorkArea | cAlias [, nIndexNumber | cTagName]])
+// Busca la clave, mueve el puntero y devuelve True si la encontró.
+func builtinSeek(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("SEEK", args, 1, 3); err != nil {
+		return err
+	}
+	wa, tag, err := seekArgs("SEEK", env, args, 1)
+	if err != nil {
+		return err
+	}
+	if err := seekRecord(wa, tag, args[0], env); err != nil {
+		return err
+	}
+	return toBoolean(wa.Found)
+}
+
+// INDEXSEEK(eExpression [, lMovePointer [, nWorkArea | cAlias [, nIndexNumber | cTagName]]])
+// Como SEEK() pero solo mueve el puntero si lMovePointer es True.
+func builtinIndexSeek(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("INDEXSEEK", args, 1, 4); err != nil {
+		return err
+	}
+	move := false
+	if len(args) > 1 {
+		flag, ok := args[1].(*object.Boolean)
+		if !ok {
+			return argTypeError("INDEXSEEK", 1, "logical", args[1])
+		}
+		move = flag.Value
+	}
+	wa, tag, err := seekArgs("INDEXSEEK", env, args, 2)
+	if err != nil {
+		return err
+	}
+	if move {
+		if err := seekRecord(wa, tag, args[0], env); err != nil {
+			return err
+		}
+		return toBoolean(wa.Found)
+	}
+	recno, bof := wa.Recno, wa.Bof
+	found, err := seekKey(wa, tag, args[0], env)
+	wa.Recno, wa.Bof = recno, bof
+	if err != nil {
+		return err
+	}
+	return toBoolean(found != 0)
+}
+
+// seekArgs devuelve el área de trabajo (argumento idx) y el tag (argumento
+// idx+1 o el orden activo) de SEEK() e INDEXSEEK().
+func seekArgs(name string, env *object.Environment, args []object.Object, idx int) (*object.WorkArea, *dbf.Tag, *object.Error) {
+	wa, err := areaArg(name, env, args, idx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if wa == nil {
+		return nil, nil, object.NewError(fmt.Sprintf("%s(): no table is open in the work area", name))
+	}
+	tag := orderTag(wa)
+	if idx+1 < len(args) {
+		if tag, err = tagArg(wa, args[idx+1]); err != nil {
+			return nil, nil, err
+		}
+	}
+	if tag == nil {
+		return nil, nil, object.NewError(fmt.Sprintf("%s(): %s has no index order", name, wa.Alias))
+	}
+	return wa, tag, nil
+}
+
+// ORDER([nWorkArea | cAlias])
+// Devuelve el nombre del tag que controla el orden ("" si es el físico).
+func builtinOrder(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("ORDER", args, 0, 1); err != nil {
+		return err
+	}
+	wa, err := areaArg("ORDER", env, args, 0)
+	if err != nil {
+		return err
+	}
+	if wa == nil {
+		return &object.String{Value: ""}
+	}
+	return &object.String{Value: wa.Order}
+}
+
+// TAG([nTagNumber [, nWorkArea | cAlias]])
+// Devuelve el nombre del tag número nTagNumber ("" si no existe).
+func builtinTag(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("TAG", args, 0, 2); err != nil {
+		return err
+	}
+	n := 1
+	if len(args) > 0 {
+		num, ok := args[0].(*object.Integer)
+		if !ok {
+			return argTypeError("TAG", 0, "number", args[0])
+		}
+		n = int(num.Value)
+	}
+	wa, err := areaArg("TAG", env, args, 1)
+	if err != nil {
+		return err
+	}
+	if wa == nil || n < 1 || n > len(wa.Table.Tags()) {
+		return &object.String{Value: ""}
+	}
+	return &object.String{Value: wa.Table.Tags()[n-1].Name}
+}
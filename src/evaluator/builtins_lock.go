@@ -0,0 +1,361 @@
+package evaluator
+
+import (
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bloqueos de tablas compartidas (USE ... SHARED o SET EXCLUSIVE OFF):
+// RLOCK() bloquea registros, FLOCK() la tabla completa y UNLOCK los libera.
+// REPLACE, DELETE, RECALL, APPEND BLANK e INSERT bloquean lo que modifican
+// y lo liberan al terminar, salvo dentro de una transacción (los bloqueos
+// duran hasta END TRANSACTION o ROLLBACK) o con buffer pesimista (hasta
+// TABLEUPDATE() o TABLEREVERT()). Cada bloqueo se intenta según SET
+// REPROCESS. En las tablas exclusivas y en los cursores los bloqueos
+// siempre se consiguen.
+
+// reprocessInterval es la espera entre dos intentos de bloqueo.
+const reprocessInterval = 100 * time.Millisecond
+
+func init() {
+	registerBuiltins(map[string]object.BuiltinFunction{
+		"rlock":     builtinRLock,
+		"lock":      builtinRLock,
+		"flock":     builtinFLock,
+		"isrlocked": builtinIsRLocked,
+		"isflocked": builtinIsFLocked,
+	})
+}
+
+// RLOCK([nWorkArea | cAlias]) | RLOCK(cRecordNumberList, nWorkArea | cAlias)
+// Bloquea el registro actual o los registros de la lista ("1,5,7"; el 0 es
+// la cabecera). Con SET MULTILOCKS OFF libera antes los demás registros
+// bloqueados. Devuelve False si algún registro lo tiene bloqueado otro
+// proceso; en ese caso no queda bloqueado ninguno de la lista.
+func builtinRLock(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("RLOCK", args, 0, 2); err != nil {
+		return err
+	}
+	areaIdx := 0
+	if len(args) == 2 {
+		areaIdx = 1
+	}
+	wa, errObj := bufferArea("RLOCK", env, args, areaIdx)
+	if errObj != nil {
+		return errObj
+	}
+	var recnos []int
+	if len(args) == 2 {
+		list, errObj := stringArg("RLOCK", args, 0)
+		if errObj != nil {
+			return errObj
+		}
+		for _, item := range strings.Split(list, ",") {
+			recno, err := strconv.Atoi(strings.TrimSpace(item))
+			if err != nil || recno < 0 || recno > wa.Table.RecordCount() {
+				return object.NewError(fmt.Sprintf("RLOCK(): invalid record number `%s`", strings.TrimSpace(item)))
+			}
+			recnos = append(recnos, recno)
+		}
+	} else if !wa.Eof() {
+		recnos = []int{wa.Recno}
+	}
+	if !isOptionOn(env, "multilocks") {
+		for _, recno := range wa.Table.LockedRecords() {
+			if !containsInt(recnos, recno) {
+				if err := wa.Table.UnlockRecord(recno); err != nil {
+					return tableError(wa, err)
+				}
+			}
+		}
+	}
+	ok, errObj := lockRecords(wa, recnos, env)
+	if errObj != nil {
+		return errObj
+	}
+	return toBoolean(ok)
+}
+
+// FLOCK([nWorkArea | cAlias])
+// Bloquea la tabla completa; devuelve False si otro proceso tiene
+// bloqueada la tabla o alguno de sus registros.
+func builtinFLock(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("FLOCK", args, 0, 1); err != nil {
+		return err
+	}
+	wa, errObj := bufferArea("FLOCK", env, args, 0)
+	if errObj != nil {
+		return errObj
+	}
+	ok, err := reprocess(env, wa.Table.LockFile)
+	if err != nil {
+		return tableError(wa, err)
+	}
+	if ok {
+		if errObj := refreshArea(wa, env); errObj != nil {
+			return errObj
+		}
+	}
+	return toBoolean(ok)
+}
+
+// ISRLOCKED([nRecordNumber [, nWorkArea | cAlias]])
+// Indica si el registro (el actual por omisión) está bloqueado por este
+// proceso.
+func builtinIsRLocked(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("ISRLOCKED", args, 0, 2); err != nil {
+		return err
+	}
+	wa, errObj := bufferArea("ISRLOCKED", env, args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	recno := wa.Recno
+	if len(args) > 0 {
+		n, errObj := numberArg("ISRLOCKED", args, 0)
+		if errObj != nil {
+			return errObj
+		}
+		recno = int(n)
+	}
+	return toBoolean(!wa.Table.Shared() || wa.Table.RecordLocked(recno))
+}
+
+// ISFLOCKED([nWorkArea | cAlias])
+// Indica si la tabla está bloqueada por este proceso.
+func builtinIsFLocked(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("ISFLOCKED", args, 0, 1); err != nil {
+		return err
+	}
+	wa, errObj := bufferArea("ISFLOCKED", env, args, 0)
+	if errObj != nil {
+		return errObj
+	}
+	return toBoolean(!wa.Table.Shared() || wa.Table.FileLocked())
+}
+
+// reprocess intenta un bloqueo según SET REPROCESS: con 0 una sola vez,
+// con n hasta n veces (o durante n segundos con SET REPROCESS TO n SECONDS)
+// y con -1 o AUTOMATIC hasta conseguirlo.
+func reprocess(env *object.Environment, try func() (bool, error)) (bool, error) {
+	n := 0
+	if num, ok := env.GetOption("reprocess").(*object.Integer); ok {
+		n = int(num.Value)
+	}
+	seconds := isOptionOn(env, "reprocessseconds")
+	deadline := time.Now().Add(time.Duration(n) * time.Second)
+	for attempt := 1; ; attempt++ {
+		ok, err := try()
+		if ok || err != nil {
+			return ok, err
+		}
+		switch {
+		case n < 0:
+		case seconds && time.Now().Before(deadline):
+		case !seconds && attempt < n:
+		default:
+			return false, nil
+		}
+		time.Sleep(reprocessInterval)
+	}
+}
+
+// lockRecords bloquea los registros recnos (0 es la cabecera) y lee los
+// cambios de otros procesos. Si alguno no se puede bloquear libera los que
+// bloqueó y devuelve false.
+func lockRecords(wa *object.WorkArea, recnos []int, env *object.Environment) (bool, *object.Error) {
+	t := wa.Table
+	var locked []int
+	for _, recno := range recnos {
+		if recno == 0 || !t.RecordLocked(recno) {
+			try := t.LockHeader
+			if recno != 0 {
+				try = func() (bool, error) { return t.LockRecord(recno) }
+			}
+			ok, err := reprocess(env, try)
+			if err == nil && ok {
+				locked = append(locked, recno)
+				continue
+			}
+			for _, r := range locked {
+				unlockRecord(t, r)
+			}
+			if err != nil {
+				return false, tableError(wa, err)
+			}
+			return false, nil
+		}
+	}
+	return true, refreshArea(wa, env)
+}
+
+func unlockRecord(t *dbf.Table, recno int) error {
+	if recno == 0 {
+		return t.UnlockHeader()
+	}
+	return t.UnlockRecord(recno)
+}
+
+// lockCurrent bloquea el registro actual antes de modificarlo y devuelve
+// la función que lo libera al terminar el comando. Con buffer optimista y
+// en los registros agregados en el buffer no hace falta bloquearlo.
+func lockCurrent(wa *object.WorkArea, env *object.Environment) (func(), *object.Error) {
+	t := wa.Table
+	recno := wa.Recno
+	if !t.Shared() || wa.Eof() || t.RecordLocked(recno) || t.Appended(recno) ||
+		wa.Buffering == bufferingRow || wa.Buffering == bufferingTable {
+		return func() {}, nil
+	}
+	ok, err := reprocess(env, func() (bool, error) { return t.LockRecord(recno) })
+	if err != nil {
+		return nil, tableError(wa, err)
+	}
+	if !ok {
+		return nil, object.NewError(fmt.Sprintf("%s: record %d is in use by another program", wa.Alias, recno))
+	}
+	if errObj := refreshArea(wa, env); errObj != nil {
+		t.UnlockRecord(recno)
+		return nil, errObj
+	}
+	if keepLocks(wa, env) {
+		return func() {}, nil
+	}
+	return func() { t.UnlockRecord(recno) }, nil
+}
+
+// lockAppend bloquea la cabecera para agregar registros y devuelve la
+// función que la libera al terminar el comando.
+func lockAppend(wa *object.WorkArea, env *object.Environment) (func(), *object.Error) {
+	t := wa.Table
+	if !t.Shared() || t.Buffered() {
+		return func() {}, nil
+	}
+	ok, err := reprocess(env, t.LockHeader)
+	if err != nil {
+		return nil, tableError(wa, err)
+	}
+	if !ok {
+		return nil, tableError(wa, dbf.ErrInUse)
+	}
+	return func() { t.UnlockHeader() }, nil
+}
+
+// keepLocks indica si los bloqueos automáticos se mantienen después del
+// comando: dentro de una transacción y con buffer pesimista.
+func keepLocks(wa *object.WorkArea, env *object.Environment) bool {
+	return env.TransactionLevel() > 0 || wa.Buffering == bufferingRowLock || wa.Buffering == bufferingTableLock
+}
+
+// lockRows bloquea antes de guardarlos los registros del buffer recnos, y
+// la cabecera si hay registros agregados. Los números de los registros
+// agregados pueden cambiar al ver los que agregaron otros procesos:
+// devuelve los números que tienen ahora y la función que libera los
+// bloqueos después de guardarlos.
+func lockRows(wa *object.WorkArea, recnos []int, env *object.Environment) ([]int, func(), *object.Error) {
+	t := wa.Table
+	if !t.Shared() {
+		return recnos, func() {}, nil
+	}
+	var locked []int
+	unlock := func() {
+		if env.TransactionLevel() > 0 {
+			return
+		}
+		for _, recno := range locked {
+			unlockRecord(t, recno)
+		}
+	}
+	var appended []int
+	for _, recno := range recnos {
+		if t.Appended(recno) {
+			appended = append(appended, recno)
+			continue
+		}
+		if t.RecordLocked(recno) {
+			// los bloqueos del buffer pesimista también se liberan al guardar
+			if wa.Buffering == bufferingRowLock || wa.Buffering == bufferingTableLock {
+				locked = append(locked, recno)
+			}
+			continue
+		}
+		ok, err := reprocess(env, func() (bool, error) { return t.LockRecord(recno) })
+		if err != nil || !ok {
+			unlock()
+			if err != nil {
+				return nil, nil, tableError(wa, err)
+			}
+			return nil, nil, object.NewError(fmt.Sprintf("%s: record %d is in use by another program", wa.Alias, recno))
+		}
+		locked = append(locked, recno)
+	}
+	if len(appended) == 0 {
+		return recnos, unlock, nil
+	}
+	ok, err := reprocess(env, t.LockHeader)
+	if err != nil || !ok {
+		unlock()
+		if err != nil {
+			return nil, nil, tableError(wa, err)
+		}
+		return nil, nil, tableError(wa, dbf.ErrInUse)
+	}
+	locked = append(locked, 0)
+	shift, errObj := refreshTable(wa)
+	if errObj != nil {
+		unlock()
+		return nil, nil, errObj
+	}
+	if shift != 0 {
+		moved := make([]int, len(recnos))
+		for i, recno := range recnos {
+			if containsInt(appended, recno) {
+				recno += shift
+			}
+			moved[i] = recno
+		}
+		recnos = moved
+	}
+	return recnos, unlock, nil
+}
+
+// unlockArea libera los bloqueos de la tabla del área.
+func unlockArea(wa *object.WorkArea) *object.Error {
+	if err := wa.Table.UnlockAll(); err != nil {
+		return tableError(wa, err)
+	}
+	return nil
+}
+
+// refreshTable lee los cambios de otros procesos en la tabla del área; si
+// el puntero está en un registro agregado en el buffer lo sigue cuando
+// cambia de número.
+func refreshTable(wa *object.WorkArea) (int, *object.Error) {
+	appended := wa.Table.Appended(wa.Recno)
+	shift, err := wa.Table.Refresh()
+	if err != nil {
+		return 0, tableError(wa, err)
+	}
+	if appended {
+		wa.Recno += shift
+	}
+	return shift, nil
+}
+
+// refreshArea lee los cambios de otros procesos en la tabla del área y
+// reconstruye el índice si dejó de corresponder a la tabla.
+func refreshArea(wa *object.WorkArea, env *object.Environment) *object.Error {
+	if !wa.Table.Shared() {
+		return nil
+	}
+	if _, errObj := refreshTable(wa); errObj != nil {
+		return errObj
+	}
+	if wa.Table.IndexStale() {
+		return reindexTable(wa, env)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package evaluator
+
+import (
+	"FoxLite/src/object"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+func init() {
+	registerBuiltins(map[string]object.BuiltinFunction{
+		"abs":     unaryMath("ABS", math.Abs),
+		"ceiling": unaryMath("CEILING", math.Ceil),
+		"floor":   unaryMath("FLOOR", math.Floor),
+		"int":     unaryMath("INT", math.Trunc),
+		"exp":     unaryMath("EXP", math.Exp),
+		"sin":     unaryMath("SIN", math.Sin),
+		"cos":     unaryMath("COS", math.Cos),
+		"tan":     unaryMath("TAN", math.Tan),
+		"atan":    unaryMath("ATAN", math.Atan),
+		"dtor":    unaryMath("DTOR", func(x float64) float64 { return x * math.Pi / 180 }),
+		"rtod":    unaryMath("RTOD", func(x float64) float64 { return x * 180 / math.Pi }),
+		"sign":    unaryMath("SIGN", sign),
+		"sqrt":    builtinSqrt,
+		"log":     builtinLog,
+		"log10":   builtinLog10,
+		"asin":    builtinAsin,
+		"acos":    builtinAcos,
+		"atn2":    builtinAtn2,
+		"round":   builtinRound,
+		"mod":     builtinMod,
+		"max":     builtinMax,
+		"min":     builtinMin,
+		"pi":      builtinPi,
+		"rand":    builtinRand,
+	})
+}
+
+// unaryMath construye una función nativa de un solo argumento numérico.
+func unaryMath(name string, fn func(float64) float64) object.BuiltinFunction {
+	return func(env *object.Environment, args ...object.Object) object.Object {
+		if err := checkArgs(name, args, 1, 1); err != nil {
+			return err
+		}
+		x, err := numberArg(name, args, 0)
+		if err != nil {
+			return err
+		}
+		return &object.Integer{Value: fn(x)}
+	}
+}
+
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+// domainMath es como unaryMath pero valida el dominio del argumento.
+func domainMath(name string, args []object.Object, valid func(float64) bool, fn func(float64) float64) object.Object {
+	if err := checkArgs(name, args, 1, 1); err != nil {
+		return err
+	}
+	x, err := numberArg(name, args, 0)
+	if err != nil {
+		return err
+	}
+	if !valid(x) {
+		return object.NewError(fmt.Sprintf("%s(): argument %v is out of range", name, x))
+	}
+	return &object.Integer{Value: fn(x)}
+}
+
+func builtinSqrt(env *object.Environment, args ...object.Object) object.Object {
+	return domainMath("SQRT", args, func(x float64) bool { return x >= 0 }, math.Sqrt)
+}
+
+func builtinLog(env *object.Environment, args ...object.Object) object.Object {
+	return domainMath("LOG", args, func(x float64) bool { return x > 0 }, math.Log)
+}
+
+func builtinLog10(env *object.Environment, args ...object.Object) object.Object {
+	return domainMath("LOG10", args, func(x float64) bool { return x > 0 }, math.Log10)
+}
+
+func builtinAsin(env *object.Environment, args ...object.Object) object.Object {
+	return domainMath("ASIN", args, func(x float64) bool { return x >= -1 && x <= 1 }, math.Asin)
+}
+
+func builtinAcos(env *object.Environment, args ...object.Object) object.Object {
+	return domainMath("ACOS", args, func(x float64) bool { return x >= -1 && x <= 1 }, math.Acos)
+}
+
+func builtinAtn2(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("ATN2", args, 2, 2); err != nil {
+		return err
+	}
+	y, err := numberArg("ATN2", args, 0)
+	if err != nil {
+		return err
+	}
+	x, err := numberArg("ATN2", args, 1)
+	if err != nil {
+		return err
+	}
+	return &object.Integer{Value: math.Atan2(y, x)}
+}
+
+func builtinPi(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("PI", args, 0, 0); err != nil {
+		return err
+	}
+	return &object.Integer{Value: math.Pi}
+}
+
+// ROUND(nExpression, nDecimalPlaces)
+// Redondea alejándose del cero (2.5 => 3, -2.5 => -3) sobre la
+// representación decimal del número, de modo que ROUND(2.675, 2) = 2.68.
+// Con decimales negativos redondea a la izquierda del punto.
+func builtinRound(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("ROUND", args, 2, 2); err != nil {
+		return err
+	}
+	x, err := numberArg("ROUND", args, 0)
+	if err != nil {
+		return err
+	}
+	places, err := numberArg("ROUND", args, 1)
+	if err != nil {
+		return err
+	}
+	return &object.Integer{Value: roundHalfAway(x, int(places))}
+}
+
+func roundHalfAway(x float64, places int) float64 {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return x
+	}
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(x, 'g', -1, 64))
+	if !ok {
+		return x
+	}
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt(places))), nil))
+	if places >= 0 {
+		r.Mul(r, scale)
+	} else {
+		r.Quo(r, scale)
+	}
+	// r = q + rem/den; redondeamos q según el resto
+	q, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	rem.Abs(rem).Mul(rem, big.NewInt(2))
+	if rem.Cmp(r.Denom()) >= 0 {
+		if r.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	result := new(big.Rat).SetInt(q)
+	if places >= 0 {
+		result.Quo(result, scale)
+	} else {
+		result.Mul(result, scale)
+	}
+	f, _ := result.Float64()
+	return f
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// MOD(nDividend, nDivisor)
+// A diferencia de math.Mod el resultado toma el signo del divisor:
+// MOD(-7, 3) = 2 y MOD(7, -3) = -2.
+func builtinMod(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("MOD", args, 2, 2); err != nil {
+		return err
+	}
+	x, err := numberArg("MOD", args, 0)
+	if err != nil {
+		return err
+	}
+	y, err := numberArg("MOD", args, 1)
+	if err != nil {
+		return err
+	}
+	return foxMod(x, y)
+}
+
+// foxMod implementa el módulo de FoxPro, compartido por MOD() y el operador `%`.
+func foxMod(x float64, y float64) object.Object {
+	if y == 0 {
+		return object.NewError("division by zero")
+	}
+	r := math.Mod(x, y)
+	if r != 0 && (r < 0) != (y < 0) {
+		r += y
+	}
+	return &object.Integer{Value: r}
+}
+
+func builtinMax(env *object.Environment, args ...object.Object) object.Object {
+	return extremeValue("MAX", args, env, 1)
+}
+
+func builtinMin(env *object.Environment, args ...object.Object) object.Object {
+	return extremeValue("MIN", args, env, -1)
+}
+
+// extremeValue devuelve el mayor (want = 1) o el menor (want = -1) de los
+// argumentos, que pueden ser de cualquier tipo comparable siempre que sean
+// todos del mismo tipo.
+func extremeValue(name string, args []object.Object, env *object.Environment, want int) object.Object {
+	if err := checkArgs(name, args, 2, -1); err != nil {
+		return err
+	}
+	result := args[0]
+	for _, arg := range args[1:] {
+		cmp, err := compareValues(arg, result, env)
+		if err != nil {
+			return object.NewError(fmt.Sprintf("%s(): %s", name, err.Message))
+		}
+		if cmp == want {
+			result = arg
+		}
+	}
+	return result
+}
+
+// maxRandSpan es la mayor distancia entre los límites de RAND(nMin, nMax).
+const maxRandSpan = 1 << 53
+
+// RAND([nSeedValue]) | RAND(nMin, nMax)
+// Sin argumentos devuelve el siguiente número entre 0 y 1 de la secuencia.
+// Con una semilla positiva reinicia la secuencia (resultados reproducibles)
+// y con una semilla negativa la inicializa con el reloj del sistema.
+// Con dos argumentos devuelve un entero entre nMin y nMax (inclusive).
+func builtinRand(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("RAND", args, 0, 2); err != nil {
+		return err
+	}
+	if len(args) == 2 {
+		lo, err := numberArg("RAND", args, 0)
+		if err != nil {
+			return err
+		}
+		hi, err := numberArg("RAND", args, 1)
+		if err != nil {
+			return err
+		}
+		if hi < lo {
+			lo, hi = hi, lo
+		}
+		lo, hi = math.Ceil(lo), math.Floor(hi)
+		if hi < lo {
+			return object.NewError(fmt.Sprintf("RAND(): there is no integer between %s and %s", env.Format().Inspect(args[0]), env.Format().Inspect(args[1])))
+		}
+		// los números de FoxLite solo representan enteros exactos hasta 2^53;
+		// la comparación negada también rechaza los límites NaN
+		if !(hi-lo < maxRandSpan) {
+			return object.NewError(fmt.Sprintf("RAND(): the range between %s and %s is too large", env.Format().Inspect(args[0]), env.Format().Inspect(args[1])))
+		}
+		return &object.Integer{Value: lo + float64(env.Rand().Int63n(int64(hi-lo)+1))}
+	}
+	if len(args) == 1 {
+		seed, err := numberArg("RAND", args, 0)
+		if err != nil {
+			return err
+		}
+		if seed < 0 {
+			env.Rand().Seed(time.Now().UnixNano())
+		} else {
+			env.Rand().Seed(int64(seed))
+		}
+	}
+	return &object.Integer{Value: env.Rand().Float64()}
+}
@@ -0,0 +1,55 @@
+package evaluator
+
+import (
+	"FoxLite/src/object"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	registerBuiltins(map[string]object.BuiltinFunction{
+		"createobject": builtinCreateObject,
+		"addproperty":  builtinAddProperty,
+	})
+}
+
+// CREATEOBJECT(cClassName)
+// Solo se admite la clase Empty: un objeto sin propiedades.
+func builtinCreateObject(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("CREATEOBJECT", args, 1, 1); err != nil {
+		return err
+	}
+	name, err := stringArg("CREATEOBJECT", args, 0)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(strings.TrimSpace(name), "empty") {
+		return object.NewError(fmt.Sprintf("CREATEOBJECT(): class `%s` is not found", strings.TrimSpace(name)))
+	}
+	return object.NewEmpty()
+}
+
+// ADDPROPERTY(oObject, cPropertyName [, eValue])
+// Agrega la propiedad (False si no se indica el valor) o cambia su valor.
+func builtinAddProperty(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("ADDPROPERTY", args, 2, 3); err != nil {
+		return err
+	}
+	obj, ok := args[0].(*object.Empty)
+	if !ok {
+		return argTypeError("ADDPROPERTY", 0, "object", args[0])
+	}
+	name, err := stringArg("ADDPROPERTY", args, 1)
+	if err != nil {
+		return err
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		return object.NewError("ADDPROPERTY(): expecting a property name")
+	}
+	var val object.Object = False
+	if len(args) == 3 {
+		val = args[2]
+	}
+	obj.Set(name, val)
+	return True
+}
@@ -0,0 +1,1040 @@
+package evaluator
+
+import (
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SQL pass-through sobre bases de datos SQLite: SQLSTRINGCONNECT() abre una
+// conexión con un archivo de SQLite y devuelve su handle, SQLEXEC() ejecuta
+// una sentencia y guarda las filas del resultado en un cursor (SQLRESULT si
+// no se indica otro nombre), SQLPREPARE() prepara una sentencia para los
+// siguientes SQLEXEC(h) y SQLDISCONNECT() cierra la conexión. En el texto
+// de la sentencia ?nombre y ?(expresión) son parámetros que toman el valor
+// de variables o expresiones de FoxLite al ejecutarla.
+//
+// Con SQLSETPROP(h, "Transactions", 2) la conexión empieza una transacción
+// en la primera sentencia, que dura hasta SQLCOMMIT() o SQLROLLBACK(); con 1
+// (el valor inicial) cada sentencia se confirma al ejecutarse.
+//
+// Los tipos de las columnas salen del tipo declarado en SQLite: INTEGER (I,
+// o N(20) si algún valor no cabe en 32 bits), REAL (B), NUMERIC(p,s) (N),
+// CHAR/VARCHAR(n) (C), TEXT sin longitud (M), BLOB (M binario), DATE (D),
+// DATETIME y TIMESTAMP (T) y BOOLEAN (L). Las columnas sin tipo declarado
+// (expresiones) toman el tipo de sus valores. Todas admiten null.
+//
+// Como en VFP, cuando SQLite informa un error las funciones devuelven -1 y
+// el programa sigue; AERROR() recupera el mensaje. Los argumentos inválidos
+// y los handles inexistentes son errores de FoxLite. SQLite necesita que el
+// intérprete se compile con el driver (go build -tags sqlite).
+
+// sqliteDriver es el nombre con que se registra el driver de SQLite.
+const sqliteDriver = "sqlite"
+
+// sptCursor es el cursor del resultado si SQLEXEC() no indica otro.
+const sptCursor = "SQLRESULT"
+
+func init() {
+	registerBuiltins(map[string]object.BuiltinFunction{
+		"sqlstringconnect": builtinSqlStringConnect,
+		"sqldisconnect":    builtinSqlDisconnect,
+		"sqlexec":          builtinSqlExec,
+		"sqlprepare":       builtinSqlPrepare,
+		"sqltables":        builtinSqlTables,
+		"sqlcolumns":       builtinSqlColumns,
+		"sqlsetprop":       builtinSqlSetProp,
+		"sqlgetprop":       builtinSqlGetProp,
+		"sqlcommit":        builtinSqlCommit,
+		"sqlrollback":      builtinSqlRollback,
+		"aerror":           builtinAError,
+	})
+}
+
+// SQLSTRINGCONNECT(cConnectString [, lShared])
+// cConnectString es la ruta del archivo de SQLite o una cadena de conexión
+// ODBC con Database=ruta. Si el archivo no existe se crea.
+func builtinSqlStringConnect(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("SQLSTRINGCONNECT", args, 1, 2); err != nil {
+		return err
+	}
+	connect, errObj := stringArg("SQLSTRINGCONNECT", args, 0)
+	if errObj != nil {
+		return errObj
+	}
+	path := sqlitePath(connect)
+	if path == "" {
+		return object.NewError("SQLSTRINGCONNECT(): the connection string has no database")
+	}
+	if !hasDriver(sqliteDriver) {
+		return object.NewError("SQLSTRINGCONNECT(): SQLite driver is not available (build FoxLite with -tags sqlite)")
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	db, err := sql.Open(sqliteDriver, path)
+	if err != nil {
+		return sptError("SQLSTRINGCONNECT", err, nil, env)
+	}
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err == nil {
+		// lee la cabecera: falla si el archivo no es una base de SQLite
+		_, err = conn.ExecContext(ctx, "PRAGMA schema_version")
+		if err != nil {
+			conn.Close()
+		}
+	}
+	if err != nil {
+		db.Close()
+		return sptError("SQLSTRINGCONNECT", err, nil, env)
+	}
+	handle := env.AddConnection(&object.Connection{
+		ConnectString: connect,
+		Path:          path,
+		Transactions:  object.TransactionsAuto,
+		DB:            db,
+		Conn:          conn,
+	})
+	return &object.Integer{Value: float64(handle)}
+}
+
+// sqlitePath extrae la ruta del archivo de una cadena de conexión:
+// "ventas.db" o "Driver=SQLite3 ODBC Driver;Database=ventas.db".
+func sqlitePath(connect string) string {
+	connect = strings.TrimSpace(connect)
+	if !strings.Contains(connect, "=") {
+		return connect
+	}
+	for _, part := range strings.Split(connect, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "database", "dbq", "data source":
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+func hasDriver(name string) bool {
+	for _, driver := range sql.Drivers() {
+		if driver == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sptErrorNumber es el número de error de VFP para los errores de ODBC.
+const sptErrorNumber = 1526
+
+// sptError guarda el error de la base de datos para AERROR() y devuelve el
+// -1 con que las funciones de SQL pass-through informan que fallaron. c es
+// nil si el error no es de una conexión.
+func sptError(fn string, err error, c *object.Connection, env *object.Environment) object.Object {
+	var handle object.Object = Null
+	if c != nil {
+		handle = &object.Integer{Value: float64(c.Handle)}
+	}
+	code := 0
+	var coded interface{ Code() int }
+	if errors.As(err, &coded) {
+		code = coded.Code()
+	}
+	env.SetLastError([]object.Object{
+		&object.Integer{Value: sptErrorNumber},
+		&object.String{Value: fmt.Sprintf("%s(): %v", fn, err)},
+		&object.String{Value: err.Error()},
+		Null, // SQLite no informa el SQLSTATE
+		&object.Integer{Value: float64(code)},
+		handle,
+		Null,
+	})
+	return &object.Integer{Value: -1}
+}
+
+// AERROR(ArrayName)
+// Crea el array con el último error de SQL pass-through (los demás errores
+// detienen el programa): una fila con el número (1526), el mensaje, el
+// mensaje de SQLite, el SQLSTATE (.NULL.), el código de error de SQLite, el
+// handle de la conexión y .NULL.. Devuelve la cantidad de filas, 0 si no
+// hubo errores.
+func builtinAError(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("AERROR", args, 1, 1); err != nil {
+		return err
+	}
+	name, errObj := stringArg("AERROR", args, 0)
+	if errObj != nil {
+		return errObj
+	}
+	info := env.LastError()
+	if info == nil {
+		return &object.Integer{Value: 0}
+	}
+	row := &object.Array{Elements: append([]object.Object{}, info...)}
+	env.Set(name, &object.Array{Elements: []object.Object{row}})
+	return &object.Integer{Value: 1}
+}
+
+// connectionArg devuelve la conexión del handle del argumento idx.
+func connectionArg(fn string, args []object.Object, idx int, env *object.Environment) (*object.Connection, *object.Error) {
+	num, errObj := numberArg(fn, args, idx)
+	if errObj != nil {
+		return nil, errObj
+	}
+	c := env.Connection(int(num))
+	if c == nil {
+		return nil, object.NewError(fmt.Sprintf("%s(): connection handle %v is invalid", fn, num))
+	}
+	return c, nil
+}
+
+// SQLDISCONNECT(nConnectionHandle)
+// Con 0 cierra todas las conexiones. Una transacción manual sin confirmar
+// se deshace.
+func builtinSqlDisconnect(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("SQLDISCONNECT", args, 1, 1); err != nil {
+		return err
+	}
+	if num, ok := args[0].(*object.Integer); ok && num.Value == 0 {
+		if err := env.CloseConnections(); err != nil {
+			return sptError("SQLDISCONNECT", err, nil, env)
+		}
+		return &object.Integer{Value: 1}
+	}
+	c, errObj := connectionArg("SQLDISCONNECT", args, 0, env)
+	if errObj != nil {
+		return errObj
+	}
+	if err := env.CloseConnection(c); err != nil {
+		return sptError("SQLDISCONNECT", err, c, env)
+	}
+	return &object.Integer{Value: 1}
+}
+
+// SQLPREPARE(nConnectionHandle, cSQLCommand [, cCursorName])
+// Prepara la sentencia para ejecutarla con SQLEXEC(nConnectionHandle); los
+// parámetros toman su valor en cada ejecución.
+func builtinSqlPrepare(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("SQLPREPARE", args, 2, 3); err != nil {
+		return err
+	}
+	c, errObj := connectionArg("SQLPREPARE", args, 0, env)
+	if errObj != nil {
+		return errObj
+	}
+	text, errObj := stringArg("SQLPREPARE", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	cursor := sptCursor
+	if len(args) == 3 {
+		if cursor, errObj = stringArg("SQLPREPARE", args, 2); errObj != nil {
+			return errObj
+		}
+	}
+	query, _ := splitParams(text)
+	stmt, err := c.Conn.PrepareContext(context.Background(), query)
+	if err != nil {
+		return sptError("SQLPREPARE", err, c, env)
+	}
+	if c.Prepared != nil {
+		c.Prepared.Close()
+	}
+	c.Prepared, c.PreparedSQL, c.PreparedCursor = stmt, text, cursor
+	return &object.Integer{Value: 1}
+}
+
+// SQLEXEC(nConnectionHandle [, cSQLCommand [, cCursorName]])
+// Sin cSQLCommand ejecuta la sentencia de SQLPREPARE(). Si la sentencia
+// devuelve filas se guardan en el cursor, que queda seleccionado. Devuelve
+// la cantidad de resultados (1).
+func builtinSqlExec(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("SQLEXEC", args, 1, 3); err != nil {
+		return err
+	}
+	c, errObj := connectionArg("SQLEXEC", args, 0, env)
+	if errObj != nil {
+		return errObj
+	}
+	text, cursor := c.PreparedSQL, c.PreparedCursor
+	prepared := len(args) == 1
+	if prepared && c.Prepared == nil {
+		return object.NewError("SQLEXEC(): no SQL statement is prepared")
+	}
+	if !prepared {
+		if text, errObj = stringArg("SQLEXEC", args, 1); errObj != nil {
+			return errObj
+		}
+		cursor = sptCursor
+		if len(args) == 3 {
+			if cursor, errObj = stringArg("SQLEXEC", args, 2); errObj != nil {
+				return errObj
+			}
+		}
+	}
+	query, params := splitParams(text)
+	values := make([]interface{}, len(params))
+	for i, param := range params {
+		if values[i], errObj = paramValue(param, env); errObj != nil {
+			return errObj
+		}
+	}
+
+	ctx := context.Background()
+	if c.Transactions == object.TransactionsManual {
+		if err := c.Begin(ctx); err != nil {
+			return sptError("SQLEXEC", err, c, env)
+		}
+	}
+	var rows *sql.Rows
+	var err error
+	switch {
+	case prepared && c.Tx != nil:
+		rows, err = c.Tx.StmtContext(ctx, c.Prepared).QueryContext(ctx, values...)
+	case prepared:
+		rows, err = c.Prepared.QueryContext(ctx, values...)
+	case c.Tx != nil:
+		rows, err = c.Tx.QueryContext(ctx, query, values...)
+	default:
+		rows, err = c.Conn.QueryContext(ctx, query, values...)
+	}
+	if err != nil {
+		return sptError("SQLEXEC", err, c, env)
+	}
+	fields, data, err := readRows(rows)
+	if err != nil {
+		return sptError("SQLEXEC", err, c, env)
+	}
+	if len(fields) > 0 {
+		if errObj := sptResult(cursor, fields, data, env); errObj != nil {
+			return errObj
+		}
+	}
+	return &object.Integer{Value: 1}
+}
+
+// splitParams cambia los parámetros ?nombre y ?(expresión) del texto de
+// una sentencia por el ? de SQLite y devuelve sus expresiones. Los textos
+// entre comillas y los nombres entre corchetes no se modifican.
+func splitParams(text string) (string, []string) {
+	var out strings.Builder
+	var params []string
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\'' || r == '"' || r == '[' || r == '`':
+			end := r
+			if r == '[' {
+				end = ']'
+			}
+			j := i + 1
+			for j < len(runes) && runes[j] != end {
+				j++
+			}
+			if j >= len(runes) {
+				j = len(runes) - 1
+			}
+			out.WriteString(string(runes[i : j+1]))
+			i = j
+		case r == '?' && i+1 < len(runes) && runes[i+1] == '(':
+			depth, j := 0, i+1
+			for ; j < len(runes); j++ {
+				if runes[j] == '(' {
+					depth++
+				} else if runes[j] == ')' {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+			if j >= len(runes) {
+				j = len(runes) - 1
+			}
+			params = append(params, string(runes[i+1:j+1]))
+			out.WriteRune('?')
+			i = j
+		case r == '?' && i+1 < len(runes) && (unicode.IsLetter(runes[i+1]) || runes[i+1] == '_'):
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_' ||
+				(runes[j] == '.' && j+1 < len(runes) && (unicode.IsLetter(runes[j+1]) || runes[j+1] == '_'))) {
+				j++
+			}
+			params = append(params, string(runes[i+1:j]))
+			out.WriteRune('?')
+			i = j - 1
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String(), params
+}
+
+// paramValue evalúa un parámetro y lo convierte al valor que recibe SQLite.
+func paramValue(param string, env *object.Environment) (interface{}, *object.Error) {
+	exp, errObj := compileExpression(param)
+	if errObj != nil {
+		return nil, object.NewError(fmt.Sprintf("SQLEXEC(): parameter `%s`: %s", param, errObj.Message))
+	}
+	val := Eval(exp, env)
+	switch val := val.(type) {
+	case *object.Error:
+		return nil, object.NewError(fmt.Sprintf("SQLEXEC(): parameter `%s`: %s", param, val.Message))
+	case *object.Null:
+		return nil, nil
+	case *object.String:
+		return val.Value, nil
+	case *object.Integer:
+		if val.Value == math.Trunc(val.Value) && math.Abs(val.Value) < 1<<53 {
+			return int64(val.Value), nil
+		}
+		return val.Value, nil
+	case *object.Boolean:
+		return val.Value, nil
+	case *object.Date:
+		if val.DateTime {
+			return val.Value.Format("2006-01-02 15:04:05"), nil
+		}
+		return val.Value.Format("2006-01-02"), nil
+	}
+	return nil, object.NewError(fmt.Sprintf("SQLEXEC(): parameter `%s`: cannot send a `%s` value", param, object.TypeToStr(val.Type())))
+}
+
+// readRows lee todas las filas de un resultado y define los campos del
+// cursor a partir de los tipos declarados y de los valores.
+func readRows(rows *sql.Rows) ([]dbf.Field, [][]object.Object, error) {
+	defer rows.Close()
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, nil, err
+	}
+	var raw [][]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, nil, err
+		}
+		raw = append(raw, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	fields := make([]dbf.Field, len(columns))
+	used := map[string]bool{}
+	for i, column := range columns {
+		values := make([]interface{}, len(raw))
+		for j, row := range raw {
+			values[j] = row[i]
+		}
+		fields[i] = columnField(column.DatabaseTypeName(), values)
+		fields[i].Name = columnName(column.Name(), i, used)
+	}
+	data := make([][]object.Object, len(raw))
+	for j, row := range raw {
+		data[j] = make([]object.Object, len(columns))
+		for i, value := range row {
+			data[j][i] = columnValue(&fields[i], value)
+		}
+	}
+	return fields, data, nil
+}
+
+// columnName convierte el nombre de una columna en un nombre de campo:
+// los caracteres que no valen en un nombre se cambian por '_' y las
+// expresiones sin alias ("1 + 1") se llaman EXP_n, como en VFP.
+func columnName(name string, idx int, used map[string]bool) string {
+	var out strings.Builder
+	for _, r := range strings.ToUpper(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			out.WriteRune(r)
+		} else {
+			out.WriteRune('_')
+		}
+	}
+	result := out.String()
+	if first := []rune(result + " ")[0]; !unicode.IsLetter(first) && first != '_' || strings.Trim(result, "_") == "" {
+		result = fmt.Sprintf("EXP_%d", idx+1)
+	}
+	if len(result) > maxColumnName {
+		result = result[:maxColumnName]
+	}
+	base := result
+	for n := 1; used[result]; n++ {
+		suffix := fmt.Sprintf("_%d", n)
+		if len(base)+len(suffix) > maxColumnName {
+			base = base[:maxColumnName-len(suffix)]
+		}
+		result = base + suffix
+	}
+	used[result] = true
+	return result
+}
+
+// declSize lee la longitud y los decimales de un tipo declarado:
+// VARCHAR(30), NUMERIC(10,2).
+var declSize = regexp.MustCompile(`\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\)`)
+
+// columnField define el campo de una columna según su tipo declarado en
+// SQLite y, si no lo tiene, según sus valores.
+func columnField(decl string, values []interface{}) dbf.Field {
+	decl = strings.ToUpper(strings.TrimSpace(decl))
+	size, decimals := 0, 0
+	if m := declSize.FindStringSubmatch(decl); m != nil {
+		size, _ = strconv.Atoi(m[1])
+		decimals, _ = strconv.Atoi(m[2] + "0")
+		decimals /= 10
+	}
+	base := decl
+	if idx := strings.IndexByte(decl, '('); idx >= 0 {
+		base = strings.TrimSpace(decl[:idx])
+	}
+	field := dbf.Field{Flags: dbf.FlagNullable}
+	switch {
+	case base == "":
+		return valuesField(values)
+	case strings.Contains(base, "INT"):
+		field.Type = dbf.Integer
+		for _, value := range values {
+			if num, ok := numberOf(value); ok && (num != math.Trunc(num) || num > math.MaxInt32 || num < math.MinInt32) {
+				field.Type, field.Length = dbf.Numeric, 20
+				break
+			}
+		}
+	case strings.Contains(base, "CHAR") || strings.Contains(base, "CLOB") || strings.Contains(base, "TEXT"):
+		longest := longestText(values)
+		switch {
+		case size == 0 || longest > 254 || size > 254:
+			field.Type = dbf.Memo
+		default:
+			field.Type, field.Length = dbf.Character, max(size, longest)
+		}
+	case strings.Contains(base, "BLOB"):
+		field.Type = dbf.Memo
+		field.Flags |= dbf.FlagBinary
+	case strings.Contains(base, "REAL") || strings.Contains(base, "FLOA") || strings.Contains(base, "DOUB"):
+		field.Type = dbf.Double
+	case strings.Contains(base, "BOOL") || base == "BIT":
+		field.Type = dbf.Logical
+	case strings.Contains(base, "DATETIME") || strings.Contains(base, "TIMESTAMP"):
+		field.Type = dbf.DateTime
+	case base == "DATE":
+		field.Type = dbf.Date
+	case (strings.Contains(base, "DEC") || strings.Contains(base, "NUM")) && size > 0:
+		// el signo y el punto decimal también ocupan lugar en el campo
+		field.Type, field.Length, field.Decimals = dbf.Numeric, min(size+2, 20), decimals
+		if decimals == 0 {
+			field.Length = min(size+1, 20)
+		}
+	case strings.Contains(base, "DEC") || strings.Contains(base, "NUM") || strings.Contains(base, "MONEY"):
+		field.Type = dbf.Double
+	default:
+		return valuesField(values)
+	}
+	return field
+}
+
+// valuesField define el campo de una columna sin tipo declarado por el
+// primer valor que no es null.
+func valuesField(values []interface{}) dbf.Field {
+	field := dbf.Field{Flags: dbf.FlagNullable}
+	var first interface{}
+	for _, value := range values {
+		if value != nil {
+			first = value
+			break
+		}
+	}
+	switch first.(type) {
+	case int64:
+		return columnField("INTEGER", values)
+	case float64:
+		field.Type = dbf.Double
+	case bool:
+		field.Type = dbf.Logical
+	case time.Time:
+		field.Type = dbf.DateTime
+	case []byte:
+		field.Type = dbf.Memo
+		field.Flags |= dbf.FlagBinary
+	default:
+		if longest := longestText(values); longest > 254 {
+			field.Type = dbf.Memo
+		} else {
+			field.Type, field.Length = dbf.Character, max(longest, 1)
+		}
+	}
+	return field
+}
+
+func numberOf(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case int64:
+		return float64(value), true
+	case float64:
+		return value, true
+	}
+	return 0, false
+}
+
+func longestText(values []interface{}) int {
+	longest := 0
+	for _, value := range values {
+		switch value := value.(type) {
+		case string:
+			longest = max(longest, len([]rune(value)))
+		case []byte:
+			longest = max(longest, len(value))
+		}
+	}
+	return longest
+}
+
+// dateLayouts son los formatos de fecha que SQLite guarda como texto.
+var dateLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// columnValue convierte el valor de SQLite al tipo del campo; un valor que
+// no se puede convertir queda en null.
+func columnValue(field *dbf.Field, value interface{}) object.Object {
+	if value == nil {
+		return Null
+	}
+	switch field.Type {
+	case dbf.Character, dbf.Memo:
+		switch value := value.(type) {
+		case string:
+			return &object.String{Value: value}
+		case []byte:
+			return &object.String{Value: string(value)}
+		case time.Time:
+			return &object.String{Value: value.Format("2006-01-02 15:04:05")}
+		default:
+			return &object.String{Value: fmt.Sprint(value)}
+		}
+	case dbf.Integer, dbf.Numeric, dbf.Double:
+		if num, ok := numberOf(value); ok {
+			return &object.Integer{Value: num}
+		}
+		if text, ok := value.(string); ok {
+			if num, err := strconv.ParseFloat(strings.TrimSpace(text), 64); err == nil {
+				return &object.Integer{Value: num}
+			}
+		}
+	case dbf.Logical:
+		switch value := value.(type) {
+		case bool:
+			return toBoolean(value)
+		case int64:
+			return toBoolean(value != 0)
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+				return toBoolean(b)
+			}
+		}
+	case dbf.Date, dbf.DateTime:
+		var t time.Time
+		switch value := value.(type) {
+		case time.Time:
+			t = value
+		case string:
+			for _, layout := range dateLayouts {
+				if parsed, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+					t = parsed
+					break
+				}
+			}
+		}
+		if !t.IsZero() {
+			return &object.Date{Value: t, DateTime: field.Type == dbf.DateTime}
+		}
+	}
+	return Null
+}
+
+// sptResult guarda las filas en un cursor de lectura y escritura, que
+// reemplaza a otro con el mismo alias.
+func sptResult(name string, fields []dbf.Field, data [][]object.Object, env *object.Environment) *object.Error {
+	table, errObj := createTempTable(fields)
+	if errObj != nil {
+		return errObj
+	}
+	if errObj := writeSqlRows(table, &sqlResult{rows: data}); errObj != nil {
+		table.Drop()
+		return errObj
+	}
+	if errObj, ok := openCursor(strings.ToUpper(strings.TrimSpace(name)), table, env).(*object.Error); ok {
+		return errObj
+	}
+	return nil
+}
+
+// SQLTABLES(nConnectionHandle [, cTableTypes [, cCursorName]])
+// Guarda en el cursor las tablas y vistas de la base: TABLE_CAT,
+// TABLE_SCHEM, TABLE_NAME, TABLE_TYPE y REMARKS. cTableTypes limita los
+// tipos: "TABLE", "VIEW" o "'TABLE','VIEW'".
+func builtinSqlTables(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("SQLTABLES", args, 1, 3); err != nil {
+		return err
+	}
+	c, errObj := connectionArg("SQLTABLES", args, 0, env)
+	if errObj != nil {
+		return errObj
+	}
+	types := map[string]bool{"TABLE": true, "VIEW": true}
+	if len(args) >= 2 {
+		list, errObj := stringArg("SQLTABLES", args, 1)
+		if errObj != nil {
+			return errObj
+		}
+		if strings.TrimSpace(list) != "" {
+			types = map[string]bool{}
+			for _, kind := range strings.Split(list, ",") {
+				types[strings.ToUpper(strings.Trim(kind, " '\""))] = true
+			}
+		}
+	}
+	cursor := sptCursor
+	if len(args) == 3 {
+		if cursor, errObj = stringArg("SQLTABLES", args, 2); errObj != nil {
+			return errObj
+		}
+	}
+	rows, err := sptQuery(c, "SELECT name, upper(type) FROM sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite!_%' ESCAPE '!' ORDER BY name")
+	if err != nil {
+		return sptError("SQLTABLES", err, c, env)
+	}
+	var data [][]object.Object
+	for _, row := range rows {
+		if types[row[1].(string)] {
+			data = append(data, []object.Object{Null, Null, &object.String{Value: row[0].(string)}, &object.String{Value: row[1].(string)}, Null})
+		}
+	}
+	fields := []dbf.Field{
+		{Name: "TABLE_CAT", Type: dbf.Character, Length: 128, Flags: dbf.FlagNullable},
+		{Name: "TABLE_SCHEM", Type: dbf.Character, Length: 128, Flags: dbf.FlagNullable},
+		{Name: "TABLE_NAME", Type: dbf.Character, Length: 128, Flags: dbf.FlagNullable},
+		{Name: "TABLE_TYPE", Type: dbf.Character, Length: 32, Flags: dbf.FlagNullable},
+		{Name: "REMARKS", Type: dbf.Character, Length: 254, Flags: dbf.FlagNullable},
+	}
+	if errObj := sptResult(cursor, fields, data, env); errObj != nil {
+		return errObj
+	}
+	return &object.Integer{Value: 1}
+}
+
+// sqlTypes son los códigos de ODBC de los tipos de los campos (SQLCOLUMNS
+// con NATIVE).
+var sqlTypes = map[byte]int{
+	dbf.Character: 12, // SQL_VARCHAR
+	dbf.Memo:      -1, // SQL_LONGVARCHAR
+	dbf.Integer:   4,  // SQL_INTEGER
+	dbf.Numeric:   2,  // SQL_NUMERIC
+	dbf.Double:    8,  // SQL_DOUBLE
+	dbf.Logical:   -7, // SQL_BIT
+	dbf.Date:      91, // SQL_TYPE_DATE
+	dbf.DateTime:  93, // SQL_TYPE_TIMESTAMP
+}
+
+// SQLCOLUMNS(nConnectionHandle, cTableName [, "FOXPRO" | "NATIVE" [, cCursorName]])
+// Guarda en el cursor las columnas de la tabla. Con FOXPRO (el formato por
+// omisión) el cursor tiene FIELD_NAME, FIELD_TYPE, FIELD_LEN y FIELD_DEC con
+// el campo que tendría la columna en un cursor de FoxLite; con NATIVE tiene
+// las columnas de ODBC con el tipo declarado en SQLite.
+func builtinSqlColumns(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("SQLCOLUMNS", args, 2, 4); err != nil {
+		return err
+	}
+	c, errObj := connectionArg("SQLCOLUMNS", args, 0, env)
+	if errObj != nil {
+		return errObj
+	}
+	table, errObj := stringArg("SQLCOLUMNS", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	format := "FOXPRO"
+	if len(args) >= 3 {
+		if format, errObj = stringArg("SQLCOLUMNS", args, 2); errObj != nil {
+			return errObj
+		}
+		format = strings.ToUpper(strings.TrimSpace(format))
+		if format != "FOXPRO" && format != "NATIVE" {
+			return object.NewError(fmt.Sprintf("SQLCOLUMNS(): invalid format `%s`, expecting FOXPRO or NATIVE", format))
+		}
+	}
+	cursor := sptCursor
+	if len(args) == 4 {
+		if cursor, errObj = stringArg("SQLCOLUMNS", args, 3); errObj != nil {
+			return errObj
+		}
+	}
+	table = strings.TrimSpace(table)
+	rows, err := sptQuery(c, "SELECT name, type, \"notnull\", dflt_value FROM pragma_table_info(?) ORDER BY cid", table)
+	if err != nil {
+		return sptError("SQLCOLUMNS", err, c, env)
+	}
+	if len(rows) == 0 {
+		return sptError("SQLCOLUMNS", fmt.Errorf("table `%s` is not found", table), c, env)
+	}
+	var fields []dbf.Field
+	var data [][]object.Object
+	if format == "FOXPRO" {
+		fields = []dbf.Field{
+			{Name: "FIELD_NAME", Type: dbf.Character, Length: 128},
+			{Name: "FIELD_TYPE", Type: dbf.Character, Length: 1},
+			{Name: "FIELD_LEN", Type: dbf.Numeric, Length: 3},
+			{Name: "FIELD_DEC", Type: dbf.Numeric, Length: 3},
+		}
+		used := map[string]bool{}
+		for i, row := range rows {
+			field := columnField(textOf(row[1]), nil)
+			if field.Type == dbf.Character && field.Length == 0 {
+				field.Length = 1
+			}
+			normalizeLength(&field)
+			data = append(data, []object.Object{
+				&object.String{Value: columnName(textOf(row[0]), i, used)},
+				&object.String{Value: string(rune(field.Type))},
+				&object.Integer{Value: float64(field.Length)},
+				&object.Integer{Value: float64(field.Decimals)},
+			})
+		}
+	} else {
+		fields = []dbf.Field{
+			{Name: "TABLE_CAT", Type: dbf.Character, Length: 128, Flags: dbf.FlagNullable},
+			{Name: "TABLE_SCHEM", Type: dbf.Character, Length: 128, Flags: dbf.FlagNullable},
+			{Name: "TABLE_NAME", Type: dbf.Character, Length: 128},
+			{Name: "COLUMN_NAME", Type: dbf.Character, Length: 128},
+			{Name: "DATA_TYPE", Type: dbf.Integer},
+			{Name: "TYPE_NAME", Type: dbf.Character, Length: 128},
+			{Name: "COLUMN_SIZE", Type: dbf.Integer, Flags: dbf.FlagNullable},
+			{Name: "DECIMAL_DIGITS", Type: dbf.Integer, Flags: dbf.FlagNullable},
+			{Name: "NULLABLE", Type: dbf.Integer},
+			{Name: "REMARKS", Type: dbf.Character, Length: 254, Flags: dbf.FlagNullable},
+			{Name: "COLUMN_DEF", Type: dbf.Memo, Flags: dbf.FlagNullable},
+		}
+		for _, row := range rows {
+			decl := textOf(row[1])
+			field := columnField(decl, nil)
+			normalizeLength(&field)
+			dataType := sqlTypes[field.Type]
+			if field.Flags&dbf.FlagBinary != 0 {
+				dataType = -4 // SQL_LONGVARBINARY
+			}
+			nullable := 1
+			if num, _ := numberOf(row[2]); num != 0 {
+				nullable = 0
+			}
+			var def object.Object = Null
+			if row[3] != nil {
+				def = &object.String{Value: textOf(row[3])}
+			}
+			var size, decimals object.Object = Null, Null
+			if m := declSize.FindStringSubmatch(decl); m != nil {
+				n, _ := strconv.Atoi(m[1])
+				size = &object.Integer{Value: float64(n)}
+				if m[2] != "" {
+					n, _ = strconv.Atoi(m[2])
+					decimals = &object.Integer{Value: float64(n)}
+				}
+			}
+			data = append(data, []object.Object{
+				Null, Null,
+				&object.String{Value: table},
+				&object.String{Value: textOf(row[0])},
+				&object.Integer{Value: float64(dataType)},
+				&object.String{Value: decl},
+				size, decimals,
+				&object.Integer{Value: float64(nullable)},
+				Null, def,
+			})
+		}
+	}
+	if errObj := sptResult(cursor, fields, data, env); errObj != nil {
+		return errObj
+	}
+	return &object.Integer{Value: 1}
+}
+
+// normalizeLength completa la longitud de los tipos de longitud fija.
+func normalizeLength(field *dbf.Field) {
+	switch field.Type {
+	case dbf.Integer, dbf.Memo:
+		field.Length = 4
+	case dbf.Double, dbf.Date, dbf.DateTime:
+		field.Length = 8
+	case dbf.Logical:
+		field.Length = 1
+	}
+}
+
+func textOf(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(value)
+	case string:
+		return value
+	}
+	return fmt.Sprint(value)
+}
+
+// sptQuery ejecuta una consulta interna de la conexión (dentro de la
+// transacción manual si hay una en curso).
+func sptQuery(c *object.Connection, query string, args ...interface{}) ([][]interface{}, error) {
+	ctx := context.Background()
+	var rows *sql.Rows
+	var err error
+	if c.Tx != nil {
+		rows, err = c.Tx.QueryContext(ctx, query, args...)
+	} else {
+		rows, err = c.Conn.QueryContext(ctx, query, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var result [][]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			if b, ok := value.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		result = append(result, values)
+	}
+	return result, rows.Err()
+}
+
+// SQLSETPROP(nConnectionHandle, cSetting [, eExpression])
+// Solo admite "Transactions": 1 (automáticas) o 2 (manuales). Al volver a
+// automáticas se confirma la transacción en curso.
+func builtinSqlSetProp(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("SQLSETPROP", args, 3, 3); err != nil {
+		return err
+	}
+	c, errObj := connectionArg("SQLSETPROP", args, 0, env)
+	if errObj != nil {
+		return errObj
+	}
+	setting, errObj := stringArg("SQLSETPROP", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	if !strings.EqualFold(strings.TrimSpace(setting), "transactions") {
+		return object.NewError(fmt.Sprintf("SQLSETPROP(): setting `%s` is not supported", setting))
+	}
+	mode, errObj := numberArg("SQLSETPROP", args, 2)
+	if errObj != nil {
+		return errObj
+	}
+	switch int(mode) {
+	case object.TransactionsAuto:
+		if c.Tx != nil {
+			if err := c.Tx.Commit(); err != nil {
+				c.Tx = nil
+				return sptError("SQLSETPROP", err, c, env)
+			}
+			c.Tx = nil
+		}
+	case object.TransactionsManual:
+	default:
+		return object.NewError(fmt.Sprintf("SQLSETPROP(): invalid Transactions value %v, expecting 1 or 2", mode))
+	}
+	c.Transactions = int(mode)
+	return &object.Integer{Value: 1}
+}
+
+// SQLGETPROP(nConnectionHandle, cSetting)
+// Admite "Transactions", "ConnectString" y "Database" (la ruta del archivo).
+func builtinSqlGetProp(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("SQLGETPROP", args, 2, 2); err != nil {
+		return err
+	}
+	c, errObj := connectionArg("SQLGETPROP", args, 0, env)
+	if errObj != nil {
+		return errObj
+	}
+	setting, errObj := stringArg("SQLGETPROP", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	switch strings.ToLower(strings.TrimSpace(setting)) {
+	case "transactions":
+		return &object.Integer{Value: float64(c.Transactions)}
+	case "connectstring":
+		return &object.String{Value: c.ConnectString}
+	case "database":
+		return &object.String{Value: c.Path}
+	}
+	return object.NewError(fmt.Sprintf("SQLGETPROP(): setting `%s` is not supported", setting))
+}
+
+// SQLCOMMIT(nConnectionHandle) confirma la transacción manual en curso.
+func builtinSqlCommit(env *object.Environment, args ...object.Object) object.Object {
+	return endSqlTransaction("SQLCOMMIT", env, args, (*sql.Tx).Commit)
+}
+
+// SQLROLLBACK(nConnectionHandle) deshace la transacción manual en curso.
+func builtinSqlRollback(env *object.Environment, args ...object.Object) object.Object {
+	return endSqlTransaction("SQLROLLBACK", env, args, (*sql.Tx).Rollback)
+}
+
+// endSqlTransaction termina la transacción de la conexión; sin transacción
+// en curso no hace nada.
+func endSqlTransaction(fn string, env *object.Environment, args []object.Object, end func(*sql.Tx) error) object.Object {
+	if err := checkArgs(fn, args, 1, 1); err != nil {
+		return err
+	}
+	c, errObj := connectionArg(fn, args, 0, env)
+	if errObj != nil {
+		return errObj
+	}
+	if c.Tx == nil {
+		return &object.Integer{Value: 1}
+	}
+	tx := c.Tx
+	c.Tx = nil
+	if err := end(tx); err != nil {
+		return sptError(fn, err, c, env)
+	}
+	return &object.Integer{Value: 1}
+}
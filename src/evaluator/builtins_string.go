@@ -0,0 +1,159 @@
+package evaluator
+
+import (
+	"FoxLite/src/object"
+	"strconv"
+	"strings"
+)
+
+// Funciones de caracteres que suelen formar las claves de los índices:
+// INDEX ON UPPER(apellido) + STR(edad, 3) TAG nombre
+
+func init() {
+	registerBuiltins(map[string]object.BuiltinFunction{
+		"upper":   unaryString("UPPER", strings.ToUpper),
+		"lower":   unaryString("LOWER", strings.ToLower),
+		"alltrim": unaryString("ALLTRIM", func(s string) string { return strings.Trim(s, " ") }),
+		"ltrim":   unaryString("LTRIM", func(s string) string { return strings.TrimLeft(s, " ") }),
+		"rtrim":   unaryString("RTRIM", func(s string) string { return strings.TrimRight(s, " ") }),
+		"trim":    unaryString("TRIM", func(s string) string { return strings.TrimRight(s, " ") }),
+		"len":     builtinLen,
+		"left":    builtinLeft,
+		"right":   builtinRight,
+		"substr":  builtinSubstr,
+		"str":     builtinStr,
+	})
+}
+
+// unaryString construye una función nativa de un solo argumento string.
+func unaryString(name string, fn func(string) string) object.BuiltinFunction {
+	return func(env *object.Environment, args ...object.Object) object.Object {
+		if err := checkArgs(name, args, 1, 1); err != nil {
+			return err
+		}
+		s, err := stringArg(name, args, 0)
+		if err != nil {
+			return err
+		}
+		return &object.String{Value: fn(s)}
+	}
+}
+
+// LEN(cExpression)
+func builtinLen(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("LEN", args, 1, 1); err != nil {
+		return err
+	}
+	s, err := stringArg("LEN", args, 0)
+	if err != nil {
+		return err
+	}
+	return &object.Integer{Value: float64(len([]rune(s)))}
+}
+
+// LEFT(cExpression, nCharacters)
+func builtinLeft(env *object.Environment, args ...object.Object) object.Object {
+	return substring("LEFT", args, func(runes []rune, n int) (int, int) { return 0, n })
+}
+
+// RIGHT(cExpression, nCharacters)
+func builtinRight(env *object.Environment, args ...object.Object) object.Object {
+	return substring("RIGHT", args, func(runes []rune, n int) (int, int) { return len(runes) - n, len(runes) })
+}
+
+// SUBSTR(cExpression, nStartPosition [, nCharactersReturned])
+// nStartPosition empieza en 1, como en FoxPro.
+func builtinSubstr(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("SUBSTR", args, 2, 3); err != nil {
+		return err
+	}
+	s, err := stringArg("SUBSTR", args, 0)
+	if err != nil {
+		return err
+	}
+	start, err := numberArg("SUBSTR", args, 1)
+	if err != nil {
+		return err
+	}
+	runes := []rune(s)
+	from := clamp(int(start)-1, 0, len(runes))
+	to := len(runes)
+	if len(args) == 3 {
+		count, err := numberArg("SUBSTR", args, 2)
+		if err != nil {
+			return err
+		}
+		to = clamp(from+int(count), from, len(runes))
+	}
+	return &object.String{Value: string(runes[from:to])}
+}
+
+// substring implementa LEFT() y RIGHT(): span devuelve el rango a copiar.
+func substring(name string, args []object.Object, span func(runes []rune, n int) (int, int)) object.Object {
+	if err := checkArgs(name, args, 2, 2); err != nil {
+		return err
+	}
+	s, err := stringArg(name, args, 0)
+	if err != nil {
+		return err
+	}
+	n, err := numberArg(name, args, 1)
+	if err != nil {
+		return err
+	}
+	runes := []rune(s)
+	count := clamp(int(n), 0, len(runes))
+	from, to := span(runes, count)
+	return &object.String{Value: string(runes[from:to])}
+}
+
+func clamp(n int, min int, max int) int {
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
+
+// STR(nExpression [, nLength [, nDecimalPlaces]])
+// Convierte el número en un string alineado a la derecha de nLength
+// caracteres (10 por defecto); si no cabe devuelve asteriscos.
+func builtinStr(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("STR", args, 1, 3); err != nil {
+		return err
+	}
+	x, err := numberArg("STR", args, 0)
+	if err != nil {
+		return err
+	}
+	length, decimals := 10, 0
+	if len(args) > 1 {
+		n, err := numberArg("STR", args, 1)
+		if err != nil {
+			return err
+		}
+		length = int(n)
+	}
+	if len(args) > 2 {
+		n, err := numberArg("STR", args, 2)
+		if err != nil {
+			return err
+		}
+		decimals = clamp(int(n), 0, 18)
+	}
+	if length < 1 {
+		return &object.String{Value: ""}
+	}
+	s := strconv.FormatFloat(x, 'f', decimals, 64)
+	for len(s) > length && decimals > 0 {
+		// se sacrifican decimales antes que la parte entera
+		decimals--
+		s = strconv.FormatFloat(x, 'f', decimals, 64)
+	}
+	if len(s) > length {
+		return &object.String{Value: strings.Repeat("*", length)}
+	}
+	return &object.String{Value: strings.Repeat(" ", length-len(s)) + s}
+}
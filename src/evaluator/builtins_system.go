@@ -0,0 +1,56 @@
+package evaluator
+
+import (
+	"FoxLite/src/object"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	registerBuiltins(map[string]object.BuiltinFunction{
+		"pcount": builtinPCount,
+		"set":    builtinSet,
+	})
+}
+
+// PCOUNT()
+// Devuelve la cantidad de argumentos que recibió la rutina actual.
+func builtinPCount(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("PCOUNT", args, 0, 0); err != nil {
+		return err
+	}
+	return &object.Integer{Value: float64(len(env.Arguments()))}
+}
+
+// SET(cComando)
+// Devuelve el valor de un comando SET: "ON" u "OFF" para los lógicos, un
+// número para los numéricos y un string para el resto. SET("PROCEDURE")
+// devuelve las librerías abiertas separadas por comas.
+func builtinSet(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("SET", args, 1, 1); err != nil {
+		return err
+	}
+	name, err := stringArg("SET", args, 0)
+	if err != nil {
+		return err
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "procedure" {
+		var files []string
+		for _, lib := range env.Libraries() {
+			files = append(files, strings.ToUpper(lib.File))
+		}
+		return &object.String{Value: strings.Join(files, ",")}
+	}
+	value := env.GetOption(name)
+	if value == nil {
+		return object.NewError(fmt.Sprintf("SET(): unknown SET command `%s`", strings.ToUpper(name)))
+	}
+	if b, ok := value.(*object.Boolean); ok {
+		if b.Value {
+			return &object.String{Value: "ON"}
+		}
+		return &object.String{Value: "OFF"}
+	}
+	return value
+}
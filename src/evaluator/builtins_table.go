@@ -0,0 +1,237 @@
+package evaluator
+
+import (
+	"FoxLite/src/object"
+	"strings"
+)
+
+func init() {
+	registerBuiltins(map[string]object.BuiltinFunction{
+		"eof":      builtinEof,
+		"bof":      builtinBof,
+		"recno":    builtinRecno,
+		"reccount": builtinRecCount,
+		"deleted":  builtinDeleted,
+		"fcount":   builtinFCount,
+		"field":    builtinField,
+		"alias":    builtinAlias,
+		"select":   builtinSelect,
+		"used":     builtinUsed,
+		"found":    builtinFound,
+	})
+}
+
+// EOF([nWorkArea | cAlias])
+// Indica si el puntero está después del último registro.
+func builtinEof(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("EOF", args, 0, 1); err != nil {
+		return err
+	}
+	wa, err := areaArg("EOF", env, args, 0)
+	if err != nil {
+		return err
+	}
+	return toBoolean(wa != nil && wa.Eof())
+}
+
+// BOF([nWorkArea | cAlias])
+// Indica si se intentó retroceder antes del primer registro.
+func builtinBof(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("BOF", args, 0, 1); err != nil {
+		return err
+	}
+	wa, err := areaArg("BOF", env, args, 0)
+	if err != nil {
+		return err
+	}
+	return toBoolean(wa != nil && wa.Bof)
+}
+
+// RECNO([nWorkArea | cAlias])
+// Devuelve el número del registro actual (0 si no hay tabla abierta). En
+// una consulta SQL es el registro de la fila en evaluación.
+func builtinRecno(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("RECNO", args, 0, 1); err != nil {
+		return err
+	}
+	if recno, _, ok := rowRecord(env, args, 0); ok {
+		return &object.Integer{Value: float64(recno)}
+	}
+	wa, err := areaArg("RECNO", env, args, 0)
+	if err != nil {
+		return err
+	}
+	if wa == nil {
+		return &object.Integer{Value: 0}
+	}
+	return &object.Integer{Value: float64(wa.Recno)}
+}
+
+// RECCOUNT([nWorkArea | cAlias])
+// Devuelve la cantidad de registros de la tabla, incluidos los borrados.
+func builtinRecCount(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("RECCOUNT", args, 0, 1); err != nil {
+		return err
+	}
+	wa, err := areaArg("RECCOUNT", env, args, 0)
+	if err != nil {
+		return err
+	}
+	if wa == nil {
+		return &object.Integer{Value: 0}
+	}
+	return &object.Integer{Value: float64(wa.Table.RecordCount())}
+}
+
+// DELETED([nWorkArea | cAlias])
+// Indica si el registro actual está marcado como borrado. En una consulta
+// SQL es el registro de la fila en evaluación.
+func builtinDeleted(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("DELETED", args, 0, 1); err != nil {
+		return err
+	}
+	if _, deleted, ok := rowRecord(env, args, 0); ok {
+		return toBoolean(deleted)
+	}
+	wa, err := areaArg("DELETED", env, args, 0)
+	if err != nil {
+		return err
+	}
+	if wa == nil || wa.Eof() {
+		return False
+	}
+	rec, err := currentRecord(wa)
+	if err != nil {
+		return err
+	}
+	return toBoolean(rec.Deleted())
+}
+
+// rowRecord devuelve el registro de la fila SQL en evaluación al que se
+// refiere el argumento idx: sin argumento el de la primera tabla de From y
+// con un alias el de esa tabla. ok es false fuera de SQL o si el argumento
+// es un área de trabajo o un alias que no está en la consulta.
+func rowRecord(env *object.Environment, args []object.Object, idx int) (recno int, deleted bool, ok bool) {
+	qualifier := ""
+	if idx < len(args) {
+		str, isStr := args[idx].(*object.String)
+		if !isStr {
+			return 0, false, false
+		}
+		qualifier = strings.TrimSpace(str.Value)
+	}
+	return env.Record(qualifier)
+}
+
+// FCOUNT([nWorkArea | cAlias])
+// Devuelve la cantidad de campos de la tabla.
+func builtinFCount(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("FCOUNT", args, 0, 1); err != nil {
+		return err
+	}
+	wa, err := areaArg("FCOUNT", env, args, 0)
+	if err != nil {
+		return err
+	}
+	if wa == nil {
+		return &object.Integer{Value: 0}
+	}
+	return &object.Integer{Value: float64(len(wa.Table.Fields))}
+}
+
+// FIELD(nFieldNumber [, nWorkArea | cAlias])
+// Devuelve el nombre del campo número nFieldNumber (desde 1) o "" si no existe.
+func builtinField(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("FIELD", args, 1, 2); err != nil {
+		return err
+	}
+	n, err := numberArg("FIELD", args, 0)
+	if err != nil {
+		return err
+	}
+	wa, err := areaArg("FIELD", env, args, 1)
+	if err != nil {
+		return err
+	}
+	if wa == nil || int(n) < 1 || int(n) > len(wa.Table.Fields) {
+		return &object.String{Value: ""}
+	}
+	return &object.String{Value: wa.Table.Fields[int(n)-1].Name}
+}
+
+// ALIAS([nWorkArea | cAlias])
+// Devuelve el alias de la tabla del área de trabajo ("" si no está en uso).
+func builtinAlias(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("ALIAS", args, 0, 1); err != nil {
+		return err
+	}
+	wa, err := areaArg("ALIAS", env, args, 0)
+	if err != nil {
+		return err
+	}
+	if wa == nil {
+		return &object.String{Value: ""}
+	}
+	return &object.String{Value: wa.Alias}
+}
+
+// SELECT([0 | 1 | cAlias])
+// Sin argumento o con 0 devuelve el área de trabajo actual, con 1 el área
+// libre más alta (0 si no hay ninguna) y con un alias el número de su área
+// (0 si no está en uso).
+func builtinSelect(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("SELECT", args, 0, 1); err != nil {
+		return err
+	}
+	number := env.SelectedArea()
+	if len(args) == 1 {
+		switch arg := args[0].(type) {
+		case *object.Integer:
+			if arg.Value == 1 {
+				number = object.MaxWorkArea
+				for number > 0 && env.WorkArea(number) != nil {
+					number--
+				}
+			}
+		case *object.String:
+			number = 0
+			if wa := env.AreaByAlias(strings.TrimSpace(arg.Value)); wa != nil {
+				number = wa.Number
+			}
+		default:
+			return argTypeError("SELECT", 0, "number or alias", args[0])
+		}
+	}
+	return &object.Integer{Value: float64(number)}
+}
+
+// USED([nWorkArea | cAlias])
+// Indica si hay una tabla abierta en el área de trabajo.
+func builtinUsed(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("USED", args, 0, 1); err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		if alias, ok := args[0].(*object.String); ok {
+			return toBoolean(env.AreaByAlias(strings.TrimSpace(alias.Value)) != nil)
+		}
+	}
+	wa, err := areaArg("USED", env, args, 0)
+	if err != nil {
+		return err
+	}
+	return toBoolean(wa != nil)
+}
+
+// FOUND([nWorkArea | cAlias])
+// Indica si el último LOCATE o CONTINUE encontró un registro.
+func builtinFound(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("FOUND", args, 0, 1); err != nil {
+		return err
+	}
+	wa, err := areaArg("FOUND", env, args, 0)
+	if err != nil {
+		return err
+	}
+	return toBoolean(wa != nil && wa.Found)
+}
@@ -0,0 +1,112 @@
+package evaluator
+
+import (
+	"FoxLite/src/object"
+	"strings"
+)
+
+func init() {
+	registerBuiltins(map[string]object.BuiltinFunction{
+		"vartype": builtinVarType,
+		"type":    builtinType,
+		"empty":   builtinEmpty,
+		"isnull":  builtinIsNull,
+		"nvl":     builtinNvl,
+		"evl":     builtinEvl,
+	})
+}
+
+// VARTYPE(eExpression [, lNullDataType])
+// Devuelve el código de una letra del tipo del valor (ver object.TypeToCode).
+func builtinVarType(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("VARTYPE", args, 1, 2); err != nil {
+		return err
+	}
+	return &object.String{Value: object.TypeToCode(args[0].Type())}
+}
+
+// TYPE(cExpression)
+// Evalúa la expresión contenida en el string y devuelve el código de su
+// tipo, o "U" si no se puede evaluar (variable indefinida, sintaxis
+// inválida, error en tiempo de ejecución...).
+func builtinType(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("TYPE", args, 1, 1); err != nil {
+		return err
+	}
+	src, err := stringArg("TYPE", args, 0)
+	if err != nil {
+		return err
+	}
+	undefined := &object.String{Value: "U"}
+	exp, err := compileExpression(src)
+	if err != nil {
+		return undefined
+	}
+	val := Eval(exp, env)
+	if val == nil || isError(val) {
+		return undefined
+	}
+	return &object.String{Value: object.TypeToCode(val.Type())}
+}
+
+// EMPTY(eExpression)
+func builtinEmpty(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("EMPTY", args, 1, 1); err != nil {
+		return err
+	}
+	return toBoolean(isEmpty(args[0]))
+}
+
+// isEmpty aplica las reglas de EMPTY() según el tipo:
+// strings vacíos o solo con espacios, tabuladores y saltos de línea,
+// el número 0, False, fechas vacías, arrays sin elementos y la ausencia de valor.
+// Null no se considera vacío.
+func isEmpty(obj object.Object) bool {
+	switch obj := obj.(type) {
+	case *object.String:
+		return strings.Trim(obj.Value, " \t\r\n") == ""
+	case *object.Integer:
+		return obj.Value == 0
+	case *object.Boolean:
+		return !obj.Value
+	case *object.Array:
+		return len(obj.Elements) == 0
+	case *object.Date:
+		return obj.Value.IsZero()
+	case *object.None:
+		return true
+	}
+	return false
+}
+
+// ISNULL(eExpression)
+func builtinIsNull(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("ISNULL", args, 1, 1); err != nil {
+		return err
+	}
+	return toBoolean(args[0].Type() == object.NullObj)
+}
+
+// NVL(eExpression1, eExpression2)
+// Devuelve eExpression2 si eExpression1 es Null.
+func builtinNvl(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("NVL", args, 2, 2); err != nil {
+		return err
+	}
+	if args[0].Type() == object.NullObj {
+		return args[1]
+	}
+	return args[0]
+}
+
+// EVL(eExpression1, eExpression2)
+// Devuelve eExpression2 si eExpression1 está vacío o es Null.
+func builtinEvl(env *object.Environment, args ...object.Object) object.Object {
+	if err := checkArgs("EVL", args, 2, 2); err != nil {
+		return err
+	}
+	if isEmpty(args[0]) || args[0].Type() == object.NullObj {
+		return args[1]
+	}
+	return args[0]
+}
@@ -0,0 +1,66 @@
+package evaluator
+
+import (
+	"FoxLite/src/object"
+	"strings"
+)
+
+// Secuencias de ordenación soportadas por SET COLLATE TO
+const (
+	collateMachine = "MACHINE" // orden binario (por defecto)
+	collateGeneral = "GENERAL" // sin distinguir mayúsculas ni acentos
+)
+
+// Tabla de letras acentuadas y su letra base para la secuencia GENERAL.
+// La 'ñ' se mantiene como letra propia.
+var accentFold = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ý", "y", "ÿ", "y",
+)
+
+// currentCollation devuelve la secuencia activa según SET COLLATE.
+func currentCollation(env *object.Environment) string {
+	if opt, ok := env.GetOption("collate").(*object.String); ok {
+		if strings.EqualFold(strings.TrimSpace(opt.Value), collateGeneral) {
+			return collateGeneral
+		}
+	}
+	return collateMachine
+}
+
+// collationKey transforma un string en su clave de ordenación.
+func collationKey(s string, collation string) string {
+	if collation == collateGeneral {
+		return accentFold.Replace(strings.ToLower(s))
+	}
+	return s
+}
+
+// stringsMatch implementa el operador `=` para strings:
+// con SET EXACT OFF basta con que el string de la izquierda comience por
+// el de la derecha, con SET EXACT ON ambos deben coincidir ignorando los
+// espacios finales.
+func stringsMatch(left string, right string, env *object.Environment) bool {
+	collation := currentCollation(env)
+	left = collationKey(left, collation)
+	right = collationKey(right, collation)
+	if isOptionOn(env, "exact") {
+		return strings.TrimRight(left, " ") == strings.TrimRight(right, " ")
+	}
+	return strings.HasPrefix(left, right)
+}
+
+// compareStrings compara dos strings con la secuencia activa y devuelve
+// -1, 0 o 1. Con SET EXACT OFF se consideran iguales cuando el de la
+// izquierda comienza por el de la derecha, igual que el operador `=`.
+func compareStrings(left string, right string, env *object.Environment) int {
+	if stringsMatch(left, right, env) {
+		return 0
+	}
+	collation := currentCollation(env)
+	return strings.Compare(collationKey(left, collation), collationKey(right, collation))
+}
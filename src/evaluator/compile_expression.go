@@ -0,0 +1,36 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/lexer"
+	"FoxLite/src/object"
+	"FoxLite/src/parser"
+	"fmt"
+	"strings"
+)
+
+// compileExpression analiza un string con una única expresión de FoxLite,
+// como los que reciben TYPE("expr") o EVALUATE("expr"). Nunca termina el
+// programa: cualquier error léxico o sintáctico se devuelve como *object.Error.
+func compileExpression(src string) (exp ast.Expression, err *object.Error) {
+	defer func() {
+		if r := recover(); r != nil {
+			exp, err = nil, object.NewError(fmt.Sprintf("invalid expression `%s`", src))
+		}
+	}()
+	l := lexer.New()
+	l.ScanExpression([]rune(src))
+	p := parser.New(l)
+	program := p.Parse()
+	if errors := append(l.Errors(), p.Errors()...); len(errors) > 0 {
+		return nil, object.NewError(fmt.Sprintf("invalid expression `%s`: %s", src, strings.TrimSpace(errors[0])))
+	}
+	if len(program.Statements) != 1 {
+		return nil, object.NewError(fmt.Sprintf("invalid expression `%s`", src))
+	}
+	stmt, ok := program.Statements[0].(*ast.ExpressionStmt)
+	if !ok || stmt.Expression == nil {
+		return nil, object.NewError(fmt.Sprintf("`%s` is not an expression", src))
+	}
+	return stmt.Expression, nil
+}
@@ -0,0 +1,366 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Reglas de las tablas de una base de datos.
+//
+// Al agregar un registro (APPEND BLANK, INSERT) sus campos toman el valor
+// por omisión (DefaultValue) y se comprueban las reglas de todos los
+// campos, la regla del registro y el trigger de inserción: si alguna falla
+// el registro no se agrega. Al modificar campos (REPLACE, UPDATE, GATHER,
+// APPEND MEMO) se comprueban sus reglas y, al terminar el comando, la regla
+// del registro y el trigger de actualización; si alguna falla el registro
+// vuelve a como estaba antes del comando. DELETE comprueba el trigger de
+// borrado. El mensaje de error es el valor de la expresión RuleText de la
+// regla si la tiene.
+
+// dbcExprs guarda las reglas, triggers y valores por omisión ya
+// compilados.
+var dbcExprs = map[string]ast.Expression{}
+
+// dbcExpression compila una expresión guardada en la base de datos. Como
+// en VFP, el '=' de "saldo = 0" compara: entre paréntesis no se analiza
+// como una asignación.
+func dbcExpression(src string) (ast.Expression, *object.Error) {
+	if exp, ok := dbcExprs[src]; ok {
+		return exp, nil
+	}
+	exp, errObj := compileExpression("(" + src + ")")
+	if errObj != nil {
+		return nil, object.NewError(fmt.Sprintf("invalid expression `%s`", src))
+	}
+	dbcExprs[src] = exp
+	return exp, nil
+}
+
+// resolveTable busca el archivo de una tabla: base!tabla (la base se abre
+// si hace falta), una tabla de la base de datos actual o un archivo .dbf. Devuelve también el nombre de la
+// tabla en la base ("" si se busca por el archivo).
+func resolveTable(name string, env *object.Environment) (string, string, *object.Error) {
+	db := env.CurrentDatabase()
+	// en la base las tablas se buscan por su nombre, sin la extensión que
+	// agrega evalFileName
+	tableName := name
+	if strings.EqualFold(filepath.Ext(name), ".dbf") {
+		tableName = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	if bang := strings.IndexByte(tableName, '!'); bang > 0 {
+		// base!tabla abre la base si no lo estaba
+		if db = env.DatabaseByName(tableName[:bang]); db == nil {
+			fileName, errObj := resolveFile(tableName[:bang], ".dbc", env)
+			if errObj != nil {
+				return "", "", object.NewError(fmt.Sprintf("database `%s` is not found", tableName[:bang]))
+			}
+			if db, errObj = openDatabase(fileName, isOptionOn(env, "exclusive"), env); errObj != nil {
+				return "", "", errObj
+			}
+		}
+		table := db.Table(tableName[bang+1:])
+		if table == nil {
+			return "", "", object.NewError(fmt.Sprintf("table `%s` is not found in database `%s`", tableName[bang+1:], db.Name()))
+		}
+		return db.TablePath(table), table.Name, nil
+	}
+	if db != nil {
+		if table := db.Table(tableName); table != nil {
+			return db.TablePath(table), table.Name, nil
+		}
+	}
+	fileName, errObj := resolveFile(name, ".dbf", env)
+	return fileName, "", errObj
+}
+
+// openTable abre una tabla del disco. Si pertenece a una base de datos
+// abre también la base (si no lo estaba) y devuelve el objeto de la tabla
+// en ella; sus campos toman los nombres largos de la base.
+func openTable(fileName string, readOnly bool, env *object.Environment) (*dbf.Table, *dbf.DBObject, *object.Error) {
+	table, err := dbf.Open(fileName, readOnly)
+	if err != nil {
+		return nil, nil, object.NewError(fmt.Sprintf("cannot open table: %v", err))
+	}
+	path := table.DatabasePath()
+	if path == "" {
+		return table, nil, nil
+	}
+	db := env.DatabaseByName(path)
+	if db == nil {
+		if _, err := os.Stat(path); err != nil {
+			table.Close()
+			return nil, nil, object.NewError(fmt.Sprintf("cannot open table: database `%s` is not found", path))
+		}
+		var errObj *object.Error
+		if db, errObj = openDatabase(path, isOptionOn(env, "exclusive"), env); errObj != nil {
+			table.Close()
+			return nil, nil, errObj
+		}
+	}
+	dbTable, err := db.Attach(table)
+	if err != nil {
+		table.Close()
+		return nil, nil, object.NewError(fmt.Sprintf("cannot open table: %v", err))
+	}
+	return table, dbTable, nil
+}
+
+// openDatabase abre una base de datos y la registra, sin cambiar la base
+// de datos actual.
+func openDatabase(fileName string, exclusive bool, env *object.Environment) (*dbf.Database, *object.Error) {
+	db, err := dbf.OpenDatabase(fileName, false)
+	if err != nil {
+		return nil, object.NewError(fmt.Sprintf("cannot open database: %v", err))
+	}
+	if err := db.Share(exclusive); err != nil {
+		db.Close()
+		return nil, object.NewError(fmt.Sprintf("cannot open database: %v", err))
+	}
+	env.OpenDatabase(db, false)
+	return db, nil
+}
+
+// checkRule evalúa una regla o un trigger en el registro actual de wa.
+func checkRule(wa *object.WorkArea, src string, clause string, env *object.Environment) (bool, *object.Error) {
+	exp, errObj := dbcExpression(src)
+	if errObj != nil {
+		return false, errObj
+	}
+	return evalCondition(exp, wa, env, clause)
+}
+
+// ruleError devuelve el error de una regla que no se cumple. text es su
+// RuleText, una expresión que se evalúa en el registro actual de wa como
+// en VFP: "Saldo negativo" (con las comillas) o "Saldo de " + nombre.
+func ruleError(wa *object.WorkArea, text string, message string, env *object.Environment) *object.Error {
+	if text == "" {
+		return object.NewError(fmt.Sprintf("%s: %s", wa.Alias, message))
+	}
+	exp, errObj := dbcExpression(text)
+	if errObj != nil {
+		return errObj
+	}
+	val := evalInArea(exp, wa, env)
+	if errObj, ok := val.(*object.Error); ok {
+		return errObj
+	}
+	if str, ok := val.(*object.String); ok {
+		return object.NewError(str.Value)
+	}
+	return object.NewError(env.Format().Inspect(val))
+}
+
+// checkField comprueba la regla del campo idx en el registro actual.
+func checkField(wa *object.WorkArea, idx int, env *object.Environment) *object.Error {
+	if wa.DBTable == nil {
+		return nil
+	}
+	name := wa.Table.Fields[idx].Name
+	field := wa.DBTable.Field(name)
+	if field == nil {
+		return nil
+	}
+	rule := field.Text("RuleExpression")
+	if rule == "" {
+		return nil
+	}
+	ok, errObj := checkRule(wa, rule, "field rule", env)
+	if errObj != nil {
+		return errObj
+	}
+	if !ok {
+		return ruleError(wa, field.Text("RuleText"), fmt.Sprintf("field `%s` validation rule is violated", name), env)
+	}
+	return nil
+}
+
+// triggers son las propiedades de los triggers de cada operación.
+var triggers = map[string]string{
+	"insert": "InsertTrigger",
+	"update": "UpdateTrigger",
+	"delete": "DeleteTrigger",
+}
+
+// checkTrigger evalúa el trigger de la operación (insert, update o delete)
+// en el registro actual.
+func checkTrigger(wa *object.WorkArea, operation string, env *object.Environment) *object.Error {
+	trigger := wa.DBTable.Text(triggers[operation])
+	if trigger == "" {
+		return nil
+	}
+	ok, errObj := checkRule(wa, trigger, operation+" trigger", env)
+	if errObj != nil {
+		return errObj
+	}
+	if !ok {
+		return object.NewError(fmt.Sprintf("%s: %s trigger failed", wa.Alias, operation))
+	}
+	return nil
+}
+
+// checkRecord comprueba la regla del registro actual y el trigger de la
+// operación.
+func checkRecord(wa *object.WorkArea, operation string, env *object.Environment) *object.Error {
+	if wa.DBTable == nil {
+		return nil
+	}
+	if rule := wa.DBTable.Text("RuleExpression"); rule != "" {
+		ok, errObj := checkRule(wa, rule, "record rule", env)
+		if errObj != nil {
+			return errObj
+		}
+		if !ok {
+			return ruleError(wa, wa.DBTable.Text("RuleText"), "record validation rule is violated", env)
+		}
+	}
+	return checkTrigger(wa, operation, env)
+}
+
+// applyDefaults asigna a los campos del registro nuevo rec sus valores por
+// omisión, salvo a los que tienen un valor en given.
+func applyDefaults(wa *object.WorkArea, rec *dbf.Record, given map[int]object.Object, env *object.Environment) *object.Error {
+	if wa.DBTable == nil {
+		return nil
+	}
+	for idx, field := range wa.Table.Fields {
+		if _, ok := given[idx]; ok {
+			continue
+		}
+		dbField := wa.DBTable.Field(field.Name)
+		if dbField == nil || dbField.Text("DefaultValue") == "" {
+			continue
+		}
+		exp, errObj := dbcExpression(dbField.Text("DefaultValue"))
+		if errObj != nil {
+			return errObj
+		}
+		val := evalInArea(exp, wa, env)
+		if errObj, ok := val.(*object.Error); ok {
+			return errObj
+		}
+		value, errObj := toFieldValue(field, val)
+		if errObj != nil {
+			return errObj
+		}
+		if err := rec.SetValue(idx, value); err != nil {
+			return tableError(wa, err)
+		}
+	}
+	return nil
+}
+
+// checkInsert comprueba las reglas del registro recién agregado, que es el
+// actual.
+func checkInsert(wa *object.WorkArea, env *object.Environment) *object.Error {
+	if wa.DBTable == nil {
+		return nil
+	}
+	for idx := range wa.Table.Fields {
+		if errObj := checkField(wa, idx, env); errObj != nil {
+			return errObj
+		}
+	}
+	return checkRecord(wa, "insert", env)
+}
+
+// discardAppend descarta el registro recno recién agregado, que no se pudo
+// completar, y vuelve el puntero al registro prev.
+func discardAppend(wa *object.WorkArea, recno int, prev int) *object.Error {
+	for _, tag := range wa.Table.Tags() {
+		tag.Remove(recno)
+	}
+	if err := wa.Table.Unappend(recno); err != nil {
+		return tableError(wa, err)
+	}
+	if prev > wa.Table.RecordCount()+1 {
+		prev = wa.Table.RecordCount() + 1
+	}
+	wa.Recno, wa.Bof = prev, false
+	return nil
+}
+
+// recordSnapshot guarda los valores de un registro antes de modificarlo,
+// incluidos los memos, para poder volver a ellos.
+type recordSnapshot struct {
+	recno   int
+	deleted bool
+	values  []interface{}
+}
+
+// beginUpdate guarda el registro actual de una tabla de una base de datos
+// antes de que un comando lo modifique; en las tablas libres y en el fin
+// de archivo devuelve nil.
+func beginUpdate(wa *object.WorkArea) (*recordSnapshot, *object.Error) {
+	if wa.DBTable == nil || wa.Eof() {
+		return nil, nil
+	}
+	rec, errObj := currentRecord(wa)
+	if errObj != nil {
+		return nil, errObj
+	}
+	snap := &recordSnapshot{recno: rec.Recno, deleted: rec.Deleted()}
+	for idx := range wa.Table.Fields {
+		value, err := rec.Value(idx)
+		if err != nil {
+			return nil, tableError(wa, err)
+		}
+		snap.values = append(snap.values, value)
+	}
+	return snap, nil
+}
+
+// endUpdate termina la modificación del registro que guardó beginUpdate:
+// si el comando falló (errObj) o el registro no cumple su regla o el
+// trigger de actualización, el registro vuelve a como estaba.
+func endUpdate(wa *object.WorkArea, snap *recordSnapshot, errObj *object.Error, env *object.Environment) *object.Error {
+	if snap == nil {
+		return errObj
+	}
+	if errObj == nil {
+		errObj = checkUpdate(wa, snap, env)
+	}
+	if errObj != nil {
+		restoreSnapshot(wa, snap, env)
+	}
+	return errObj
+}
+
+// checkUpdate comprueba la regla y el trigger de actualización del
+// registro que guardó beginUpdate.
+func checkUpdate(wa *object.WorkArea, snap *recordSnapshot, env *object.Environment) *object.Error {
+	if snap == nil {
+		return nil
+	}
+	recno := wa.Recno
+	defer func() { wa.Recno = recno }()
+	wa.Recno = snap.recno
+	return checkRecord(wa, "update", env)
+}
+
+// restoreSnapshot vuelve el registro a los valores que guardó beginUpdate.
+func restoreSnapshot(wa *object.WorkArea, snap *recordSnapshot, env *object.Environment) {
+	if snap == nil {
+		return
+	}
+	recno := wa.Recno
+	defer func() { wa.Recno = recno }()
+	wa.Recno = snap.recno
+	rec, errObj := currentRecord(wa)
+	if errObj != nil {
+		return
+	}
+	for idx, value := range snap.values {
+		if err := rec.SetValue(idx, value); err != nil {
+			return
+		}
+	}
+	rec.SetDeleted(snap.deleted)
+	if err := wa.Table.WriteRecord(rec); err == nil {
+		updateIndexes(wa, env)
+	}
+}
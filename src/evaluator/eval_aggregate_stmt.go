@@ -0,0 +1,402 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Comandos que resumen los registros del área actual recorriéndolos en el
+// orden del índice activo y respetando SET FILTER y SET DELETED. Sin
+// alcance recorren toda la tabla (o desde el registro actual si tienen
+// While). _TALLY guarda la cantidad de registros procesados.
+
+// evalAggregateStmt => Count | Sum | Average | Calculate
+func evalAggregateStmt(node *ast.AggregateStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	command := strings.ToUpper(node.Command)
+	exprs := node.Exprs
+	if len(exprs) == 0 && node.Command != "count" {
+		// Sum y Average sin expresiones: todos los campos numéricos
+		for _, field := range wa.Table.Fields {
+			if isNumericField(field) {
+				exprs = append(exprs, fieldExp(wa.Alias, field.Name))
+			}
+		}
+	}
+
+	var aggregates []*ast.SqlAggregateExp
+	if node.Command == "calculate" {
+		for _, exp := range exprs {
+			collectAggregates(exp, &aggregates)
+		}
+	} else {
+		// Sum y Average suman cada expresión como un agregado más
+		for _, exp := range exprs {
+			aggregates = append(aggregates, &ast.SqlAggregateExp{Func: "sum", Arg: exp})
+		}
+	}
+	accumulators := make([]*accumulator, len(aggregates))
+	for i := range accumulators {
+		accumulators[i] = &accumulator{}
+	}
+	count := 0
+	errObj = eachInScope(wa, node.Scope, node.For, node.While, env, func() *object.Error {
+		count++
+		for i, agg := range aggregates {
+			if agg.Arg == nil { // Cnt()
+				continue
+			}
+			val := evalInArea(agg.Arg, wa, env)
+			if errObj := accumulators[i].add(command, agg.Func, val, env); errObj != nil {
+				return errObj
+			}
+		}
+		return nil
+	})
+	if errObj != nil {
+		return errObj
+	}
+	setTally(env, count)
+
+	var values []object.Object
+	switch node.Command {
+	case "count":
+		values = []object.Object{&object.Integer{Value: float64(count)}}
+	case "sum":
+		for _, acc := range accumulators {
+			values = append(values, &object.Integer{Value: acc.sum})
+		}
+	case "average":
+		for _, acc := range accumulators {
+			values = append(values, acc.result("avg", count))
+		}
+	case "calculate":
+		results := make(map[*ast.SqlAggregateExp]object.Object, len(aggregates))
+		for i, agg := range aggregates {
+			results[agg] = accumulators[i].result(agg.Func, count)
+		}
+		rowEnv := object.NewRowEnv(env, &sqlContext{aggregates: results})
+		for _, exp := range exprs {
+			val := Eval(exp, rowEnv)
+			if isError(val) {
+				return val
+			}
+			values = append(values, val)
+		}
+	}
+	return commandResult(storeResults(command, node.To, node.ToArray, values, env))
+}
+
+// accumulator acumula los valores de una expresión; los null no se
+// tienen en cuenta.
+type accumulator struct {
+	count int
+	sum   float64
+	sumSq float64
+	best  object.Object
+}
+
+func (a *accumulator) add(command string, fn string, val object.Object, env *object.Environment) *object.Error {
+	switch val := val.(type) {
+	case *object.Error:
+		return val
+	case *object.Null:
+		return nil
+	}
+	switch fn {
+	case "min", "max":
+		if a.best == nil {
+			a.best = val
+			return nil
+		}
+		cmp, errObj := compareValues(val, a.best, env)
+		if errObj != nil {
+			return object.NewError(fmt.Sprintf("%s: %s", command, errObj.Message))
+		}
+		if (fn == "min" && cmp < 0) || (fn == "max" && cmp > 0) {
+			a.best = val
+		}
+		return nil
+	}
+	num, ok := val.(*object.Integer)
+	if !ok {
+		return object.NewError(fmt.Sprintf("%s: expecting a numeric expression, got `%s`", command, object.TypeToStr(val.Type())))
+	}
+	a.count++
+	a.sum += num.Value
+	a.sumSq += num.Value * num.Value
+	return nil
+}
+
+// result devuelve el valor del agregado; records es la cantidad de
+// registros procesados (Cnt()).
+func (a *accumulator) result(fn string, records int) object.Object {
+	switch fn {
+	case "cnt":
+		return &object.Integer{Value: float64(records)}
+	case "sum":
+		return &object.Integer{Value: a.sum}
+	case "min", "max":
+		if a.best == nil {
+			return Null
+		}
+		return a.best
+	}
+	if a.count == 0 {
+		return &object.Integer{Value: 0}
+	}
+	n := float64(a.count)
+	mean := a.sum / n
+	switch fn {
+	case "var":
+		return &object.Integer{Value: math.Max(a.sumSq/n-mean*mean, 0)}
+	case "std":
+		return &object.Integer{Value: math.Sqrt(math.Max(a.sumSq/n-mean*mean, 0))}
+	}
+	return &object.Integer{Value: mean}
+}
+
+// storeResults guarda los resultados en las variables de To o en el array
+// de To Array; sin To los muestra por pantalla.
+func storeResults(command string, to []string, toArray string, values []object.Object, env *object.Environment) *object.Error {
+	switch {
+	case toArray != "":
+		env.Set(toArray, &object.Array{Elements: values})
+	case len(to) > 0:
+		if len(to) != len(values) {
+			return object.NewError(fmt.Sprintf("%s: expecting %d variables, got %d", command, len(values), len(to)))
+		}
+		for i, name := range to {
+			env.Set(name, values[i])
+		}
+	default:
+		format := env.Format()
+		texts := make([]string, len(values))
+		for i, val := range values {
+			texts[i] = format.Inspect(val)
+		}
+		fmt.Println(strings.Join(texts, " "))
+	}
+	return nil
+}
+
+// eachInScope ejecuta fn en cada registro visible del alcance que cumple
+// las condiciones For y While, con el puntero en el registro. All (el
+// alcance por omisión) y Rest terminan en el fin de archivo; Next n queda
+// en el último registro del alcance y Record n en ese registro.
+func eachInScope(wa *object.WorkArea, scope *ast.Scope, forCond ast.Expression, whileCond ast.Expression, env *object.Environment, fn func() *object.Error) *object.Error {
+	kind := "all"
+	if whileCond != nil {
+		kind = "rest"
+	}
+	if scope != nil {
+		kind = scope.Kind
+	}
+	limit := -1
+	switch kind {
+	case "all":
+		if errObj := goTop(wa, env); errObj != nil {
+			return errObj
+		}
+	case "next", "record":
+		n, errObj := scopeCount(scope, env)
+		if errObj != nil {
+			return errObj
+		}
+		if kind == "next" {
+			if limit = n; limit <= 0 {
+				return nil
+			}
+			break
+		}
+		if errObj := goRecord(wa, env, n); errObj != nil {
+			return errObj
+		}
+		if wa.Eof() {
+			return nil
+		}
+		state, errObj := scopeConditions(forCond, whileCond, wa, env)
+		if errObj != nil || state != matchScope {
+			return errObj
+		}
+		return fn()
+	}
+	for !wa.Eof() {
+		state, errObj := scopeConditions(forCond, whileCond, wa, env)
+		if errObj != nil {
+			return errObj
+		}
+		if state == stopScope {
+			break
+		}
+		if state == matchScope {
+			if errObj := fn(); errObj != nil {
+				return errObj
+			}
+		}
+		if limit > 0 {
+			if limit--; limit == 0 {
+				break
+			}
+		}
+		if errObj := skipRecords(wa, env, 1); errObj != nil {
+			return errObj
+		}
+	}
+	return nil
+}
+
+// scopeCount evalúa el número de Next n o Record n.
+func scopeCount(scope *ast.Scope, env *object.Environment) (int, *object.Error) {
+	val := Eval(scope.Count, env)
+	if errObj, ok := val.(*object.Error); ok {
+		return 0, errObj
+	}
+	num, ok := val.(*object.Integer)
+	if !ok {
+		return 0, object.NewError(fmt.Sprintf("%s: expecting a number, got `%s`", strings.ToUpper(scope.Kind), object.TypeToStr(val.Type())))
+	}
+	return int(num.Value), nil
+}
+
+func isNumericField(field *dbf.Field) bool {
+	switch field.Type {
+	case dbf.Numeric, dbf.Float, dbf.Integer, dbf.Currency, dbf.Double:
+		return true
+	}
+	return false
+}
+
+// evalTotalStmt => Total On clave To tabla [Fields campos] [alcance] ...
+// Crea una tabla con la estructura del área actual (sin los campos memo) y
+// un registro por cada grupo de registros consecutivos con la misma clave,
+// con los campos numéricos (o los de Fields) sumados y el resto de los
+// campos del primer registro del grupo. La tabla debe estar ordenada por la
+// clave.
+func evalTotalStmt(node *ast.TotalStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	var sources []int
+	var fields []*dbf.Field
+	for i, field := range wa.Table.Fields {
+		if field.Type != dbf.Memo {
+			sources = append(sources, i)
+			fields = append(fields, field)
+		}
+	}
+	summed := make([]bool, len(fields))
+	if len(node.Fields) == 0 {
+		for i, field := range fields {
+			summed[i] = isNumericField(field)
+		}
+	}
+	for _, name := range node.Fields {
+		idx := -1
+		for i, field := range fields {
+			if strings.EqualFold(field.Name, name) {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			return object.NewError(fmt.Sprintf("TOTAL: field `%s` is not found", name))
+		}
+		if !isNumericField(fields[idx]) {
+			return object.NewError(fmt.Sprintf("TOTAL: field `%s` is not numeric", name))
+		}
+		summed[idx] = true
+	}
+	table, _, errObj := createTable("TOTAL", node.File, tableStructure(fields), env)
+	if errObj != nil {
+		return errObj
+	}
+	defer table.Close()
+
+	var key object.Object
+	var group []object.Object
+	count := 0
+	flush := func() *object.Error {
+		if group == nil {
+			return nil
+		}
+		count++
+		rec, err := table.AppendBlank()
+		if err != nil {
+			return object.NewError(fmt.Sprintf("TOTAL: %v", err))
+		}
+		for i, val := range group {
+			value, errObj := toFieldValue(table.Fields[i], val)
+			if errObj != nil {
+				return errObj
+			}
+			if err := rec.SetValue(i, value); err != nil {
+				return object.NewError(fmt.Sprintf("TOTAL: %v", err))
+			}
+		}
+		if err := table.WriteRecord(rec); err != nil {
+			return object.NewError(fmt.Sprintf("TOTAL: %v", err))
+		}
+		return nil
+	}
+	errObj = eachInScope(wa, node.Scope, node.For, node.While, env, func() *object.Error {
+		val := evalInArea(node.Key, wa, env)
+		if errObj, ok := val.(*object.Error); ok {
+			return errObj
+		}
+		values := make([]object.Object, len(sources))
+		for i, src := range sources {
+			values[i] = fieldValue(wa, src)
+			if errObj, ok := values[i].(*object.Error); ok {
+				return errObj
+			}
+		}
+		if group != nil && sameKey(key, val, env) {
+			for i, ok := range summed {
+				if ok {
+					group[i] = addNumbers(group[i], values[i])
+				}
+			}
+			return nil
+		}
+		if errObj := flush(); errObj != nil {
+			return errObj
+		}
+		key, group = val, values
+		return nil
+	})
+	if errObj == nil {
+		errObj = flush()
+	}
+	setTally(env, count)
+	return commandResult(errObj)
+}
+
+// sameKey compara las claves de Total; dos null son la misma clave.
+func sameKey(left object.Object, right object.Object, env *object.Environment) bool {
+	if left.Type() == object.NullObj || right.Type() == object.NullObj {
+		return left.Type() == right.Type()
+	}
+	cmp, errObj := compareValues(left, right, env)
+	return errObj == nil && cmp == 0
+}
+
+// addNumbers suma dos valores numéricos sin tener en cuenta los null.
+func addNumbers(left object.Object, right object.Object) object.Object {
+	l, okLeft := left.(*object.Integer)
+	r, okRight := right.(*object.Integer)
+	switch {
+	case okLeft && okRight:
+		return &object.Integer{Value: l.Value + r.Value}
+	case okRight:
+		return r
+	}
+	return left
+}
@@ -4,8 +4,10 @@ import (
 	"FoxLite/src/ast"
 	"FoxLite/src/object"
 	"FoxLite/src/token"
+	"fmt"
 	"math"
 	"strings"
+	"time"
 )
 
 func evalArithmeticExp(node *ast.InfixExp, env *object.Environment) object.Object {
@@ -25,6 +27,12 @@ func evalArithmeticExp(node *ast.InfixExp, env *object.Environment) object.Objec
 	if lType == object.StringObj && rType == object.StringObj {
 		return evalBinaryString(left.(*object.String), right.(*object.String), node.Op)
 	}
+	if date, ok := left.(*object.Date); ok {
+		return evalBinaryDate(date, right, node.Op)
+	}
+	if date, ok := right.(*object.Date); ok && lType == object.IntegerObj && node.Op == token.Plus {
+		return evalBinaryDate(date, left, node.Op)
+	}
 
 	// Reportar el error correspondiente
 	return reportInfixError(lType, rType)
@@ -44,7 +52,7 @@ func evalBinaryInteger(left *object.Integer, right *object.Integer, op token.Tok
 		}
 		return &object.Integer{Value: left.Value / right.Value}
 	case token.Mod:
-		return &object.Integer{Value: math.Mod(left.Value, right.Value)}
+		return foxMod(left.Value, right.Value)
 	case token.Pow:
 		return &object.Integer{Value: math.Pow(left.Value, right.Value)}
 	}
@@ -60,3 +68,29 @@ func evalBinaryString(left *object.String, right *object.String, op token.TokenT
 	}
 	return reportUnexpectedError(op)
 }
+
+// evalBinaryDate suma o resta días a una fecha (segundos a una fecha y
+// hora) y calcula la diferencia entre dos fechas.
+func evalBinaryDate(left *object.Date, right object.Object, op token.TokenType) object.Object {
+	unit := 24 * time.Hour
+	if left.DateTime {
+		unit = time.Second
+	}
+	switch right := right.(type) {
+	case *object.Integer:
+		if left.Value.IsZero() {
+			return left
+		}
+		switch op {
+		case token.Plus:
+			return &object.Date{Value: left.Value.Add(time.Duration(right.Value * float64(unit))), DateTime: left.DateTime}
+		case token.Minus:
+			return &object.Date{Value: left.Value.Add(-time.Duration(right.Value * float64(unit))), DateTime: left.DateTime}
+		}
+	case *object.Date:
+		if op == token.Minus && right.DateTime == left.DateTime {
+			return &object.Integer{Value: math.Round(float64(left.Value.Sub(right.Value)) / float64(unit))}
+		}
+	}
+	return object.NewError(fmt.Sprintf("operator `%s` is not defined for `%s` and `%s`", token.GetTokenStr(op), object.TypeToStr(left.Type()), object.TypeToStr(right.Type())))
+}
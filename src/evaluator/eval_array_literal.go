@@ -0,0 +1,17 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/object"
+)
+
+func evalArrayLiteral(node *ast.ArrayLiteral, env *object.Environment) object.Object {
+	elements := evalExpressions(node.Elements, env)
+	if len(elements) == 1 && isError(elements[0]) {
+		return elements[0]
+	}
+	if elements == nil {
+		elements = []object.Object{}
+	}
+	return &object.Array{Elements: elements}
+}
@@ -0,0 +1,47 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/object"
+	"fmt"
+)
+
+func evalAssignStmt(node *ast.AssignStmt, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+	switch target := node.Target.(type) {
+	case *ast.IndexExp:
+		return assignIndex(target, val, env)
+	case *ast.InfixExp:
+		return assignDot(target, val, env)
+	}
+	return object.NewError(fmt.Sprintf("cannot assign to `%s`", node.Target.String()))
+}
+
+// assignIndex => laFrutas[0] = "Manzana"
+func assignIndex(target *ast.IndexExp, val object.Object, env *object.Environment) object.Object {
+	left := Eval(target.Left, env)
+	if isError(left) {
+		return left
+	}
+	index := Eval(target.Index, env)
+	if isError(index) {
+		return index
+	}
+	array, ok := left.(*object.Array)
+	if !ok {
+		return object.NewError(fmt.Sprintf("index assignment not supported: `%s`", object.TypeToStr(left.Type())))
+	}
+	idx, ok := index.(*object.Integer)
+	if !ok {
+		return object.NewError(fmt.Sprintf("array index must be a number, got `%s`", object.TypeToStr(index.Type())))
+	}
+	pos := int(idx.Value)
+	if pos < 0 || pos >= len(array.Elements) {
+		return object.NewError(fmt.Sprintf("array index out of range: %d (length %d)", pos, len(array.Elements)))
+	}
+	array.Elements[pos] = val
+	return val
+}
@@ -3,7 +3,9 @@ package evaluator
 import (
 	"FoxLite/src/ast"
 	"FoxLite/src/object"
+	"FoxLite/src/token"
 	"fmt"
+	"strings"
 )
 
 func evalCallExpression(node *ast.CallExp, env *object.Environment) object.Object {
@@ -12,12 +14,99 @@ func evalCallExpression(node *ast.CallExp, env *object.Environment) object.Objec
 		return function
 	}
 	// Evaluamos los argumentos (si es que existen)
-	args := evalExpressions(node.Args, env)
+	args := evalArguments(function, node.Args, env)
 	if len(args) == 1 && isError(args[0]) {
 		return args[0]
 	}
 
-	return applyFunction(function, args)
+	return applyFunction(function, args, env)
+}
+
+// evalArguments evalúa los argumentos de una llamada. Los precedidos por '@'
+// (o todas las variables si SET UDFPARMS TO REFERENCE) se pasan por
+// referencia a las funciones del usuario; el resto se pasan por valor, por
+// lo que los arrays se copian para que la función no altere el original.
+// Las funciones nativas de arrayTargets reciben el nombre del array.
+func evalArguments(fn object.Object, exps []ast.Expression, env *object.Environment) []object.Object {
+	_, userFn := fn.(*object.Function)
+	byRef := userFn && isUdfParmsByReference(env)
+	target := -1
+	if builtin, ok := fn.(*object.Builtin); ok {
+		if idx, ok := arrayTargets[builtin.Name]; ok {
+			target = idx
+		}
+	}
+	var result []object.Object
+	for idx, exp := range exps {
+		var res object.Object
+		ref, isRef := exp.(*ast.ReferenceExp)
+		if lit, ok := exp.(*ast.Literal); ok && byRef && lit.Token.Type == token.Ident && env.GetVector(lit.Value.(string)) != nil {
+			ref, isRef = &ast.ReferenceExp{Token: lit.Token, Name: lit}, true
+		}
+		if idx == target {
+			if name := arrayName(exp); name != "" {
+				result = append(result, &object.String{Value: name})
+				continue
+			}
+		}
+		switch {
+		case isRef && userFn:
+			res = evalReference(ref, env)
+		case isRef: // las funciones nativas reciben el valor
+			res = Eval(ref.Name, env)
+		default:
+			res = Eval(exp, env)
+			if array, ok := res.(*object.Array); ok && userFn {
+				res = copyArray(array)
+			}
+		}
+		if isError(res) {
+			return []object.Object{res}
+		}
+		result = append(result, res)
+	}
+	return result
+}
+
+// arrayTargets son las funciones nativas que crean un array en la variable
+// que se les indica, como AERROR(laError), con la posición del argumento.
+var arrayTargets = map[string]int{
+	"AERROR": 0,
+}
+
+// arrayName devuelve el nombre de la variable de un argumento, con o sin
+// '@', o "" si el argumento es una expresión.
+func arrayName(exp ast.Expression) string {
+	if ref, ok := exp.(*ast.ReferenceExp); ok {
+		exp = ref.Name
+	}
+	if lit, ok := exp.(*ast.Literal); ok && lit.Token.Type == token.Ident {
+		return lit.Value.(string)
+	}
+	return ""
+}
+
+func evalReference(node *ast.ReferenceExp, env *object.Environment) object.Object {
+	name := node.Name.Value.(string)
+	vec := env.GetVector(name)
+	if vec == nil {
+		lincol := fmt.Sprintf("%d:%d", node.Token.Line, node.Token.Col)
+		return object.NewError(fmt.Sprintf("[%s] undefined ident: `%s`", lincol, name))
+	}
+	return &object.Reference{Name: name, Vector: vec}
+}
+
+func isUdfParmsByReference(env *object.Environment) bool {
+	if opt, ok := env.GetOption("udfparms").(*object.String); ok {
+		return strings.EqualFold(opt.Value, "reference")
+	}
+	return false
+}
+
+func copyArray(array *object.Array) *object.Array {
+	elements := make([]object.Object, len(array.Elements))
+	copy(elements, array.Elements)
+	return &object.Array{Elements: elements}
 }
 
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
@@ -32,28 +121,81 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	return result
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+func applyFunction(fn object.Object, args []object.Object, env *object.Environment) object.Object {
 	switch fn := fn.(type) {
+	case *object.Builtin:
+		return fn.Fn(env, args...)
 	case *object.Function:
-		extendedEnv := extendFunctionEnv(fn, args)
+		extendedEnv, err := extendFunctionEnv(fn, args, env)
+		if err != nil {
+			return err
+		}
 		result := Eval(fn.Body, extendedEnv)
 		if ret, ok := result.(*object.Return); ok {
 			return ret.Value
 		}
-		return result
+		return tagError(result, fn.File)
 	default:
 		return object.NewError(fmt.Sprintf("unknown function"))
 	}
-	return None
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
-	// primero creamos un nuevo environment
-	env := object.NewEnclosedEnv(fn.Env)
+func extendFunctionEnv(fn *object.Function, args []object.Object, caller *object.Environment) (*object.Environment, object.Object) {
+	// primero creamos un nuevo environment enlazado con la rutina que llama
+	env := object.NewRoutineEnv(fn.Env, caller)
+	if fn.Closure {
+		env = object.NewClosureEnv(fn.Env, caller)
+	}
+	env.SetArguments(args)
+
+	// Si la función declara sus parámetros con LParameters/Parameters
+	// será esa sentencia la que valide la cantidad de argumentos.
+	if len(args) > len(fn.Parameters) && !declaresParameters(fn.Body) {
+		return nil, object.NewError(fmt.Sprintf("%s(): too many arguments, got %d, want %d", functionName(fn), len(args), len(fn.Parameters)))
+	}
 
 	for idx, param := range fn.Parameters {
-		env.Set(param.Value.(string), 'l', args[idx])
+		// Los parámetros que no se pasan valen False, salvo que tengan un
+		// valor por defecto, que se evalúa en el environment de la llamada.
+		var val object.Object = False
+		if idx < len(args) {
+			val = args[idx]
+		} else if param.Default != nil {
+			val = Eval(param.Default, env)
+			if isError(val) {
+				return nil, val
+			}
+		}
+		bindParameter(env, param.Name.Value.(string), 'l', val)
 	}
 
-	return env
+	return env, nil
+}
+
+// bindParameter crea el parámetro en el environment de la rutina; si el
+// argumento se pasó por referencia comparte el vector de la variable original.
+func bindParameter(env *object.Environment, name string, scope byte, val object.Object) {
+	if ref, ok := val.(*object.Reference); ok {
+		env.Bind(name, ref.Vector)
+		return
+	}
+	env.Declare(name, scope, val)
+}
+
+// declaresParameters indica si el cuerpo de la función contiene una
+// sentencia LParameters o Parameters.
+func declaresParameters(body *ast.BlockStmt) bool {
+	for _, stmt := range body.Statements {
+		if _, ok := stmt.(*ast.ParametersStmt); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func functionName(fn *object.Function) string {
+	if fn.Name == "" {
+		return "func"
+	}
+	return fn.Name
 }
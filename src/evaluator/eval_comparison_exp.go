@@ -5,6 +5,7 @@ import (
 	"FoxLite/src/object"
 	"FoxLite/src/token"
 	"fmt"
+	"strings"
 )
 
 func evalComparisonExp(node *ast.InfixExp, env *object.Environment) object.Object {
@@ -26,11 +27,27 @@ func evalComparisonExp(node *ast.InfixExp, env *object.Environment) object.Objec
 		return evalBooleanComparison(left.(*object.Boolean), right.(*object.Boolean), node.Op)
 	}
 	if lType == object.StringObj && rType == object.StringObj {
-		return evalStringComparison(left.(*object.String), right.(*object.String), node.Op)
+		return evalStringComparison(left.(*object.String), right.(*object.String), node.Op, env)
+	}
+	if ld, ok := left.(*object.Date); ok {
+		if rd, ok := right.(*object.Date); ok {
+			return evalDateComparison(ld, rd, node.Op)
+		}
+	}
+	if node.Op == token.Contains {
+		return object.NewError(fmt.Sprintf("`$` operator expects two strings, got `%s` and `%s`", object.TypeToStr(lType), object.TypeToStr(rType)))
+	}
+	if isEqualityOp(node.Op) && (lType != rType || lType == object.NullObj) {
+		// Valores de tipos distintos nunca son iguales
+		return toBoolean((lType == rType) != (node.Op == token.NotEq))
 	}
 	return reportInfixError(lType, rType)
 }
 
+func isEqualityOp(op token.TokenType) bool {
+	return op == token.Assign || op == token.Equal || op == token.NotEq
+}
+
 func evalIntegerComparison(left *object.Integer, right *object.Integer, op token.TokenType) object.Object {
 	switch op {
 	case token.Less:
@@ -53,7 +70,7 @@ func evalIntegerComparison(left *object.Integer, right *object.Integer, op token
 			return True
 		}
 		return False
-	case token.Equal:
+	case token.Equal, token.Assign:
 		if left.Value == right.Value {
 			return True
 		}
@@ -69,7 +86,7 @@ func evalIntegerComparison(left *object.Integer, right *object.Integer, op token
 
 func evalBooleanComparison(left *object.Boolean, right *object.Boolean, op token.TokenType) object.Object {
 	switch op {
-	case token.Equal:
+	case token.Equal, token.Assign:
 		if left.Value == right.Value {
 			return True
 		}
@@ -83,18 +100,91 @@ func evalBooleanComparison(left *object.Boolean, right *object.Boolean, op token
 	return reportUnexpectedError(op)
 }
 
-func evalStringComparison(left *object.String, right *object.String, op token.TokenType) object.Object {
+func evalDateComparison(left *object.Date, right *object.Date, op token.TokenType) object.Object {
+	cmp := compareDates(left, right)
 	switch op {
-	case token.Equal:
-		if left.Value == right.Value {
-			return True
-		}
-		return False
+	case token.Less:
+		return toBoolean(cmp < 0)
+	case token.LessEq:
+		return toBoolean(cmp <= 0)
+	case token.Greater:
+		return toBoolean(cmp > 0)
+	case token.GreaterEq:
+		return toBoolean(cmp >= 0)
+	case token.Equal, token.Assign:
+		return toBoolean(cmp == 0)
 	case token.NotEq:
-		if left.Value != right.Value {
-			return True
-		}
-		return False
+		return toBoolean(cmp != 0)
+	}
+	return reportUnexpectedError(op)
+}
+
+// compareDates compara dos fechas; la fecha vacía es menor que cualquier otra.
+func compareDates(left *object.Date, right *object.Date) int {
+	switch {
+	case left.Value.Before(right.Value):
+		return -1
+	case left.Value.After(right.Value):
+		return 1
+	}
+	return 0
+}
+
+func evalStringComparison(left *object.String, right *object.String, op token.TokenType, env *object.Environment) object.Object {
+	switch op {
+	case token.Equal: // `==` siempre es una comparación exacta
+		return toBoolean(left.Value == right.Value)
+	case token.Assign: // `=` depende de SET EXACT y SET COLLATE
+		return toBoolean(stringsMatch(left.Value, right.Value, env))
+	case token.NotEq:
+		return toBoolean(!stringsMatch(left.Value, right.Value, env))
+	case token.Contains: // "ox" $ "FoxLite"
+		return toBoolean(strings.Contains(right.Value, left.Value))
+	case token.Less:
+		return toBoolean(compareStrings(left.Value, right.Value, env) < 0)
+	case token.LessEq:
+		return toBoolean(compareStrings(left.Value, right.Value, env) <= 0)
+	case token.Greater:
+		return toBoolean(compareStrings(left.Value, right.Value, env) > 0)
+	case token.GreaterEq:
+		return toBoolean(compareStrings(left.Value, right.Value, env) >= 0)
 	}
 	return object.NewError(fmt.Sprintf("`%s` operator does not support string types", token.GetTokenStr(op)))
 }
+
+// compareValues compara dos valores del mismo tipo y devuelve -1, 0 o 1.
+// Los strings se comparan con la secuencia activa (SET COLLATE) y en los
+// lógicos False es menor que True.
+func compareValues(left object.Object, right object.Object, env *object.Environment) (int, *object.Error) {
+	switch left := left.(type) {
+	case *object.Integer:
+		if right, ok := right.(*object.Integer); ok {
+			switch {
+			case left.Value < right.Value:
+				return -1, nil
+			case left.Value > right.Value:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case *object.String:
+		if right, ok := right.(*object.String); ok {
+			return compareStrings(left.Value, right.Value, env), nil
+		}
+	case *object.Boolean:
+		if right, ok := right.(*object.Boolean); ok {
+			switch {
+			case left.Value == right.Value:
+				return 0, nil
+			case right.Value:
+				return -1, nil
+			}
+			return 1, nil
+		}
+	case *object.Date:
+		if right, ok := right.(*object.Date); ok {
+			return compareDates(left, right), nil
+		}
+	}
+	return 0, object.NewError(fmt.Sprintf("cannot compare `%s` with `%s`", object.TypeToStr(left.Type()), object.TypeToStr(right.Type())))
+}
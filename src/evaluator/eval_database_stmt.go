@@ -0,0 +1,115 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// evalCreateDatabaseStmt crea una base de datos vacía, la deja abierta en
+// exclusiva y la convierte en la base de datos actual.
+func evalCreateDatabaseStmt(node *ast.CreateDatabaseStmt, env *object.Environment) object.Object {
+	fileName, errObj := evalFileName(node.Name, env, ".dbc")
+	if errObj != nil {
+		return errObj
+	}
+	if env.DatabaseByName(fileName) != nil {
+		return object.NewError(fmt.Sprintf("CREATE DATABASE: database `%s` is open", fileName))
+	}
+	if errObj := checkOverwrite("CREATE DATABASE", fileName, env); errObj != nil {
+		return errObj
+	}
+	db, err := dbf.CreateDatabase(fileName)
+	if err != nil {
+		return object.NewError(fmt.Sprintf("cannot create database: %v", err))
+	}
+	if err := db.Share(true); err != nil {
+		db.Close()
+		return object.NewError(fmt.Sprintf("cannot create database: %v", err))
+	}
+	env.OpenDatabase(db, true)
+	return None
+}
+
+// evalOpenDatabaseStmt abre una base de datos y la convierte en la base
+// de datos actual; si ya estaba abierta solo pasa a ser la actual.
+func evalOpenDatabaseStmt(node *ast.OpenDatabaseStmt, env *object.Environment) object.Object {
+	name, errObj := evalFileName(node.Name, env, ".dbc")
+	if errObj != nil {
+		return errObj
+	}
+	fileName, errObj := resolveFile(name, ".dbc", env)
+	if errObj != nil {
+		return errObj
+	}
+	db := env.DatabaseByName(fileName)
+	if db == nil {
+		exclusive := node.Exclusive || (!node.Shared && isOptionOn(env, "exclusive"))
+		if db, errObj = openDatabase(fileName, exclusive, env); errObj != nil {
+			return errObj
+		}
+	}
+	env.SetCurrentDatabase(db)
+	return None
+}
+
+// evalSetDatabaseStmt cambia la base de datos actual entre las abiertas.
+func evalSetDatabaseStmt(node *ast.SetDatabaseStmt, env *object.Environment) object.Object {
+	if node.Name == nil {
+		env.SetCurrentDatabase(nil)
+		return None
+	}
+	name, errObj := evalFileName(node.Name, env, "")
+	if errObj != nil {
+		return errObj
+	}
+	db := env.DatabaseByName(name)
+	if db == nil {
+		return object.NewError(fmt.Sprintf("database `%s` is not open", name))
+	}
+	env.SetCurrentDatabase(db)
+	return None
+}
+
+// evalAddTableStmt agrega una tabla libre a la base de datos actual. La
+// tabla no puede estar abierta.
+func evalAddTableStmt(node *ast.AddTableStmt, env *object.Environment) object.Object {
+	db := env.CurrentDatabase()
+	if db == nil {
+		return object.NewError("ADD TABLE: no database is open")
+	}
+	name, errObj := evalFileName(node.File, env, ".dbf")
+	if errObj != nil {
+		return errObj
+	}
+	fileName, errObj := resolveFile(name, ".dbf", env)
+	if errObj != nil {
+		return errObj
+	}
+	longName := ""
+	if node.Name != nil {
+		if longName, errObj = evalFileName(node.Name, env, ""); errObj != nil {
+			return errObj
+		}
+	}
+	for _, wa := range env.WorkAreas() {
+		if path, err := filepath.Abs(wa.Table.Path); err == nil && strings.EqualFold(path, fileName) {
+			return object.NewError(fmt.Sprintf("ADD TABLE: table `%s` is in use", wa.Alias))
+		}
+	}
+	table, err := dbf.Open(fileName, false)
+	if err != nil {
+		return object.NewError(fmt.Sprintf("cannot open table: %v", err))
+	}
+	defer table.Close()
+	if err := table.Share(true); err != nil {
+		return object.NewError(fmt.Sprintf("ADD TABLE: %v", err))
+	}
+	if _, err := db.AddTable(table, longName, nil); err != nil {
+		return object.NewError(fmt.Sprintf("ADD TABLE: %v", err))
+	}
+	return None
+}
@@ -0,0 +1,50 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/object"
+	"fmt"
+)
+
+func evalDoStmt(node *ast.DoStmt, env *object.Environment) object.Object {
+	if node.File != nil {
+		name, errObj := evalFileName(node.File, env, ".flp")
+		if errObj != nil {
+			return errObj
+		}
+		fileName, errObj := resolveFile(name, ".flp", env)
+		if errObj != nil {
+			return errObj
+		}
+		return discardValue(evalDoFile(fileName, node.Args, env))
+	}
+	// Do programa: si no hay una rutina con ese nombre se busca programa.flp
+	name := node.Name.Value.(string)
+	if env.Get(name) == nil && env.GetProcedure(name) == nil {
+		if _, ok := lookupBuiltin(name); !ok {
+			if fileName, errObj := resolveFile(name, ".flp", env); errObj == nil {
+				return discardValue(evalDoFile(fileName, node.Args, env))
+			}
+		}
+	}
+	function := Eval(node.Name, env)
+	if isError(function) {
+		return function
+	}
+	if function.Type() != object.FuncObj && function.Type() != object.BuiltinObj {
+		return object.NewError(fmt.Sprintf("`%s` is not a routine", node.Name.String()))
+	}
+	args := evalArguments(function, node.Args, env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0]
+	}
+	return discardValue(applyFunction(function, args, env))
+}
+
+// discardValue descarta el valor devuelto por la rutina invocada con Do.
+func discardValue(res object.Object) object.Object {
+	if isError(res) {
+		return res
+	}
+	return None
+}
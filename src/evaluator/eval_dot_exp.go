@@ -0,0 +1,78 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/object"
+	"FoxLite/src/token"
+	"fmt"
+	"strings"
+)
+
+// evalDotExp => cli.nombre | m.lnTotal | loCliente.nombre
+// El prefijo m. fuerza la variable de memoria aunque exista un campo con el
+// mismo nombre; cualquier otro prefijo es el alias de una tabla de la
+// consulta SQL en evaluación, una variable que contiene un objeto o el
+// alias de un área de trabajo.
+func evalDotExp(node *ast.InfixExp, env *object.Environment) object.Object {
+	qualifier, name, errObj := dotNames(node)
+	if errObj != nil {
+		return errObj
+	}
+	if isMemvarQualifier(qualifier) {
+		val := env.Get(name)
+		if val == nil {
+			return object.NewError(fmt.Sprintf("variable `%s` is not found", name))
+		}
+		return val
+	}
+	if val, ok := env.Column(qualifier, name); ok {
+		return val
+	}
+	if obj, ok := env.Get(qualifier).(*object.Empty); ok {
+		val, ok := obj.Get(name)
+		if !ok {
+			return object.NewError(fmt.Sprintf("property `%s` is not found", name))
+		}
+		return val
+	}
+	wa, idx, errObj := resolveField(qualifier+"."+name, env)
+	if errObj != nil {
+		return errObj
+	}
+	return fieldValue(wa, idx)
+}
+
+// assignDot => m.lnTotal = 0 | loCliente.nombre = "Ana"
+// Los campos solo se modifican con REPLACE; las propiedades de un objeto
+// deben existir (ADDPROPERTY).
+func assignDot(target *ast.InfixExp, val object.Object, env *object.Environment) object.Object {
+	qualifier, name, errObj := dotNames(target)
+	if errObj != nil {
+		return errObj
+	}
+	if obj, ok := env.Get(qualifier).(*object.Empty); ok && !isMemvarQualifier(qualifier) {
+		if _, ok := obj.Get(name); !ok {
+			return object.NewError(fmt.Sprintf("property `%s` is not found", name))
+		}
+		obj.Set(name, val)
+		return val
+	}
+	if !isMemvarQualifier(qualifier) {
+		return object.NewError(fmt.Sprintf("cannot assign to field `%s.%s`, use REPLACE", qualifier, name))
+	}
+	return env.Set(name, val)
+}
+
+// dotNames devuelve los nombres a cada lado del punto.
+func dotNames(node *ast.InfixExp) (string, string, *object.Error) {
+	left, okLeft := node.Left.(*ast.Literal)
+	right, okRight := node.Right.(*ast.Literal)
+	if !okLeft || !okRight || left.Token.Type != token.Ident || right.Token.Type != token.Ident {
+		return "", "", object.NewError(fmt.Sprintf("invalid name `%s.%s`", node.Left.String(), node.Right.String()))
+	}
+	return left.Value.(string), right.Value.(string), nil
+}
+
+func isMemvarQualifier(name string) bool {
+	return strings.EqualFold(name, "m")
+}
@@ -0,0 +1,70 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/object"
+	"fmt"
+)
+
+func evalForStmt(node *ast.For, env *object.Environment) object.Object {
+	iterable := Eval(node.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+
+	var items []object.Object
+	switch iterable := iterable.(type) {
+	case *object.Integer: // For i in 10 => 0, 1, ..., 9
+		for i := 0; i < int(iterable.Value); i++ {
+			items = append(items, &object.Integer{Value: float64(i)})
+		}
+	case *object.String: // recorre los caracteres
+		for _, ch := range iterable.Value {
+			items = append(items, &object.String{Value: string(ch)})
+		}
+	case *object.Array:
+		items = iterable.Elements
+	default:
+		return object.NewError(fmt.Sprintf("cannot iterate over `%s`", object.TypeToStr(iterable.Type())))
+	}
+
+	for idx, item := range items {
+		// Cada iteración tiene su propio environment para que los closures
+		// creados dentro del bucle capturen el valor de esa iteración.
+		iterEnv := object.NewBlockEnv(env)
+		if node.Key != nil {
+			iterEnv.Define(node.Key.Value.(string), 'l', &object.Integer{Value: float64(idx)})
+		}
+		iterEnv.Define(node.Value.Value.(string), 'l', item)
+
+		var action byte
+		var res object.Object
+		// Evaluamos el bloque del For
+		for _, stmt := range node.Body.Statements {
+			res = Eval(stmt, iterEnv)
+			if isError(res) {
+				return res
+			}
+
+			rType := res.Type()
+			if rType == object.ExitObj {
+				action = 'b' // break
+				break
+			} else if rType == object.LoopObj {
+				action = 'l' // loop
+				break
+			} else if rType == object.ReturnObj {
+				action = 'r' // return
+				break
+			}
+		}
+
+		// evaluamos el estado de la ejecución de la iteración actual
+		if action == 'b' {
+			break
+		} else if action == 'r' {
+			return res
+		}
+	}
+	return None
+}
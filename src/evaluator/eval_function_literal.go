@@ -6,12 +6,24 @@ import (
 )
 
 func evalFunctionLiteral(node *ast.FunctionLiteral, env *object.Environment) object.Object {
-	name := node.Name.String()
 	f := &object.Function{
-		Name:       node.Name.String(),
 		Parameters: node.Parameters,
 		Body:       node.Body,
 		Env:        env,
+		File:       env.CurrentFile(),
+		// las funciones anónimas y las definidas dentro de otra función
+		// capturan todo su entorno, incluidas las variables locales.
+		Closure: node.Name == nil || env.HasOuter(),
+	}
+	if node.Name == nil { // función anónima: solo es un valor
+		return f
+	}
+	name := node.Name.String()
+	f.Name = name
+	// las funciones del programa principal son públicas y las definidas
+	// dentro de otra función son privadas de ésta.
+	if env.HasOuter() {
+		return env.Declare(name, 'p', f)
 	}
-	return env.Set(name, 'g', f)
+	return env.Declare(name, 'g', f)
 }
@@ -0,0 +1,24 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/object"
+	"fmt"
+)
+
+func evalIifExp(node *ast.IifExp, env *object.Environment) object.Object {
+	cond := Eval(node.Condition, env)
+	if isError(cond) {
+		return cond
+	}
+
+	if cond.Type() != object.BooleanObj {
+		return object.NewError(fmt.Sprintf("non-bool type `%s` used as iif condition", object.TypeToStr(cond.Type())))
+	}
+
+	// Solo se evalúa la rama elegida
+	if cond.(*object.Boolean).Value {
+		return Eval(node.Consequence, env)
+	}
+	return Eval(node.Alternative, env)
+}
@@ -0,0 +1,31 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/object"
+	"fmt"
+)
+
+func evalIndexExp(node *ast.IndexExp, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+	index := Eval(node.Index, env)
+	if isError(index) {
+		return index
+	}
+	array, ok := left.(*object.Array)
+	if !ok {
+		return object.NewError(fmt.Sprintf("index operator not supported: `%s`", object.TypeToStr(left.Type())))
+	}
+	idx, ok := index.(*object.Integer)
+	if !ok {
+		return object.NewError(fmt.Sprintf("array index must be a number, got `%s`", object.TypeToStr(index.Type())))
+	}
+	pos := int(idx.Value)
+	if pos < 0 || pos >= len(array.Elements) {
+		return object.NewError(fmt.Sprintf("array index out of range: %d (length %d)", pos, len(array.Elements)))
+	}
+	return array.Elements[pos]
+}
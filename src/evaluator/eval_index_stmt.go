@@ -0,0 +1,146 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"fmt"
+	"strings"
+)
+
+// evalIndexStmt crea (o reemplaza) un tag en el índice estructural de la
+// tabla actual, lo convierte en el orden activo y va al primer registro.
+func evalIndexStmt(node *ast.IndexStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	if !wa.Exclusive {
+		return object.NewError(fmt.Sprintf("%s: table must be opened exclusively", wa.Alias))
+	}
+	if node.Unique && node.Candidate {
+		return object.NewError("INDEX: UNIQUE and CANDIDATE cannot be used together")
+	}
+	// el tag de la clave primaria de la base de datos es siempre candidato
+	candidate := node.Candidate
+	if wa.DBTable != nil && strings.EqualFold(wa.DBTable.Text("PrimaryKey"), node.Tag) {
+		if node.Unique {
+			return object.NewError(fmt.Sprintf("INDEX: primary key tag `%s` cannot be UNIQUE", node.Tag))
+		}
+		candidate = true
+	}
+	// las expresiones se compilan a partir del texto que se guarda en el índice
+	if _, errObj := indexExpression(node.Source); errObj != nil {
+		return errObj
+	}
+	if node.For != nil {
+		if _, errObj := indexExpression(node.ForSource); errObj != nil {
+			return errObj
+		}
+	}
+	tag := wa.Table.AddTag(&dbf.Tag{
+		Name:       strings.ToUpper(node.Tag),
+		Expr:       node.Source,
+		For:        node.ForSource,
+		Descending: node.Descending,
+		Unique:     node.Unique,
+		Candidate:  candidate,
+	})
+	if errObj := buildTag(wa, tag, env); errObj != nil {
+		wa.Table.DeleteTag(tag.Name)
+		return errObj
+	}
+	wa.Order = tag.Name
+	wa.Reverse = false
+	return commandResult(goTop(wa, env))
+}
+
+func evalReindexStmt(node *ast.ReindexStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	if errObj := reindexTable(wa, env); errObj != nil {
+		return errObj
+	}
+	return commandResult(goTop(wa, env))
+}
+
+func evalDeleteTagStmt(node *ast.DeleteTagStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	if !wa.Exclusive {
+		return object.NewError(fmt.Sprintf("%s: table must be opened exclusively", wa.Alias))
+	}
+	names := node.Names
+	if node.All {
+		names = nil
+		for _, tag := range wa.Table.Tags() {
+			names = append(names, tag.Name)
+		}
+	}
+	for _, name := range names {
+		if !wa.Table.DeleteTag(name) {
+			return object.NewError(fmt.Sprintf("%s: tag `%s` is not found", wa.Alias, name))
+		}
+		if strings.EqualFold(wa.Order, name) {
+			wa.Order = ""
+		}
+	}
+	return None
+}
+
+// evalSetOrderStmt cambia el tag que controla el orden sin mover el puntero.
+func evalSetOrderStmt(node *ast.SetOrderStmt, env *object.Environment) object.Object {
+	wa, errObj := areaClause(node.In, env)
+	if errObj != nil {
+		return errObj
+	}
+	wa.Order = ""
+	wa.Reverse = false
+	if node.Order == nil {
+		return None
+	}
+	order := Eval(node.Order, env)
+	if isError(order) {
+		return order
+	}
+	if num, ok := order.(*object.Integer); ok && num.Value == 0 {
+		return None
+	}
+	tag, errObj := tagArg(wa, order)
+	if errObj != nil {
+		return errObj
+	}
+	wa.Order = tag.Name
+	// Descending invierte el orden del tag y Ascending lo fuerza ascendente
+	wa.Reverse = (node.Descending && !tag.Descending) || (node.Ascending && tag.Descending)
+	return None
+}
+
+func evalSeekStmt(node *ast.SeekStmt, env *object.Environment) object.Object {
+	wa, errObj := areaClause(node.In, env)
+	if errObj != nil {
+		return errObj
+	}
+	tag := orderTag(wa)
+	if node.Order != nil {
+		order := Eval(node.Order, env)
+		if isError(order) {
+			return order
+		}
+		if tag, errObj = tagArg(wa, order); errObj != nil {
+			return errObj
+		}
+	}
+	if tag == nil {
+		return object.NewError(fmt.Sprintf("%s: SEEK requires an index order", wa.Alias))
+	}
+	key := Eval(node.Value, env)
+	if isError(key) {
+		return key
+	}
+	return commandResult(seekRecord(wa, tag, key, env))
+}
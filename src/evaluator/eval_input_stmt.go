@@ -21,7 +21,7 @@ func evalInputStmt(node *ast.Input, env *object.Environment) object.Object {
 	if scanner.Scan() {
 		val := &object.String{Value: scanner.Text()}
 		// guardar el string
-		env.Set(node.Output.Value.(string), 'l', val)
+		env.Set(node.Output.Value.(string), val)
 	}
 	if scanner.Err() != nil {
 		return object.NewError(fmt.Sprintf("could not read from console: %v", scanner.Err()))
@@ -27,9 +27,25 @@ func evalLiteral(node *ast.Literal, env *object.Environment) object.Object {
 
 func evalIdentifier(node *ast.Literal, env *object.Environment) object.Object {
 	name := node.Value.(string)
+	// dentro de una consulta SQL las columnas de sus tablas tienen prioridad
+	if val, ok := env.Column("", name); ok {
+		return val
+	}
+	// los campos de la tabla del área actual tienen prioridad
+	if field, ok := lookupField(name, env); ok {
+		return field
+	}
 	// resolver el nombre
-	result := env.Get(name, true)
+	result := env.Get(name)
 	if result == nil {
+		// funciones y clases de las librerías abiertas con SET PROCEDURE
+		if proc := env.GetProcedure(name); proc != nil {
+			return proc
+		}
+		// las funciones nativas no distinguen mayúsculas
+		if fn, ok := lookupBuiltin(name); ok {
+			return fn
+		}
 		lincol := fmt.Sprintf("%d:%d", node.Token.Line, node.Token.Col)
 		return &object.Error{Message: fmt.Sprintf("[%s] undefined ident: `%s`", lincol, name)}
 	}
@@ -0,0 +1,152 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/object"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func evalReleaseStmt(node *ast.ReleaseStmt, env *object.Environment) object.Object {
+	if node.All {
+		env.ReleaseAll(node.Skeleton, node.Except)
+		return None
+	}
+	for _, name := range node.Names {
+		if !env.Release(name) {
+			return object.NewError(fmt.Sprintf("variable `%s` is not found", name))
+		}
+	}
+	return None
+}
+
+func evalClearMemoryStmt(node *ast.ClearMemoryStmt, env *object.Environment) object.Object {
+	env.ClearMemory()
+	return None
+}
+
+// evalListMemoryStmt muestra las variables visibles ordenadas por nombre:
+// nombre, ámbito, tipo y valor.
+func evalListMemoryStmt(node *ast.ListMemoryStmt, env *object.Environment) object.Object {
+	vars := visibleMemory(env, node.Skeleton, node.Except)
+	for _, name := range sortedNames(vars) {
+		vec := vars[name]
+		fmt.Printf("%-20s %-6s %s  %s\n", name, scopeLabel(vec.Scope), object.TypeToCode(vec.Value.Type()), memoryValue(vec.Value, env.Format()))
+	}
+	fmt.Printf("%d variables defined\n", len(vars))
+	return None
+}
+
+func evalSaveStmt(node *ast.SaveStmt, env *object.Environment) object.Object {
+	fileName, errObj := evalFileName(node.File, env, ".mem")
+	if errObj != nil {
+		return errObj
+	}
+	if errObj := checkOverwrite("SAVE TO", fileName, env); errObj != nil {
+		return errObj
+	}
+	vars := visibleMemory(env, node.Skeleton, node.Except)
+	if errObj := writeMemFile(fileName, vars); errObj != nil {
+		return errObj
+	}
+	return None
+}
+
+func evalRestoreStmt(node *ast.RestoreStmt, env *object.Environment) object.Object {
+	fileName, errObj := evalFileName(node.File, env, ".mem")
+	if errObj != nil {
+		return errObj
+	}
+	vars, errObj := readMemFile(fileName)
+	if errObj != nil {
+		return errObj
+	}
+	if !node.Additive {
+		env.ClearMemory()
+	}
+	for _, v := range vars {
+		if v.scope == 'g' {
+			env.Declare(v.name, 'g', v.value)
+		} else {
+			env.Declare(v.name, 'p', v.value)
+		}
+	}
+	return None
+}
+
+// visibleMemory devuelve las variables visibles cuyo nombre coincide con
+// skeleton (o no coincide, si except es true).
+func visibleMemory(env *object.Environment, skeleton string, except bool) map[string]*object.Vector {
+	vars := map[string]*object.Vector{}
+	for name, vec := range env.Visible() {
+		if vec.Value.Type() == object.ClassObj || vec.Value.Type() == object.BuiltinObj {
+			continue
+		}
+		if object.MatchSkeleton(skeleton, name) != except {
+			vars[name] = vec
+		}
+	}
+	return vars
+}
+
+func sortedNames(vars map[string]*object.Vector) []string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return strings.ToLower(names[i]) < strings.ToLower(names[j])
+	})
+	return names
+}
+
+func scopeLabel(scope byte) string {
+	switch scope {
+	case 'l':
+		return "Local"
+	case 'g':
+		return "Pub"
+	default:
+		return "Priv"
+	}
+}
+
+func memoryValue(value object.Object, format *object.Format) string {
+	if value.Type() == object.StringObj {
+		return `"` + value.Inspect() + `"`
+	}
+	return format.Inspect(value)
+}
+
+// evalFileName evalúa el nombre de un archivo y le agrega la extensión por
+// defecto si no tiene ninguna.
+func evalFileName(exp ast.Expression, env *object.Environment, ext string) (string, *object.Error) {
+	obj := Eval(exp, env)
+	if isError(obj) {
+		return "", obj.(*object.Error)
+	}
+	if obj.Type() != object.StringObj {
+		return "", object.NewError(fmt.Sprintf("file name must be `string`, got `%s`", object.TypeToStr(obj.Type())))
+	}
+	name := obj.(*object.String).Value
+	if filepath.Ext(name) == "" {
+		name += ext
+	}
+	return name, nil
+}
+
+// checkOverwrite impide reemplazar un archivo que ya existe mientras SET
+// SAFETY está ON. VFP pide confirmación; FoxLite no pregunta y lo informa
+// como error.
+func checkOverwrite(command string, fileName string, env *object.Environment) *object.Error {
+	if !isOptionOn(env, "safety") {
+		return nil
+	}
+	if _, err := os.Stat(fileName); err == nil {
+		return object.NewError(fmt.Sprintf("%s: file `%s` already exists (SET SAFETY OFF to overwrite it)", command, fileName))
+	}
+	return nil
+}
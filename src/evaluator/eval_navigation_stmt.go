@@ -0,0 +1,228 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/object"
+	"fmt"
+	"strings"
+)
+
+// evalScanStmt recorre los registros visibles del área actual ejecutando el
+// bloque para los que cumplen la condición For. Sin While empieza en el
+// primer registro; con While sigue desde el actual mientras se cumpla.
+func evalScanStmt(node *ast.ScanStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	if node.While == nil {
+		if errObj := goTop(wa, env); errObj != nil {
+			return errObj
+		}
+	}
+	// al terminar cada vuelta (y el Scan) se vuelve a seleccionar su área
+	defer env.SelectArea(wa.Number)
+	for !wa.Eof() {
+		state, errObj := scopeConditions(node.For, node.While, wa, env)
+		if errObj != nil {
+			return errObj
+		}
+		if state == stopScope {
+			break
+		}
+		if state == matchScope {
+			var action byte
+			var res object.Object
+			for _, stmt := range node.Body.Statements {
+				res = Eval(stmt, env)
+				if isError(res) {
+					return res
+				}
+				rType := res.Type()
+				if rType == object.ExitObj {
+					action = 'b' // break
+					break
+				} else if rType == object.LoopObj {
+					action = 'l' // loop
+					break
+				} else if rType == object.ReturnObj {
+					action = 'r' // return
+					break
+				}
+			}
+			if action == 'b' {
+				break
+			} else if action == 'r' {
+				return res
+			}
+		}
+		if env.WorkArea(wa.Number) != wa {
+			return object.NewError(fmt.Sprintf("SCAN: table `%s` was closed", wa.Alias))
+		}
+		env.SelectArea(wa.Number)
+		if wa.Eof() { // el bloque pudo mover el puntero al final
+			break
+		}
+		if errObj := skipRecords(wa, env, 1); errObj != nil {
+			return errObj
+		}
+	}
+	return None
+}
+
+// Resultado de evaluar las condiciones For y While sobre un registro.
+const (
+	skipScope  = iota // no cumple For: se pasa al siguiente
+	matchScope        // cumple For
+	stopScope         // no cumple While: termina el recorrido
+)
+
+// scopeConditions evalúa las condiciones For y While sobre el registro
+// actual de wa.
+func scopeConditions(forCond ast.Expression, whileCond ast.Expression, wa *object.WorkArea, env *object.Environment) (int, *object.Error) {
+	if whileCond != nil {
+		ok, errObj := evalCondition(whileCond, wa, env, "WHILE")
+		if errObj != nil {
+			return 0, errObj
+		}
+		if !ok {
+			return stopScope, nil
+		}
+	}
+	if forCond != nil {
+		ok, errObj := evalCondition(forCond, wa, env, "FOR")
+		if errObj != nil || !ok {
+			return skipScope, errObj
+		}
+	}
+	return matchScope, nil
+}
+
+func evalLocateStmt(node *ast.LocateStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	wa.Locate = &object.Locate{For: node.For, While: node.While}
+	if node.While == nil {
+		if errObj := goTop(wa, env); errObj != nil {
+			return errObj
+		}
+	}
+	return commandResult(locate(wa, env))
+}
+
+func evalContinueStmt(node *ast.ContinueStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	if wa.Locate == nil {
+		return object.NewError("CONTINUE without a previous LOCATE")
+	}
+	if wa.Eof() {
+		wa.Found = false
+		return None
+	}
+	if errObj := skipRecords(wa, env, 1); errObj != nil {
+		return errObj
+	}
+	return commandResult(locate(wa, env))
+}
+
+// locate busca desde el registro actual el primero que cumple las
+// condiciones del último LOCATE y actualiza FOUND().
+func locate(wa *object.WorkArea, env *object.Environment) *object.Error {
+	wa.Found = false
+	for !wa.Eof() {
+		state, errObj := scopeConditions(wa.Locate.For, wa.Locate.While, wa, env)
+		if errObj != nil {
+			return errObj
+		}
+		switch state {
+		case matchScope:
+			wa.Found = true
+			return nil
+		case stopScope:
+			return nil
+		}
+		if errObj := skipRecords(wa, env, 1); errObj != nil {
+			return errObj
+		}
+	}
+	return nil
+}
+
+func evalSetFilterStmt(node *ast.SetFilterStmt, env *object.Environment) object.Object {
+	wa, errObj := areaClause(node.In, env)
+	if errObj != nil {
+		return errObj
+	}
+	wa.Filter = node.Cond
+	return None
+}
+
+func evalSetRelationStmt(node *ast.SetRelationStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	if node.Off {
+		child, errObj := areaClause(node.Relations[0].Into, env)
+		if errObj != nil {
+			return errObj
+		}
+		for i, rel := range wa.Relations {
+			if strings.EqualFold(rel.Child, child.Alias) {
+				wa.Relations = append(wa.Relations[:i], wa.Relations[i+1:]...)
+				break
+			}
+		}
+		return None
+	}
+	if !node.Additive {
+		wa.Relations = nil
+	}
+	for _, def := range node.Relations {
+		child, errObj := areaClause(def.Into, env)
+		if errObj != nil {
+			return errObj
+		}
+		if relatedTo(child, wa, env) {
+			return object.NewError(fmt.Sprintf("cyclic relation: `%s` is already related to `%s`", child.Alias, wa.Alias))
+		}
+		wa.Relations = append(wa.Relations, &object.Relation{Expr: def.Expr, Child: child.Alias})
+	}
+	return commandResult(syncRelations(wa, env))
+}
+
+// relatedTo indica si target es el área wa o una de sus hijas (directa o
+// indirectamente).
+func relatedTo(wa *object.WorkArea, target *object.WorkArea, env *object.Environment) bool {
+	if wa == target {
+		return true
+	}
+	for _, rel := range wa.Relations {
+		if child := env.AreaByAlias(rel.Child); child != nil && relatedTo(child, target, env) {
+			return true
+		}
+	}
+	return false
+}
+
+// areaClause devuelve el área indicada en la cláusula In de un comando o la
+// actual si no hay cláusula; el área debe tener una tabla abierta.
+func areaClause(exp ast.Expression, env *object.Environment) (*object.WorkArea, *object.Error) {
+	if exp == nil {
+		return currentArea(env)
+	}
+	number, errObj := workAreaNumber(exp, env)
+	if errObj != nil {
+		return nil, errObj
+	}
+	wa := env.WorkArea(number)
+	if wa == nil {
+		return nil, object.NewError(fmt.Sprintf("no table is open in work area %d", number))
+	}
+	return wa, nil
+}
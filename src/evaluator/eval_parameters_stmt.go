@@ -0,0 +1,23 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/object"
+	"fmt"
+)
+
+func evalParametersStmt(node *ast.ParametersStmt, env *object.Environment) object.Object {
+	args := env.Arguments()
+	if len(args) > len(node.Names) {
+		return object.NewError(fmt.Sprintf("too many arguments, got %d, want %d", len(args), len(node.Names)))
+	}
+	for idx, name := range node.Names {
+		// los parámetros que no se pasan valen False
+		var val object.Object = False
+		if idx < len(args) {
+			val = args[idx]
+		}
+		bindParameter(env, name.Value.(string), node.Scope, val)
+	}
+	return None
+}
@@ -13,7 +13,7 @@ func evalPrintStmt(node *ast.PrintStmt, env *object.Environment) object.Object {
 		if isError(res) {
 			return res
 		}
-		fmt.Printf("%s ", res.Inspect())
+		fmt.Printf("%s ", env.Format().Inspect(res))
 	}
 	fmt.Print("\n")
 
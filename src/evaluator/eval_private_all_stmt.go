@@ -0,0 +1,11 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/object"
+)
+
+func evalPrivateAllStmt(node *ast.PrivateAllStmt, env *object.Environment) object.Object {
+	env.Hide(node.Skeleton, node.Except)
+	return None
+}
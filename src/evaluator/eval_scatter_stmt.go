@@ -0,0 +1,145 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"fmt"
+	"strings"
+)
+
+// Scatter copia los campos del registro actual en un array, en variables de
+// memoria (con el nombre del campo en minúsculas) o en un objeto con una
+// propiedad por campo; Gather hace el camino inverso. Los campos memo solo
+// se incluyen con la cláusula Memo.
+
+func evalScatterStmt(node *ast.ScatterStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	fields, errObj := filterFields(wa, &node.Filter)
+	if errObj != nil {
+		return errObj
+	}
+	rec, errObj := currentRecord(wa)
+	if errObj != nil {
+		return errObj
+	}
+	if node.Blank {
+		rec = wa.Table.Blank()
+	}
+	values := make([]object.Object, len(fields))
+	for i, idx := range fields {
+		value, err := rec.Value(idx)
+		if err != nil {
+			return tableError(wa, err)
+		}
+		values[i] = fromFieldValue(wa.Table.Fields[idx], value)
+	}
+
+	switch node.Target {
+	case "memvar":
+		for i, idx := range fields {
+			env.Set(strings.ToLower(wa.Table.Fields[idx].Name), values[i])
+		}
+	case "name":
+		obj, ok := env.Get(node.Name).(*object.Empty)
+		if !node.Additive || !ok {
+			obj = object.NewEmpty()
+		}
+		for i, idx := range fields {
+			obj.Set(strings.ToLower(wa.Table.Fields[idx].Name), values[i])
+		}
+		env.Set(node.Name, obj)
+	default:
+		env.Set(node.Name, &object.Array{Elements: values})
+	}
+	return None
+}
+
+func evalGatherStmt(node *ast.GatherStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	fields, errObj := filterFields(wa, &node.Filter)
+	if errObj != nil {
+		return errObj
+	}
+	if wa.Eof() {
+		return None
+	}
+
+	values := make([]object.Object, len(fields)) // nil: el campo no cambia
+	switch node.Source {
+	case "memvar":
+		for i, idx := range fields {
+			values[i] = memvarOf(wa.Table.Fields[idx].Name, env)
+		}
+	case "name":
+		obj, ok := env.Get(node.Name).(*object.Empty)
+		if !ok {
+			return object.NewError(fmt.Sprintf("GATHER: `%s` is not an object", node.Name))
+		}
+		for i, idx := range fields {
+			if val, ok := obj.Get(wa.Table.Fields[idx].Name); ok {
+				values[i] = val
+			}
+		}
+	default:
+		arr, ok := env.Get(node.Name).(*object.Array)
+		if !ok {
+			return object.NewError(fmt.Sprintf("GATHER: `%s` is not an array", node.Name))
+		}
+		// los elementos se asignan en orden; si sobran campos no cambian
+		copy(values, arr.Elements)
+	}
+	snap, errObj := beginUpdate(wa)
+	if errObj != nil {
+		return errObj
+	}
+	for i := 0; i < len(fields) && errObj == nil; i++ {
+		if values[i] != nil {
+			errObj = replaceField(wa, fields[i], values[i], env)
+		}
+	}
+	return commandResult(endUpdate(wa, snap, errObj, env))
+}
+
+// filterFields devuelve las posiciones de los campos que eligen las
+// cláusulas Fields, Like, Except y Memo.
+func filterFields(wa *object.WorkArea, filter *ast.FieldFilter) ([]int, *object.Error) {
+	var candidates []int
+	if len(filter.Fields) > 0 {
+		for _, name := range filter.Fields {
+			idx := wa.Table.FieldIndex(name)
+			if dot := strings.IndexByte(name, '.'); dot >= 0 && strings.EqualFold(name[:dot], wa.Alias) {
+				idx = wa.Table.FieldIndex(name[dot+1:])
+			}
+			if idx < 0 {
+				return nil, object.NewError(fmt.Sprintf("field `%s` is not found", name))
+			}
+			candidates = append(candidates, idx)
+		}
+	} else {
+		for i := range wa.Table.Fields {
+			candidates = append(candidates, i)
+		}
+	}
+	var fields []int
+	for _, idx := range candidates {
+		field := wa.Table.Fields[idx]
+		if field.Type == dbf.Memo && !filter.Memo {
+			continue
+		}
+		if filter.Like != "" && !object.MatchSkeleton(filter.Like, field.Name) {
+			continue
+		}
+		if filter.Except != "" && object.MatchSkeleton(filter.Except, field.Name) {
+			continue
+		}
+		fields = append(fields, idx)
+	}
+	return fields, nil
+}
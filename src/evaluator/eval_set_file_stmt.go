@@ -0,0 +1,38 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/object"
+	"strings"
+)
+
+func evalSetFileStmt(node *ast.SetFileStmt, env *object.Environment) object.Object {
+	switch node.Name {
+	case "procedure":
+		// Sin ADDITIVE se cierran las librerías abiertas antes de abrir las nuevas
+		if !node.Additive {
+			env.CloseLibraries()
+		}
+		for _, file := range node.Files {
+			if errObj := openLibraryExp(file, env); errObj != nil {
+				return errObj
+			}
+		}
+	case "path":
+		var dirs []string
+		if node.Additive {
+			dirs = searchPath(env)
+		}
+		for _, file := range node.Files {
+			dir := Eval(file, env)
+			if isError(dir) {
+				return dir
+			}
+			dirs = append(dirs, dir.Inspect())
+		}
+		if err := env.SetOption("path", &object.String{Value: strings.Join(dirs, ";")}); err != nil {
+			return err
+		}
+	}
+	return None
+}
@@ -0,0 +1,57 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/object"
+	"FoxLite/src/token"
+)
+
+func evalSetStmt(node *ast.SetStmt, env *object.Environment) object.Object {
+	var val object.Object
+	switch lit, ok := node.Value.(*ast.Literal); {
+	case node.Value == nil: // SET PATH TO
+	case ok && lit.Token.Type == token.Ident && isKeywordValue(lit, env):
+		// Las opciones con palabras clave se escriben sin comillas:
+		// SET UDFPARMS TO REFERENCE
+		val = &object.String{Value: lit.Value.(string)}
+	default:
+		val = Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+	}
+	if err := env.SetOption(node.Name, val); err != nil {
+		return err
+	}
+	if node.Name == "reprocess" {
+		// SET REPROCESS TO n SECONDS: n es un tiempo y no una cantidad de intentos
+		return commandResult(env.SetOption("reprocessseconds", &object.Boolean{Value: node.Seconds}))
+	}
+	return None
+}
+
+func evalPushSetStmt(node *ast.PushSetStmt, env *object.Environment) object.Object {
+	if !node.Pop {
+		env.PushOptions()
+		return None
+	}
+	if !env.PopOptions() {
+		return object.NewError("POP SET without a previous PUSH SET")
+	}
+	return None
+}
+
+// isKeywordValue indica si el valor es una palabra clave sin comillas y no
+// una variable: SET DATE TO BRITISH
+func isKeywordValue(lit *ast.Literal, env *object.Environment) bool {
+	name, ok := lit.Value.(string)
+	return ok && env.Get(name) == nil
+}
+
+// isOptionOn indica si un comando SET de tipo ON/OFF está activado.
+func isOptionOn(env *object.Environment, name string) bool {
+	if opt, ok := env.GetOption(name).(*object.Boolean); ok {
+		return opt.Value
+	}
+	return false
+}
@@ -0,0 +1,644 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Comandos SQL que modifican los datos (Insert, Update y Delete From) y la
+// estructura de las tablas (Create Table y Alter Table). La tabla de destino
+// es un alias abierto o una tabla del disco, que se abre en el área libre
+// más baja sin cambiar el área seleccionada. _TALLY guarda la cantidad de
+// registros agregados, modificados o borrados.
+
+// evalSqlInsertStmt => Insert Into t [(campos)] Values (valores) |
+// From Array arr | From Memvar | From Name obj
+func evalSqlInsertStmt(node *ast.SqlInsertStmt, env *object.Environment) object.Object {
+	wa, errObj := sqlTarget(node.Table, env, false)
+	if errObj != nil {
+		return errObj
+	}
+	rows, errObj := insertRows(node, wa, env)
+	if errObj != nil {
+		return errObj
+	}
+	count := 0
+	for _, row := range rows {
+		if errObj := insertRecord(wa, row, env); errObj != nil {
+			setTally(env, count)
+			return errObj
+		}
+		count++
+	}
+	setTally(env, count)
+	return None
+}
+
+// insertRows devuelve los valores de los registros que agrega Insert,
+// indexados por la posición del campo.
+func insertRows(node *ast.SqlInsertStmt, wa *object.WorkArea, env *object.Environment) ([]map[int]object.Object, *object.Error) {
+	fields := wa.Table.Fields
+	switch node.From {
+	case "memvar":
+		row := map[int]object.Object{}
+		for i, field := range fields {
+			if val := memvarOf(field.Name, env); val != nil {
+				row[i] = val
+			}
+		}
+		return []map[int]object.Object{row}, nil
+	case "name":
+		val := Eval(node.Source, env)
+		if isError(val) {
+			return nil, val.(*object.Error)
+		}
+		obj, ok := val.(*object.Empty)
+		if !ok {
+			return nil, object.NewError(fmt.Sprintf("INSERT: expecting an object, got `%s`", object.TypeToStr(val.Type())))
+		}
+		row := map[int]object.Object{}
+		for i, field := range fields {
+			if prop, ok := obj.Get(field.Name); ok {
+				row[i] = prop
+			}
+		}
+		return []map[int]object.Object{row}, nil
+	case "array":
+		val := Eval(node.Source, env)
+		if isError(val) {
+			return nil, val.(*object.Error)
+		}
+		arr, ok := val.(*object.Array)
+		if !ok {
+			return nil, object.NewError(fmt.Sprintf("INSERT: expecting an array, got `%s`", object.TypeToStr(val.Type())))
+		}
+		// un array de filas agrega un registro por fila; si no, uno solo
+		// con los elementos en el orden de los campos
+		lines := [][]object.Object{arr.Elements}
+		if len(arr.Elements) > 0 {
+			if _, ok := arr.Elements[0].(*object.Array); ok {
+				lines = nil
+				for _, el := range arr.Elements {
+					line, ok := el.(*object.Array)
+					if !ok {
+						return nil, object.NewError("INSERT: every row of the array must be an array")
+					}
+					lines = append(lines, line.Elements)
+				}
+			}
+		}
+		var rows []map[int]object.Object
+		for _, line := range lines {
+			row := map[int]object.Object{}
+			for i := 0; i < len(line) && i < len(fields); i++ {
+				row[i] = line[i]
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	}
+
+	columns := make([]int, len(node.Values))
+	for i := range node.Values {
+		if len(node.Columns) == 0 {
+			if i >= len(fields) {
+				return nil, object.NewError(fmt.Sprintf("INSERT: %s has %d fields, got %d values", wa.Alias, len(fields), len(node.Values)))
+			}
+			columns[i] = i
+			continue
+		}
+		idx, errObj := targetColumn(wa, node.Columns[i])
+		if errObj != nil {
+			return nil, errObj
+		}
+		columns[i] = idx
+	}
+	row := map[int]object.Object{}
+	for i, exp := range node.Values {
+		val := Eval(exp, env)
+		if isError(val) {
+			return nil, val.(*object.Error)
+		}
+		row[columns[i]] = val
+	}
+	return []map[int]object.Object{row}, nil
+}
+
+// memvarOf busca la variable de memoria de un campo: con el nombre tal como
+// está en la tabla, en minúsculas o en mayúsculas.
+func memvarOf(name string, env *object.Environment) object.Object {
+	for _, candidate := range []string{name, strings.ToLower(name), strings.ToUpper(name)} {
+		if val := env.Get(candidate); val != nil {
+			return val
+		}
+	}
+	return nil
+}
+
+// insertRecord agrega un registro con los valores indicados (los demás
+// campos quedan vacíos o con su valor por omisión en la base de datos) y
+// deja el puntero en él. Los valores se validan antes de agregar el
+// registro.
+func insertRecord(wa *object.WorkArea, row map[int]object.Object, env *object.Environment) *object.Error {
+	_, errObj := insertRecordIf(wa, row, nil, env)
+	return errObj
+}
+
+// insertRecordIf es insertRecord con la condición For de Append From: si
+// cond es falsa en el registro nuevo se descarta antes de comprobar sus
+// reglas. Devuelve si el registro se agregó.
+func insertRecordIf(wa *object.WorkArea, row map[int]object.Object, cond ast.Expression, env *object.Environment) (bool, *object.Error) {
+	rec := wa.Table.Blank()
+	for idx, val := range row {
+		value, errObj := toFieldValue(wa.Table.Fields[idx], val)
+		if errObj != nil {
+			return false, errObj
+		}
+		if err := rec.SetValue(idx, value); err != nil {
+			return false, tableError(wa, err)
+		}
+	}
+	if errObj := applyDefaults(wa, rec, row, env); errObj != nil {
+		return false, errObj
+	}
+	unlock, errObj := lockAppend(wa, env)
+	if errObj != nil {
+		return false, errObj
+	}
+	defer unlock()
+	prev := wa.Recno
+	appended, err := wa.Table.AppendBlank()
+	if err != nil {
+		return false, tableError(wa, err)
+	}
+	// en una tabla compartida otro proceso puede haber agregado registros
+	rec.Recno = appended.Recno
+	if err := wa.Table.WriteRecord(rec); err != nil {
+		return false, tableError(wa, err)
+	}
+	if errObj := goRecord(wa, env, rec.Recno); errObj != nil {
+		return false, errObj
+	}
+	if cond != nil {
+		ok, errObj := evalCondition(cond, wa, env, "APPEND FROM FOR")
+		if errObj != nil || !ok {
+			if discardErr := discardAppend(wa, rec.Recno, prev); errObj == nil {
+				errObj = discardErr
+			}
+			return false, errObj
+		}
+	}
+	return true, completeAppend(wa, prev, env)
+}
+
+// evalSqlUpdateStmt => Update t Set campo = expr [, ...] [Where cond]
+// Las expresiones ven la fila como estaba antes del Update, y las
+// subconsultas pueden usar sus columnas (Update correlacionado).
+func evalSqlUpdateStmt(node *ast.SqlUpdateStmt, env *object.Environment) object.Object {
+	wa, errObj := sqlTarget(node.Table, env, false)
+	if errObj != nil {
+		return errObj
+	}
+	columns := make([]int, len(node.Set))
+	for i, assign := range node.Set {
+		if columns[i], errObj = targetColumn(wa, assign.Column); errObj != nil {
+			return errObj
+		}
+	}
+	count := 0
+	errObj = eachTargetRow(wa, node.Where, env, func(rowEnv *object.Environment) *object.Error {
+		values := make([]object.Object, len(node.Set))
+		for i, assign := range node.Set {
+			val := Eval(assign.Value, rowEnv)
+			if isError(val) {
+				return val.(*object.Error)
+			}
+			values[i] = val
+		}
+		snap, errObj := beginUpdate(wa)
+		if errObj != nil {
+			return errObj
+		}
+		for i := 0; i < len(values) && errObj == nil; i++ {
+			errObj = replaceField(wa, columns[i], values[i], env)
+		}
+		if errObj := endUpdate(wa, snap, errObj, env); errObj != nil {
+			return errObj
+		}
+		count++
+		return nil
+	})
+	setTally(env, count)
+	return commandResult(errObj)
+}
+
+// evalSqlDeleteStmt => Delete From t [Where cond]
+// Los registros solo se marcan como borrados, como con DELETE.
+func evalSqlDeleteStmt(node *ast.SqlDeleteStmt, env *object.Environment) object.Object {
+	wa, errObj := sqlTarget(node.Table, env, false)
+	if errObj != nil {
+		return errObj
+	}
+	count := 0
+	errObj = eachTargetRow(wa, node.Where, env, func(rowEnv *object.Environment) *object.Error {
+		if errObj := deleteRecord(wa, true, env); errObj != nil {
+			return errObj
+		}
+		count++
+		return nil
+	})
+	setTally(env, count)
+	return commandResult(errObj)
+}
+
+// eachTargetRow ejecuta fn en cada registro de la tabla que cumple la
+// condición, con el puntero del área en el registro. Al terminar el puntero
+// vuelve a donde estaba.
+func eachTargetRow(wa *object.WorkArea, where ast.Expression, env *object.Environment, fn func(rowEnv *object.Environment) *object.Error) *object.Error {
+	src, errObj := tableSource(&sqlSource{alias: wa.Alias}, wa.Table, env)
+	if errObj != nil {
+		return errObj
+	}
+	ctx := &sqlContext{sources: []*sqlSource{src}}
+	rowEnv := object.NewRowEnv(env, ctx)
+	recno, bof := wa.Recno, wa.Bof
+	defer func() { wa.Recno, wa.Bof = recno, bof }()
+	for _, rec := range src.rows {
+		ctx.row = sqlRow{rec}
+		wa.Recno, wa.Bof = rec.recno, false
+		if where != nil {
+			ok, errObj := sqlCondition(where, rowEnv, "WHERE")
+			if errObj != nil {
+				return errObj
+			}
+			if !ok {
+				continue
+			}
+		}
+		if errObj := fn(rowEnv); errObj != nil {
+			return errObj
+		}
+	}
+	return nil
+}
+
+// sqlTarget devuelve el área de trabajo de la tabla de un comando SQL; si
+// la tabla no está abierta la abre en el área libre más baja, en exclusiva
+// si se pide o si SET EXCLUSIVE está ON.
+func sqlTarget(exp ast.Expression, env *object.Environment, exclusive bool) (*object.WorkArea, *object.Error) {
+	val := Eval(exp, env)
+	if errObj, ok := val.(*object.Error); ok {
+		return nil, errObj
+	}
+	str, ok := val.(*object.String)
+	if !ok || strings.TrimSpace(str.Value) == "" {
+		return nil, object.NewError("SQL: expecting a table name")
+	}
+	name := strings.TrimSpace(str.Value)
+	if wa := env.AreaByAlias(name); wa != nil {
+		return wa, nil
+	}
+	fileName, longName, errObj := resolveTable(name, env)
+	if errObj != nil {
+		return nil, errObj
+	}
+	alias := strings.ToUpper(longName)
+	if alias == "" {
+		alias = strings.ToUpper(strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName)))
+	}
+	if env.AreaByAlias(alias) != nil {
+		return nil, object.NewError(fmt.Sprintf("alias `%s` is already in use", alias))
+	}
+	table, dbTable, errObj := openTable(fileName, false, env)
+	if errObj != nil {
+		return nil, errObj
+	}
+	number, err := env.UnusedArea()
+	if err != nil {
+		table.Close()
+		return nil, object.NewError(err.Error())
+	}
+	wa := &object.WorkArea{
+		Number:    number,
+		Alias:     alias,
+		Table:     table,
+		Exclusive: exclusive || isOptionOn(env, "exclusive"),
+		DBTable:   dbTable,
+	}
+	if err := env.OpenArea(wa); err != nil {
+		return nil, object.NewError(fmt.Sprintf("cannot open table: %v", err))
+	}
+	if wa.Table.IndexStale() {
+		if errObj := reindexTable(wa, env); errObj != nil {
+			return nil, errObj
+		}
+	}
+	return wa, goTop(wa, env)
+}
+
+// targetColumn devuelve la posición de un campo de la tabla de destino:
+// nombre o alias.nombre.
+func targetColumn(wa *object.WorkArea, name string) (int, *object.Error) {
+	field := name
+	if dot := strings.IndexByte(name, '.'); dot >= 0 {
+		if !strings.EqualFold(name[:dot], wa.Alias) {
+			return 0, object.NewError(fmt.Sprintf("column `%s` is not found", name))
+		}
+		field = name[dot+1:]
+	}
+	idx := wa.Table.FieldIndex(field)
+	if idx < 0 {
+		return 0, object.NewError(fmt.Sprintf("column `%s` is not found", name))
+	}
+	return idx, nil
+}
+
+// evalCreateTableStmt => Create Table clientes (id I, nombre C(20) Null) [Free]
+// La tabla nueva queda abierta en exclusiva en el área libre más baja. Si
+// hay una base de datos actual y no se pide FREE la tabla se agrega a la
+// base, con los nombres largos de sus campos.
+func evalCreateTableStmt(node *ast.CreateTableStmt, env *object.Environment) object.Object {
+	table, alias, errObj := createTable("CREATE TABLE", node.Name, fieldDefs(node.Fields, env), env)
+	if errObj != nil {
+		return errObj
+	}
+	var dbTable *dbf.DBObject
+	if db := env.CurrentDatabase(); db != nil && !node.Free {
+		var err error
+		if dbTable, err = db.AddTable(table, "", nil); err != nil {
+			table.Drop()
+			return object.NewError(fmt.Sprintf("CREATE TABLE: %v", err))
+		}
+	}
+	return openNewTable(alias, table, true, dbTable, env)
+}
+
+// createTable crea una tabla vacía; su alias no puede estar en uso y, con
+// SET SAFETY ON, el archivo no puede existir.
+func createTable(command string, name ast.Expression, fields []dbf.Field, env *object.Environment) (*dbf.Table, string, *object.Error) {
+	fileName, errObj := evalFileName(name, env, ".dbf")
+	if errObj != nil {
+		return nil, "", errObj
+	}
+	alias := strings.ToUpper(strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName)))
+	if env.AreaByAlias(alias) != nil {
+		return nil, "", object.NewError(fmt.Sprintf("alias `%s` is already in use", alias))
+	}
+	if errObj := checkOverwrite(command, fileName, env); errObj != nil {
+		return nil, "", errObj
+	}
+	table, err := dbf.Create(fileName, fields)
+	if err != nil {
+		return nil, "", object.NewError(fmt.Sprintf("cannot create table: %v", err))
+	}
+	return table, alias, nil
+}
+
+// openNewTable abre una tabla recién creada en el área libre más baja y la
+// selecciona; dbTable es su objeto en la base de datos (nil si es libre).
+func openNewTable(alias string, table *dbf.Table, exclusive bool, dbTable *dbf.DBObject, env *object.Environment) object.Object {
+	number, err := env.UnusedArea()
+	if err != nil {
+		table.Close()
+		return object.NewError(err.Error())
+	}
+	wa := &object.WorkArea{
+		Number:    number,
+		Alias:     alias,
+		Table:     table,
+		Exclusive: exclusive,
+		DBTable:   dbTable,
+	}
+	if err := env.OpenArea(wa); err != nil {
+		return object.NewError(fmt.Sprintf("cannot open table: %v", err))
+	}
+	env.SelectArea(wa.Number)
+	return commandResult(goTop(wa, env))
+}
+
+// evalAlterTableStmt => Alter Table t Add | Alter | Drop | Rename Column ...
+// La tabla se reconstruye en un archivo nuevo con la estructura modificada
+// (los valores se convierten al tipo nuevo cuando es posible y si no quedan
+// vacíos) que luego reemplaza al original. Los tags del índice se vuelven a
+// crear; los que dejan de ser válidos se eliminan.
+func evalAlterTableStmt(node *ast.AlterTableStmt, env *object.Environment) object.Object {
+	wa, errObj := sqlTarget(node.Table, env, true)
+	if errObj != nil {
+		return errObj
+	}
+	if !wa.Exclusive {
+		return object.NewError(fmt.Sprintf("%s: table must be opened exclusively", wa.Alias))
+	}
+	if env.TransactionLevel() > 0 {
+		return object.NewError(fmt.Sprintf("ALTER TABLE: %s: %v", wa.Alias, dbf.ErrTransaction))
+	}
+	if len(wa.Table.Modified()) > 0 {
+		return object.NewError(fmt.Sprintf("ALTER TABLE: %s: %v", wa.Alias, dbf.ErrPendingChanges))
+	}
+	fields, sources, errObj := alteredFields(node, wa.Table, env)
+	if errObj != nil {
+		return errObj
+	}
+	file, err := os.CreateTemp(filepath.Dir(wa.Table.Path), "foxlite_*.dbf")
+	if err != nil {
+		return object.NewError(fmt.Sprintf("ALTER TABLE: %v", err))
+	}
+	tempPath := file.Name()
+	file.Close()
+	table, err := dbf.Create(tempPath, fields)
+	if err != nil {
+		os.Remove(tempPath)
+		return object.NewError(fmt.Sprintf("ALTER TABLE: %v", err))
+	}
+	if errObj := copyAltered(wa, table, sources); errObj != nil {
+		table.Drop()
+		return errObj
+	}
+
+	var tags []dbf.Tag
+	for _, tag := range wa.Table.Tags() {
+		tags = append(tags, dbf.Tag{
+			Name:       tag.Name,
+			Expr:       tag.Expr,
+			For:        tag.For,
+			Descending: tag.Descending,
+			Unique:     tag.Unique,
+			Candidate:  tag.Candidate,
+		})
+	}
+	path, link := wa.Table.Path, wa.Table.Backlink()
+	if err := table.Close(); err != nil {
+		os.Remove(tempPath)
+		return object.NewError(fmt.Sprintf("ALTER TABLE: %v", err))
+	}
+	if err := wa.Table.Close(); err != nil {
+		return tableError(wa, err)
+	}
+	if err := dbf.Rename(tempPath, path); err != nil {
+		return tableError(wa, err)
+	}
+	if wa.Table, err = dbf.Open(path, false); err == nil {
+		if err = wa.Table.Share(true); err != nil {
+			wa.Table.Close()
+		}
+	}
+	if err == nil && wa.DBTable != nil {
+		err = alteredDatabase(wa, link, fields, sources)
+	}
+	if err != nil {
+		env.CloseArea(wa.Number)
+		return object.NewError(fmt.Sprintf("cannot open table: %v", err))
+	}
+	for i := range tags {
+		tag := wa.Table.AddTag(&tags[i])
+		if buildTag(wa, tag, env) != nil {
+			wa.Table.DeleteTag(tag.Name)
+			if strings.EqualFold(wa.Order, tag.Name) {
+				wa.Order, wa.Reverse = "", false
+			}
+		}
+	}
+	if wa.Recno > wa.Table.RecordCount()+1 {
+		return commandResult(goTop(wa, env))
+	}
+	return None
+}
+
+// alteredFields devuelve la estructura modificada y, para cada campo, la
+// posición del campo de origen en la tabla actual (-1 si es nuevo).
+func alteredFields(node *ast.AlterTableStmt, table *dbf.Table, env *object.Environment) ([]dbf.Field, []int, *object.Error) {
+	fields := tableStructure(table.Fields)
+	sources := make([]int, len(fields))
+	for i := range sources {
+		sources[i] = i
+	}
+	find := func(name string) int {
+		for i, field := range fields {
+			if strings.EqualFold(field.Name, name) {
+				return i
+			}
+		}
+		return -1
+	}
+	switch node.Action {
+	case "add":
+		def := fieldDefs([]*ast.FieldDef{node.Field}, env)[0]
+		if find(def.Name) >= 0 {
+			return nil, nil, object.NewError(fmt.Sprintf("ALTER TABLE: field `%s` already exists", def.Name))
+		}
+		fields = append(fields, def)
+		sources = append(sources, -1)
+	case "alter":
+		def := fieldDefs([]*ast.FieldDef{node.Field}, env)[0]
+		idx := find(def.Name)
+		if idx < 0 {
+			return nil, nil, object.NewError(fmt.Sprintf("ALTER TABLE: field `%s` is not found", def.Name))
+		}
+		fields[idx] = def
+	case "drop":
+		idx := find(node.Column)
+		if idx < 0 {
+			return nil, nil, object.NewError(fmt.Sprintf("ALTER TABLE: field `%s` is not found", node.Column))
+		}
+		fields = append(fields[:idx], fields[idx+1:]...)
+		sources = append(sources[:idx], sources[idx+1:]...)
+	case "rename":
+		idx := find(node.Column)
+		if idx < 0 {
+			return nil, nil, object.NewError(fmt.Sprintf("ALTER TABLE: field `%s` is not found", node.Column))
+		}
+		if other := find(node.NewName); other >= 0 && other != idx {
+			return nil, nil, object.NewError(fmt.Sprintf("ALTER TABLE: field `%s` already exists", node.NewName))
+		}
+		fields[idx].Name = node.NewName
+	}
+	return fields, sources, nil
+}
+
+// alteredDatabase vuelve a enlazar a su base de datos una tabla cuya
+// estructura cambió y actualiza en la base sus campos.
+func alteredDatabase(wa *object.WorkArea, link string, fields []dbf.Field, sources []int) error {
+	if err := wa.Table.SetBacklink(link); err != nil {
+		return err
+	}
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Name
+	}
+	db := wa.DBTable.Database()
+	if err := db.SetFields(wa.DBTable, names, sources); err != nil {
+		return err
+	}
+	_, err := db.Attach(wa.Table)
+	return err
+}
+
+// copyAltered copia los registros del área a la tabla con la estructura
+// modificada, incluidas las marcas de borrado.
+func copyAltered(wa *object.WorkArea, table *dbf.Table, sources []int) *object.Error {
+	for recno := 1; recno <= wa.Table.RecordCount(); recno++ {
+		old, err := wa.Table.Record(recno)
+		if err != nil {
+			return tableError(wa, err)
+		}
+		rec, err := table.AppendBlank()
+		if err != nil {
+			return tableError(wa, err)
+		}
+		rec.SetDeleted(old.Deleted())
+		for i, src := range sources {
+			if src < 0 {
+				continue
+			}
+			value, err := old.Value(src)
+			if err != nil {
+				return tableError(wa, err)
+			}
+			val := fromFieldValue(wa.Table.Fields[src], value)
+			if converted, ok := convertFieldValue(table.Fields[i], wa.Table.Fields[src], val); ok {
+				// un valor que no cabe en el campo nuevo queda vacío
+				rec.SetValue(i, converted)
+			}
+		}
+		if err := table.WriteRecord(rec); err != nil {
+			return tableError(wa, err)
+		}
+	}
+	return nil
+}
+
+// convertFieldValue convierte un valor del campo source al tipo de un campo
+// que cambió de tipo: cualquier valor a texto (los números con los
+// decimales de source) y el texto numérico a número.
+func convertFieldValue(field *dbf.Field, source *dbf.Field, val object.Object) (interface{}, bool) {
+	if value, errObj := toFieldValue(field, val); errObj == nil {
+		return value, true
+	}
+	switch field.Type {
+	case dbf.Character, dbf.Memo:
+		if num, ok := val.(*object.Integer); ok {
+			decimals := source.Decimals
+			switch {
+			case source.Type == dbf.Currency:
+				decimals = 4
+			case source.Type == dbf.Double && decimals == 0:
+				decimals = -1 // todos los decimales necesarios
+			}
+			return strconv.FormatFloat(num.Value, 'f', decimals, 64), true
+		}
+		return strings.TrimSpace(val.Inspect()), true
+	case dbf.Numeric, dbf.Float, dbf.Integer, dbf.Currency, dbf.Double:
+		if str, ok := val.(*object.String); ok {
+			if num, err := strconv.ParseFloat(strings.TrimSpace(str.Value), 64); err == nil {
+				return num, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,192 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/object"
+	"fmt"
+	"strings"
+)
+
+// Expresiones que solo existen dentro de las consultas SQL. Las
+// comparaciones con null devuelven null, que en una condición es falso.
+
+// evalSqlAggregateExp devuelve el valor del agregado en el grupo actual.
+func evalSqlAggregateExp(node *ast.SqlAggregateExp, env *object.Environment) object.Object {
+	if ctx := sqlContextOf(env); ctx != nil && ctx.aggregates != nil {
+		if val, ok := ctx.aggregates[node]; ok {
+			return val
+		}
+	}
+	return object.NewError(fmt.Sprintf("aggregate function `%s` is not allowed here", node.String()))
+}
+
+// evalSqlSubqueryExp => (Select ...): la primera columna de la primera fila
+// o null si no hay ninguna.
+func evalSqlSubqueryExp(node *ast.SqlSubqueryExp, env *object.Environment) object.Object {
+	values, errObj := subqueryValues(node.Query, env)
+	if errObj != nil {
+		return errObj
+	}
+	if len(values) == 0 {
+		return Null
+	}
+	return values[0]
+}
+
+// subqueryValues devuelve los valores de la única columna de una subconsulta.
+func subqueryValues(query *ast.SqlQuery, env *object.Environment) ([]object.Object, *object.Error) {
+	result, errObj := runSubquery(query, env)
+	if errObj != nil {
+		return nil, errObj
+	}
+	if len(result.columns) != 1 {
+		return nil, object.NewError(fmt.Sprintf("subquery must return one column, got %d", len(result.columns)))
+	}
+	values := make([]object.Object, len(result.rows))
+	for i, row := range result.rows {
+		values[i] = row[0]
+	}
+	return values, nil
+}
+
+// evalSqlInExp => expr [Not] In (lista | subconsulta)
+func evalSqlInExp(node *ast.SqlInExp, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) || left.Type() == object.NullObj {
+		return left
+	}
+	var values []object.Object
+	if node.Query != nil {
+		var errObj *object.Error
+		if values, errObj = subqueryValues(node.Query, env); errObj != nil {
+			return errObj
+		}
+	} else {
+		for _, exp := range node.List {
+			val := Eval(exp, env)
+			if isError(val) {
+				return val
+			}
+			values = append(values, val)
+		}
+	}
+	for _, val := range values {
+		if sqlEqual(left, val, env) {
+			return toBoolean(!node.Not)
+		}
+	}
+	return toBoolean(node.Not)
+}
+
+// sqlEqual compara dos valores para In; los strings no distinguen los
+// espacios a la derecha.
+func sqlEqual(left object.Object, right object.Object, env *object.Environment) bool {
+	if l, ok := left.(*object.String); ok {
+		if r, ok := right.(*object.String); ok {
+			return compareStrings(strings.TrimRight(l.Value, " "), strings.TrimRight(r.Value, " "), env) == 0
+		}
+	}
+	cmp, errObj := compareValues(left, right, env)
+	return errObj == nil && cmp == 0
+}
+
+// evalSqlBetweenExp => expr [Not] Between a And b
+func evalSqlBetweenExp(node *ast.SqlBetweenExp, env *object.Environment) object.Object {
+	values := make([]object.Object, 3)
+	for i, exp := range []ast.Expression{node.Left, node.Low, node.High} {
+		val := Eval(exp, env)
+		if isError(val) || val.Type() == object.NullObj {
+			return val
+		}
+		values[i] = val
+	}
+	low, errObj := compareValues(values[0], values[1], env)
+	if errObj != nil {
+		return errObj
+	}
+	high, errObj := compareValues(values[0], values[2], env)
+	if errObj != nil {
+		return errObj
+	}
+	return toBoolean((low >= 0 && high <= 0) != node.Not)
+}
+
+// evalSqlLikeExp => expr [Not] Like patrón, donde % es cualquier texto y _
+// cualquier caracter.
+func evalSqlLikeExp(node *ast.SqlLikeExp, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) || left.Type() == object.NullObj {
+		return left
+	}
+	pattern := Eval(node.Pattern, env)
+	if isError(pattern) || pattern.Type() == object.NullObj {
+		return pattern
+	}
+	text, okText := left.(*object.String)
+	pat, okPat := pattern.(*object.String)
+	if !okText || !okPat {
+		return object.NewError(fmt.Sprintf("LIKE: expecting strings, got `%s` and `%s`", object.TypeToStr(left.Type()), object.TypeToStr(pattern.Type())))
+	}
+	match := likeMatch([]rune(strings.TrimRight(text.Value, " ")), []rune(strings.TrimRight(pat.Value, " ")))
+	return toBoolean(match != node.Not)
+}
+
+func likeMatch(text []rune, pattern []rune) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '%':
+			for len(pattern) > 0 && pattern[0] == '%' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(text); i++ {
+				if likeMatch(text[i:], pattern) {
+					return true
+				}
+			}
+			return false
+		case '_':
+			if len(text) == 0 {
+				return false
+			}
+		default:
+			if len(text) == 0 || text[0] != pattern[0] {
+				return false
+			}
+		}
+		text, pattern = text[1:], pattern[1:]
+	}
+	return len(text) == 0
+}
+
+// evalSqlIsNullExp => expr Is [Not] Null
+func evalSqlIsNullExp(node *ast.SqlIsNullExp, env *object.Environment) object.Object {
+	val := Eval(node.Left, env)
+	if isError(val) {
+		return val
+	}
+	return toBoolean((val.Type() == object.NullObj) != node.Not)
+}
+
+// evalSqlExistsExp => Exists (Select ...)
+func evalSqlExistsExp(node *ast.SqlExistsExp, env *object.Environment) object.Object {
+	result, errObj := runSubquery(node.Query, env)
+	if errObj != nil {
+		return errObj
+	}
+	return toBoolean(len(result.rows) > 0)
+}
+
+// evalSqlNotExp => Not cond
+func evalSqlNotExp(node *ast.SqlNotExp, env *object.Environment) object.Object {
+	val := Eval(node.Right, env)
+	switch val := val.(type) {
+	case *object.Error, *object.Null:
+		return val
+	case *object.Boolean:
+		return toBoolean(!val.Value)
+	}
+	return object.NewError(fmt.Sprintf("NOT: operand must be logical, got `%s`", object.TypeToStr(val.Type())))
+}
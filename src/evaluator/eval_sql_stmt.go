@@ -0,0 +1,205 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// evalSqlSelectStmt ejecuta la consulta y guarda el resultado en un cursor,
+// un array o una tabla, o lo muestra por pantalla. _TALLY guarda el número
+// de filas del resultado.
+func evalSqlSelectStmt(node *ast.SqlSelectStmt, env *object.Environment) object.Object {
+	result, errObj := runSqlQuery(node.Query, env)
+	if errObj != nil {
+		return errObj
+	}
+	setTally(env, len(result.rows))
+	if node.Into == nil {
+		return commandResult(printSqlResult(result, env))
+	}
+	if node.Into.Kind == "array" {
+		return intoArray(node.Into, result, env)
+	}
+	fields, errObj := sqlFields(result, env)
+	if errObj != nil {
+		return errObj
+	}
+	if node.Into.Kind == "table" {
+		return intoTable(node.Into, fields, result, env)
+	}
+	return intoCursor(node.Into, fields, result, env)
+}
+
+// setTally actualiza la variable del sistema _TALLY con el número de
+// registros procesados por el último comando SQL.
+func setTally(env *object.Environment, count int) {
+	env.Declare("_TALLY", 'g', &object.Integer{Value: float64(count)})
+}
+
+// intoArray guarda el resultado en un array de filas; si no hay ninguna
+// fila el array no se crea (ni se modifica).
+func intoArray(into *ast.SqlInto, result *sqlResult, env *object.Environment) object.Object {
+	name, errObj := sqlIntoName(into, env)
+	if errObj != nil {
+		return errObj
+	}
+	if len(result.rows) == 0 {
+		return None
+	}
+	rows := make([]object.Object, len(result.rows))
+	for i, row := range result.rows {
+		rows[i] = &object.Array{Elements: append([]object.Object{}, row...)}
+	}
+	env.Set(name, &object.Array{Elements: rows})
+	return None
+}
+
+// intoCursor guarda el resultado en un cursor; salvo ReadWrite el cursor es
+// de solo lectura.
+func intoCursor(into *ast.SqlInto, fields []dbf.Field, result *sqlResult, env *object.Environment) object.Object {
+	name, errObj := sqlIntoName(into, env)
+	if errObj != nil {
+		return errObj
+	}
+	table, errObj := createTempTable(fields)
+	if errObj != nil {
+		return errObj
+	}
+	if errObj := writeSqlRows(table, result); errObj != nil {
+		table.Drop()
+		return errObj
+	}
+	if !into.ReadWrite {
+		path, created := table.Path, table.Fields
+		if err := table.Close(); err != nil {
+			os.Remove(path)
+			return object.NewError(fmt.Sprintf("cannot create cursor: %v", err))
+		}
+		var err error
+		if table, err = dbf.Open(path, true); err != nil {
+			os.Remove(path)
+			return object.NewError(fmt.Sprintf("cannot create cursor: %v", err))
+		}
+		// los nombres largos de las columnas solo estaban en memoria
+		for i, field := range created {
+			table.SetLongName(i, field.Name)
+		}
+	}
+	return openCursor(strings.ToUpper(name), table, env)
+}
+
+// intoTable guarda el resultado en una tabla nueva y la abre en el área
+// libre más baja.
+func intoTable(into *ast.SqlInto, fields []dbf.Field, result *sqlResult, env *object.Environment) object.Object {
+	table, alias, errObj := createTable("SELECT", into.Name, fields, env)
+	if errObj != nil {
+		return errObj
+	}
+	if errObj := writeSqlRows(table, result); errObj != nil {
+		table.Close()
+		return errObj
+	}
+	return openNewTable(alias, table, isOptionOn(env, "exclusive"), nil, env)
+}
+
+// sqlIntoName evalúa el nombre del cursor o del array del resultado.
+func sqlIntoName(into *ast.SqlInto, env *object.Environment) (string, *object.Error) {
+	val := Eval(into.Name, env)
+	if errObj, ok := val.(*object.Error); ok {
+		return "", errObj
+	}
+	str, ok := val.(*object.String)
+	if !ok || strings.TrimSpace(str.Value) == "" {
+		return "", object.NewError(fmt.Sprintf("INTO %s: expecting a name", strings.ToUpper(into.Kind)))
+	}
+	return strings.TrimSpace(str.Value), nil
+}
+
+// writeSqlRows agrega las filas del resultado a la tabla.
+func writeSqlRows(table *dbf.Table, result *sqlResult) *object.Error {
+	for _, row := range result.rows {
+		rec, err := table.AppendBlank()
+		if err != nil {
+			return object.NewError(err.Error())
+		}
+		for i, val := range row {
+			value, errObj := toFieldValue(table.Fields[i], val)
+			if errObj != nil {
+				return errObj
+			}
+			if err := rec.SetValue(i, value); err != nil {
+				return object.NewError(err.Error())
+			}
+		}
+		if err := table.WriteRecord(rec); err != nil {
+			return object.NewError(err.Error())
+		}
+	}
+	return nil
+}
+
+// printSqlResult muestra el resultado en columnas con los nombres de las
+// columnas como cabecera; los números se alinean a la derecha.
+func printSqlResult(result *sqlResult, env *object.Environment) *object.Error {
+	fields, errObj := sqlFields(result, env)
+	if errObj != nil {
+		return errObj
+	}
+	widths := make([]int, len(fields))
+	for i, field := range fields {
+		widths[i] = utf8.RuneCountInString(field.Name)
+	}
+	format := env.Format()
+	cells := make([][]string, len(result.rows))
+	for r, row := range result.rows {
+		cells[r] = make([]string, len(row))
+		for i, val := range row {
+			text := sqlCellText(val, &fields[i], format)
+			if n := utf8.RuneCountInString(text); n > widths[i] {
+				widths[i] = n
+			}
+			cells[r][i] = text
+		}
+	}
+	line := func(texts []string) {
+		parts := make([]string, len(texts))
+		for i, text := range texts {
+			switch fields[i].Type {
+			case dbf.Numeric, dbf.Float, dbf.Integer, dbf.Currency, dbf.Double:
+				parts[i] = fmt.Sprintf("%*s", widths[i], text)
+			default:
+				parts[i] = text + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(text))
+			}
+		}
+		fmt.Println(strings.TrimRight(strings.Join(parts, " "), " "))
+	}
+	header := make([]string, len(fields))
+	for i, field := range fields {
+		header[i] = field.Name
+	}
+	line(header)
+	for _, row := range cells {
+		line(row)
+	}
+	return nil
+}
+
+// sqlCellText es el texto con el que se muestra un valor del resultado.
+func sqlCellText(val object.Object, field *dbf.Field, format *object.Format) string {
+	switch val := val.(type) {
+	case *object.String:
+		text := strings.TrimRight(val.Value, " ")
+		return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(text)
+	case *object.Integer:
+		if field.Type == dbf.Numeric || field.Type == dbf.Float {
+			return strconv.FormatFloat(val.Value, 'f', field.Decimals, 64)
+		}
+	}
+	return format.Inspect(val)
+}
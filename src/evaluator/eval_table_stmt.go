@@ -0,0 +1,519 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func evalUseStmt(node *ast.UseStmt, env *object.Environment) object.Object {
+	number := env.SelectedArea()
+	if node.In != nil {
+		var errObj *object.Error
+		if number, errObj = workAreaNumber(node.In, env); errObj != nil {
+			return errObj
+		}
+	}
+	if node.File == nil { // Use [In cli]: cierra la tabla del área
+		if err := env.CloseArea(number); err != nil {
+			return object.NewError(err.Error())
+		}
+		return None
+	}
+	name, errObj := evalFileName(node.File, env, ".dbf")
+	if errObj != nil {
+		return errObj
+	}
+	fileName, longName, errObj := resolveTable(name, env)
+	if errObj != nil {
+		return errObj
+	}
+	alias := node.Alias
+	if alias == "" && longName != "" {
+		alias = longName
+	} else if alias == "" {
+		alias = strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	}
+	alias = strings.ToUpper(alias)
+	if other := env.AreaByAlias(alias); other != nil && other.Number != number {
+		return object.NewError(fmt.Sprintf("alias `%s` is already in use", alias))
+	}
+	if err := env.CloseArea(number); err != nil {
+		return object.NewError(err.Error())
+	}
+	table, dbTable, errObj := openTable(fileName, false, env)
+	if errObj != nil {
+		return errObj
+	}
+	wa := &object.WorkArea{
+		Number:    number,
+		Alias:     alias,
+		Table:     table,
+		Exclusive: node.Exclusive || (!node.Shared && isOptionOn(env, "exclusive")),
+		DBTable:   dbTable,
+	}
+	if err := env.OpenArea(wa); err != nil {
+		return object.NewError(fmt.Sprintf("cannot open table: %v", err))
+	}
+	// un índice desactualizado (la tabla se modificó fuera de FoxLite) se
+	// reconstruye al abrir la tabla
+	if wa.Table.IndexStale() {
+		if errObj := reindexTable(wa, env); errObj != nil {
+			return errObj
+		}
+	}
+	return commandResult(goTop(wa, env))
+}
+
+func evalAppendBlankStmt(node *ast.AppendBlankStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	return commandResult(insertRecord(wa, nil, env))
+}
+
+// completeAppend actualiza los índices con el registro recién agregado,
+// que es el actual, y comprueba sus reglas; si la clave se repite en un tag
+// candidato o no se cumple una regla el registro se descarta.
+func completeAppend(wa *object.WorkArea, prev int, env *object.Environment) *object.Error {
+	errObj := updateIndexes(wa, env)
+	if errObj == nil {
+		errObj = checkInsert(wa, env)
+	}
+	if errObj != nil {
+		if discardErr := discardAppend(wa, wa.Recno, prev); discardErr != nil {
+			return discardErr
+		}
+	}
+	return errObj
+}
+
+// evalReplaceStmt reemplaza los campos del registro actual o, con alcance,
+// For o While, los de cada registro visible del área actual que cumple las
+// condiciones.
+func evalReplaceStmt(node *ast.ReplaceStmt, env *object.Environment) object.Object {
+	if node.Scope == nil && node.For == nil && node.While == nil {
+		return commandResult(replaceRecord(node, env))
+	}
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	count := 0
+	errObj = eachInScope(wa, node.Scope, node.For, node.While, env, func() *object.Error {
+		count++
+		return replaceRecord(node, env)
+	})
+	setTally(env, count)
+	return commandResult(errObj)
+}
+
+// replaceRecord reemplaza los campos de REPLACE en el registro actual.
+func replaceRecord(node *ast.ReplaceStmt, env *object.Environment) *object.Error {
+	// en una tabla compartida los registros se bloquean antes de evaluar
+	// los valores, que pueden depender de lo que hay en ellos; los de las
+	// tablas de una base de datos se guardan para deshacer el comando si no
+	// cumplen sus reglas
+	var snaps []*recordSnapshot
+	var areas []*object.WorkArea
+	for _, item := range node.Replacements {
+		wa, _, errObj := resolveField(item.Field, env)
+		if errObj != nil {
+			return errObj
+		}
+		unlock, errObj := lockCurrent(wa, env)
+		if errObj != nil {
+			return errObj
+		}
+		defer unlock()
+		if snap, errObj := beginUpdate(wa); errObj != nil {
+			return errObj
+		} else if snap != nil && !containsArea(areas, wa) {
+			snaps, areas = append(snaps, snap), append(areas, wa)
+		}
+	}
+	errObj := replaceFields(node, env)
+	for i := 0; i < len(areas) && errObj == nil; i++ {
+		errObj = checkUpdate(areas[i], snaps[i], env)
+	}
+	if errObj != nil {
+		for i, wa := range areas {
+			restoreSnapshot(wa, snaps[i], env)
+		}
+		return errObj
+	}
+	return nil
+}
+
+func containsArea(areas []*object.WorkArea, wa *object.WorkArea) bool {
+	for _, area := range areas {
+		if area == wa {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceFields evalúa y guarda los valores de REPLACE en orden.
+func replaceFields(node *ast.ReplaceStmt, env *object.Environment) *object.Error {
+	for _, item := range node.Replacements {
+		wa, idx, errObj := resolveField(item.Field, env)
+		if errObj != nil {
+			return errObj
+		}
+		// En el fin de archivo no hay registro que reemplazar
+		if wa.Eof() {
+			continue
+		}
+		val := Eval(item.Value, env)
+		if errObj, ok := val.(*object.Error); ok {
+			return errObj
+		}
+		if item.Additive && wa.Table.Fields[idx].Type == dbf.Memo {
+			// REPLACE memo WITH texto ADDITIVE agrega el texto al final
+			old := fieldValue(wa, idx)
+			if errObj, ok := old.(*object.Error); ok {
+				return errObj
+			}
+			prev, isStr := old.(*object.String)
+			if str, ok := val.(*object.String); ok && isStr {
+				val = &object.String{Value: prev.Value + str.Value}
+			}
+		}
+		if errObj := replaceField(wa, idx, val, env); errObj != nil {
+			return errObj
+		}
+	}
+	return nil
+}
+
+func evalAppendMemoStmt(node *ast.AppendMemoStmt, env *object.Environment) object.Object {
+	wa, idx, errObj := resolveMemoField(node.Field, env)
+	if errObj != nil {
+		return errObj
+	}
+	name, errObj := evalFileName(node.File, env, "")
+	if errObj != nil {
+		return errObj
+	}
+	fileName, errObj := resolveFile(name, "", env)
+	if errObj != nil {
+		return errObj
+	}
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return object.NewError(fmt.Sprintf("cannot read file `%s`: %v", fileName, err))
+	}
+	if wa.Eof() {
+		return None
+	}
+	text := string(data)
+	if !node.Overwrite {
+		old := fieldValue(wa, idx)
+		if isError(old) {
+			return old
+		}
+		if prev, ok := old.(*object.String); ok {
+			text = prev.Value + text
+		}
+	}
+	snap, errObj := beginUpdate(wa)
+	if errObj != nil {
+		return errObj
+	}
+	errObj = replaceField(wa, idx, &object.String{Value: text}, env)
+	return commandResult(endUpdate(wa, snap, errObj, env))
+}
+
+func evalCopyMemoStmt(node *ast.CopyMemoStmt, env *object.Environment) object.Object {
+	wa, idx, errObj := resolveMemoField(node.Field, env)
+	if errObj != nil {
+		return errObj
+	}
+	fileName, errObj := evalFileName(node.File, env, "")
+	if errObj != nil {
+		return errObj
+	}
+	value := fieldValue(wa, idx)
+	if isError(value) {
+		return value
+	}
+	text := ""
+	if str, ok := value.(*object.String); ok {
+		text = str.Value
+	}
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if node.Additive {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	} else if errObj := checkOverwrite("COPY MEMO", fileName, env); errObj != nil {
+		return errObj
+	}
+	file, err := os.OpenFile(fileName, flag, 0644)
+	if err != nil {
+		return object.NewError(fmt.Sprintf("cannot create file `%s`: %v", fileName, err))
+	}
+	defer file.Close()
+	if _, err := file.WriteString(text); err != nil {
+		return object.NewError(fmt.Sprintf("cannot write file `%s`: %v", fileName, err))
+	}
+	return None
+}
+
+// resolveMemoField busca un campo que debe ser de tipo memo.
+func resolveMemoField(name string, env *object.Environment) (*object.WorkArea, int, *object.Error) {
+	wa, idx, errObj := resolveField(name, env)
+	if errObj != nil {
+		return nil, 0, errObj
+	}
+	if field := wa.Table.Fields[idx]; field.Type != dbf.Memo {
+		return nil, 0, object.NewError(fmt.Sprintf("field `%s` is not a memo field", field.Name))
+	}
+	return wa, idx, nil
+}
+
+// resolveField busca un campo por su nombre (cli.nombre o nombre en el área
+// actual) y devuelve su área de trabajo y su posición.
+func resolveField(name string, env *object.Environment) (*object.WorkArea, int, *object.Error) {
+	var wa *object.WorkArea
+	if dot := strings.IndexByte(name, '.'); dot >= 0 {
+		wa = env.AreaByAlias(name[:dot])
+		if wa == nil {
+			return nil, 0, object.NewError(fmt.Sprintf("alias `%s` is not found", name[:dot]))
+		}
+		name = name[dot+1:]
+	} else {
+		var errObj *object.Error
+		if wa, errObj = currentArea(env); errObj != nil {
+			return nil, 0, errObj
+		}
+	}
+	idx := wa.Table.FieldIndex(name)
+	if idx < 0 {
+		return nil, 0, object.NewError(fmt.Sprintf("variable `%s` is not found", name))
+	}
+	return wa, idx, nil
+}
+
+// replaceField guarda el valor en el campo idx del registro actual y
+// actualiza los índices; si el valor repite la clave de un tag candidato o
+// no cumple la regla del campo se deshace el cambio.
+func replaceField(wa *object.WorkArea, idx int, val object.Object, env *object.Environment) *object.Error {
+	value, errObj := toFieldValue(wa.Table.Fields[idx], val)
+	if errObj != nil {
+		return errObj
+	}
+	unlock, errObj := lockCurrent(wa, env)
+	if errObj != nil {
+		return errObj
+	}
+	defer unlock()
+	old, errObj := currentRecord(wa)
+	if errObj != nil {
+		return errObj
+	}
+	snap, errObj := beginUpdate(wa)
+	if errObj != nil {
+		return errObj
+	}
+	rec, errObj := currentRecord(wa)
+	if errObj != nil {
+		return errObj
+	}
+	if err := rec.SetValue(idx, value); err != nil {
+		return object.NewError(err.Error())
+	}
+	if err := wa.Table.WriteRecord(rec); err != nil {
+		return tableError(wa, err)
+	}
+	errObj = updateIndexes(wa, env)
+	if errObj == nil {
+		errObj = checkField(wa, idx, env)
+	}
+	if errObj != nil {
+		if snap != nil {
+			restoreSnapshot(wa, snap, env)
+		} else if err := wa.Table.WriteRecord(old); err == nil {
+			updateIndexes(wa, env)
+		}
+		return errObj
+	}
+	return nil
+}
+
+// evalDeleteStmt marca (o desmarca con RECALL) el registro actual o, con
+// alcance, For o While, cada registro visible que cumple las condiciones.
+func evalDeleteStmt(node *ast.DeleteStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	if node.Scope == nil && node.For == nil && node.While == nil {
+		if wa.Eof() {
+			return None
+		}
+		return commandResult(deleteRecord(wa, !node.Recall, env))
+	}
+	count := 0
+	errObj = eachInScope(wa, node.Scope, node.For, node.While, env, func() *object.Error {
+		count++
+		return deleteRecord(wa, !node.Recall, env)
+	})
+	setTally(env, count)
+	return commandResult(errObj)
+}
+
+// deleteRecord marca o desmarca como borrado el registro actual. Al borrar
+// un registro de una tabla de una base de datos se comprueba el trigger de
+// borrado; si falla el registro no se borra.
+func deleteRecord(wa *object.WorkArea, deleted bool, env *object.Environment) *object.Error {
+	unlock, errObj := lockCurrent(wa, env)
+	if errObj != nil {
+		return errObj
+	}
+	defer unlock()
+	rec, errObj := currentRecord(wa)
+	if errObj != nil {
+		return errObj
+	}
+	rec.SetDeleted(deleted)
+	if err := wa.Table.WriteRecord(rec); err != nil {
+		return tableError(wa, err)
+	}
+	if errObj := updateIndexes(wa, env); errObj != nil || !deleted || wa.DBTable == nil {
+		return errObj
+	}
+	if errObj := checkTrigger(wa, "delete", env); errObj != nil {
+		rec.SetDeleted(false)
+		if err := wa.Table.WriteRecord(rec); err == nil {
+			updateIndexes(wa, env)
+		}
+		return errObj
+	}
+	return nil
+}
+
+// evalUnlockStmt libera los bloqueos de la tabla del área (de todas con
+// ALL) o solo el de un registro. Dentro de una transacción los bloqueos se
+// mantienen hasta que termina.
+func evalUnlockStmt(node *ast.UnlockStmt, env *object.Environment) object.Object {
+	if env.TransactionLevel() > 0 {
+		return None
+	}
+	if node.All {
+		for _, wa := range env.WorkAreas() {
+			if errObj := unlockArea(wa); errObj != nil {
+				return errObj
+			}
+		}
+		return None
+	}
+	wa := env.CurrentArea()
+	if node.In != nil {
+		number, errObj := workAreaNumber(node.In, env)
+		if errObj != nil {
+			return errObj
+		}
+		wa = env.WorkArea(number)
+	}
+	if wa == nil {
+		return object.NewError("UNLOCK: no table is open in the work area")
+	}
+	if node.Record == nil {
+		return commandResult(unlockArea(wa))
+	}
+	val := Eval(node.Record, env)
+	if isError(val) {
+		return val
+	}
+	num, ok := val.(*object.Integer)
+	if !ok {
+		return object.NewError(fmt.Sprintf("UNLOCK: record number must be a number, got `%s`", object.TypeToStr(val.Type())))
+	}
+	if err := unlockRecord(wa.Table, int(num.Value)); err != nil {
+		return tableError(wa, err)
+	}
+	return None
+}
+
+func evalPackStmt(node *ast.PackStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	if !wa.Exclusive {
+		return object.NewError(fmt.Sprintf("%s: table must be opened exclusively", wa.Alias))
+	}
+	var err error
+	if node.Zap {
+		err = wa.Table.Zap()
+	} else {
+		err = wa.Table.Pack()
+	}
+	if err != nil {
+		return tableError(wa, err)
+	}
+	if wa.Table.IndexStale() {
+		if errObj := reindexTable(wa, env); errObj != nil {
+			return errObj
+		}
+	}
+	return commandResult(goTop(wa, env))
+}
+
+func evalGoStmt(node *ast.GoStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	switch node.Where {
+	case "top":
+		return commandResult(goTop(wa, env))
+	case "bottom":
+		return commandResult(goBottom(wa, env))
+	default:
+		recno := Eval(node.Record, env)
+		if isError(recno) {
+			return recno
+		}
+		num, ok := recno.(*object.Integer)
+		if !ok {
+			return object.NewError(fmt.Sprintf("GO: record number must be a number, got `%s`", object.TypeToStr(recno.Type())))
+		}
+		// puede ser un registro que acaba de agregar otro proceso
+		if errObj := refreshArea(wa, env); errObj != nil {
+			return errObj
+		}
+		if errObj := goRecord(wa, env, int(num.Value)); errObj != nil {
+			return errObj
+		}
+	}
+	return None
+}
+
+func evalSkipStmt(node *ast.SkipStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	n := 1
+	if node.Count != nil {
+		count := Eval(node.Count, env)
+		if isError(count) {
+			return count
+		}
+		num, ok := count.(*object.Integer)
+		if !ok {
+			return object.NewError(fmt.Sprintf("SKIP: expecting a number, got `%s`", object.TypeToStr(count.Type())))
+		}
+		n = int(num.Value)
+	}
+	if errObj := skipRecords(wa, env, n); errObj != nil {
+		return errObj
+	}
+	return None
+}
@@ -0,0 +1,520 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Intercambio de datos con archivos de texto: COPY TO exporta los
+// registros del área actual y APPEND FROM agrega a la tabla o cursor actual
+// los registros del archivo.
+//
+//   - CSV: la primera línea tiene los nombres de los campos y los valores se
+//     separan con comas. Al importar, las columnas se asignan a los campos
+//     con el mismo nombre; si ningún nombre coincide, por posición.
+//   - DELIMITED: como CSV sin la línea de nombres. WITH cambia el delimitador
+//     de los textos (por omisión '"') y WITH CHARACTER, WITH BLANK o WITH TAB
+//     el separador de los valores.
+//   - SDF: cada campo ocupa siempre el mismo ancho y no hay separadores.
+//
+// Los textos van entre delimitadores; un delimitador dentro del texto se
+// escribe dos veces y los saltos de línea se conservan. Los memos se
+// exportan con CSV y DELIMITED pero no con SDF. Las fechas se escriben
+// AAAAMMDD (y la hora hhmmss) como en VFP, salvo en CSV, que usa el formato
+// ISO 8601 (AAAA-MM-DD hh:mm:ss) para que lo lean otros programas; al
+// importar se aceptan ambos y también el orden de SET DATE. Los null se
+// escriben vacíos y los valores vacíos se importan como el valor en blanco
+// del campo (o su valor por omisión en una base de datos).
+//
+// AS nCodePage indica la página de códigos del archivo: 65001 (UTF-8, por
+// omisión), 1252, 28591 (ISO-8859-1), 437 o 850. _TALLY guarda la cantidad
+// de registros copiados o agregados.
+
+// textFile es el formato ya evaluado de un archivo de texto.
+type textFile struct {
+	kind      string // "csv", "sdf" o "delimited"
+	delimiter string // "" si los textos no llevan delimitador
+	separator string
+	codePage  *dbf.TextCodePage
+	utf8      bool
+}
+
+// textRecord es una línea leída del archivo.
+type textRecord struct {
+	line   int
+	values []string
+	quoted []bool
+}
+
+// textEOL es el fin de línea de los archivos que se escriben, como en VFP.
+const textEOL = "\r\n"
+
+func evalTextFormat(command string, format *ast.TextFormat, env *object.Environment) (*textFile, *object.Error) {
+	f := &textFile{kind: format.Type, delimiter: `"`, separator: ","}
+	if format.Delimiter != nil {
+		f.delimiter = *format.Delimiter
+	}
+	if format.Separator != "" {
+		f.separator = format.Separator
+	}
+	number := 65001
+	if format.CodePage != nil {
+		val := Eval(format.CodePage, env)
+		if errObj, ok := val.(*object.Error); ok {
+			return nil, errObj
+		}
+		num, ok := val.(*object.Integer)
+		if !ok {
+			return nil, object.NewError(fmt.Sprintf("%s: code page must be `number`, got `%s`", command, object.TypeToStr(val.Type())))
+		}
+		number = int(num.Value)
+	}
+	cp, ok := dbf.NewTextCodePage(number)
+	if !ok {
+		return nil, object.NewError(fmt.Sprintf("%s: code page %d is not supported", command, number))
+	}
+	f.codePage, f.utf8 = cp, number == 65001
+	return f, nil
+}
+
+// ext es la extensión por omisión del archivo.
+func (f *textFile) ext() string {
+	if f.kind == "csv" {
+		return ".csv"
+	}
+	return ".txt"
+}
+
+// evalCopyToStmt => Copy To archivo [Fields ...] [alcance] [For cond]
+// [While cond] Type Csv | Sdf | Delimited
+func evalCopyToStmt(node *ast.CopyToStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	format, errObj := evalTextFormat("COPY TO", &node.Format, env)
+	if errObj != nil {
+		return errObj
+	}
+	fileName, errObj := evalFileName(node.File, env, format.ext())
+	if errObj != nil {
+		return errObj
+	}
+	filter := node.Filter
+	filter.Memo = format.kind != "sdf"
+	fields, errObj := filterFields(wa, &filter)
+	if errObj != nil {
+		return errObj
+	}
+	if format.kind == "sdf" {
+		for _, name := range node.Filter.Fields {
+			if idx := wa.Table.FieldIndex(name); idx >= 0 && wa.Table.Fields[idx].Type == dbf.Memo {
+				return object.NewError(fmt.Sprintf("COPY TO: memo field `%s` cannot be copied to an SDF file", name))
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return object.NewError("COPY TO: there are no fields to copy")
+	}
+
+	if errObj := checkOverwrite("COPY TO", fileName, env); errObj != nil {
+		return errObj
+	}
+	file, err := os.Create(fileName)
+	if err != nil {
+		return object.NewError(fmt.Sprintf("cannot create file `%s`: %v", fileName, err))
+	}
+	defer file.Close()
+	out := bufio.NewWriter(file)
+	if format.kind == "csv" {
+		names := make([]string, len(fields))
+		for i, idx := range fields {
+			names[i] = strings.ToLower(wa.Table.Fields[idx].Name)
+		}
+		out.Write(format.codePage.Encode(strings.Join(names, format.separator) + textEOL))
+	}
+	count := 0
+	errObj = eachInScope(wa, node.Scope, node.For, node.While, env, func() *object.Error {
+		var line strings.Builder
+		for i, idx := range fields {
+			val := fieldValue(wa, idx)
+			if errObj, ok := val.(*object.Error); ok {
+				return errObj
+			}
+			if i > 0 && format.kind != "sdf" {
+				line.WriteString(format.separator)
+			}
+			line.WriteString(format.text(wa.Table.Fields[idx], val))
+		}
+		line.WriteString(textEOL)
+		out.Write(format.codePage.Encode(line.String()))
+		count++
+		return nil
+	})
+	if errObj != nil {
+		return errObj
+	}
+	if err := out.Flush(); err != nil {
+		return object.NewError(fmt.Sprintf("cannot write file `%s`: %v", fileName, err))
+	}
+	setTally(env, count)
+	return None
+}
+
+// text convierte el valor de un campo en el texto que se escribe.
+func (f *textFile) text(field *dbf.Field, val object.Object) string {
+	var text string
+	switch val := val.(type) {
+	case *object.String:
+		text = strings.TrimRight(val.Value, " ")
+		if field.Type == dbf.Character && f.kind == "sdf" {
+			return padText(text, field.Length)
+		}
+		if f.delimiter != "" {
+			text = f.delimiter + strings.ReplaceAll(text, f.delimiter, f.delimiter+f.delimiter) + f.delimiter
+		}
+		return text
+	case *object.Integer:
+		text = numberText(field, val.Value)
+		if f.kind == "sdf" {
+			width := sdfWidth(field)
+			if len(text) > width {
+				return strings.Repeat("*", width)
+			}
+			return strings.Repeat(" ", width-len(text)) + text
+		}
+		return text
+	case *object.Boolean:
+		text = "F"
+		if val.Value {
+			text = "T"
+		}
+	case *object.Date:
+		text = dateText(val, f.kind == "csv")
+	}
+	if f.kind == "sdf" {
+		return padText(text, sdfWidth(field))
+	}
+	return text
+}
+
+// numberText escribe un número con los decimales del campo.
+func numberText(field *dbf.Field, value float64) string {
+	decimals := field.Decimals
+	switch field.Type {
+	case dbf.Integer:
+		decimals = 0
+	case dbf.Currency:
+		decimals = 4
+	case dbf.Double:
+		if decimals == 0 {
+			decimals = -1
+		}
+	}
+	text := strconv.FormatFloat(value, 'f', decimals, 64)
+	if strings.Trim(text, "-0.") == "" {
+		text = strings.TrimPrefix(text, "-")
+	}
+	return text
+}
+
+func dateText(d *object.Date, iso bool) string {
+	if d.Value.IsZero() {
+		return ""
+	}
+	switch {
+	case iso && d.DateTime:
+		return d.Value.Format("2006-01-02 15:04:05")
+	case iso:
+		return d.Value.Format("2006-01-02")
+	case d.DateTime:
+		return d.Value.Format("20060102150405")
+	}
+	return d.Value.Format("20060102")
+}
+
+// sdfWidth es el ancho de un campo en un archivo SDF.
+func sdfWidth(field *dbf.Field) int {
+	switch field.Type {
+	case dbf.Integer:
+		return 11
+	case dbf.Currency, dbf.Double:
+		return 20
+	case dbf.Logical:
+		return 1
+	case dbf.Date:
+		return 8
+	case dbf.DateTime:
+		return 14
+	}
+	return field.Length
+}
+
+// padText completa o corta el texto hasta width caracteres.
+func padText(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) >= width {
+		return string(runes[:width])
+	}
+	return text + strings.Repeat(" ", width-len(runes))
+}
+
+// evalAppendFromStmt => Append From archivo [Fields campos] [For cond]
+// Type Csv | Sdf | Delimited
+// Los registros se agregan como con INSERT: se completan los valores por
+// omisión y se comprueban las reglas. Si un valor no se puede convertir al
+// tipo de su campo no se agrega ningún registro; si falla una regla, los
+// registros anteriores quedan en la tabla.
+func evalAppendFromStmt(node *ast.AppendFromStmt, env *object.Environment) object.Object {
+	wa, errObj := currentArea(env)
+	if errObj != nil {
+		return errObj
+	}
+	format, errObj := evalTextFormat("APPEND FROM", &node.Format, env)
+	if errObj != nil {
+		return errObj
+	}
+	name, errObj := evalFileName(node.File, env, format.ext())
+	if errObj != nil {
+		return errObj
+	}
+	fileName, errObj := resolveFile(name, format.ext(), env)
+	if errObj != nil {
+		return errObj
+	}
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return object.NewError(fmt.Sprintf("cannot read file `%s`: %v", fileName, err))
+	}
+	if format.utf8 {
+		data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+	}
+	// VFP termina los archivos de texto con Ctrl+Z
+	text := format.codePage.Decode(bytes.TrimRight(data, "\x1A"))
+
+	var targets []int
+	if len(node.Fields) > 0 {
+		for _, name := range node.Fields {
+			idx := wa.Table.FieldIndex(name)
+			if idx < 0 {
+				return object.NewError(fmt.Sprintf("APPEND FROM: field `%s` is not found", name))
+			}
+			if format.kind == "sdf" && wa.Table.Fields[idx].Type == dbf.Memo {
+				return object.NewError(fmt.Sprintf("APPEND FROM: memo field `%s` cannot be read from an SDF file", name))
+			}
+			targets = append(targets, idx)
+		}
+	} else {
+		for idx, field := range wa.Table.Fields {
+			if format.kind != "sdf" || field.Type != dbf.Memo {
+				targets = append(targets, idx)
+			}
+		}
+	}
+	var records []textRecord
+	if format.kind == "sdf" {
+		records = format.parseSdf(text, wa.Table.Fields, targets)
+	} else {
+		records = format.parseDelimited(text)
+	}
+	if format.kind == "csv" && len(records) > 0 {
+		header := records[0]
+		records = records[1:]
+		if len(node.Fields) == 0 {
+			targets = headerTargets(wa, header.values, targets)
+		}
+	}
+
+	// se convierte todo el archivo antes de agregar el primer registro
+	dates := env.Format()
+	rows := make([]map[int]object.Object, len(records))
+	for r, record := range records {
+		rows[r] = map[int]object.Object{}
+		for i, value := range record.values {
+			if i >= len(targets) || targets[i] < 0 {
+				continue
+			}
+			field := wa.Table.Fields[targets[i]]
+			val, ok := textValue(field, value, record.quoted[i], dates)
+			if !ok {
+				setTally(env, 0)
+				return object.NewError(fmt.Sprintf("APPEND FROM: line %d: invalid value `%s` for field `%s`", record.line, value, field.Name))
+			}
+			if val != nil {
+				rows[r][targets[i]] = val
+			}
+		}
+	}
+	count := 0
+	for r, record := range records {
+		added, errObj := insertRecordIf(wa, rows[r], node.For, env)
+		if errObj != nil {
+			setTally(env, count)
+			return object.NewError(fmt.Sprintf("APPEND FROM: line %d: %s", record.line, errObj.Message))
+		}
+		if added {
+			count++
+		}
+	}
+	setTally(env, count)
+	return None
+}
+
+// headerTargets asigna las columnas de un CSV a los campos con el mismo
+// nombre (-1 si no hay ninguno); si ningún nombre coincide se asignan por
+// posición.
+func headerTargets(wa *object.WorkArea, names []string, positional []int) []int {
+	targets := make([]int, len(names))
+	found := false
+	for i, name := range names {
+		targets[i] = wa.Table.FieldIndex(strings.TrimSpace(name))
+		found = found || targets[i] >= 0
+	}
+	if !found {
+		return positional
+	}
+	return targets
+}
+
+// parseDelimited separa las líneas de un archivo CSV o DELIMITED en sus
+// valores. Los valores entre delimitadores pueden tener separadores y
+// saltos de línea, y el delimitador escrito dos veces.
+func (f *textFile) parseDelimited(text string) []textRecord {
+	var records []textRecord
+	runes := []rune(text)
+	delim, sep := []rune(f.delimiter), []rune(f.separator)[0]
+	line := 1
+	record := textRecord{line: line}
+	var value strings.Builder
+	quoted := false
+	endValue := func() {
+		text := value.String()
+		if !quoted && f.separator != " " {
+			text = strings.TrimSpace(text)
+		}
+		record.values = append(record.values, text)
+		record.quoted = append(record.quoted, quoted)
+		value.Reset()
+		quoted = false
+	}
+	endRecord := func() {
+		endValue()
+		// las líneas vacías no son registros
+		if len(record.values) > 1 || record.quoted[0] || record.values[0] != "" {
+			records = append(records, record)
+		}
+		record = textRecord{line: line}
+	}
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case len(delim) > 0 && r == delim[0] && strings.TrimSpace(value.String()) == "" && !quoted:
+			value.Reset()
+			quoted = true
+			for i++; i < len(runes); i++ {
+				if runes[i] == delim[0] {
+					if i+1 < len(runes) && runes[i+1] == delim[0] {
+						i++
+					} else {
+						break
+					}
+				} else if runes[i] == '\n' {
+					line++
+				}
+				value.WriteRune(runes[i])
+			}
+		case r == sep:
+			endValue()
+		case r == '\r' || r == '\n':
+			if r == '\r' && i+1 < len(runes) && runes[i+1] == '\n' {
+				i++
+			}
+			line++
+			endRecord()
+		case quoted:
+			// lo que sigue al delimitador de cierre no es parte del valor
+		default:
+			value.WriteRune(r)
+		}
+	}
+	if value.Len() > 0 || quoted || len(record.values) > 0 {
+		endRecord()
+	}
+	return records
+}
+
+// parseSdf separa las líneas de un archivo SDF en los valores de los
+// campos targets según sus anchos.
+func (f *textFile) parseSdf(text string, fields []*dbf.Field, targets []int) []textRecord {
+	var records []textRecord
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	for n, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		runes := []rune(line)
+		record := textRecord{line: n + 1}
+		pos := 0
+		for _, idx := range targets {
+			end := min(pos+sdfWidth(fields[idx]), len(runes))
+			value := ""
+			if pos < end {
+				value = string(runes[pos:end])
+			}
+			record.values = append(record.values, value)
+			record.quoted = append(record.quoted, false)
+			pos = end
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// textValue convierte el texto de un valor al tipo del campo; nil si el
+// valor está vacío. ok es false si el texto no es válido para el campo.
+// Las fechas se leen en el orden de SET DATE de format.
+func textValue(field *dbf.Field, text string, quoted bool, format *object.Format) (object.Object, bool) {
+	if field.Type == dbf.Character || field.Type == dbf.Memo {
+		if !quoted {
+			text = strings.TrimRight(text, " ")
+		}
+		if text == "" {
+			return nil, true
+		}
+		return &object.String{Value: text}, true
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, true
+	}
+	switch field.Type {
+	case dbf.Logical:
+		switch strings.ToUpper(strings.Trim(text, ".")) {
+		case "T", "Y", "S", "TRUE", "YES", "SI", "SÍ", "1":
+			return True, true
+		case "F", "N", "FALSE", "NO", "0":
+			return False, true
+		}
+	case dbf.Date, dbf.DateTime:
+		if t, ok := format.ParseDate(text); ok {
+			if field.Type == dbf.Date {
+				t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+			}
+			return &object.Date{Value: t, DateTime: field.Type == dbf.DateTime}, true
+		}
+	default:
+		if num, err := strconv.ParseFloat(text, 64); err == nil && !math.IsInf(num, 0) && !math.IsNaN(num) {
+			return &object.Integer{Value: num}, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,107 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"fmt"
+)
+
+// Transacciones: cada tabla abierta (y las que se abren durante la
+// transacción) guarda en su diario el contenido original de lo que se
+// modifica. END TRANSACTION confirma los cambios de todas las tablas a la
+// vez y ROLLBACK los deshace; si el programa se interrumpe antes, la tabla
+// se restaura al volver a abrirla. Los bloqueos de las tablas compartidas
+// se mantienen hasta que termina la transacción más externa.
+
+// maxTransactionLevel es la cantidad máxima de transacciones anidadas.
+const maxTransactionLevel = 5
+
+func evalTransactionStmt(node *ast.TransactionStmt, env *object.Environment) object.Object {
+	level := env.TransactionLevel()
+	switch node.Action {
+	case "begin":
+		if level == maxTransactionLevel {
+			return object.NewError(fmt.Sprintf("BEGIN TRANSACTION: too many nested transactions (the maximum is %d)", maxTransactionLevel))
+		}
+		areas := env.WorkAreas()
+		for i, wa := range areas {
+			if err := wa.Table.BeginTransaction(); err != nil {
+				for _, begun := range areas[:i] {
+					begun.Table.RollbackTransaction()
+				}
+				return tableError(wa, err)
+			}
+		}
+		env.SetTransactionLevel(level + 1)
+		return None
+	case "end":
+		if level == 0 {
+			return object.NewError("END TRANSACTION: no transaction is in progress")
+		}
+		env.SetTransactionLevel(level - 1)
+		areas := env.WorkAreas()
+		tables := make([]*dbf.Table, len(areas))
+		for i, wa := range areas {
+			tables[i] = wa.Table
+		}
+		var errObj *object.Error
+		if err := dbf.EndTransactions(tables); err != nil {
+			errObj = object.NewError(fmt.Sprintf("END TRANSACTION: %v", err))
+		}
+		for _, wa := range areas {
+			// si no se pudo confirmar, las tablas se restauraron (el error
+			// que se informa es el de la confirmación)
+			if errObj != nil {
+				restoredArea(wa, env)
+			}
+			if e := releaseTransactionLocks(wa, env); e != nil && errObj == nil {
+				errObj = e
+			}
+		}
+		return commandResult(errObj)
+	}
+	if level == 0 {
+		return object.NewError("ROLLBACK: no transaction is in progress")
+	}
+	env.SetTransactionLevel(level - 1)
+	var errObj *object.Error
+	for _, wa := range env.WorkAreas() {
+		if err := wa.Table.RollbackTransaction(); err != nil {
+			if errObj == nil {
+				errObj = tableError(wa, err)
+			}
+			continue
+		}
+		if e := restoredArea(wa, env); e != nil && errObj == nil {
+			errObj = e
+		}
+		if e := releaseTransactionLocks(wa, env); e != nil && errObj == nil {
+			errObj = e
+		}
+	}
+	return commandResult(errObj)
+}
+
+// restoredArea reconstruye el índice con los registros restaurados de la
+// tabla y deja el puntero dentro de la tabla.
+func restoredArea(wa *object.WorkArea, env *object.Environment) *object.Error {
+	if wa.Table.IndexStale() {
+		if errObj := reindexTable(wa, env); errObj != nil {
+			return errObj
+		}
+	}
+	if wa.Recno > wa.Table.RecordCount()+1 {
+		wa.Recno = wa.Table.RecordCount() + 1
+	}
+	return nil
+}
+
+// releaseTransactionLocks libera los bloqueos de la tabla al terminar la
+// transacción más externa.
+func releaseTransactionLocks(wa *object.WorkArea, env *object.Environment) *object.Error {
+	if env.TransactionLevel() > 0 {
+		return nil
+	}
+	return unlockArea(wa)
+}
@@ -3,12 +3,27 @@ package evaluator
 import (
 	"FoxLite/src/ast"
 	"FoxLite/src/object"
+	"FoxLite/src/token"
 )
 
 func evalVarStmt(node *ast.VarStmt, env *object.Environment) object.Object {
-	val := Eval(node.Value, env)
-	if isError(val) {
-		return val
+	var val object.Object = False // las variables declaradas sin valor valen False
+	if node.Value != nil {
+		val = Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
 	}
-	return env.Set(node.Name, node.Scope, val)
+	switch node.Token.Type {
+	case token.Local, token.Private:
+		return env.Declare(node.Name, node.Scope, val)
+	case token.Public:
+		// declarar de nuevo una variable pública sin valor la conserva
+		if node.Value == nil && env.Get(node.Name) != nil {
+			return None
+		}
+		return env.Declare(node.Name, node.Scope, val)
+	}
+	// asignación sin declaración previa: lnTotal = 10
+	return env.Set(node.Name, val)
 }
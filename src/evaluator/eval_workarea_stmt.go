@@ -0,0 +1,160 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func evalSelectStmt(node *ast.SelectStmt, env *object.Environment) object.Object {
+	number, errObj := workAreaNumber(node.Area, env)
+	if errObj != nil {
+		return errObj
+	}
+	env.SelectArea(number)
+	return None
+}
+
+// workAreaNumber evalúa el área de trabajo de un comando: un número (0 es
+// el área libre más baja), un alias o las letras A a J de las áreas 1 a 10.
+func workAreaNumber(exp ast.Expression, env *object.Environment) (int, *object.Error) {
+	val := Eval(exp, env)
+	if errObj, ok := val.(*object.Error); ok {
+		return 0, errObj
+	}
+	switch val := val.(type) {
+	case *object.Integer:
+		number := int(val.Value)
+		if number == 0 {
+			number, err := env.UnusedArea()
+			if err != nil {
+				return 0, object.NewError(err.Error())
+			}
+			return number, nil
+		}
+		if number < 0 || number > object.MaxWorkArea {
+			return 0, object.NewError(fmt.Sprintf("work area %d is out of range", number))
+		}
+		return number, nil
+	case *object.String:
+		alias := strings.TrimSpace(val.Value)
+		if wa := env.AreaByAlias(alias); wa != nil {
+			return wa.Number, nil
+		}
+		if len(alias) == 1 {
+			if letter := strings.ToUpper(alias)[0]; letter >= 'A' && letter <= 'J' {
+				return int(letter-'A') + 1, nil
+			}
+		}
+		return 0, object.NewError(fmt.Sprintf("alias `%s` is not found", alias))
+	}
+	return 0, object.NewError(fmt.Sprintf("expecting a work area or an alias, got `%s`", object.TypeToStr(val.Type())))
+}
+
+// evalCreateCursorStmt crea una tabla temporal que se borra al cerrarla.
+func evalCreateCursorStmt(node *ast.CreateCursorStmt, env *object.Environment) object.Object {
+	name := Eval(node.Name, env)
+	if isError(name) {
+		return name
+	}
+	str, ok := name.(*object.String)
+	if !ok || strings.TrimSpace(str.Value) == "" {
+		return object.NewError("CREATE CURSOR: expecting a cursor name")
+	}
+	alias := strings.ToUpper(strings.TrimSpace(str.Value))
+	fields := fieldDefs(node.Fields, env)
+	table, errObj := createTempTable(fields)
+	if errObj != nil {
+		return errObj
+	}
+	return openCursor(alias, table, env)
+}
+
+// openCursor abre la tabla temporal de un cursor en exclusiva en el área
+// libre más baja; un cursor nuevo reemplaza al anterior con el mismo alias.
+func openCursor(alias string, table *dbf.Table, env *object.Environment) object.Object {
+	if other := env.AreaByAlias(alias); other != nil {
+		if err := env.CloseArea(other.Number); err != nil {
+			table.Drop()
+			return object.NewError(err.Error())
+		}
+	}
+	number, err := env.UnusedArea()
+	if err != nil {
+		table.Drop()
+		return object.NewError(err.Error())
+	}
+	wa := &object.WorkArea{
+		Number:    number,
+		Alias:     alias,
+		Table:     table,
+		Exclusive: true,
+		Temporary: true,
+	}
+	if err := env.OpenArea(wa); err != nil {
+		return object.NewError(fmt.Sprintf("cannot open cursor: %v", err))
+	}
+	env.SelectArea(wa.Number)
+	return commandResult(goTop(wa, env))
+}
+
+// fieldDefs convierte las definiciones de campos del comando; sin NULL ni
+// NOT NULL el campo admite null según SET NULL.
+func fieldDefs(defs []*ast.FieldDef, env *object.Environment) []dbf.Field {
+	fields := make([]dbf.Field, len(defs))
+	for i, def := range defs {
+		fields[i] = dbf.Field{
+			Name:     def.Name,
+			Type:     def.Type,
+			Length:   def.Length,
+			Decimals: def.Decimals,
+		}
+		if def.Null || (!def.NotNull && isOptionOn(env, "null")) {
+			fields[i].Flags |= dbf.FlagNullable
+		}
+	}
+	return fields
+}
+
+// createTempTable crea una tabla en el directorio temporal del sistema.
+func createTempTable(fields []dbf.Field) (*dbf.Table, *object.Error) {
+	file, err := os.CreateTemp("", "foxlite_*.dbf")
+	if err != nil {
+		return nil, object.NewError(fmt.Sprintf("cannot create temporary table: %v", err))
+	}
+	path := file.Name()
+	file.Close()
+	table, err := dbf.Create(path, fields)
+	if err != nil {
+		os.Remove(path)
+		return nil, object.NewError(fmt.Sprintf("cannot create temporary table: %v", err))
+	}
+	return table, nil
+}
+
+// evalCloseStmt cierra las tablas de todas las áreas; CLOSE DATABASES
+// cierra además la base de datos actual (todas con ALL) y CLOSE ALL todas
+// las bases de datos.
+func evalCloseStmt(node *ast.CloseStmt, env *object.Environment) object.Object {
+	if err := env.CloseAreas(); err != nil {
+		return object.NewError(err.Error())
+	}
+	switch {
+	case node.What == "all" || (node.What == "databases" && node.All):
+		if err := env.CloseDatabases(); err != nil {
+			return object.NewError(err.Error())
+		}
+	case node.What == "databases" && env.CurrentDatabase() != nil:
+		if err := env.CloseDatabase(env.CurrentDatabase()); err != nil {
+			return object.NewError(err.Error())
+		}
+	}
+	// CLOSE ALL y CLOSE DATABASES vuelven a seleccionar el área 1
+	if node.What != "tables" {
+		env.SelectArea(1)
+	}
+	return None
+}
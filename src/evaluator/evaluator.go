@@ -26,10 +26,16 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalReturnStmt(node, env)
 	case *ast.InfixExp:
 		return evalInfixExp(node, env)
+	case *ast.PrefixExp:
+		return evalPrefixExp(node, env)
 	case *ast.VarStmt:
 		return evalVarStmt(node, env)
+	case *ast.PrivateAllStmt:
+		return evalPrivateAllStmt(node, env)
 	case *ast.IfStmt:
 		return evalIfExp(node, env)
+	case *ast.IifExp:
+		return evalIifExp(node, env)
 	case *ast.FunctionLiteral:
 		return evalFunctionLiteral(node, env)
 	case *ast.CallExp:
@@ -40,6 +46,16 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalDoCaseStmt(node, env)
 	case *ast.While:
 		return evalWhileStmt(node, env)
+	case *ast.For:
+		return evalForStmt(node, env)
+	case *ast.ParametersStmt:
+		return evalParametersStmt(node, env)
+	case *ast.AssignStmt:
+		return evalAssignStmt(node, env)
+	case *ast.DoStmt:
+		return evalDoStmt(node, env)
+	case *ast.ReferenceExp:
+		return object.NewError(fmt.Sprintf("`%s`: references can only be passed as arguments", node.String()))
 	case *ast.Loop:
 		return &object.Loop{}
 	case *ast.Exit:
@@ -48,6 +64,122 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalInputStmt(node, env)
 	case *ast.Class:
 		return evalClassStmt(node, env)
+	case *ast.SetStmt:
+		return evalSetStmt(node, env)
+	case *ast.PushSetStmt:
+		return evalPushSetStmt(node, env)
+	case *ast.SetFileStmt:
+		return evalSetFileStmt(node, env)
+	case *ast.ArrayLiteral:
+		return evalArrayLiteral(node, env)
+	case *ast.IndexExp:
+		return evalIndexExp(node, env)
+	case *ast.ReleaseStmt:
+		return evalReleaseStmt(node, env)
+	case *ast.ClearMemoryStmt:
+		return evalClearMemoryStmt(node, env)
+	case *ast.ListMemoryStmt:
+		return evalListMemoryStmt(node, env)
+	case *ast.SaveStmt:
+		return evalSaveStmt(node, env)
+	case *ast.RestoreStmt:
+		return evalRestoreStmt(node, env)
+	case *ast.UseStmt:
+		return evalUseStmt(node, env)
+	case *ast.AppendBlankStmt:
+		return evalAppendBlankStmt(node, env)
+	case *ast.AppendMemoStmt:
+		return evalAppendMemoStmt(node, env)
+	case *ast.CopyMemoStmt:
+		return evalCopyMemoStmt(node, env)
+	case *ast.CopyToStmt:
+		return evalCopyToStmt(node, env)
+	case *ast.AppendFromStmt:
+		return evalAppendFromStmt(node, env)
+	case *ast.ReplaceStmt:
+		return evalReplaceStmt(node, env)
+	case *ast.DeleteStmt:
+		return evalDeleteStmt(node, env)
+	case *ast.PackStmt:
+		return evalPackStmt(node, env)
+	case *ast.GoStmt:
+		return evalGoStmt(node, env)
+	case *ast.SkipStmt:
+		return evalSkipStmt(node, env)
+	case *ast.SelectStmt:
+		return evalSelectStmt(node, env)
+	case *ast.CreateCursorStmt:
+		return evalCreateCursorStmt(node, env)
+	case *ast.CloseStmt:
+		return evalCloseStmt(node, env)
+	case *ast.ScanStmt:
+		return evalScanStmt(node, env)
+	case *ast.LocateStmt:
+		return evalLocateStmt(node, env)
+	case *ast.ContinueStmt:
+		return evalContinueStmt(node, env)
+	case *ast.SetFilterStmt:
+		return evalSetFilterStmt(node, env)
+	case *ast.SetRelationStmt:
+		return evalSetRelationStmt(node, env)
+	case *ast.IndexStmt:
+		return evalIndexStmt(node, env)
+	case *ast.ReindexStmt:
+		return evalReindexStmt(node, env)
+	case *ast.DeleteTagStmt:
+		return evalDeleteTagStmt(node, env)
+	case *ast.SetOrderStmt:
+		return evalSetOrderStmt(node, env)
+	case *ast.SeekStmt:
+		return evalSeekStmt(node, env)
+	case *ast.ScatterStmt:
+		return evalScatterStmt(node, env)
+	case *ast.GatherStmt:
+		return evalGatherStmt(node, env)
+	case *ast.TransactionStmt:
+		return evalTransactionStmt(node, env)
+	case *ast.UnlockStmt:
+		return evalUnlockStmt(node, env)
+	case *ast.AggregateStmt:
+		return evalAggregateStmt(node, env)
+	case *ast.TotalStmt:
+		return evalTotalStmt(node, env)
+	case *ast.SqlSelectStmt:
+		return evalSqlSelectStmt(node, env)
+	case *ast.SqlInsertStmt:
+		return evalSqlInsertStmt(node, env)
+	case *ast.SqlUpdateStmt:
+		return evalSqlUpdateStmt(node, env)
+	case *ast.SqlDeleteStmt:
+		return evalSqlDeleteStmt(node, env)
+	case *ast.CreateTableStmt:
+		return evalCreateTableStmt(node, env)
+	case *ast.AlterTableStmt:
+		return evalAlterTableStmt(node, env)
+	case *ast.CreateDatabaseStmt:
+		return evalCreateDatabaseStmt(node, env)
+	case *ast.OpenDatabaseStmt:
+		return evalOpenDatabaseStmt(node, env)
+	case *ast.SetDatabaseStmt:
+		return evalSetDatabaseStmt(node, env)
+	case *ast.AddTableStmt:
+		return evalAddTableStmt(node, env)
+	case *ast.SqlAggregateExp:
+		return evalSqlAggregateExp(node, env)
+	case *ast.SqlSubqueryExp:
+		return evalSqlSubqueryExp(node, env)
+	case *ast.SqlInExp:
+		return evalSqlInExp(node, env)
+	case *ast.SqlBetweenExp:
+		return evalSqlBetweenExp(node, env)
+	case *ast.SqlLikeExp:
+		return evalSqlLikeExp(node, env)
+	case *ast.SqlIsNullExp:
+		return evalSqlIsNullExp(node, env)
+	case *ast.SqlExistsExp:
+		return evalSqlExistsExp(node, env)
+	case *ast.SqlNotExp:
+		return evalSqlNotExp(node, env)
 	default:
 		return None
 	}
@@ -60,6 +192,14 @@ func isError(obj object.Object) bool {
 	return false
 }
 
+// toBoolean convierte un bool nativo en el objeto True o False.
+func toBoolean(value bool) object.Object {
+	if value {
+		return True
+	}
+	return False
+}
+
 func reportInfixError(lType object.ObjType, rType object.ObjType) object.Object {
 	if lType == object.StringObj || lType == object.IntegerObj {
 		return object.NewError(fmt.Sprintf("infix expr: cannot use `%s` (right expression) as `%s`", object.TypeToStr(rType), object.TypeToStr(lType)))
@@ -0,0 +1,224 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// indexExprs guarda las expresiones de los tags ya compiladas.
+var indexExprs = map[string]ast.Expression{}
+
+// indexExpression compila (una sola vez) la expresión de un tag.
+func indexExpression(src string) (ast.Expression, *object.Error) {
+	if exp, ok := indexExprs[src]; ok {
+		return exp, nil
+	}
+	exp, errObj := compileExpression(src)
+	if errObj != nil {
+		return nil, errObj
+	}
+	indexExprs[src] = exp
+	return exp, nil
+}
+
+// orderTag devuelve el tag que controla el orden del área o nil.
+func orderTag(wa *object.WorkArea) *dbf.Tag {
+	if wa.Order == "" {
+		return nil
+	}
+	return wa.Table.Tag(wa.Order)
+}
+
+// tagArg busca un tag por su número (desde 1) o su nombre.
+func tagArg(wa *object.WorkArea, arg object.Object) (*dbf.Tag, *object.Error) {
+	switch arg := arg.(type) {
+	case *object.Integer:
+		tags := wa.Table.Tags()
+		n := int(arg.Value)
+		if n < 1 || n > len(tags) {
+			return nil, object.NewError(fmt.Sprintf("%s: index %d is not found", wa.Alias, n))
+		}
+		return tags[n-1], nil
+	case *object.String:
+		tag := wa.Table.Tag(arg.Value)
+		if tag == nil {
+			return nil, object.NewError(fmt.Sprintf("%s: tag `%s` is not found", wa.Alias, arg.Value))
+		}
+		return tag, nil
+	}
+	return nil, object.NewError(fmt.Sprintf("expecting a tag name or number, got `%s`", object.TypeToStr(arg.Type())))
+}
+
+// keyValue convierte el valor de una clave de índice al tipo de la tabla.
+func keyValue(obj object.Object) (interface{}, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Null:
+		return nil, nil
+	case *object.String:
+		return obj.Value, nil
+	case *object.Integer:
+		return obj.Value, nil
+	case *object.Boolean:
+		return obj.Value, nil
+	case *object.Date:
+		t := obj.Value
+		if !obj.DateTime && !t.IsZero() {
+			// las fechas se comparan por día, como en los campos D
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		return t, nil
+	}
+	return nil, object.NewError(fmt.Sprintf("invalid index key type `%s`", object.TypeToStr(obj.Type())))
+}
+
+// tagKey calcula la clave del registro actual de wa y si cumple la
+// condición FOR del tag.
+func tagKey(wa *object.WorkArea, tag *dbf.Tag, env *object.Environment) (interface{}, bool, *object.Error) {
+	if tag.For != "" {
+		cond, errObj := indexExpression(tag.For)
+		if errObj != nil {
+			return nil, false, errObj
+		}
+		ok, errObj := evalCondition(cond, wa, env, "INDEX FOR")
+		if errObj != nil || !ok {
+			return nil, false, errObj
+		}
+	}
+	exp, errObj := indexExpression(tag.Expr)
+	if errObj != nil {
+		return nil, false, errObj
+	}
+	val := evalInArea(exp, wa, env)
+	if errObj, ok := val.(*object.Error); ok {
+		return nil, false, errObj
+	}
+	key, errObj := keyValue(val)
+	if errObj != nil {
+		return nil, false, errObj
+	}
+	return key, true, nil
+}
+
+// updateIndexes actualiza las claves del registro actual en todos los tags
+// de la tabla después de modificarlo. Si al escribir se vieron cambios de
+// otros procesos se reconstruyen los tags completos.
+func updateIndexes(wa *object.WorkArea, env *object.Environment) *object.Error {
+	if wa.Table.IndexStale() {
+		return reindexTable(wa, env)
+	}
+	for _, tag := range wa.Table.Tags() {
+		key, ok, errObj := tagKey(wa, tag, env)
+		if errObj != nil {
+			return errObj
+		}
+		if err := tag.Set(wa.Recno, key, ok); err != nil {
+			return tableError(wa, err)
+		}
+	}
+	return nil
+}
+
+// buildTag reconstruye un tag evaluando su expresión en todos los registros.
+func buildTag(wa *object.WorkArea, tag *dbf.Tag, env *object.Environment) *object.Error {
+	recno, bof := wa.Recno, wa.Bof
+	defer func() { wa.Recno, wa.Bof = recno, bof }()
+	var keyErr *object.Error
+	err := tag.Build(wa.Table.RecordCount(), func(recno int) (interface{}, bool, error) {
+		wa.Recno = recno
+		key, ok, errObj := tagKey(wa, tag, env)
+		if errObj != nil {
+			keyErr = errObj
+			return nil, false, errors.New(errObj.Message)
+		}
+		return key, ok, nil
+	})
+	if keyErr != nil {
+		return keyErr
+	}
+	if err != nil {
+		return tableError(wa, err)
+	}
+	return nil
+}
+
+// reindexTable reconstruye todos los tags de la tabla.
+func reindexTable(wa *object.WorkArea, env *object.Environment) *object.Error {
+	for _, tag := range wa.Table.Tags() {
+		if errObj := buildTag(wa, tag, env); errObj != nil {
+			return errObj
+		}
+	}
+	wa.Table.ReindexDone()
+	return nil
+}
+
+// seekKey busca la clave en el tag teniendo en cuenta SET EXACT, SET
+// DELETED y el filtro del área; devuelve 0 si no la encuentra.
+func seekKey(wa *object.WorkArea, tag *dbf.Tag, key object.Object, env *object.Environment) (int, *object.Error) {
+	if errObj := refreshArea(wa, env); errObj != nil {
+		return 0, errObj
+	}
+	value, errObj := keyValue(key)
+	if errObj != nil {
+		return 0, errObj
+	}
+	if tag.KeyType() == 0 {
+		if errObj := checkKeyType(wa, tag, value, env); errObj != nil {
+			return 0, errObj
+		}
+	}
+	var visibleErr *object.Error
+	recno, err := tag.Seek(value, !isOptionOn(env, "exact"), func(recno int) bool {
+		if visibleErr != nil {
+			return false
+		}
+		ok, errObj := visible(wa, env, recno)
+		visibleErr = errObj
+		return ok
+	})
+	if visibleErr != nil {
+		return 0, visibleErr
+	}
+	if err != nil {
+		return 0, object.NewError(fmt.Sprintf("%s: %v", wa.Alias, err))
+	}
+	return recno, nil
+}
+
+// checkKeyType comprueba el tipo de la clave buscada en un tag que todavía
+// no tiene claves (Seek solo lo comprueba contra las claves indexadas):
+// el tipo del tag es el de su expresión en el registro actual, que en una
+// tabla vacía es el registro en blanco del fin de archivo.
+func checkKeyType(wa *object.WorkArea, tag *dbf.Tag, value interface{}, env *object.Environment) *object.Error {
+	exp, errObj := indexExpression(tag.Expr)
+	if errObj != nil {
+		return errObj
+	}
+	sample, errObj := keyValue(evalInArea(exp, wa, env))
+	if errObj != nil {
+		return nil // la expresión no se puede evaluar aquí: no hay con qué comparar
+	}
+	want, got := dbf.KeyTypeOf(sample), dbf.KeyTypeOf(value)
+	if want != 0 && got != 0 && want != got {
+		return object.NewError(fmt.Sprintf("%s: data type mismatch in SEEK on tag `%s`", wa.Alias, tag.Name))
+	}
+	return nil
+}
+
+// seekRecord busca la clave y deja el puntero en el registro encontrado o
+// en el fin de archivo; actualiza FOUND().
+func seekRecord(wa *object.WorkArea, tag *dbf.Tag, key object.Object, env *object.Environment) *object.Error {
+	recno, errObj := seekKey(wa, tag, key, env)
+	if errObj != nil {
+		return errObj
+	}
+	wa.Found = recno != 0
+	if recno == 0 {
+		recno = wa.Table.RecordCount() + 1
+	}
+	return goRecord(wa, env, recno)
+}
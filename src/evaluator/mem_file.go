@@ -0,0 +1,220 @@
+package evaluator
+
+import (
+	"FoxLite/src/object"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Formato de los archivos de memoria (SAVE TO / RESTORE FROM).
+//
+// Es un archivo de texto UTF-8 con un objeto JSON por línea. La primera
+// línea es la cabecera:
+//
+//	{"format":"FoxLite MEM","version":1}
+//
+// y cada una de las siguientes guarda una variable:
+//
+//	{"name":"lnTotal","scope":"p","value":150.5}
+//	{"name":"gaLista","scope":"g","value":[1,"dos",true,null]}
+//	{"name":"ldAlta","scope":"p","value":{"date":"2024-03-15"}}
+//	{"name":"ltHora","scope":"p","value":{"datetime":"2024-03-15T10:30:00"}}
+//
+// scope es "l" (local), "p" (private) o "g" (public); value es un número,
+// un string, un lógico, null, una fecha, una fecha y hora (las vacías son
+// "") o un arreglo (que puede anidar arreglos). Las funciones y clases no se
+// guardan; cualquier otro valor (un objeto) es un error.
+//
+// La versión 1 no tenía fechas.
+
+const (
+	memFormat  = "FoxLite MEM"
+	memVersion = 2
+
+	memDateLayout     = "2006-01-02"
+	memDateTimeLayout = "2006-01-02T15:04:05"
+)
+
+type memHeader struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+}
+
+type memRecord struct {
+	Name  string      `json:"name"`
+	Scope string      `json:"scope"`
+	Value interface{} `json:"value"`
+}
+
+// memVar es una variable leída de un archivo de memoria.
+type memVar struct {
+	name  string
+	scope byte
+	value object.Object
+}
+
+func writeMemFile(fileName string, vars map[string]*object.Vector) *object.Error {
+	// se convierten todas las variables antes de crear el archivo
+	var records []memRecord
+	for _, name := range sortedNames(vars) {
+		vec := vars[name]
+		if vec.Value.Type() == object.FuncObj {
+			continue
+		}
+		value, ok := toMemValue(vec.Value)
+		if !ok {
+			return object.NewError(fmt.Sprintf("SAVE TO: variable `%s` has a value that cannot be saved", name))
+		}
+		records = append(records, memRecord{Name: name, Scope: string(vec.Scope), Value: value})
+	}
+	file, err := os.Create(fileName)
+	if err != nil {
+		return object.NewError(fmt.Sprintf("cannot create file `%s`: %v", fileName, err))
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(memHeader{Format: memFormat, Version: memVersion}); err != nil {
+		return object.NewError(fmt.Sprintf("cannot write file `%s`: %v", fileName, err))
+	}
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return object.NewError(fmt.Sprintf("cannot write file `%s`: %v", fileName, err))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return object.NewError(fmt.Sprintf("cannot write file `%s`: %v", fileName, err))
+	}
+	return nil
+}
+
+func readMemFile(fileName string) ([]memVar, *object.Error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, object.NewError(fmt.Sprintf("file `%s` does not exist", fileName))
+	}
+	defer file.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(file))
+	var header memHeader
+	if err := dec.Decode(&header); err != nil || header.Format != memFormat {
+		return nil, object.NewError(fmt.Sprintf("`%s` is not a memory file", fileName))
+	}
+	if header.Version > memVersion {
+		return nil, object.NewError(fmt.Sprintf("`%s`: unsupported memory file version %d", fileName, header.Version))
+	}
+	var vars []memVar
+	for dec.More() {
+		var record memRecord
+		if err := dec.Decode(&record); err != nil {
+			return nil, object.NewError(fmt.Sprintf("`%s`: invalid memory file: %v", fileName, err))
+		}
+		if record.Name == "" || len(record.Scope) != 1 {
+			return nil, object.NewError(fmt.Sprintf("`%s`: invalid memory variable record", fileName))
+		}
+		value, ok := fromMemValue(record.Value)
+		if !ok {
+			return nil, object.NewError(fmt.Sprintf("`%s`: invalid value for variable `%s`", fileName, record.Name))
+		}
+		vars = append(vars, memVar{
+			name:  record.Name,
+			scope: record.Scope[0],
+			value: value,
+		})
+	}
+	return vars, nil
+}
+
+// toMemValue convierte un objeto en un valor que se puede codificar en JSON.
+func toMemValue(obj object.Object) (interface{}, bool) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return obj.Value, true
+	case *object.String:
+		return obj.Value, true
+	case *object.Boolean:
+		return obj.Value, true
+	case *object.Null:
+		return nil, true
+	case *object.Date:
+		key, layout := "date", memDateLayout
+		if obj.DateTime {
+			key, layout = "datetime", memDateTimeLayout
+		}
+		text := ""
+		if !obj.Value.IsZero() {
+			text = obj.Value.Format(layout)
+		}
+		return map[string]string{key: text}, true
+	case *object.Reference:
+		return toMemValue(obj.Vector.Value)
+	case *object.Array:
+		elements := make([]interface{}, 0, len(obj.Elements))
+		for _, el := range obj.Elements {
+			value, ok := toMemValue(el)
+			if !ok {
+				return nil, false
+			}
+			elements = append(elements, value)
+		}
+		return elements, true
+	}
+	return nil, false
+}
+
+// fromMemValue convierte un valor leído del archivo en un objeto; false si
+// el valor no es de ningún tipo conocido.
+func fromMemValue(value interface{}) (object.Object, bool) {
+	switch value := value.(type) {
+	case nil:
+		return Null, true
+	case float64:
+		return &object.Integer{Value: value}, true
+	case string:
+		return &object.String{Value: value}, true
+	case bool:
+		return toBoolean(value), true
+	case []interface{}:
+		elements := make([]object.Object, 0, len(value))
+		for _, el := range value {
+			obj, ok := fromMemValue(el)
+			if !ok {
+				return nil, false
+			}
+			elements = append(elements, obj)
+		}
+		return &object.Array{Elements: elements}, true
+	case map[string]interface{}:
+		if len(value) != 1 {
+			return nil, false
+		}
+		for key, raw := range value {
+			text, isStr := raw.(string)
+			layout := memDateLayout
+			switch {
+			case !isStr:
+				return nil, false
+			case key == "datetime":
+				layout = memDateTimeLayout
+			case key != "date":
+				return nil, false
+			}
+			date := &object.Date{DateTime: key == "datetime"}
+			if text == "" {
+				return date, true
+			}
+			t, err := time.Parse(layout, text)
+			if err != nil {
+				return nil, false
+			}
+			date.Value = t
+			return date, true
+		}
+	}
+	return nil, false
+}
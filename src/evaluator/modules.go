@@ -0,0 +1,192 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/lexer"
+	"FoxLite/src/object"
+	"FoxLite/src/parser"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// module es un programa ya analizado. Los programas y librerías se analizan
+// una sola vez y se vuelven a analizar solo si el archivo cambia.
+type module struct {
+	program *ast.Program
+	modTime time.Time
+	size    int64
+}
+
+var moduleCache = map[string]*module{}
+
+// loadProgram devuelve el programa analizado de un archivo.
+func loadProgram(fileName string) (*ast.Program, *object.Error) {
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return nil, object.NewError(fmt.Sprintf("file `%s` does not exist", fileName))
+	}
+	if m, ok := moduleCache[fileName]; ok && m.modTime.Equal(info.ModTime()) && m.size == info.Size() {
+		return m.program, nil
+	}
+	l := lexer.New()
+	l.ScanFile(fileName)
+	p := parser.New(l)
+	program := p.Parse()
+	if errors := p.Errors(); len(errors) > 0 {
+		errObj := object.NewError("syntax error:\n" + strings.TrimRight(strings.Join(errors, ""), "\n"))
+		errObj.File = fileName
+		return nil, errObj
+	}
+	moduleCache[fileName] = &module{program: program, modTime: info.ModTime(), size: info.Size()}
+	return program, nil
+}
+
+// resolveFile busca un archivo: primero en la carpeta del archivo que se
+// está ejecutando, luego en la carpeta actual y por último en las carpetas
+// de SET PATH. Si el nombre no tiene extensión se le agrega ext.
+func resolveFile(name string, ext string, env *object.Environment) (string, *object.Error) {
+	if filepath.Ext(name) == "" {
+		name += ext
+	}
+	var candidates []string
+	if filepath.IsAbs(name) {
+		candidates = append(candidates, name)
+	} else {
+		if current := env.CurrentFile(); current != "" {
+			candidates = append(candidates, filepath.Join(filepath.Dir(current), name))
+		}
+		candidates = append(candidates, name)
+		for _, dir := range searchPath(env) {
+			candidates = append(candidates, filepath.Join(dir, name))
+		}
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			if abs, err := filepath.Abs(candidate); err == nil {
+				return abs, nil
+			}
+			return candidate, nil
+		}
+	}
+	return "", object.NewError(fmt.Sprintf("file `%s` does not exist", name))
+}
+
+// searchPath devuelve las carpetas de SET PATH.
+func searchPath(env *object.Environment) []string {
+	opt, ok := env.GetOption("path").(*object.String)
+	if !ok {
+		return nil
+	}
+	var dirs []string
+	for _, dir := range strings.FieldsFunc(opt.Value, func(r rune) bool { return r == ';' || r == ',' }) {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// evalDoFile ejecuta otro programa como si fuera una rutina: ve las variables
+// privadas de quien lo invoca y recibe los argumentos con PARAMETERS.
+func evalDoFile(fileName string, args []ast.Expression, env *object.Environment) object.Object {
+	program, errObj := loadProgram(fileName)
+	if errObj != nil {
+		return errObj
+	}
+	if !env.EnterFile(fileName) {
+		return circularError(fileName, env)
+	}
+	defer env.LeaveFile()
+
+	routine := &object.Function{
+		Name: strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName)),
+		Body: &ast.BlockStmt{Statements: program.Statements},
+		Env:  env,
+		File: fileName,
+	}
+	values := evalArguments(routine, args, env)
+	if len(values) == 1 && isError(values[0]) {
+		return values[0]
+	}
+	return applyFunction(routine, values, env)
+}
+
+// openLibrary carga las funciones y clases de un archivo para SET PROCEDURE.
+// El código del programa principal de la librería no se ejecuta, salvo sus
+// propios SET PROCEDURE, que se agregan a los ya abiertos.
+func openLibrary(fileName string, env *object.Environment) *object.Error {
+	program, errObj := loadProgram(fileName)
+	if errObj != nil {
+		return errObj
+	}
+	if !env.EnterFile(fileName) {
+		return circularError(fileName, env)
+	}
+	defer env.LeaveFile()
+
+	libEnv := object.NewEnclosedEnv(env)
+	lib := &object.Library{File: fileName, Procedures: map[string]object.Object{}}
+	for _, stmt := range program.Statements {
+		switch stmt := stmt.(type) {
+		case *ast.FunctionLiteral:
+			if stmt.Name == nil {
+				continue
+			}
+			lib.Procedures[stmt.Name.String()] = &object.Function{
+				Name:       stmt.Name.String(),
+				Parameters: stmt.Parameters,
+				Body:       stmt.Body,
+				Env:        libEnv,
+				File:       fileName,
+			}
+		case *ast.Class:
+			class := evalClassStmt(stmt, libEnv)
+			if isError(class) {
+				return tagError(class, fileName).(*object.Error)
+			}
+			lib.Procedures[stmt.Name] = class
+		case *ast.SetFileStmt:
+			if stmt.Name != "procedure" {
+				continue
+			}
+			for _, file := range stmt.Files {
+				if errObj := openLibraryExp(file, libEnv); errObj != nil {
+					return tagError(errObj, fileName).(*object.Error)
+				}
+			}
+		}
+	}
+	env.OpenLibrary(lib)
+	return nil
+}
+
+func openLibraryExp(exp ast.Expression, env *object.Environment) *object.Error {
+	name, errObj := evalFileName(exp, env, ".flp")
+	if errObj != nil {
+		return errObj
+	}
+	fileName, errObj := resolveFile(name, ".flp", env)
+	if errObj != nil {
+		return errObj
+	}
+	return openLibrary(fileName, env)
+}
+
+func circularError(fileName string, env *object.Environment) *object.Error {
+	var chain []string
+	for _, file := range append(env.Files(), fileName) {
+		chain = append(chain, filepath.Base(file))
+	}
+	return object.NewError(fmt.Sprintf("circular reference to `%s`: %s", filepath.Base(fileName), strings.Join(chain, " -> ")))
+}
+
+// tagError anota en el error el archivo donde se produjo, si aún no lo tiene.
+func tagError(obj object.Object, fileName string) object.Object {
+	if err, ok := obj.(*object.Error); ok && err.File == "" && fileName != "" {
+		err.File = fileName
+	}
+	return obj
+}
@@ -0,0 +1,1059 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"FoxLite/src/token"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Motor de las consultas SQL locales. Las tablas de la cláusula From se
+// cargan en memoria (sin los registros borrados si SET DELETED está ON) y
+// la consulta se resuelve por etapas: combinación (Join), Where, Group By y
+// agregados, Having, proyección de las columnas, Distinct, Order By y Top.
+// Las expresiones se evalúan con el evaluador de FoxLite en un environment
+// cuya fila (object.RowSource) resuelve los nombres de las columnas.
+
+// sqlSource es una tabla de la cláusula From cargada en memoria.
+type sqlSource struct {
+	alias  string
+	fields []*dbf.Field
+	names  map[string]int // nombre del campo en mayúsculas -> posición
+	rows   []*sqlRecord
+}
+
+// sqlRecord es un registro de una tabla de la cláusula From.
+type sqlRecord struct {
+	values  []object.Object
+	recno   int
+	deleted bool
+}
+
+// sqlRow es una fila combinada: un registro de cada tabla de la consulta,
+// nil para la tabla que no tiene registro en un Outer Join.
+type sqlRow []*sqlRecord
+
+// sqlContext es la fila en evaluación de una consulta.
+type sqlContext struct {
+	sources    []*sqlSource
+	row        sqlRow
+	aggregates map[*ast.SqlAggregateExp]object.Object // valores del grupo actual
+	subqueries map[*ast.SqlQuery]*sqlResult           // subconsultas no correlacionadas
+}
+
+// sqlColumn es una columna del resultado.
+type sqlColumn struct {
+	name     string
+	expr     ast.Expression
+	field    *dbf.Field // campo de origen si la columna es un campo de una tabla
+	decimals int        // decimales del campo de un agregado: Sum(saldo)
+}
+
+// sqlResult es el resultado de una consulta.
+type sqlResult struct {
+	columns []*sqlColumn
+	rows    [][]object.Object
+}
+
+// sqlOutput es una fila del resultado con los valores de Order By.
+type sqlOutput struct {
+	values []object.Object
+	keys   []object.Object
+}
+
+// sqlOrder es un criterio de Order By: una columna del resultado (column)
+// o una expresión (column -1).
+type sqlOrder struct {
+	column     int
+	expr       ast.Expression
+	descending bool
+}
+
+// Column resuelve el nombre de una columna en la fila actual (RowSource).
+func (c *sqlContext) Column(qualifier string, name string) (object.Object, bool) {
+	name = strings.ToUpper(name)
+	if qualifier != "" {
+		for i, src := range c.sources {
+			if strings.EqualFold(src.alias, qualifier) {
+				idx, ok := src.names[name]
+				if !ok {
+					return object.NewError(fmt.Sprintf("column `%s.%s` is not found", qualifier, name)), true
+				}
+				return c.value(i, idx), true
+			}
+		}
+		return nil, false
+	}
+	found := -1
+	var val object.Object
+	for i, src := range c.sources {
+		if idx, ok := src.names[name]; ok {
+			if found >= 0 {
+				return object.NewError(fmt.Sprintf("column `%s` is ambiguous", name)), true
+			}
+			found, val = i, c.value(i, idx)
+		}
+	}
+	return val, found >= 0
+}
+
+func (c *sqlContext) value(src int, idx int) object.Object {
+	if c.row == nil || c.row[src] == nil {
+		return Null
+	}
+	return c.row[src].values[idx]
+}
+
+// Record devuelve el registro de la fila actual de una tabla (RowSource),
+// que es el que usan RECNO() y DELETED() dentro de la consulta. En un Outer
+// Join la tabla sin registro devuelve 0.
+func (c *sqlContext) Record(qualifier string) (int, bool, bool) {
+	for i, src := range c.sources {
+		if qualifier != "" && !strings.EqualFold(src.alias, qualifier) {
+			continue
+		}
+		if c.row == nil || c.row[i] == nil {
+			return 0, false, true
+		}
+		return c.row[i].recno, c.row[i].deleted, true
+	}
+	return 0, false, false
+}
+
+// sourceField devuelve el campo de origen de una expresión que es solo un
+// nombre de campo (nombre o alias.nombre), o nil.
+func (c *sqlContext) sourceField(exp ast.Expression) *dbf.Field {
+	var qualifier, name string
+	switch exp := exp.(type) {
+	case *ast.Literal:
+		if exp.Token.Type != token.Ident {
+			return nil
+		}
+		name = exp.Value.(string)
+	case *ast.InfixExp:
+		if exp.Op != token.Dot {
+			return nil
+		}
+		var errObj *object.Error
+		if qualifier, name, errObj = dotNames(exp); errObj != nil {
+			return nil
+		}
+	default:
+		return nil
+	}
+	var field *dbf.Field
+	for _, src := range c.sources {
+		if qualifier != "" && !strings.EqualFold(src.alias, qualifier) {
+			continue
+		}
+		if idx, ok := src.names[strings.ToUpper(name)]; ok {
+			if field != nil {
+				return nil // ambiguo
+			}
+			field = src.fields[idx]
+		}
+	}
+	return field
+}
+
+// sqlProbe se interpone entre una subconsulta y la consulta que la contiene
+// para saber si la subconsulta usa columnas de la fila exterior
+// (correlacionada) o si su resultado se puede reutilizar.
+type sqlProbe struct {
+	outer   *object.Environment
+	touched bool
+}
+
+func (p *sqlProbe) Column(qualifier string, name string) (object.Object, bool) {
+	val, ok := p.outer.Column(qualifier, name)
+	if ok {
+		p.touched = true
+	}
+	return val, ok
+}
+
+func (p *sqlProbe) Record(qualifier string) (int, bool, bool) {
+	recno, deleted, ok := p.outer.Record(qualifier)
+	if ok {
+		p.touched = true
+	}
+	return recno, deleted, ok
+}
+
+// sqlContextOf devuelve la consulta más interna en evaluación.
+func sqlContextOf(env *object.Environment) *sqlContext {
+	ctx, _ := env.RowSource().(*sqlContext)
+	return ctx
+}
+
+// runSubquery ejecuta una subconsulta; si no está correlacionada su
+// resultado se guarda para el resto de filas de la consulta exterior.
+func runSubquery(query *ast.SqlQuery, env *object.Environment) (*sqlResult, *object.Error) {
+	ctx := sqlContextOf(env)
+	if ctx != nil {
+		if result, ok := ctx.subqueries[query]; ok {
+			return result, nil
+		}
+	}
+	probe := &sqlProbe{outer: env}
+	result, errObj := runSqlQuery(query, object.NewRowEnv(env, probe))
+	if errObj != nil {
+		return nil, errObj
+	}
+	if ctx != nil && !probe.touched {
+		if ctx.subqueries == nil {
+			ctx.subqueries = map[*ast.SqlQuery]*sqlResult{}
+		}
+		ctx.subqueries[query] = result
+	}
+	return result, nil
+}
+
+// runSqlQuery ejecuta una consulta.
+func runSqlQuery(query *ast.SqlQuery, env *object.Environment) (*sqlResult, *object.Error) {
+	ctx := &sqlContext{}
+	for _, table := range query.From {
+		src, errObj := loadSqlSource(table, env)
+		if errObj != nil {
+			return nil, errObj
+		}
+		for _, other := range ctx.sources {
+			if strings.EqualFold(other.alias, src.alias) {
+				return nil, object.NewError(fmt.Sprintf("SQL: duplicated alias `%s`, use a local alias", src.alias))
+			}
+		}
+		ctx.sources = append(ctx.sources, src)
+	}
+	rowEnv := object.NewRowEnv(env, ctx)
+
+	rows, errObj := joinSqlSources(query, ctx, rowEnv)
+	if errObj != nil {
+		return nil, errObj
+	}
+	if query.Where != nil {
+		var filtered []sqlRow
+		for _, row := range rows {
+			ctx.row = row
+			ok, errObj := sqlCondition(query.Where, rowEnv, "WHERE")
+			if errObj != nil {
+				return nil, errObj
+			}
+			if ok {
+				filtered = append(filtered, row)
+			}
+		}
+		rows = filtered
+	}
+
+	columns, errObj := sqlColumns(query, ctx)
+	if errObj != nil {
+		return nil, errObj
+	}
+	orders, errObj := sqlOrders(query, columns)
+	if errObj != nil {
+		return nil, errObj
+	}
+	project := func() (*sqlOutput, *object.Error) {
+		out := &sqlOutput{
+			values: make([]object.Object, len(columns)),
+			keys:   make([]object.Object, len(orders)),
+		}
+		for i, col := range columns {
+			val := Eval(col.expr, rowEnv)
+			if errObj, ok := val.(*object.Error); ok {
+				return nil, errObj
+			}
+			out.values[i] = val
+		}
+		for i, order := range orders {
+			if order.column >= 0 {
+				out.keys[i] = out.values[order.column]
+				continue
+			}
+			val := Eval(order.expr, rowEnv)
+			if errObj, ok := val.(*object.Error); ok {
+				return nil, errObj
+			}
+			out.keys[i] = val
+		}
+		return out, nil
+	}
+
+	var outputs []*sqlOutput
+	var aggregates []*ast.SqlAggregateExp
+	for _, col := range columns {
+		collectAggregates(col.expr, &aggregates)
+	}
+	collectAggregates(query.Having, &aggregates)
+	for _, order := range orders {
+		collectAggregates(order.expr, &aggregates)
+	}
+	if len(query.GroupBy) > 0 || len(aggregates) > 0 || query.Having != nil {
+		groups, errObj := groupSqlRows(query, columns, rows, ctx, rowEnv)
+		if errObj != nil {
+			return nil, errObj
+		}
+		for _, group := range groups {
+			values := map[*ast.SqlAggregateExp]object.Object{}
+			for _, agg := range aggregates {
+				val, errObj := sqlAggregate(agg, group, ctx, rowEnv)
+				if errObj != nil {
+					return nil, errObj
+				}
+				values[agg] = val
+			}
+			// las columnas que no son agregados toman el valor de la
+			// última fila del grupo
+			ctx.row = make(sqlRow, len(ctx.sources))
+			if len(group) > 0 {
+				ctx.row = group[len(group)-1]
+			}
+			ctx.aggregates = values
+			if query.Having != nil {
+				ok, errObj := sqlCondition(query.Having, rowEnv, "HAVING")
+				if errObj != nil {
+					return nil, errObj
+				}
+				if !ok {
+					continue
+				}
+			}
+			out, errObj := project()
+			if errObj != nil {
+				return nil, errObj
+			}
+			outputs = append(outputs, out)
+		}
+		ctx.aggregates = nil
+	} else {
+		for _, row := range rows {
+			ctx.row = row
+			out, errObj := project()
+			if errObj != nil {
+				return nil, errObj
+			}
+			outputs = append(outputs, out)
+		}
+	}
+
+	if query.Distinct {
+		seen := map[string]bool{}
+		unique := outputs[:0]
+		for _, out := range outputs {
+			key := sqlRowKey(out.values)
+			if !seen[key] {
+				seen[key] = true
+				unique = append(unique, out)
+			}
+		}
+		outputs = unique
+	}
+	if query.Distinct && len(orders) == 0 {
+		// como en FoxPro, Distinct devuelve las filas ordenadas
+		for i := range columns {
+			orders = append(orders, &sqlOrder{column: i})
+		}
+		for _, out := range outputs {
+			out.keys = out.values
+		}
+	}
+	if len(orders) > 0 {
+		sort.SliceStable(outputs, func(i, j int) bool {
+			for k, order := range orders {
+				cmp := compareSqlValues(outputs[i].keys[k], outputs[j].keys[k], env)
+				if cmp == 0 {
+					continue
+				}
+				if order.descending {
+					return cmp > 0
+				}
+				return cmp < 0
+			}
+			return false
+		})
+	}
+	if query.Top != nil {
+		top, errObj := sqlTop(query, len(outputs), env)
+		if errObj != nil {
+			return nil, errObj
+		}
+		if top < len(outputs) {
+			outputs = outputs[:top]
+		}
+	}
+
+	result := &sqlResult{columns: columns, rows: make([][]object.Object, len(outputs))}
+	for i, out := range outputs {
+		result.rows[i] = out.values
+	}
+	return result, nil
+}
+
+// loadSqlSource carga una tabla de la cláusula From: un alias abierto en
+// un área de trabajo o una tabla del disco, que se abre solo para leerla.
+func loadSqlSource(table *ast.SqlTable, env *object.Environment) (*sqlSource, *object.Error) {
+	val := Eval(table.Name, env)
+	if errObj, ok := val.(*object.Error); ok {
+		return nil, errObj
+	}
+	str, ok := val.(*object.String)
+	if !ok || strings.TrimSpace(str.Value) == "" {
+		return nil, object.NewError("SQL: expecting a table name in FROM")
+	}
+	name := strings.TrimSpace(str.Value)
+	src := &sqlSource{alias: strings.ToUpper(table.Alias)}
+
+	dbfTable := (*dbf.Table)(nil)
+	if wa := env.AreaByAlias(name); wa != nil {
+		dbfTable = wa.Table
+		if src.alias == "" {
+			src.alias = wa.Alias
+		}
+	} else {
+		fileName, longName, errObj := resolveTable(name, env)
+		if errObj != nil {
+			return nil, errObj
+		}
+		opened, _, errObj := openTable(fileName, true, env)
+		if errObj != nil {
+			return nil, errObj
+		}
+		defer opened.Close()
+		dbfTable = opened
+		if src.alias == "" && longName != "" {
+			src.alias = strings.ToUpper(longName)
+		} else if src.alias == "" {
+			src.alias = strings.ToUpper(strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName)))
+		}
+	}
+
+	return tableSource(src, dbfTable, env)
+}
+
+// tableSource carga en src los registros de la tabla, sin los borrados si
+// SET DELETED está ON.
+func tableSource(src *sqlSource, table *dbf.Table, env *object.Environment) (*sqlSource, *object.Error) {
+	src.fields = table.Fields
+	src.names = make(map[string]int, len(src.fields))
+	for i, field := range src.fields {
+		src.names[strings.ToUpper(field.Name)] = i
+	}
+	skipDeleted := isOptionOn(env, "deleted")
+	for recno := 1; recno <= table.RecordCount(); recno++ {
+		rec, err := table.Record(recno)
+		if err != nil {
+			return nil, object.NewError(fmt.Sprintf("%s: %v", src.alias, err))
+		}
+		if skipDeleted && rec.Deleted() {
+			continue
+		}
+		row := make([]object.Object, len(src.fields))
+		for i, field := range src.fields {
+			value, err := rec.Value(i)
+			if err != nil {
+				return nil, object.NewError(fmt.Sprintf("%s: %v", src.alias, err))
+			}
+			row[i] = fromFieldValue(field, value)
+		}
+		src.rows = append(src.rows, &sqlRecord{values: row, recno: recno, deleted: rec.Deleted()})
+	}
+	return src, nil
+}
+
+// joinSqlSources combina las filas de las tablas de la cláusula From.
+func joinSqlSources(query *ast.SqlQuery, ctx *sqlContext, env *object.Environment) ([]sqlRow, *object.Error) {
+	count := len(ctx.sources)
+	var rows []sqlRow
+	for _, rec := range ctx.sources[0].rows {
+		row := make(sqlRow, count)
+		row[0] = rec
+		rows = append(rows, row)
+	}
+	for i := 1; i < count; i++ {
+		table := query.From[i]
+		right := ctx.sources[i].rows
+		matchedRight := make([]bool, len(right))
+		var joined []sqlRow
+		for _, left := range rows {
+			matched := false
+			for r, rec := range right {
+				row := make(sqlRow, count)
+				copy(row, left)
+				row[i] = rec
+				if table.On != nil {
+					ctx.row = row
+					ok, errObj := sqlCondition(table.On, env, "ON")
+					if errObj != nil {
+						return nil, errObj
+					}
+					if !ok {
+						continue
+					}
+				}
+				matched = true
+				matchedRight[r] = true
+				joined = append(joined, row)
+			}
+			if !matched && (table.Join == "left" || table.Join == "full") {
+				joined = append(joined, left)
+			}
+		}
+		if table.Join == "right" || table.Join == "full" {
+			for r, rec := range right {
+				if !matchedRight[r] {
+					row := make(sqlRow, count)
+					row[i] = rec
+					joined = append(joined, row)
+				}
+			}
+		}
+		rows = joined
+	}
+	return rows, nil
+}
+
+// sqlCondition evalúa una condición de la consulta; null se considera
+// falso.
+func sqlCondition(exp ast.Expression, env *object.Environment, clause string) (bool, *object.Error) {
+	val := Eval(exp, env)
+	switch val := val.(type) {
+	case *object.Error:
+		return false, val
+	case *object.Boolean:
+		return val.Value, nil
+	case *object.Null:
+		return false, nil
+	}
+	return false, object.NewError(fmt.Sprintf("%s: condition must be logical, got `%s`", clause, object.TypeToStr(val.Type())))
+}
+
+// maxColumnName es la longitud máxima de los nombres de las columnas del
+// resultado; en una tabla libre se abrevian a los 10 caracteres del .dbf.
+const maxColumnName = 128
+
+// sqlColumns expande * y alias.* y da nombre a las columnas del resultado.
+func sqlColumns(query *ast.SqlQuery, ctx *sqlContext) ([]*sqlColumn, *object.Error) {
+	var columns []*sqlColumn
+	for _, col := range query.Columns {
+		if !col.Star {
+			// los nombres ambiguos o inexistentes se detectan aunque la
+			// consulta no tenga filas
+			if errObj := checkColumnRef(col.Expr, ctx); errObj != nil {
+				return nil, errObj
+			}
+			column := &sqlColumn{name: col.Alias, expr: col.Expr, field: ctx.sourceField(col.Expr)}
+			if column.name == "" {
+				column.name = sqlColumnName(col.Expr, ctx)
+			}
+			if agg, ok := col.Expr.(*ast.SqlAggregateExp); ok && agg.Arg != nil {
+				if field := ctx.sourceField(agg.Arg); field != nil {
+					column.decimals = field.Decimals
+				}
+			}
+			columns = append(columns, column)
+			continue
+		}
+		found := false
+		for _, src := range ctx.sources {
+			if col.Source != "" && !strings.EqualFold(src.alias, col.Source) {
+				continue
+			}
+			found = true
+			for _, field := range src.fields {
+				columns = append(columns, &sqlColumn{
+					name:  field.Name,
+					expr:  fieldExp(src.alias, field.Name),
+					field: field,
+				})
+			}
+		}
+		if !found {
+			return nil, object.NewError(fmt.Sprintf("alias `%s` is not found", col.Source))
+		}
+	}
+	// las columnas sin nombre se llaman EXP_n y los nombres repetidos se
+	// distinguen con el sufijo _A, _B...
+	count := map[string]int{}
+	for i, col := range columns {
+		if col.name == "" {
+			col.name = fmt.Sprintf("EXP_%d", i+1)
+		}
+		col.name = strings.ToUpper(col.name)
+		if len(col.name) > maxColumnName {
+			col.name = col.name[:maxColumnName]
+		}
+		count[col.name]++
+	}
+	suffix := map[string]int{}
+	for _, col := range columns {
+		if count[col.name] > 1 {
+			base := col.name
+			if len(base) > maxColumnName-2 {
+				base = base[:maxColumnName-2]
+			}
+			n := suffix[col.name]
+			suffix[col.name]++
+			col.name = fmt.Sprintf("%s_%c", base, 'A'+n)
+		}
+	}
+	return columns, nil
+}
+
+// checkColumnRef comprueba la columna a la que se refiere un nombre (nombre
+// o alias.nombre) de la lista de columnas.
+func checkColumnRef(exp ast.Expression, ctx *sqlContext) *object.Error {
+	var val object.Object
+	switch exp := exp.(type) {
+	case *ast.Literal:
+		if exp.Token.Type == token.Ident {
+			val, _ = ctx.Column("", exp.Value.(string))
+		}
+	case *ast.InfixExp:
+		if qualifier, name, errObj := dotNames(exp); exp.Op == token.Dot && errObj == nil {
+			val, _ = ctx.Column(qualifier, name)
+		}
+	}
+	if errObj, ok := val.(*object.Error); ok {
+		return errObj
+	}
+	return nil
+}
+
+// sqlColumnName es el nombre por defecto de una columna: el del campo o,
+// en los agregados, CNT, SUM_campo, AVG_campo, MIN_campo o MAX_campo.
+func sqlColumnName(exp ast.Expression, ctx *sqlContext) string {
+	if field := ctx.sourceField(exp); field != nil {
+		return field.Name
+	}
+	agg, ok := exp.(*ast.SqlAggregateExp)
+	if !ok {
+		return ""
+	}
+	if agg.Func == "count" {
+		return "CNT"
+	}
+	if field := ctx.sourceField(agg.Arg); field != nil {
+		return agg.Func + "_" + field.Name
+	}
+	return ""
+}
+
+// fieldExp construye la expresión alias.campo.
+func fieldExp(alias string, name string) ast.Expression {
+	ident := func(lit string) *ast.Literal {
+		return &ast.Literal{Token: token.Token{Type: token.Ident, Literal: lit}, Value: lit}
+	}
+	return &ast.InfixExp{
+		Token: token.Token{Type: token.Dot, Literal: "."},
+		Left:  ident(alias),
+		Op:    token.Dot,
+		Right: ident(name),
+	}
+}
+
+// columnRef devuelve la columna del resultado a la que se refiere una
+// expresión de Group By u Order By: su número o su nombre; -1 si es otra
+// expresión.
+func columnRef(exp ast.Expression, columns []*sqlColumn) (int, *object.Error) {
+	lit, ok := exp.(*ast.Literal)
+	if !ok {
+		if infix, ok := exp.(*ast.InfixExp); ok && infix.Op == token.Dot {
+			for i, col := range columns {
+				if col.expr.String() == infix.String() {
+					return i, nil
+				}
+			}
+		}
+		return -1, nil
+	}
+	switch lit.Token.Type {
+	case token.Number:
+		n := int(lit.Value.(float64))
+		if n < 1 || n > len(columns) {
+			return 0, object.NewError(fmt.Sprintf("SQL: column number %d is out of range", n))
+		}
+		return n - 1, nil
+	case token.Ident:
+		for i, col := range columns {
+			if strings.EqualFold(col.name, lit.Value.(string)) {
+				return i, nil
+			}
+		}
+	}
+	return -1, nil
+}
+
+// sqlOrders resuelve los criterios de Order By.
+func sqlOrders(query *ast.SqlQuery, columns []*sqlColumn) ([]*sqlOrder, *object.Error) {
+	orders := make([]*sqlOrder, len(query.OrderBy))
+	for i, item := range query.OrderBy {
+		column, errObj := columnRef(item.Expr, columns)
+		if errObj != nil {
+			return nil, errObj
+		}
+		orders[i] = &sqlOrder{column: column, expr: item.Expr, descending: item.Descending}
+		if column >= 0 {
+			orders[i].expr = nil
+		}
+	}
+	return orders, nil
+}
+
+// groupSqlRows agrupa las filas según Group By; sin Group By (una consulta
+// con agregados) hay un único grupo, aunque no haya ninguna fila. Los
+// grupos se devuelven ordenados por sus claves.
+func groupSqlRows(query *ast.SqlQuery, columns []*sqlColumn, rows []sqlRow, ctx *sqlContext, env *object.Environment) ([][]sqlRow, *object.Error) {
+	if len(query.GroupBy) == 0 {
+		return [][]sqlRow{rows}, nil
+	}
+	exprs := make([]ast.Expression, len(query.GroupBy))
+	for i, exp := range query.GroupBy {
+		column, errObj := columnRef(exp, columns)
+		if errObj != nil {
+			return nil, errObj
+		}
+		exprs[i] = exp
+		if column >= 0 {
+			exprs[i] = columns[column].expr
+		}
+	}
+	var groups [][]sqlRow
+	var keys [][]object.Object
+	index := map[string]int{}
+	for _, row := range rows {
+		ctx.row = row
+		values := make([]object.Object, len(exprs))
+		for i, exp := range exprs {
+			val := Eval(exp, env)
+			if errObj, ok := val.(*object.Error); ok {
+				return nil, errObj
+			}
+			values[i] = val
+		}
+		key := sqlRowKey(values)
+		idx, ok := index[key]
+		if !ok {
+			idx = len(groups)
+			index[key] = idx
+			groups = append(groups, nil)
+			keys = append(keys, values)
+		}
+		groups[idx] = append(groups[idx], row)
+	}
+	order := make([]int, len(groups))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		for k := range exprs {
+			if cmp := compareSqlValues(keys[order[i]][k], keys[order[j]][k], env); cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+	sorted := make([][]sqlRow, len(groups))
+	for i, idx := range order {
+		sorted[i] = groups[idx]
+	}
+	return sorted, nil
+}
+
+// collectAggregates agrega a list las funciones de agregado de exp, sin
+// entrar en las subconsultas.
+func collectAggregates(exp ast.Expression, list *[]*ast.SqlAggregateExp) {
+	switch exp := exp.(type) {
+	case *ast.SqlAggregateExp:
+		*list = append(*list, exp)
+	case *ast.InfixExp:
+		collectAggregates(exp.Left, list)
+		collectAggregates(exp.Right, list)
+	case *ast.PrefixExp:
+		collectAggregates(exp.Right, list)
+	case *ast.CallExp:
+		for _, arg := range exp.Args {
+			collectAggregates(arg, list)
+		}
+	case *ast.IifExp:
+		collectAggregates(exp.Condition, list)
+		collectAggregates(exp.Consequence, list)
+		collectAggregates(exp.Alternative, list)
+	case *ast.IndexExp:
+		collectAggregates(exp.Left, list)
+		collectAggregates(exp.Index, list)
+	case *ast.SqlNotExp:
+		collectAggregates(exp.Right, list)
+	case *ast.SqlInExp:
+		collectAggregates(exp.Left, list)
+		for _, item := range exp.List {
+			collectAggregates(item, list)
+		}
+	case *ast.SqlBetweenExp:
+		collectAggregates(exp.Left, list)
+		collectAggregates(exp.Low, list)
+		collectAggregates(exp.High, list)
+	case *ast.SqlLikeExp:
+		collectAggregates(exp.Left, list)
+		collectAggregates(exp.Pattern, list)
+	case *ast.SqlIsNullExp:
+		collectAggregates(exp.Left, list)
+	}
+}
+
+// sqlAggregate calcula una función de agregado sobre las filas de un
+// grupo. Los valores null no se tienen en cuenta.
+func sqlAggregate(agg *ast.SqlAggregateExp, rows []sqlRow, ctx *sqlContext, env *object.Environment) (object.Object, *object.Error) {
+	if agg.Arg == nil { // Count(*)
+		return &object.Integer{Value: float64(len(rows))}, nil
+	}
+	var values []object.Object
+	seen := map[string]bool{}
+	for _, row := range rows {
+		ctx.row = row
+		val := Eval(agg.Arg, env)
+		if errObj, ok := val.(*object.Error); ok {
+			return nil, errObj
+		}
+		if val.Type() == object.NullObj {
+			continue
+		}
+		if agg.Distinct {
+			key := sqlValueKey(val)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		values = append(values, val)
+	}
+	if agg.Func == "count" {
+		return &object.Integer{Value: float64(len(values))}, nil
+	}
+	if len(values) == 0 {
+		return Null, nil
+	}
+	switch agg.Func {
+	case "sum", "avg":
+		sum := 0.0
+		for _, val := range values {
+			num, ok := val.(*object.Integer)
+			if !ok {
+				return nil, object.NewError(fmt.Sprintf("%s(): expecting a numeric expression, got `%s`", strings.ToUpper(agg.Func), object.TypeToStr(val.Type())))
+			}
+			sum += num.Value
+		}
+		if agg.Func == "avg" {
+			sum /= float64(len(values))
+		}
+		return &object.Integer{Value: sum}, nil
+	}
+	best := values[0]
+	for _, val := range values[1:] {
+		cmp, errObj := compareValues(val, best, env)
+		if errObj != nil {
+			return nil, object.NewError(fmt.Sprintf("%s(): %s", strings.ToUpper(agg.Func), errObj.Message))
+		}
+		if (agg.Func == "min" && cmp < 0) || (agg.Func == "max" && cmp > 0) {
+			best = val
+		}
+	}
+	return best, nil
+}
+
+// sqlTop evalúa el número de filas de Top n [Percent].
+func sqlTop(query *ast.SqlQuery, count int, env *object.Environment) (int, *object.Error) {
+	val := Eval(query.Top, env)
+	if errObj, ok := val.(*object.Error); ok {
+		return 0, errObj
+	}
+	num, ok := val.(*object.Integer)
+	if !ok || num.Value < 0 {
+		return 0, object.NewError("TOP: expecting a positive number")
+	}
+	if query.Percent {
+		return int(math.Ceil(float64(count) * num.Value / 100)), nil
+	}
+	return int(num.Value), nil
+}
+
+// compareSqlValues ordena los valores del resultado: null va antes que
+// cualquier valor y los tipos distintos se ordenan por su tipo.
+func compareSqlValues(left object.Object, right object.Object, env *object.Environment) int {
+	leftNull, rightNull := left.Type() == object.NullObj, right.Type() == object.NullObj
+	switch {
+	case leftNull && rightNull:
+		return 0
+	case leftNull:
+		return -1
+	case rightNull:
+		return 1
+	}
+	cmp, errObj := compareValues(left, right, env)
+	if errObj != nil {
+		switch {
+		case left.Type() < right.Type():
+			return -1
+		case left.Type() > right.Type():
+			return 1
+		}
+		return 0
+	}
+	return cmp
+}
+
+// sqlValueKey convierte un valor en una clave para agrupar y eliminar
+// repetidos; los strings no distinguen los espacios a la derecha.
+func sqlValueKey(val object.Object) string {
+	switch val := val.(type) {
+	case *object.String:
+		return "C" + strings.TrimRight(val.Value, " ")
+	case *object.Integer:
+		return "N" + strconv.FormatFloat(val.Value, 'g', -1, 64)
+	case *object.Date:
+		return "D" + val.Value.Format(time.RFC3339Nano)
+	case *object.Boolean:
+		return "L" + strconv.FormatBool(val.Value)
+	case *object.Null:
+		return "X"
+	}
+	return fmt.Sprintf("%d%s", val.Type(), val.Inspect())
+}
+
+func sqlRowKey(values []object.Object) string {
+	keys := make([]string, len(values))
+	for i, val := range values {
+		keys[i] = sqlValueKey(val)
+	}
+	return strings.Join(keys, "\x00")
+}
+
+// sqlFields deduce la estructura de la tabla del resultado: las columnas
+// que son campos conservan su tipo y el resto se deduce de sus valores.
+func sqlFields(result *sqlResult, env *object.Environment) ([]dbf.Field, *object.Error) {
+	fields := make([]dbf.Field, len(result.columns))
+	for i, col := range result.columns {
+		field, errObj := sqlField(col, result.rows, i, env)
+		if errObj != nil {
+			return nil, errObj
+		}
+		fields[i] = field
+	}
+	return fields, nil
+}
+
+func sqlField(col *sqlColumn, rows [][]object.Object, idx int, env *object.Environment) (dbf.Field, *object.Error) {
+	field := dbf.Field{Name: col.name}
+	var kind object.ObjType
+	known, nulls := false, false
+	width, intDigits, decimals := 0, 1, 0
+	for _, row := range rows {
+		val := row[idx]
+		if val.Type() == object.NullObj {
+			nulls = true
+			continue
+		}
+		if !known {
+			kind, known = val.Type(), true
+		} else if kind != val.Type() {
+			return field, object.NewError(fmt.Sprintf("column `%s`: data type mismatch, got `%s` and `%s`", col.name, object.TypeToStr(kind), object.TypeToStr(val.Type())))
+		}
+		switch val := val.(type) {
+		case *object.String:
+			if n := utf8.RuneCountInString(val.Value); n > width {
+				width = n
+			}
+		case *object.Integer:
+			text := strconv.FormatFloat(math.Abs(val.Value), 'f', -1, 64)
+			digits, fraction := text, ""
+			if dot := strings.IndexByte(text, '.'); dot >= 0 {
+				digits, fraction = text[:dot], text[dot+1:]
+			}
+			if val.Value < 0 {
+				digits = "-" + digits
+			}
+			if len(digits) > intDigits {
+				intDigits = len(digits)
+			}
+			if len(fraction) > decimals {
+				decimals = len(fraction)
+			}
+		case *object.Date:
+			if val.DateTime {
+				field.Type = dbf.DateTime
+			}
+		}
+	}
+	if nulls {
+		field.Flags |= dbf.FlagNullable
+	}
+	if col.field != nil {
+		field.Type, field.Length, field.Decimals = col.field.Type, col.field.Length, col.field.Decimals
+		field.Flags |= col.field.Flags & (dbf.FlagNullable | dbf.FlagBinary)
+		return field, nil
+	}
+	if !known {
+		// todos los valores son null
+		field.Type = dbf.Logical
+		field.Flags |= dbf.FlagNullable
+		return field, nil
+	}
+	switch kind {
+	case object.StringObj:
+		field.Type, field.Length = dbf.Character, width
+		if width > 254 {
+			field.Type = dbf.Memo
+		} else if width == 0 {
+			field.Length = 1
+		}
+	case object.IntegerObj:
+		// los decimales se limitan a SET DECIMALS salvo que el agregado
+		// de un campo tenga más
+		limit := 2
+		if opt, ok := env.GetOption("decimals").(*object.Integer); ok {
+			limit = int(opt.Value)
+		}
+		if col.decimals > limit {
+			limit = col.decimals
+		}
+		if decimals > limit {
+			decimals = limit
+		}
+		if decimals < col.decimals {
+			decimals = col.decimals
+		}
+		width = intDigits
+		if decimals > 0 {
+			width += decimals + 1
+		}
+		if width < 10 {
+			width = 10
+		}
+		if width > 20 {
+			width = 20
+			if decimals > width-intDigits-1 {
+				decimals = width - intDigits - 1
+			}
+			if decimals < 0 {
+				decimals = 0
+			}
+		}
+		field.Type, field.Length, field.Decimals = dbf.Numeric, width, decimals
+	case object.BooleanObj:
+		field.Type = dbf.Logical
+	case object.DateObj:
+		if field.Type != dbf.DateTime {
+			field.Type = dbf.Date
+		}
+	default:
+		return field, object.NewError(fmt.Sprintf("column `%s`: cannot store `%s` in a table", col.name, object.TypeToStr(kind)))
+	}
+	return field, nil
+}
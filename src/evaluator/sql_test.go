@@ -0,0 +1,202 @@
+package evaluator
+
+import (
+	"FoxLite/src/lexer"
+	"FoxLite/src/object"
+	"FoxLite/src/parser"
+	"strings"
+	"testing"
+)
+
+// newTestEnv devuelve un entorno que cierra sus áreas de trabajo al
+// terminar el test.
+func newTestEnv(t *testing.T) *object.Environment {
+	t.Helper()
+	env := object.NewEnv()
+	t.Cleanup(func() {
+		env.CloseAreas()
+		env.CloseDatabases()
+	})
+	return env
+}
+
+// execute analiza y ejecuta el código y devuelve el resultado, que puede
+// ser un error del programa.
+func execute(t *testing.T, env *object.Environment, source string) object.Object {
+	t.Helper()
+	l := lexer.New()
+	l.ScanText([]rune(source))
+	p := parser.New(l)
+	program := p.Parse()
+	if errors := p.Errors(); len(errors) > 0 {
+		t.Fatalf("%q: %s", source, strings.Join(errors, "; "))
+	}
+	return Eval(program, env)
+}
+
+func run(t *testing.T, env *object.Environment, source string) {
+	t.Helper()
+	if err, ok := execute(t, env, source).(*object.Error); ok {
+		t.Fatalf("%q: %s", source, err.Inspect())
+	}
+}
+
+// expectValues evalúa cada expresión de la lista (expresión, resultado
+// esperado, ...) y compara su resultado con el esperado.
+func expectValues(t *testing.T, env *object.Environment, pairs ...string) {
+	t.Helper()
+	for i := 0; i < len(pairs); i += 2 {
+		result := execute(t, env, pairs[i])
+		if err, ok := result.(*object.Error); ok {
+			t.Fatalf("%s: %s", pairs[i], err.Inspect())
+		}
+		if got := result.Inspect(); got != pairs[i+1] {
+			t.Errorf("%s = %s, want %s", pairs[i], got, pairs[i+1])
+		}
+	}
+}
+
+func expectError(t *testing.T, env *object.Environment, source, message string) {
+	t.Helper()
+	err, ok := execute(t, env, source).(*object.Error)
+	if !ok {
+		t.Fatalf("%q: expected an error", source)
+	}
+	if !strings.Contains(err.Inspect(), message) {
+		t.Fatalf("%q: error %s does not mention %q", source, err.Inspect(), message)
+	}
+}
+
+// employees crea el cursor emp con tres registros; el segundo está
+// borrado.
+func employees(t *testing.T, env *object.Environment) {
+	t.Helper()
+	run(t, env, `create cursor emp (name C(10), dep I, salary N(8,2))
+insert into emp values ("Ana", 1, 100)
+insert into emp values ("Bea", 2, 250.5)
+insert into emp values ("Carla", 1, 300)
+go 2
+delete
+go top`)
+}
+
+func TestSelect(t *testing.T) {
+	env := newTestEnv(t)
+	employees(t, env)
+
+	run(t, env, `select name, salary * 2 as double from emp where salary > 150 order by salary desc into cursor rich`)
+	expectValues(t, env,
+		`_TALLY`, "2",
+		`alias()`, "RICH",
+		`alltrim(name) + "/" + str(double, 6)`, "Carla/   600",
+		`skip`, "",
+		`alltrim(name) + "/" + str(double, 6)`, "Bea/   501",
+	)
+
+	run(t, env, `select dep, count(*) as n, sum(salary) as total from emp group by dep having count(*) > 1 into array totals`)
+	expectValues(t, env,
+		`_TALLY`, "1",
+		`totals[0][0]`, "1",
+		`totals[0][1]`, "2",
+		`totals[0][2]`, "400",
+	)
+}
+
+// RECNO() y DELETED() dentro de la consulta leen la fila que se evalúa,
+// no el registro actual del área de trabajo.
+func TestSelectRecordFunctions(t *testing.T) {
+	env := newTestEnv(t)
+	employees(t, env)
+
+	run(t, env, `select name, recno() as rn, deleted() as del from emp into cursor rows`)
+	expectValues(t, env,
+		`_TALLY`, "3",
+		`str(rows.rn) + iif(rows.del, "*", "")`, "         1",
+		`skip`, "",
+		`str(rows.rn) + iif(rows.del, "*", "")`, "         2*",
+		`skip`, "",
+		`str(rows.rn) + iif(rows.del, "*", "")`, "         3",
+	)
+
+	run(t, env, `select name from emp where not deleted() and recno() > 1 into cursor live`)
+	expectValues(t, env,
+		`_TALLY`, "1",
+		`alltrim(live.name)`, "Carla",
+	)
+
+	// del lado vacío de una unión externa no hay registro
+	run(t, env, `create cursor deps (id I)
+insert into deps values (1)
+select e.name, recno("e") as erec, recno("d") as drec from emp e left join deps d on e.dep = d.id where e.dep = 2 into cursor joined`)
+	expectValues(t, env,
+		`_TALLY`, "1",
+		`joined.erec`, "2",
+		`joined.drec`, "0",
+	)
+
+	// con un número, RECNO() sigue refiriéndose al área de trabajo
+	run(t, env, `select emp
+go 3
+select recno(1) as area from deps into cursor areas`)
+	expectValues(t, env, `areas.area`, "3")
+}
+
+func TestInsertUpdateDelete(t *testing.T) {
+	env := newTestEnv(t)
+	employees(t, env)
+
+	run(t, env, `select emp
+update emp set salary = salary + 10 where dep = 1`)
+	expectValues(t, env,
+		`_TALLY`, "2",
+		`salary`, "110",
+	)
+
+	run(t, env, `delete from emp where salary > 200 and not deleted()`)
+	expectValues(t, env,
+		`_TALLY`, "1",
+		`go 3`, "",
+		`deleted()`, "True",
+		`go 1`, "",
+		`deleted()`, "False",
+	)
+
+	run(t, env, `insert into emp (dep, name) values (3, "Dora")`)
+	expectValues(t, env,
+		`_TALLY`, "1",
+		`reccount()`, "4",
+		`recno()`, "4",
+		`alltrim(name) + str(dep, 2)`, "Dora 3",
+	)
+
+	run(t, env, `select count(*) from emp where not deleted() into array live`)
+	expectValues(t, env, `live[0][0]`, "2")
+}
+
+func TestSelectErrors(t *testing.T) {
+	env := newTestEnv(t)
+	employees(t, env)
+
+	expectError(t, env, `select * from missing`, "missing")
+	expectError(t, env, `select nothing from emp`, "nothing")
+}
+
+// ALTER COLUMN convierte los números a texto con los decimales del campo
+// de origen.
+func TestAlterColumnToCharacter(t *testing.T) {
+	t.Chdir(t.TempDir())
+	env := newTestEnv(t)
+
+	run(t, env, `create table prices (n N(10,4), y Y, b B, i I)
+insert into prices values (12.3456, 1.2345, 3.14159, 7)
+alter table prices alter column n C(12)
+alter table prices alter column y C(12)
+alter table prices alter column b C(12)
+alter table prices alter column i C(5)`)
+	expectValues(t, env,
+		`alltrim(n)`, "12.3456",
+		`alltrim(y)`, "1.2345",
+		`alltrim(b)`, "3.14159",
+		`alltrim(i)`, "7",
+	)
+}
@@ -0,0 +1,401 @@
+package evaluator
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/dbf"
+	"FoxLite/src/object"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// currentArea devuelve el área de trabajo actual si tiene una tabla abierta.
+func currentArea(env *object.Environment) (*object.WorkArea, *object.Error) {
+	wa := env.CurrentArea()
+	if wa == nil {
+		return nil, object.NewError("no table is open in the current work area")
+	}
+	return wa, nil
+}
+
+// areaArg devuelve el área de trabajo indicada por el argumento idx de una
+// función (número de área o alias) o la actual si no se pasó. Devuelve nil
+// sin error si el área no está en uso.
+func areaArg(name string, env *object.Environment, args []object.Object, idx int) (*object.WorkArea, *object.Error) {
+	if idx >= len(args) {
+		return env.CurrentArea(), nil
+	}
+	switch arg := args[idx].(type) {
+	case *object.Integer:
+		if arg.Value == 0 {
+			return env.CurrentArea(), nil
+		}
+		return env.WorkArea(int(arg.Value)), nil
+	case *object.String:
+		wa := env.AreaByAlias(strings.TrimSpace(arg.Value))
+		if wa == nil {
+			return nil, object.NewError(fmt.Sprintf("%s(): alias `%s` is not found", name, arg.Value))
+		}
+		return wa, nil
+	}
+	return nil, argTypeError(name, idx, "work area or alias", args[idx])
+}
+
+// goRecord mueve el puntero al registro recno sin tener en cuenta el filtro;
+// un valor fuera de rango es un error salvo el fin de archivo.
+func goRecord(wa *object.WorkArea, env *object.Environment, recno int) *object.Error {
+	if recno < 1 || recno > wa.Table.RecordCount()+1 {
+		return object.NewError(fmt.Sprintf("record %d is out of range", recno))
+	}
+	wa.Recno = recno
+	wa.Bof = false
+	return recordMoved(wa, env)
+}
+
+// goTop mueve el puntero al primer registro visible. Si no hay ninguno
+// queda en el fin de archivo, que también es el principio de archivo.
+func goTop(wa *object.WorkArea, env *object.Environment) *object.Error {
+	if errObj := refreshArea(wa, env); errObj != nil {
+		return errObj
+	}
+	recno, errObj := seekVisible(wa, env, firstRecno(wa), 1)
+	if errObj != nil {
+		return errObj
+	}
+	moveTo(wa, recno)
+	return recordMoved(wa, env)
+}
+
+// goBottom mueve el puntero al último registro visible.
+func goBottom(wa *object.WorkArea, env *object.Environment) *object.Error {
+	if errObj := refreshArea(wa, env); errObj != nil {
+		return errObj
+	}
+	recno, errObj := seekVisible(wa, env, lastRecno(wa), -1)
+	if errObj != nil {
+		return errObj
+	}
+	moveTo(wa, recno)
+	return recordMoved(wa, env)
+}
+
+// commandResult convierte el error de una operación (nil si tuvo éxito) en
+// el valor de un comando.
+func commandResult(errObj *object.Error) object.Object {
+	if errObj != nil {
+		return errObj
+	}
+	return None
+}
+
+// moveTo posiciona el puntero en recno o, si es 0, en el fin de archivo
+// con BOF() también en True.
+func moveTo(wa *object.WorkArea, recno int) {
+	wa.Bof = recno == 0
+	if recno == 0 {
+		recno = wa.Table.RecordCount() + 1
+	}
+	wa.Recno = recno
+}
+
+// skipRecords avanza (o retrocede si n es negativo) n registros visibles.
+// Al pasar del último queda en el fin de archivo y al retroceder antes del
+// primero se queda en él con BOF() en True.
+func skipRecords(wa *object.WorkArea, env *object.Environment, n int) *object.Error {
+	if n > 0 && wa.Eof() {
+		return object.NewError("end of file encountered")
+	}
+	if n < 0 && wa.Bof {
+		return object.NewError("beginning of file encountered")
+	}
+	dir := 1
+	if n < 0 {
+		dir, n = -1, -n
+	}
+	wa.Bof = false
+	for ; n > 0; n-- {
+		current := wa.Recno
+		next := nextRecno(wa, current, dir)
+		if wa.Eof() && dir < 0 {
+			next = lastRecno(wa)
+		}
+		recno, errObj := seekVisible(wa, env, next, dir)
+		if errObj != nil {
+			return errObj
+		}
+		if recno == 0 {
+			if dir > 0 {
+				wa.Recno = wa.Table.RecordCount() + 1
+			} else {
+				wa.Recno = current
+				wa.Bof = true
+			}
+			break
+		}
+		wa.Recno = recno
+	}
+	return recordMoved(wa, env)
+}
+
+// firstRecno, lastRecno y nextRecno recorren la tabla en el orden del tag
+// activo (SET ORDER) o en orden físico; devuelven 0 cuando no hay más
+// registros.
+func firstRecno(wa *object.WorkArea) int {
+	if tag := orderTag(wa); tag != nil {
+		if wa.Reverse {
+			return tag.Last()
+		}
+		return tag.First()
+	}
+	if wa.Table.RecordCount() == 0 {
+		return 0
+	}
+	return 1
+}
+
+func lastRecno(wa *object.WorkArea) int {
+	if tag := orderTag(wa); tag != nil {
+		if wa.Reverse {
+			return tag.First()
+		}
+		return tag.Last()
+	}
+	return wa.Table.RecordCount()
+}
+
+func nextRecno(wa *object.WorkArea, recno int, dir int) int {
+	if tag := orderTag(wa); tag != nil {
+		if wa.Reverse {
+			dir = -dir
+		}
+		return tag.Next(recno, dir)
+	}
+	recno += dir
+	if recno < 1 || recno > wa.Table.RecordCount() {
+		return 0
+	}
+	return recno
+}
+
+// seekVisible busca desde recno, en la dirección dir, el primer registro que
+// cumple SET DELETED y SET FILTER; devuelve 0 si no hay ninguno. Deja el
+// puntero en el último registro examinado.
+func seekVisible(wa *object.WorkArea, env *object.Environment, recno int, dir int) (int, *object.Error) {
+	for ; recno != 0; recno = nextRecno(wa, recno, dir) {
+		ok, errObj := visible(wa, env, recno)
+		if errObj != nil {
+			return 0, errObj
+		}
+		if ok {
+			return recno, nil
+		}
+	}
+	return 0, nil
+}
+
+// visible indica si el registro recno no está oculto por SET DELETED ON ni
+// por el filtro del área.
+func visible(wa *object.WorkArea, env *object.Environment, recno int) (bool, *object.Error) {
+	if isOptionOn(env, "deleted") {
+		rec, err := wa.Table.Record(recno)
+		if err != nil {
+			return false, tableError(wa, err)
+		}
+		if rec.Deleted() {
+			return false, nil
+		}
+	}
+	if wa.Filter == nil {
+		return true, nil
+	}
+	wa.Recno = recno
+	if errObj := syncRelations(wa, env); errObj != nil {
+		return false, errObj
+	}
+	return evalCondition(wa.Filter, wa, env, "SET FILTER")
+}
+
+// evalInArea evalúa la expresión con wa como área de trabajo actual.
+func evalInArea(exp ast.Expression, wa *object.WorkArea, env *object.Environment) object.Object {
+	selected := env.SelectedArea()
+	env.SelectArea(wa.Number)
+	defer env.SelectArea(selected)
+	return Eval(exp, env)
+}
+
+// evalCondition evalúa una condición (FOR, WHILE, filtro...) sobre el
+// registro actual de wa.
+func evalCondition(exp ast.Expression, wa *object.WorkArea, env *object.Environment, clause string) (bool, *object.Error) {
+	val := evalInArea(exp, wa, env)
+	if errObj, ok := val.(*object.Error); ok {
+		return false, errObj
+	}
+	cond, ok := val.(*object.Boolean)
+	if !ok {
+		return false, object.NewError(fmt.Sprintf("%s: condition must be logical, got `%s`", clause, object.TypeToStr(val.Type())))
+	}
+	return cond.Value, nil
+}
+
+// recordMoved se ejecuta después de mover el puntero de wa: con buffer de
+// filas guarda el registro que se dejó y luego mueve las áreas hijas.
+func recordMoved(wa *object.WorkArea, env *object.Environment) *object.Error {
+	if errObj := commitRow(wa, env); errObj != nil {
+		return errObj
+	}
+	return syncRelations(wa, env)
+}
+
+// syncRelations mueve las áreas hijas relacionadas con wa (SET RELATION)
+// al registro que corresponde al registro actual del padre: si la hija
+// tiene un orden activo se busca la clave y si no la expresión es el número
+// de registro.
+func syncRelations(wa *object.WorkArea, env *object.Environment) *object.Error {
+	for _, rel := range wa.Relations {
+		child := env.AreaByAlias(rel.Child)
+		if child == nil {
+			continue
+		}
+		recno := child.Table.RecordCount() + 1
+		if !wa.Eof() {
+			key := evalInArea(rel.Expr, wa, env)
+			if isError(key) {
+				return key.(*object.Error)
+			}
+			if tag := orderTag(child); tag != nil {
+				found, errObj := seekKey(child, tag, key, env)
+				if errObj != nil {
+					return errObj
+				}
+				if found != 0 {
+					recno = found
+				}
+			} else {
+				num, ok := key.(*object.Integer)
+				if !ok {
+					return object.NewError(fmt.Sprintf("SET RELATION: expression must be numeric when `%s` has no index order, got `%s`", child.Alias, object.TypeToStr(key.Type())))
+				}
+				if n := int(num.Value); n >= 1 && n <= child.Table.RecordCount() {
+					recno = n
+				}
+			}
+		}
+		child.Recno = recno
+		child.Bof = false
+		if errObj := syncRelations(child, env); errObj != nil {
+			return errObj
+		}
+	}
+	return nil
+}
+
+// currentRecord lee el registro actual; en el fin de archivo devuelve un
+// registro vacío.
+func currentRecord(wa *object.WorkArea) (*dbf.Record, *object.Error) {
+	if wa.Eof() {
+		return wa.Table.Blank(), nil
+	}
+	rec, err := wa.Table.Record(wa.Recno)
+	if err != nil {
+		return nil, tableError(wa, err)
+	}
+	return rec, nil
+}
+
+// tableError convierte el error de una operación sobre la tabla de wa.
+func tableError(wa *object.WorkArea, err error) *object.Error {
+	return object.NewError(fmt.Sprintf("%s: %v", wa.Alias, err))
+}
+
+// fieldValue devuelve el valor del campo idx del registro actual.
+func fieldValue(wa *object.WorkArea, idx int) object.Object {
+	rec, errObj := currentRecord(wa)
+	if errObj != nil {
+		return errObj
+	}
+	value, err := rec.Value(idx)
+	if err != nil {
+		return tableError(wa, err)
+	}
+	return fromFieldValue(wa.Table.Fields[idx], value)
+}
+
+// lookupField busca un campo en el área de trabajo actual: FoxPro resuelve
+// los nombres de campo antes que las variables de memoria.
+func lookupField(name string, env *object.Environment) (object.Object, bool) {
+	wa := env.CurrentArea()
+	if wa == nil {
+		return nil, false
+	}
+	idx := wa.Table.FieldIndex(name)
+	if idx < 0 {
+		return nil, false
+	}
+	return fieldValue(wa, idx), true
+}
+
+// fromFieldValue convierte el valor leído de la tabla en un objeto.
+func fromFieldValue(field *dbf.Field, value interface{}) object.Object {
+	switch value := value.(type) {
+	case nil:
+		return Null
+	case string:
+		return &object.String{Value: value}
+	case float64:
+		return &object.Integer{Value: value}
+	case bool:
+		return toBoolean(value)
+	case time.Time:
+		return &object.Date{Value: value, DateTime: field.Type == dbf.DateTime}
+	}
+	return Null
+}
+
+// toFieldValue convierte un objeto en el valor que se guarda en el campo.
+func toFieldValue(field *dbf.Field, obj object.Object) (interface{}, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Null:
+		return nil, nil
+	case *object.String:
+		if field.Type == dbf.Character || field.Type == dbf.Memo {
+			return obj.Value, nil
+		}
+	case *object.Integer:
+		switch field.Type {
+		case dbf.Numeric, dbf.Float, dbf.Integer, dbf.Currency, dbf.Double:
+			return obj.Value, nil
+		}
+	case *object.Boolean:
+		if field.Type == dbf.Logical {
+			return obj.Value, nil
+		}
+	case *object.Date:
+		switch field.Type {
+		case dbf.Date:
+			t := obj.Value
+			if !t.IsZero() {
+				t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+			}
+			return t, nil
+		case dbf.DateTime:
+			return obj.Value, nil
+		}
+	}
+	return nil, object.NewError(fmt.Sprintf("data type mismatch: cannot store `%s` in field `%s` (%c)", object.TypeToStr(obj.Type()), field.Name, field.Type))
+}
+
+// tableStructure copia la definición de los campos de una tabla para crear
+// otra con la misma estructura.
+func tableStructure(fields []*dbf.Field) []dbf.Field {
+	structure := make([]dbf.Field, len(fields))
+	for i, field := range fields {
+		structure[i] = dbf.Field{
+			Name:     field.Name,
+			Type:     field.Type,
+			Length:   field.Length,
+			Decimals: field.Decimals,
+			Flags:    field.Flags,
+		}
+	}
+	return structure
+}
@@ -17,15 +17,17 @@ type Lexer struct {
 	ch        rune
 	line      int
 	col       int
+	start     int // posición del primer caracter del token actual
 	prevToken token.TokenType
 	symbol    map[string]token.TokenType
 	symbols   string
+	errors    []string
 }
 
 func New() *Lexer {
 	l := &Lexer{
 		symbol:   map[string]token.TokenType{},
-		symbols:  "+-*/^%=()[],¿?!<>.^",
+		symbols:  "+-*/^%=()[],¿?!<>.^$@",
 		line:     1,
 		col:      0,
 		fileName: "",
@@ -59,6 +61,8 @@ func New() *Lexer {
 	l.symbol[">"] = token.Greater
 	l.symbol[">="] = token.GreaterEq
 	l.symbol["."] = token.Dot
+	l.symbol["$"] = token.Contains
+	l.symbol["@"] = token.At
 
 	return l
 }
@@ -69,6 +73,7 @@ func (l *Lexer) newToken(ttype token.TokenType, lit string, col int) token.Token
 		Literal: lit,
 		Col:     col,
 		Line:    l.line,
+		Offset:  l.start,
 	}
 	l.prevToken = ttype
 	return t
@@ -80,6 +85,15 @@ func (l *Lexer) ScanText(input []rune) {
 	l.advance() // Avanza al primer caracter
 }
 
+// ScanExpression prepara el lexer para analizar una expresión construida en
+// tiempo de ejecución, por ejemplo el argumento de TYPE("expr"). En este modo
+// los errores léxicos se acumulan en Errors() en lugar de terminar el programa.
+func (l *Lexer) ScanExpression(input []rune) {
+	l.scanMode = 'e' // expression
+	l.input = input
+	l.advance() // Avanza al primer caracter
+}
+
 func (l *Lexer) ScanFile(fileName string) {
 	l.scanMode = 'f' // file
 	l.fileName = fileName
@@ -130,6 +144,13 @@ func (l *Lexer) NextToken() token.Token {
 			continue
 		} // l.isComment()
 
+		// continuación de línea
+		if l.isLineContinuation() {
+			l.skipLineContinuation()
+			continue
+		} // l.isLineContinuation()
+
+		l.start = l.pos
 		// identificadores
 		if isLetter(l.ch) {
 			col := l.col
@@ -186,6 +207,7 @@ func (l *Lexer) NextToken() token.Token {
 		return tok
 	} // for l.ch != rune(0)
 	// es EOF
+	l.start = len(l.input)
 	if l.prevToken != token.NewLine {
 		return l.newToken(token.NewLine, "", 0)
 	} else {
@@ -206,6 +228,10 @@ func (l *Lexer) isAtEnd() bool {
 }
 
 func (l *Lexer) printError(msg string) {
+	if l.scanMode == 'e' {
+		l.errors = append(l.errors, msg)
+		return
+	}
 	if l.scanMode == 'f' {
 		msg = fmt.Sprintf("%s:%d:%d: error: %s\n", l.fileName, l.line, l.col, msg)
 	}
@@ -213,6 +239,20 @@ func (l *Lexer) printError(msg string) {
 	os.Exit(1)
 }
 
+// Errors devuelve los errores léxicos acumulados en modo expresión.
+func (l *Lexer) Errors() []string {
+	return l.errors
+}
+
+// Source devuelve el texto del programa entre dos posiciones (Token.Offset),
+// sin los espacios de los extremos.
+func (l *Lexer) Source(from int, to int) string {
+	if from < 0 || to > len(l.input) || from > to {
+		return ""
+	}
+	return strings.TrimSpace(string(l.input[from:to]))
+}
+
 func (l *Lexer) GetFileName() string {
 	return l.fileName
 }
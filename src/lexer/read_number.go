@@ -5,6 +5,13 @@ func (l *Lexer) readNumber() string {
 	for isDigit(l.ch) {
 		l.advance()
 	}
+	// parte decimal: 3.14
+	if l.ch == '.' && isDigit(l.peek()) {
+		l.advance() // avanza el '.'
+		for isDigit(l.ch) {
+			l.advance()
+		}
+	}
 	return string(l.input[pos:l.pos])
 }
 
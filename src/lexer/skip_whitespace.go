@@ -9,3 +9,34 @@ func (l *Lexer) skipWhitespace() {
 func isSpace(ch rune) bool {
 	return ch == ' ' || ch == '\r' || ch == '\t'
 }
+
+// isLineContinuation indica si el caracter actual es un ';' al final de la
+// línea (seguido solo de espacios o de un comentario //): el comando sigue
+// en la línea siguiente.
+func (l *Lexer) isLineContinuation() bool {
+	if l.ch != ';' {
+		return false
+	}
+	for pos := l.peekPos; pos < len(l.input); pos++ {
+		switch ch := l.input[pos]; {
+		case ch == '\n':
+			return true
+		case ch == '/' && pos+1 < len(l.input) && l.input[pos+1] == '/':
+			return true
+		case !isSpace(ch):
+			return false
+		}
+	}
+	return true
+}
+
+// skipLineContinuation salta el ';' y el resto de la línea, incluido el
+// salto de línea.
+func (l *Lexer) skipLineContinuation() {
+	for !l.isAtEnd() && l.ch != '\n' {
+		l.advance()
+	}
+	if l.ch == '\n' {
+		l.advance()
+	}
+}
@@ -0,0 +1,26 @@
+package object
+
+import (
+	"bytes"
+	"strings"
+)
+
+type Array struct {
+	Elements []Object
+}
+
+func (a *Array) Type() ObjType {
+	return ArrayObj
+}
+
+func (a *Array) Inspect() string {
+	var out bytes.Buffer
+	var elements []string
+	for _, el := range a.Elements {
+		elements = append(elements, el.Inspect())
+	}
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+	return out.String()
+}
@@ -0,0 +1,19 @@
+package object
+
+// BuiltinFunction es la firma de las funciones nativas del lenguaje.
+// Reciben el environment desde donde se invocan para poder consultar
+// la configuración (SET) o el estado del intérprete.
+type BuiltinFunction func(env *Environment, args ...Object) Object
+
+type Builtin struct {
+	Name string
+	Fn   BuiltinFunction
+}
+
+func (b *Builtin) Type() ObjType {
+	return BuiltinObj
+}
+
+func (b *Builtin) Inspect() string {
+	return "builtin " + b.Name + "()"
+}
@@ -0,0 +1,117 @@
+package object
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+)
+
+// Transacciones de una conexión (SQLSETPROP(h, "Transactions")).
+const (
+	TransactionsAuto   = 1 // cada sentencia se confirma al ejecutarse
+	TransactionsManual = 2 // la transacción dura hasta SQLCOMMIT o SQLROLLBACK
+)
+
+// Connection es una conexión de SQL pass-through a una base de datos
+// SQLite. Todas sus sentencias usan la misma conexión física, así una
+// transacción manual abarca todos los SQLEXEC hasta SQLCOMMIT o
+// SQLROLLBACK.
+type Connection struct {
+	Handle        int
+	ConnectString string
+	Path          string // archivo de la base de datos
+	Transactions  int
+	DB            *sql.DB
+	Conn          *sql.Conn
+	Tx            *sql.Tx // transacción manual en curso (nil si no hay)
+
+	// sentencia preparada con SQLPREPARE
+	Prepared       *sql.Stmt
+	PreparedSQL    string
+	PreparedCursor string
+}
+
+// Close deshace la transacción en curso y cierra la conexión.
+func (c *Connection) Close() error {
+	var err error
+	if c.Tx != nil {
+		err = c.Tx.Rollback()
+		c.Tx = nil
+	}
+	if c.Prepared != nil {
+		c.Prepared.Close()
+		c.Prepared = nil
+	}
+	if closeErr := c.Conn.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := c.DB.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Begin empieza la transacción manual si todavía no hay una en curso.
+func (c *Connection) Begin(ctx context.Context) error {
+	if c.Tx != nil {
+		return nil
+	}
+	tx, err := c.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	c.Tx = tx
+	return nil
+}
+
+// AddConnection registra una conexión nueva y le asigna su handle, que
+// nunca se repite dentro de la sesión.
+func (e *Environment) AddConnection(c *Connection) int {
+	s := e.session
+	s.lastHandle++
+	c.Handle = s.lastHandle
+	s.connections[c.Handle] = c
+	return c.Handle
+}
+
+// Connection devuelve la conexión de un handle o nil si no existe.
+func (e *Environment) Connection(handle int) *Connection {
+	return e.session.connections[handle]
+}
+
+// Connections devuelve las conexiones abiertas ordenadas por handle.
+func (e *Environment) Connections() []*Connection {
+	var list []*Connection
+	for _, c := range e.session.connections {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Handle < list[j].Handle })
+	return list
+}
+
+// CloseConnection cierra una conexión y la quita de la sesión.
+func (e *Environment) CloseConnection(c *Connection) error {
+	delete(e.session.connections, c.Handle)
+	return c.Close()
+}
+
+// CloseConnections cierra todas las conexiones.
+func (e *Environment) CloseConnections() error {
+	var err error
+	for _, c := range e.Connections() {
+		if closeErr := e.CloseConnection(c); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// SetLastError guarda la fila que devolverá AERROR().
+func (e *Environment) SetLastError(info []Object) {
+	e.session.lastError = info
+}
+
+// LastError devuelve la fila del último error (nil si no hubo ninguno).
+func (e *Environment) LastError() []Object {
+	return e.session.lastError
+}
@@ -0,0 +1,92 @@
+package object
+
+import (
+	"FoxLite/src/dbf"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// OpenDatabase registra una base de datos abierta; con current pasa a ser
+// la base de datos actual.
+func (e *Environment) OpenDatabase(db *dbf.Database, current bool) {
+	e.session.databases = append(e.session.databases, db)
+	if current {
+		e.session.database = db
+	}
+}
+
+// Databases devuelve las bases de datos abiertas en el orden en que se
+// abrieron.
+func (e *Environment) Databases() []*dbf.Database {
+	return e.session.databases
+}
+
+// CurrentDatabase devuelve la base de datos actual o nil si no hay
+// ninguna.
+func (e *Environment) CurrentDatabase() *dbf.Database {
+	return e.session.database
+}
+
+// SetCurrentDatabase cambia la base de datos actual (nil para ninguna).
+func (e *Environment) SetCurrentDatabase(db *dbf.Database) {
+	e.session.database = db
+}
+
+// DatabaseByName busca una base de datos abierta por su nombre o por la
+// ruta de su archivo.
+func (e *Environment) DatabaseByName(name string) *dbf.Database {
+	abs, err := filepath.Abs(name)
+	for _, db := range e.session.databases {
+		if strings.EqualFold(db.Name(), name) {
+			return db
+		}
+		if dbAbs, dbErr := filepath.Abs(db.Path); err == nil && dbErr == nil && dbAbs == abs {
+			return db
+		}
+	}
+	return nil
+}
+
+// CloseDatabase cierra una base de datos y las tablas suyas que están
+// abiertas; durante una transacción no se puede cerrar.
+func (e *Environment) CloseDatabase(db *dbf.Database) error {
+	if e.session.txnLevel > 0 {
+		return fmt.Errorf("cannot close database `%s` during a transaction", db.Name())
+	}
+	var err error
+	for _, wa := range e.session.areas {
+		if wa.DBTable != nil && wa.DBTable.Database() == db {
+			if closeErr := e.closeArea(wa); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	for i, open := range e.session.databases {
+		if open == db {
+			e.session.databases = append(e.session.databases[:i], e.session.databases[i+1:]...)
+			break
+		}
+	}
+	if e.session.database == db {
+		e.session.database = nil
+	}
+	if closeErr := db.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// CloseDatabases cierra todas las bases de datos; sus tablas ya deben
+// estar cerradas.
+func (e *Environment) CloseDatabases() error {
+	var err error
+	for _, db := range e.session.databases {
+		if closeErr := db.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	e.session.databases = nil
+	e.session.database = nil
+	return err
+}
@@ -0,0 +1,141 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Date es un valor de tipo fecha (D) o fecha y hora (T). El valor cero de
+// time.Time representa la fecha vacía: {}
+type Date struct {
+	Value    time.Time
+	DateTime bool
+}
+
+func (d *Date) Type() ObjType {
+	if d.DateTime {
+		return DateTimeObj
+	}
+	return DateObj
+}
+
+func (d *Date) Inspect() string {
+	return DefaultFormat.Date(d.Value, d.DateTime)
+}
+
+// dateStyles indica el orden de día (d), mes (m) y año (y) y el separador
+// de cada formato de SET DATE ("" usa SET MARK).
+var dateStyles = map[string]struct {
+	order string
+	sep   string
+}{
+	"AMERICAN": {"mdy", "/"},
+	"ANSI":     {"ymd", "."},
+	"BRITISH":  {"dmy", "/"},
+	"FRENCH":   {"dmy", "/"},
+	"GERMAN":   {"dmy", "."},
+	"ITALIAN":  {"dmy", "-"},
+	"JAPAN":    {"ymd", "/"},
+	"TAIWAN":   {"ymd", "/"},
+	"USA":      {"mdy", "-"},
+	"MDY":      {"mdy", ""},
+	"DMY":      {"dmy", ""},
+	"YMD":      {"ymd", ""},
+	"SHORT":    {"mdy", "/"},
+	"LONG":     {"mdy", "/"},
+}
+
+// ParseDate convierte un texto en una fecha: AAAAMMDD, AAAAMMDDhhmmss,
+// AAAA-MM-DD o una fecha en el orden de SET DATE con cualquier separador
+// ('/', '-' o '.'), seguida opcionalmente de la hora (hh:mm[:ss] [AM|PM]).
+// Los años de dos dígitos se toman entre 1950 y 2049.
+func (f *Format) ParseDate(text string) (time.Time, bool) {
+	text = strings.TrimSpace(text)
+	if isDigits(text) && (len(text) == 8 || len(text) == 14) {
+		layout := "20060102150405"[:len(text)]
+		t, err := time.Parse(layout, text)
+		return t, err == nil
+	}
+	datePart, timePart, _ := strings.Cut(text, " ")
+	if d, t, ok := strings.Cut(datePart, "T"); ok && timePart == "" {
+		datePart, timePart = d, t
+	}
+	parts := strings.FieldsFunc(datePart, func(r rune) bool { return r == '/' || r == '-' || r == '.' })
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	order := dateStyles[f.DateStyle].order
+	if order == "" || len(parts[0]) == 4 {
+		order = "ymd"
+	}
+	var year, month, day int
+	for i, part := range order {
+		if !isDigits(parts[i]) {
+			return time.Time{}, false
+		}
+		var n int
+		fmt.Sscan(parts[i], &n)
+		switch part {
+		case 'y':
+			if len(parts[i]) <= 2 {
+				n += 1900
+				if n < 1950 {
+					n += 100
+				}
+			}
+			year = n
+		case 'm':
+			month = n
+		case 'd':
+			day = n
+		}
+	}
+	var hour, minute, second int
+	if timePart = strings.ToUpper(strings.TrimSpace(timePart)); timePart != "" {
+		pm, am := strings.HasSuffix(timePart, "PM"), strings.HasSuffix(timePart, "AM")
+		timePart = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(timePart, "PM"), "AM"))
+		if dot := strings.IndexByte(timePart, '.'); dot >= 0 {
+			timePart = timePart[:dot]
+		}
+		fields := strings.Split(timePart, ":")
+		if len(fields) < 2 || len(fields) > 3 {
+			return time.Time{}, false
+		}
+		values := []*int{&hour, &minute, &second}
+		for i, field := range fields {
+			if !isDigits(field) {
+				return time.Time{}, false
+			}
+			fmt.Sscan(field, values[i])
+		}
+		switch {
+		case (pm || am) && (hour < 1 || hour > 12):
+			return time.Time{}, false
+		case pm && hour < 12:
+			hour += 12
+		case am && hour == 12:
+			hour = 0
+		}
+		if hour > 23 || minute > 59 || second > 59 {
+			return time.Time{}, false
+		}
+	}
+	t := time.Date(year, time.Month(month), day, hour, minute, second, 0, time.UTC)
+	if t.Year() != year || int(t.Month()) != month || t.Day() != day {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func isDigits(text string) bool {
+	if text == "" {
+		return false
+	}
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package object
+
+import "strings"
+
+// Empty es un objeto sin métodos que solo guarda propiedades, como el de
+// la clase Empty de Visual FoxPro: CREATEOBJECT("Empty"), ADDPROPERTY() o
+// SCATTER NAME. Los nombres de las propiedades no distinguen mayúsculas y
+// se recorren en el orden en que se agregaron.
+type Empty struct {
+	names  []string
+	values map[string]Object
+}
+
+func NewEmpty() *Empty {
+	return &Empty{values: map[string]Object{}}
+}
+
+func (e *Empty) Type() ObjType {
+	return EmptyObj
+}
+
+func (e *Empty) Inspect() string {
+	return "object"
+}
+
+// Get devuelve el valor de una propiedad.
+func (e *Empty) Get(name string) (Object, bool) {
+	val, ok := e.values[strings.ToUpper(name)]
+	return val, ok
+}
+
+// Set asigna una propiedad y la agrega si no existe.
+func (e *Empty) Set(name string, val Object) {
+	key := strings.ToUpper(name)
+	if _, ok := e.values[key]; !ok {
+		e.names = append(e.names, name)
+	}
+	e.values[key] = val
+}
+
+// Names devuelve los nombres de las propiedades en orden.
+func (e *Empty) Names() []string {
+	return append([]string{}, e.names...)
+}
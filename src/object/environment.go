@@ -1,30 +1,149 @@
 package object
 
+import (
+	"path"
+	"strings"
+)
+
+// Vector es el espacio donde vive una variable junto con su ámbito:
+// 'l' local, 'p' private o 'g' public.
 type Vector struct {
 	Scope byte
 	Value Object
 }
 
+// mask oculta a una rutina (y a las que ésta invoque) las variables de las
+// rutinas que la llamaron: PRIVATE ALL LIKE l* | PRIVATE ALL EXCEPT g*
+type mask struct {
+	skeleton string
+	except   bool
+}
+
+// Environment guarda las variables de una rutina en ejecución siguiendo la
+// semántica de Visual FoxPro:
+//   - las variables PUBLIC viven en una tabla global compartida por todo el
+//     intérprete y son visibles desde cualquier rutina.
+//   - las variables PRIVATE pertenecen a la rutina que las crea y son visibles
+//     desde las rutinas que ésta invoque (se buscan recorriendo la pila de
+//     llamadas); se liberan al terminar la rutina.
+//   - las variables LOCAL solo son visibles dentro de su rutina.
+//
+// Además los bloques (cada iteración de un For) y los closures son léxicos:
+// ven todas las variables del environment donde fueron definidos.
 type Environment struct {
-	storage map[string]*Vector
-	outer   *Environment
+	storage  map[string]*Vector
+	outer    *Environment       // environment léxico: bloque o closure -> donde se definió
+	caller   *Environment       // rutina que invocó a ésta (pila de llamadas)
+	globals  map[string]*Vector // variables PUBLIC compartidas por todo el intérprete
+	hidden   []mask             // PRIVATE ALL LIKE | EXCEPT
+	settings *settings          // comandos SET, compartidos por todo el intérprete
+	kind     byte               // 'r' rutina, 'c' closure, 'b' bloque (iteración de un For)
+	args     []Object           // argumentos recibidos por la rutina (nil en el programa principal)
+	session  *session           // librerías, archivos en ejecución y áreas de trabajo del intérprete
+	row      RowSource          // fila de la consulta SQL que se está evaluando (nil fuera de SQL)
 }
 
+// NewEnv crea el environment del programa principal.
 func NewEnv() *Environment {
 	e := &Environment{
-		storage: map[string]*Vector{},
-		outer:   nil,
+		storage:  map[string]*Vector{},
+		outer:    nil,
+		globals:  map[string]*Vector{},
+		settings: newSettings(),
+		kind:     'r',
+		session:  newSession(),
 	}
 	return e
 }
 
+// NewEnclosedEnv crea un environment que comparte las variables públicas y
+// la configuración de outer.
 func NewEnclosedEnv(outer *Environment) *Environment {
 	e := NewEnv()
 	e.outer = outer
+	e.globals = outer.globals
+	e.settings = outer.settings
+	e.session = outer.session
+	return e
+}
+
+// NewRoutineEnv crea el environment de la llamada a una rutina desde caller.
+func NewRoutineEnv(outer *Environment, caller *Environment) *Environment {
+	e := NewEnclosedEnv(outer)
+	e.caller = caller.routine()
 	return e
 }
 
-func (e *Environment) Set(name string, scope byte, value Object) Object {
+// NewClosureEnv crea el environment de la llamada a un closure: a diferencia
+// de una rutina, el closure ve todas las variables (incluidas las locales)
+// del environment donde fue definido.
+func NewClosureEnv(outer *Environment, caller *Environment) *Environment {
+	e := NewRoutineEnv(outer, caller)
+	e.kind = 'c'
+	return e
+}
+
+// NewBlockEnv crea el environment de una iteración de un bucle. Solo guarda
+// las variables definidas con Define (las del bucle), de modo que cada
+// iteración tiene su propia copia y los closures creados en ella la
+// conservan; el resto de variables pertenecen a la rutina.
+func NewBlockEnv(outer *Environment) *Environment {
+	e := NewEnclosedEnv(outer)
+	e.kind = 'b'
+	return e
+}
+
+// HasOuter indica si el environment está anidado dentro de otro, es decir,
+// que no es el environment global del programa.
+func (e *Environment) HasOuter() bool {
+	return e.outer != nil
+}
+
+// routine devuelve el environment de la rutina en ejecución, atravesando
+// los bloques de los bucles.
+func (e *Environment) routine() *Environment {
+	env := e
+	for env.kind == 'b' && env.outer != nil {
+		env = env.outer
+	}
+	return env
+}
+
+// SetArguments guarda los argumentos con los que se invocó la rutina.
+func (e *Environment) SetArguments(args []Object) {
+	e.args = args
+}
+
+// Arguments devuelve los argumentos de la rutina que se está ejecutando.
+func (e *Environment) Arguments() []Object {
+	return e.routine().args
+}
+
+// Set asigna una variable sin declaración previa: si hay una variable
+// visible con ese nombre se actualiza (aunque pertenezca a una rutina
+// anterior de la pila), si no se crea como PRIVATE de la rutina actual.
+func (e *Environment) Set(name string, value Object) Object {
+	if vec := e.lookup(name); vec != nil {
+		// Actualizamos el vector existente ya que puede estar compartido
+		// con otra rutina (parámetro pasado por referencia).
+		vec.Value = value
+		return value
+	}
+	return e.Declare(name, 'p', value)
+}
+
+// Declare crea la variable con el ámbito indicado: 'l' y 'p' en la rutina
+// actual y 'g' en la tabla de variables públicas.
+func (e *Environment) Declare(name string, scope byte, value Object) Object {
+	if scope == 'g' {
+		e.globals[name] = &Vector{Scope: scope, Value: value}
+		return value
+	}
+	return e.routine().Define(name, scope, value)
+}
+
+// Define crea (o reemplaza) la variable en este mismo environment.
+func (e *Environment) Define(name string, scope byte, value Object) Object {
 	// Creamos un nuevo vector
 	v := &Vector{
 		Scope: scope,
@@ -34,21 +153,153 @@ func (e *Environment) Set(name string, scope byte, value Object) Object {
 	return value
 }
 
-func (e *Environment) Get(name string, outCall bool) Object {
-	if vec, ok := e.storage[name]; ok {
-		if outCall { // si llaman desde afuera: devolvemos sin validar scope
-			return vec.Value
-		} else {
-			// si es una llamada recursiva (interna): devolvemos solo private y public
-			if vec.Scope == 'p' || vec.Scope == 'g' {
-				return vec.Value
+// Bind enlaza el nombre con un vector ya existente de otro environment, de
+// modo que ambos comparten el valor (paso de parámetros por referencia).
+func (e *Environment) Bind(name string, vec *Vector) {
+	e.routine().storage[name] = vec
+}
+
+// Hide oculta a la rutina actual las variables de las rutinas anteriores
+// cuyo nombre coincide con skeleton (o no coincide, si except es true).
+func (e *Environment) Hide(skeleton string, except bool) {
+	r := e.routine()
+	r.hidden = append(r.hidden, mask{skeleton: skeleton, except: except})
+}
+
+func (e *Environment) hides(name string) bool {
+	for _, m := range e.hidden {
+		if MatchSkeleton(m.skeleton, name) != m.except {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Environment) Get(name string) Object {
+	if vec := e.lookup(name); vec != nil {
+		return vec.Value
+	}
+	return nil
+}
+
+// GetVector devuelve el vector donde se guarda la variable visible con ese
+// nombre, o nil si no existe.
+func (e *Environment) GetVector(name string) *Vector {
+	return e.lookup(name)
+}
+
+func (e *Environment) lookup(name string) *Vector {
+	_, vec := e.locate(name)
+	return vec
+}
+
+// locate busca la variable visible con ese nombre y devuelve también la
+// tabla donde está guardada.
+func (e *Environment) locate(name string) (map[string]*Vector, *Vector) {
+	// 1. la rutina actual, sus bloques y, si es un closure, el environment
+	// donde fue definido (todas las variables, incluidas las locales).
+	for env := e; env != nil; env = env.outer {
+		if vec, ok := env.storage[name]; ok {
+			return env.storage, vec
+		}
+		if env.kind == 'r' {
+			break
+		}
+	}
+	// 2. las variables PRIVATE de las rutinas de la pila de llamadas, salvo
+	// que alguna rutina las oculte con PRIVATE ALL.
+	current := e.routine()
+	for r := current; r != nil; r = r.caller {
+		if r != current {
+			if vec, ok := r.storage[name]; ok && vec.Scope == 'p' {
+				return r.storage, vec
 			}
-			return nil
 		}
-	} else {
-		if e.outer != nil {
-			return e.outer.Get(name, false)
+		if r.hides(name) {
+			return nil, nil
 		}
 	}
-	return nil
+	// 3. las variables PUBLIC
+	if vec, ok := e.globals[name]; ok {
+		return e.globals, vec
+	}
+	return nil, nil
+}
+
+// Release elimina la variable visible con ese nombre.
+func (e *Environment) Release(name string) bool {
+	owner, vec := e.locate(name)
+	if vec == nil {
+		return false
+	}
+	delete(owner, name)
+	return true
+}
+
+// ReleaseAll elimina las variables de la rutina actual cuyo nombre coincide
+// con skeleton (o no coincide, si except es true). En el programa principal
+// también elimina las variables públicas. Las funciones no se consideran
+// variables de memoria y no se eliminan.
+func (e *Environment) ReleaseAll(skeleton string, except bool) {
+	r := e.routine()
+	releaseMatching(r.storage, skeleton, except, 0)
+	if r.caller == nil && r.outer == nil {
+		releaseMatching(e.globals, skeleton, except, 0)
+	}
+}
+
+// ClearMemory elimina todas las variables públicas y las privadas de las
+// rutinas de la pila de llamadas (CLEAR MEMORY).
+func (e *Environment) ClearMemory() {
+	releaseMatching(e.globals, "*", false, 0)
+	for r := e.routine(); r != nil; r = r.caller {
+		releaseMatching(r.storage, "*", false, 'p')
+	}
+}
+
+func releaseMatching(storage map[string]*Vector, skeleton string, except bool, scope byte) {
+	for name, vec := range storage {
+		if vec.Value.Type() == FuncObj || (scope != 0 && vec.Scope != scope) {
+			continue
+		}
+		if MatchSkeleton(skeleton, name) != except {
+			delete(storage, name)
+		}
+	}
+}
+
+// Visible devuelve todas las variables visibles desde la rutina actual
+// (LIST MEMORY, SAVE TO), excepto las funciones.
+func (e *Environment) Visible() map[string]*Vector {
+	var names []string
+	for env := e; env != nil; env = env.outer {
+		for name := range env.storage {
+			names = append(names, name)
+		}
+		if env.kind == 'r' {
+			break
+		}
+	}
+	for r := e.routine().caller; r != nil; r = r.caller {
+		for name := range r.storage {
+			names = append(names, name)
+		}
+	}
+	for name := range e.globals {
+		names = append(names, name)
+	}
+	visible := map[string]*Vector{}
+	for _, name := range names {
+		if vec := e.lookup(name); vec != nil && vec.Value.Type() != FuncObj {
+			visible[name] = vec
+		}
+	}
+	return visible
+}
+
+// MatchSkeleton compara un nombre con un patrón de FoxPro sin distinguir
+// mayúsculas: '*' equivale a cualquier secuencia y '?' a un solo caracter.
+func MatchSkeleton(skeleton string, name string) bool {
+	ok, err := path.Match(strings.ToLower(skeleton), strings.ToLower(name))
+	return err == nil && ok
 }
@@ -2,6 +2,7 @@ package object
 
 type Error struct {
 	Message string
+	File    string // archivo donde se produjo el error ("" si no se conoce)
 }
 
 func (e *Error) Type() ObjType {
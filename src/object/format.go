@@ -0,0 +1,127 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format es el formato con el que un intérprete muestra los valores según
+// SET DECIMALS, SET FIXED, SET POINT, SET DATE, SET CENTURY y SET MARK.
+type Format struct {
+	Decimals  int
+	Fixed     bool
+	Point     string
+	DateStyle string
+	Century   bool
+	Mark      string
+}
+
+// DefaultFormat es el formato con los valores por defecto de los comandos
+// SET. Lo usa Inspect, que no depende de ningún intérprete.
+var DefaultFormat = &Format{Decimals: 2, Point: ".", DateStyle: "AMERICAN", Mark: "/"}
+
+// Format devuelve el formato actual del intérprete.
+func (e *Environment) Format() *Format {
+	f := &Format{Point: ".", DateStyle: "AMERICAN", Mark: "/"}
+	if num, ok := e.GetOption("decimals").(*Integer); ok {
+		f.Decimals = int(num.Value)
+	}
+	if b, ok := e.GetOption("fixed").(*Boolean); ok {
+		f.Fixed = b.Value
+	}
+	if str, ok := e.GetOption("point").(*String); ok && str.Value != "" {
+		f.Point = str.Value
+	}
+	if str, ok := e.GetOption("date").(*String); ok {
+		f.DateStyle = strings.ToUpper(str.Value)
+	}
+	if b, ok := e.GetOption("century").(*Boolean); ok {
+		f.Century = b.Value
+	}
+	if str, ok := e.GetOption("mark").(*String); ok && str.Value != "" {
+		f.Mark = str.Value
+	}
+	return f
+}
+
+// Number convierte un número en texto redondeándolo a SET DECIMALS
+// decimales. Con SET FIXED OFF se omiten los ceros a la derecha.
+func (f *Format) Number(value float64) string {
+	text := strconv.FormatFloat(value, 'f', f.Decimals, 64)
+	if !f.Fixed && strings.Contains(text, ".") {
+		text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+	if text == "-0" {
+		text = "0"
+	}
+	if f.Point != "." {
+		text = strings.Replace(text, ".", f.Point, 1)
+	}
+	return text
+}
+
+// Date convierte una fecha en texto según SET DATE, SET CENTURY y SET MARK.
+// Las fechas y horas agregan la hora en formato de 12 horas.
+func (f *Format) Date(t time.Time, dateTime bool) string {
+	style := dateStyles[f.DateStyle]
+	sep := style.sep
+	if sep == "" || f.Mark != "/" {
+		sep = f.Mark
+	}
+	if f.DateStyle == "LONG" && !t.IsZero() {
+		return t.Format("Monday, January 2, 2006")
+	}
+	var parts []string
+	for _, part := range style.order {
+		switch {
+		case t.IsZero() && part == 'y' && f.Century:
+			parts = append(parts, "    ")
+		case t.IsZero():
+			parts = append(parts, "  ")
+		case part == 'd':
+			parts = append(parts, fmt.Sprintf("%02d", t.Day()))
+		case part == 'm':
+			parts = append(parts, fmt.Sprintf("%02d", int(t.Month())))
+		case f.Century:
+			parts = append(parts, fmt.Sprintf("%04d", t.Year()))
+		default:
+			parts = append(parts, fmt.Sprintf("%02d", t.Year()%100))
+		}
+	}
+	text := strings.Join(parts, sep)
+	if dateTime {
+		if t.IsZero() {
+			return text + "   :  :   "
+		}
+		text += " " + t.Format("03:04:05 PM")
+	}
+	return text
+}
+
+// Inspect es como obj.Inspect() pero con este formato.
+func (f *Format) Inspect(obj Object) string {
+	switch obj := obj.(type) {
+	case *Integer:
+		return f.Number(obj.Value)
+	case *Date:
+		return f.Date(obj.Value, obj.DateTime)
+	case *Array:
+		var out bytes.Buffer
+		var elements []string
+		for _, el := range obj.Elements {
+			elements = append(elements, f.Inspect(el))
+		}
+		out.WriteString("[")
+		out.WriteString(strings.Join(elements, ", "))
+		out.WriteString("]")
+		return out.String()
+	case *Reference:
+		return f.Inspect(obj.Vector.Value)
+	case *Return:
+		return f.Inspect(obj.Value)
+	}
+	return obj.Inspect()
+}
@@ -4,9 +4,11 @@ import "FoxLite/src/ast"
 
 type Function struct {
 	Name       string
-	Parameters []*ast.Literal
+	Parameters []*ast.Parameter
 	Body       *ast.BlockStmt
 	Env        *Environment
+	Closure    bool   // captura también las variables locales de Env
+	File       string // archivo donde se definió (para los mensajes de error)
 }
 
 func (f *Function) Type() ObjType {
@@ -1,7 +1,5 @@
 package object
 
-import "fmt"
-
 type Integer struct {
 	Value float64
 }
@@ -11,5 +9,5 @@ func (i *Integer) Type() ObjType {
 }
 
 func (i *Integer) Inspect() string {
-	return fmt.Sprintf("%v", i.Value)
+	return DefaultFormat.Number(i.Value)
 }
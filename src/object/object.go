@@ -14,6 +14,12 @@ const (
 	ExitObj
 	LoopObj
 	ClassObj
+	BuiltinObj
+	ArrayObj
+	ReferenceObj
+	DateObj
+	DateTimeObj
+	EmptyObj
 )
 
 type Object interface {
@@ -35,7 +41,62 @@ func TypeToStr(t ObjType) string {
 		return "bool"
 	case NullObj:
 		return "null"
+	case FuncObj:
+		return "function"
+	case BuiltinObj:
+		return "builtin function"
+	case ClassObj:
+		return "class"
+	case ArrayObj:
+		return "array"
+	case ReferenceObj:
+		return "reference"
+	case DateObj:
+		return "date"
+	case DateTimeObj:
+		return "datetime"
+	case EmptyObj:
+		return "object"
+	case NoneObj:
+		return "none"
+	case ReturnObj:
+		return "return"
+	case ErrorObj:
+		return "error"
+	case ExitObj:
+		return "exit"
+	case LoopObj:
+		return "loop"
 	default:
 		return ""
 	}
 }
+
+// TypeToCode devuelve el código de una letra con el que VARTYPE() y TYPE()
+// identifican cada tipo, siguiendo la convención de Visual FoxPro:
+// C (string), N (number), L (bool), D (date), T (datetime), X (null),
+// O (objeto o clase), A (array), F (función) y U (indefinido).
+func TypeToCode(t ObjType) string {
+	switch t {
+	case IntegerObj:
+		return "N"
+	case StringObj:
+		return "C"
+	case BooleanObj:
+		return "L"
+	case DateObj:
+		return "D"
+	case DateTimeObj:
+		return "T"
+	case NullObj:
+		return "X"
+	case ClassObj, EmptyObj:
+		return "O"
+	case ArrayObj:
+		return "A"
+	case FuncObj, BuiltinObj:
+		return "F"
+	default:
+		return "U"
+	}
+}
@@ -0,0 +1,17 @@
+package object
+
+// Reference es un argumento pasado por referencia (@lnTotal o DO ... WITH).
+// Nunca llega a ser el valor de una variable: al invocar la rutina el
+// parámetro se enlaza con el mismo Vector de la variable del llamador.
+type Reference struct {
+	Name   string
+	Vector *Vector
+}
+
+func (r *Reference) Type() ObjType {
+	return ReferenceObj
+}
+
+func (r *Reference) Inspect() string {
+	return r.Vector.Value.Inspect()
+}
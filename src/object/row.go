@@ -0,0 +1,62 @@
+package object
+
+// RowSource da acceso a las columnas de la fila que evalúa una consulta SQL:
+// mientras se evalúan sus expresiones los nombres de las columnas tienen
+// prioridad sobre los campos del área actual y las variables de memoria.
+type RowSource interface {
+	// Column devuelve el valor de la columna name de la tabla qualifier
+	// ("" para buscarla en todas) y false si la fila no la tiene.
+	Column(qualifier string, name string) (Object, bool)
+	// Record devuelve el número de registro y la marca de borrado de la
+	// fila de la tabla qualifier ("" para la primera de la consulta) y false
+	// si la consulta no tiene esa tabla.
+	Record(qualifier string) (recno int, deleted bool, ok bool)
+}
+
+// NewRowEnv crea el environment donde se evalúan las expresiones de una
+// consulta SQL sobre las filas de row.
+func NewRowEnv(outer *Environment, row RowSource) *Environment {
+	e := NewBlockEnv(outer)
+	e.row = row
+	return e
+}
+
+// Column busca una columna en las filas de las consultas en evaluación,
+// desde la más interna (una subconsulta) hasta la más externa.
+func (e *Environment) Column(qualifier string, name string) (Object, bool) {
+	for env := e; env != nil; env = env.outer {
+		if env.row == nil {
+			continue
+		}
+		if val, ok := env.row.Column(qualifier, name); ok {
+			return val, true
+		}
+	}
+	return nil, false
+}
+
+// Record busca el registro de una tabla en las filas de las consultas en
+// evaluación, desde la más interna hasta la más externa. Sin qualifier se
+// usa la primera tabla de la consulta más interna.
+func (e *Environment) Record(qualifier string) (int, bool, bool) {
+	for env := e; env != nil; env = env.outer {
+		if env.row == nil {
+			continue
+		}
+		if recno, deleted, ok := env.row.Record(qualifier); ok {
+			return recno, deleted, true
+		}
+	}
+	return 0, false, false
+}
+
+// RowSource devuelve la fila de la consulta SQL más interna en evaluación o
+// nil fuera de SQL.
+func (e *Environment) RowSource() RowSource {
+	for env := e; env != nil; env = env.outer {
+		if env.row != nil {
+			return env.row
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package object
+
+import (
+	"FoxLite/src/dbf"
+	"math/rand"
+)
+
+// Semilla inicial de RAND(), la misma que usa Visual FoxPro al arrancar.
+const defaultRandSeed = 100001
+
+// Library es un archivo de procedimientos abierto con SET PROCEDURE TO: sus
+// funciones y clases se pueden invocar desde cualquier rutina.
+type Library struct {
+	File       string
+	Procedures map[string]Object
+}
+
+// session guarda el estado del intérprete que comparten todos sus
+// environments y que no son variables: las librerías abiertas con
+// SET PROCEDURE, la pila de archivos en ejecución, las áreas de trabajo,
+// las bases de datos abiertas, las conexiones de SQL pass-through con su
+// último error y la secuencia de RAND().
+type session struct {
+	libraries   []*Library
+	files       []string
+	areas       map[int]*WorkArea
+	selected    int
+	txnLevel    int // niveles de BEGIN TRANSACTION en curso
+	databases   []*dbf.Database
+	database    *dbf.Database // base de datos actual (SET DATABASE TO)
+	connections map[int]*Connection
+	lastHandle  int      // último handle de conexión asignado
+	lastError   []Object // fila de AERROR() con el último error de SQL pass-through
+	rand        *rand.Rand
+}
+
+func newSession() *session {
+	return &session{
+		areas:       map[int]*WorkArea{},
+		selected:    1,
+		connections: map[int]*Connection{},
+		rand:        rand.New(rand.NewSource(defaultRandSeed)),
+	}
+}
+
+// Rand devuelve el generador de números aleatorios de RAND().
+func (e *Environment) Rand() *rand.Rand {
+	return e.session.rand
+}
+
+// OpenLibrary agrega una librería a SET PROCEDURE; si el archivo ya estaba
+// abierto se reemplaza por la nueva versión.
+func (e *Environment) OpenLibrary(lib *Library) {
+	s := e.session
+	for idx, open := range s.libraries {
+		if open.File == lib.File {
+			s.libraries[idx] = lib
+			return
+		}
+	}
+	s.libraries = append(s.libraries, lib)
+}
+
+// CloseLibraries cierra todas las librerías de SET PROCEDURE.
+func (e *Environment) CloseLibraries() {
+	e.session.libraries = nil
+}
+
+// Libraries devuelve las librerías abiertas en el orden en que se abrieron.
+func (e *Environment) Libraries() []*Library {
+	return e.session.libraries
+}
+
+// GetProcedure busca una función o clase en las librerías abiertas.
+func (e *Environment) GetProcedure(name string) Object {
+	for _, lib := range e.session.libraries {
+		if proc, ok := lib.Procedures[name]; ok {
+			return proc
+		}
+	}
+	return nil
+}
+
+// EnterFile apila el archivo que empieza a ejecutarse (o cargarse). Devuelve
+// false si el archivo ya está en la pila, es decir, si la inclusión es
+// circular.
+func (e *Environment) EnterFile(fileName string) bool {
+	s := e.session
+	for _, file := range s.files {
+		if file == fileName {
+			return false
+		}
+	}
+	s.files = append(s.files, fileName)
+	return true
+}
+
+// LeaveFile desapila el último archivo en ejecución.
+func (e *Environment) LeaveFile() {
+	s := e.session
+	if len(s.files) > 0 {
+		s.files = s.files[:len(s.files)-1]
+	}
+}
+
+// CurrentFile devuelve el archivo que se está ejecutando ("" en el REPL).
+func (e *Environment) CurrentFile() string {
+	s := e.session
+	if len(s.files) == 0 {
+		return ""
+	}
+	return s.files[len(s.files)-1]
+}
+
+// Files devuelve la pila de archivos en ejecución, del primero al último.
+func (e *Environment) Files() []string {
+	return e.session.files
+}
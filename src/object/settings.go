@@ -0,0 +1,183 @@
+package object
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SettingDef describe un comando SET: su tipo, el valor por defecto y, según
+// el tipo, los valores permitidos.
+//   - 'L' lógico: SET EXACT ON | OFF
+//   - 'N' numérico entre Min y Max o una palabra clave de Named: SET
+//     DECIMALS TO 4, SET REPROCESS TO AUTOMATIC
+//   - 'C' caracter libre: SET POINT TO ","
+//   - 'K' una palabra clave de Keywords: SET DATE TO BRITISH
+type SettingDef struct {
+	Kind     byte
+	Default  Object
+	Min, Max float64
+	Keywords []string
+	Named    map[string]float64
+}
+
+// settingDefs es la tabla de comandos SET que reconoce el intérprete.
+var settingDefs = map[string]*SettingDef{
+	"exact":     {Kind: 'L', Default: &Boolean{Value: false}},
+	"century":   {Kind: 'L', Default: &Boolean{Value: false}},
+	"null":      {Kind: 'L', Default: &Boolean{Value: false}},
+	"fixed":     {Kind: 'L', Default: &Boolean{Value: false}},
+	"decimals":  {Kind: 'N', Default: &Integer{Value: 2}, Min: 0, Max: 18},
+	"point":     {Kind: 'C', Default: &String{Value: "."}},
+	"mark":      {Kind: 'C', Default: &String{Value: "/"}},
+	"exclusive": {Kind: 'L', Default: &Boolean{Value: true}},
+	"deleted":   {Kind: 'L', Default: &Boolean{Value: false}},
+	"path":      {Kind: 'C', Default: &String{Value: ""}},
+	// SET SAFETY ON: los comandos que crean archivos (COPY TO, CREATE
+	// TABLE, TOTAL, SAVE TO...) no reemplazan los que ya existen
+	"safety": {Kind: 'L', Default: &Boolean{Value: true}},
+	"date": {Kind: 'K', Default: &String{Value: "AMERICAN"}, Keywords: []string{
+		"AMERICAN", "ANSI", "BRITISH", "FRENCH", "GERMAN", "ITALIAN", "JAPAN",
+		"TAIWAN", "USA", "MDY", "DMY", "YMD", "SHORT", "LONG",
+	}},
+	"collate":  {Kind: 'K', Default: &String{Value: "MACHINE"}, Keywords: []string{"MACHINE", "GENERAL"}},
+	"udfparms": {Kind: 'K', Default: &String{Value: "VALUE"}, Keywords: []string{"VALUE", "REFERENCE"}},
+	// SET REPROCESS TO n: 0 un intento, n intentos (n segundos si
+	// REPROCESSSECONDS está activo, como con SET REPROCESS TO n SECONDS) y
+	// -1 o AUTOMATIC hasta conseguir el bloqueo
+	"reprocess": {Kind: 'N', Default: &Integer{Value: 0}, Min: -1, Max: 32000,
+		Named: map[string]float64{"AUTOMATIC": -1}},
+	"reprocessseconds": {Kind: 'L', Default: &Boolean{Value: false}},
+	"multilocks":       {Kind: 'L', Default: &Boolean{Value: false}},
+}
+
+// SettingNames devuelve los nombres de los comandos SET ordenados.
+func SettingNames() []string {
+	var names []string
+	for name := range settingDefs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupSetting devuelve la definición de un comando SET.
+func LookupSetting(name string) (*SettingDef, bool) {
+	def, ok := settingDefs[strings.ToLower(name)]
+	return def, ok
+}
+
+// settings guarda los valores de los comandos SET de un intérprete. Solo se
+// guardan los valores asignados; el resto toma el valor por defecto.
+type settings struct {
+	values map[string]Object
+	stack  []map[string]Object // PUSH SET | POP SET
+}
+
+func newSettings() *settings {
+	return &settings{values: map[string]Object{}}
+}
+
+// SetOption asigna el valor de un comando SET validando su tipo. Un valor
+// nil restablece el valor por defecto (SET PATH TO).
+func (e *Environment) SetOption(name string, value Object) *Error {
+	name = strings.ToLower(name)
+	def, ok := settingDefs[name]
+	if !ok {
+		return NewError(fmt.Sprintf("unknown SET command `%s`", strings.ToUpper(name)))
+	}
+	if value == nil {
+		delete(e.settings.values, name)
+		return nil
+	}
+	value, err := def.convert(name, value)
+	if err != nil {
+		return err
+	}
+	e.settings.values[name] = value
+	return nil
+}
+
+// GetOption devuelve el valor de un comando SET (o su valor por defecto);
+// nil si el comando no existe.
+func (e *Environment) GetOption(name string) Object {
+	name = strings.ToLower(name)
+	if value, ok := e.settings.values[name]; ok {
+		return value
+	}
+	if def, ok := settingDefs[name]; ok {
+		return def.Default
+	}
+	return nil
+}
+
+// PushOptions guarda el valor actual de todos los comandos SET.
+func (e *Environment) PushOptions() {
+	saved := make(map[string]Object, len(e.settings.values))
+	for name, value := range e.settings.values {
+		saved[name] = value
+	}
+	e.settings.stack = append(e.settings.stack, saved)
+}
+
+// PopOptions restablece los valores guardados por el último PushOptions.
+// Devuelve false si no hay valores guardados.
+func (e *Environment) PopOptions() bool {
+	s := e.settings
+	if len(s.stack) == 0 {
+		return false
+	}
+	s.values = s.stack[len(s.stack)-1]
+	s.stack = s.stack[:len(s.stack)-1]
+	return true
+}
+
+// convert valida el valor asignado y lo convierte al tipo del comando.
+func (def *SettingDef) convert(name string, value Object) (Object, *Error) {
+	cmd := strings.ToUpper(name)
+	switch def.Kind {
+	case 'L':
+		switch value := value.(type) {
+		case *Boolean:
+			return value, nil
+		case *String:
+			switch strings.ToUpper(value.Value) {
+			case "ON":
+				return &Boolean{Value: true}, nil
+			case "OFF":
+				return &Boolean{Value: false}, nil
+			}
+		}
+		return nil, NewError(fmt.Sprintf("SET %s: expecting `ON` or `OFF`", cmd))
+	case 'N':
+		if str, ok := value.(*String); ok {
+			if n, named := def.Named[strings.ToUpper(strings.TrimSpace(str.Value))]; named {
+				return &Integer{Value: n}, nil
+			}
+		}
+		num, ok := value.(*Integer)
+		if !ok {
+			return nil, NewError(fmt.Sprintf("SET %s: expecting a number, got `%s`", cmd, TypeToStr(value.Type())))
+		}
+		if num.Value < def.Min || num.Value > def.Max {
+			return nil, NewError(fmt.Sprintf("SET %s: value %v is out of range (%v to %v)", cmd, num.Value, def.Min, def.Max))
+		}
+		return &Integer{Value: float64(int(num.Value))}, nil
+	case 'K':
+		str, ok := value.(*String)
+		if ok {
+			for _, keyword := range def.Keywords {
+				if strings.EqualFold(keyword, strings.TrimSpace(str.Value)) {
+					return &String{Value: keyword}, nil
+				}
+			}
+		}
+		return nil, NewError(fmt.Sprintf("SET %s: expecting one of %s", cmd, strings.Join(def.Keywords, ", ")))
+	default:
+		str, ok := value.(*String)
+		if !ok {
+			return nil, NewError(fmt.Sprintf("SET %s: expecting a string, got `%s`", cmd, TypeToStr(value.Type())))
+		}
+		return str, nil
+	}
+}
@@ -0,0 +1,176 @@
+package object
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/dbf"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WorkArea es un área de trabajo con una tabla abierta (USE).
+type WorkArea struct {
+	Number    int
+	Alias     string
+	Table     *dbf.Table
+	Recno     int  // registro actual; RecordCount()+1 en el fin de archivo
+	Bof       bool // se intentó retroceder antes del primer registro
+	Exclusive bool
+	Temporary bool // cursor: sus archivos se borran al cerrarlo
+
+	Filter    ast.Expression // SET FILTER TO
+	Found     bool           // resultado del último LOCATE o CONTINUE
+	Locate    *Locate        // condiciones del último LOCATE para CONTINUE
+	Relations []*Relation    // SET RELATION TO expr INTO alias
+	Order     string         // tag que controla el orden ("" para el orden físico)
+	Reverse   bool           // SET ORDER TO ... invierte el orden del tag
+	Buffering int            // CURSORSETPROP("Buffering"): 1 sin buffer, 2-3 filas, 4-5 tabla
+	DBTable   *dbf.DBObject  // objeto de la tabla en su base de datos (nil en las tablas libres)
+}
+
+// Locate guarda las condiciones de LOCATE para que CONTINUE siga buscando.
+type Locate struct {
+	For   ast.Expression
+	While ast.Expression
+}
+
+// Relation relaciona un área de trabajo con otra: al mover el puntero del
+// padre, el hijo se posiciona en el registro que indica Expr.
+type Relation struct {
+	Expr  ast.Expression
+	Child string // alias del área hija
+}
+
+// Eof indica si el puntero está después del último registro.
+func (wa *WorkArea) Eof() bool {
+	return wa.Recno > wa.Table.RecordCount()
+}
+
+// SelectedArea devuelve el número del área de trabajo actual.
+func (e *Environment) SelectedArea() int {
+	return e.session.selected
+}
+
+// SelectArea cambia el área de trabajo actual.
+func (e *Environment) SelectArea(number int) {
+	e.session.selected = number
+}
+
+// CurrentArea devuelve el área de trabajo actual o nil si no hay ninguna
+// tabla abierta en ella.
+func (e *Environment) CurrentArea() *WorkArea {
+	return e.session.areas[e.session.selected]
+}
+
+// WorkArea devuelve el área de trabajo number o nil si no está en uso.
+func (e *Environment) WorkArea(number int) *WorkArea {
+	return e.session.areas[number]
+}
+
+// AreaByAlias busca el área de trabajo de un alias (sin distinguir
+// mayúsculas).
+func (e *Environment) AreaByAlias(alias string) *WorkArea {
+	for _, wa := range e.session.areas {
+		if strings.EqualFold(wa.Alias, alias) {
+			return wa
+		}
+	}
+	return nil
+}
+
+// OpenArea registra la tabla abierta en el área de trabajo wa.Number,
+// compartida o en exclusiva según wa.Exclusive (los cursores son siempre
+// del proceso). Si hay una transacción en curso la tabla pasa a formar
+// parte de ella. El área tiene que estar libre.
+func (e *Environment) OpenArea(wa *WorkArea) error {
+	if e.session.areas[wa.Number] != nil {
+		e.closeArea(wa)
+		return fmt.Errorf("work area %d is in use", wa.Number)
+	}
+	if !wa.Temporary {
+		if err := wa.Table.Share(wa.Exclusive); err != nil {
+			e.closeArea(wa)
+			return err
+		}
+	}
+	for wa.Table.TransactionLevel() < e.session.txnLevel {
+		if err := wa.Table.BeginTransaction(); err != nil {
+			e.closeArea(wa)
+			return err
+		}
+	}
+	e.session.areas[wa.Number] = wa
+	return nil
+}
+
+// CloseArea cierra la tabla del área de trabajo number; durante una
+// transacción solo se pueden cerrar los cursores.
+func (e *Environment) CloseArea(number int) error {
+	wa, ok := e.session.areas[number]
+	if !ok {
+		return nil
+	}
+	if e.session.txnLevel > 0 && !wa.Temporary {
+		return fmt.Errorf("cannot close table `%s` during a transaction", wa.Alias)
+	}
+	return e.closeArea(wa)
+}
+
+// CloseAreas cierra las tablas de todas las áreas de trabajo; las
+// transacciones sin terminar se deshacen.
+func (e *Environment) CloseAreas() error {
+	var err error
+	for _, wa := range e.session.areas {
+		if closeErr := e.closeArea(wa); err == nil {
+			err = closeErr
+		}
+	}
+	e.session.txnLevel = 0
+	return err
+}
+
+func (e *Environment) closeArea(wa *WorkArea) error {
+	if e.session.areas[wa.Number] == wa {
+		delete(e.session.areas, wa.Number)
+	}
+	if wa.Temporary {
+		return wa.Table.Drop()
+	}
+	return wa.Table.Close()
+}
+
+// TransactionLevel devuelve la cantidad de niveles de BEGIN TRANSACTION en
+// curso.
+func (e *Environment) TransactionLevel() int {
+	return e.session.txnLevel
+}
+
+// SetTransactionLevel cambia la cantidad de niveles de transacción en curso.
+func (e *Environment) SetTransactionLevel(level int) {
+	e.session.txnLevel = level
+}
+
+// UnusedArea devuelve el área de trabajo libre con el número más bajo.
+func (e *Environment) UnusedArea() (int, error) {
+	for number := 1; number <= MaxWorkArea; number++ {
+		if e.session.areas[number] == nil {
+			return number, nil
+		}
+	}
+	return 0, fmt.Errorf("all %d work areas are in use", MaxWorkArea)
+}
+
+// MaxWorkArea es el número de área de trabajo más alto.
+const MaxWorkArea = 32767
+
+// WorkAreas devuelve las áreas de trabajo en uso ordenadas por número.
+func (e *Environment) WorkAreas() []*WorkArea {
+	var areas []*WorkArea
+	for _, wa := range e.session.areas {
+		areas = append(areas, wa)
+	}
+	sort.Slice(areas, func(i, j int) bool {
+		return areas[i].Number < areas[j].Number
+	})
+	return areas
+}
@@ -0,0 +1,199 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+	"fmt"
+	"strings"
+)
+
+// scopeWords son las palabras que inician el alcance de un comando.
+var scopeWords = []string{"all", "next", "rest", "record"}
+
+// parseAggregateStmt => Count | Sum [exprs] | Average [exprs] |
+// Calculate exprs [alcance] [For cond] [While cond] [To vars | To Array arr]
+func (p *Parser) parseAggregateStmt() ast.Statement {
+	stmt := &ast.AggregateStmt{
+		Token:   p.curToken,
+		Command: strings.ToLower(p.curToken.Literal),
+	}
+	p.nextToken() // skip 'Count' | 'Sum' | 'Average' | 'Calculate' token
+	if stmt.Command == "calculate" {
+		p.calc = true
+		defer func() { p.calc = false }()
+	}
+	if stmt.Command != "count" && !p.eof() && !p.match(token.NewLine, token.For, token.While) &&
+		!p.matchWord("to") && !p.matchWord(scopeWords...) {
+		for {
+			exp := p.parseExpression(lowest)
+			if exp == nil {
+				return nil
+			}
+			stmt.Exprs = append(stmt.Exprs, exp)
+			if !p.match(token.Comma) {
+				break
+			}
+			p.nextToken() // skip ',' token
+		}
+	}
+	if stmt.Command == "calculate" && len(stmt.Exprs) == 0 {
+		p.newError("CALCULATE: expecting an expression")
+		p.recovery()
+		return nil
+	}
+	var ok bool
+	if stmt.Scope, stmt.For, stmt.While, ok = p.parseScopeClauses(strings.ToUpper(stmt.Command)); !ok {
+		return nil
+	}
+	if p.matchWord("to") {
+		p.nextToken() // skip 'To' token
+		if p.matchWord("array") && stmt.Command != "count" {
+			p.nextToken() // skip 'Array' token
+			if !p.match(token.Ident) {
+				p.newError(fmt.Sprintf("unexpected token `%s`, expecting an array name", p.curToken.Literal))
+				p.recovery()
+				return nil
+			}
+			stmt.ToArray = p.curToken.Literal
+			p.nextToken() // skip array name
+		} else {
+			for {
+				if !p.match(token.Ident) {
+					p.newError(fmt.Sprintf("unexpected token `%s`, expecting a variable name", p.curToken.Literal))
+					p.recovery()
+					return nil
+				}
+				stmt.To = append(stmt.To, p.curToken.Literal)
+				p.nextToken() // skip variable name
+				if !p.match(token.Comma) {
+					break
+				}
+				p.nextToken() // skip ',' token
+			}
+		}
+	}
+	if stmt.Scope == nil && stmt.For == nil && stmt.While == nil {
+		// las cláusulas también se admiten después de To
+		if stmt.Scope, stmt.For, stmt.While, ok = p.parseScopeClauses(strings.ToUpper(stmt.Command)); !ok {
+			return nil
+		}
+	}
+	if !p.eof() && !p.match(token.NewLine) {
+		p.newError(fmt.Sprintf("unexpected token `%s` in %s command", p.curToken.Literal, strings.ToUpper(stmt.Command)))
+		p.recovery()
+		return nil
+	}
+	return stmt
+}
+
+// parseTotalStmt => Total On clave To tabla [Fields campos] [alcance]
+// [For cond] [While cond]
+func (p *Parser) parseTotalStmt() ast.Statement {
+	stmt := &ast.TotalStmt{Token: p.curToken}
+	p.nextToken() // skip 'Total' token
+	if !p.expectWord("on") {
+		return nil
+	}
+	if stmt.Key = p.parseExpression(lowest); stmt.Key == nil {
+		return nil
+	}
+	if !p.expectWord("to") {
+		return nil
+	}
+	stmt.File = p.parseFileName(append([]string{"fields", "for", "while"}, scopeWords...)...)
+	var ok bool
+	if stmt.Scope, stmt.For, stmt.While, ok = p.parseScopeClauses("TOTAL"); !ok {
+		return nil
+	}
+	if p.matchWord("fields") {
+		p.nextToken() // skip 'Fields' token
+		for {
+			if !p.match(token.Ident) {
+				p.newError(fmt.Sprintf("unexpected token `%s`, expecting a field name", p.curToken.Literal))
+				p.recovery()
+				return nil
+			}
+			stmt.Fields = append(stmt.Fields, p.curToken.Literal)
+			p.nextToken() // skip field name
+			if !p.match(token.Comma) {
+				break
+			}
+			p.nextToken() // skip ',' token
+		}
+	}
+	if stmt.Scope == nil && stmt.For == nil && stmt.While == nil {
+		if stmt.Scope, stmt.For, stmt.While, ok = p.parseScopeClauses("TOTAL"); !ok {
+			return nil
+		}
+	}
+	return stmt
+}
+
+// parseScopeClauses analiza el alcance (All, Next n, Rest, Record n) y las
+// cláusulas For y While de un comando, en cualquier orden.
+func (p *Parser) parseScopeClauses(command string) (*ast.Scope, ast.Expression, ast.Expression, bool) {
+	var scope *ast.Scope
+	var forCond, whileCond ast.Expression
+	for {
+		switch {
+		case p.matchWord(scopeWords...):
+			if scope != nil {
+				p.newError(fmt.Sprintf("duplicated scope clause in %s command", command))
+				p.recovery()
+				return nil, nil, nil, false
+			}
+			scope = &ast.Scope{Kind: strings.ToLower(p.curToken.Literal)}
+			p.nextToken() // skip 'All' | 'Next' | 'Rest' | 'Record' token
+			if scope.Kind == "next" || scope.Kind == "record" {
+				if scope.Count = p.parseExpression(lowest); scope.Count == nil {
+					return nil, nil, nil, false
+				}
+			}
+		case p.match(token.For, token.While):
+			f, w, ok := p.parseConditions(command)
+			if !ok {
+				return nil, nil, nil, false
+			}
+			if (f != nil && forCond != nil) || (w != nil && whileCond != nil) {
+				p.newError(fmt.Sprintf("duplicated condition clause in %s command", command))
+				p.recovery()
+				return nil, nil, nil, false
+			}
+			if f != nil {
+				forCond = f
+			}
+			if w != nil {
+				whileCond = w
+			}
+		default:
+			return scope, forCond, whileCond, true
+		}
+	}
+}
+
+// calcAggregates son las funciones de agregado de CALCULATE.
+var calcAggregates = map[string]bool{
+	"avg": true, "cnt": true, "max": true, "min": true, "std": true, "sum": true, "var": true,
+}
+
+func isCalcAggregate(caller ast.Expression) bool {
+	ident, ok := caller.(*ast.Literal)
+	return ok && ident.Token.Type == token.Ident && calcAggregates[strings.ToLower(ident.Token.Literal)]
+}
+
+// parseCalcAggregateExp => Cnt() | Sum(expr) | Avg(expr) | Max(expr) | ...
+// Se representan como los agregados de SQL.
+func (p *Parser) parseCalcAggregateExp(caller ast.Expression) ast.Expression {
+	exp := &ast.SqlAggregateExp{
+		Token: p.curToken,
+		Func:  strings.ToLower(caller.(*ast.Literal).Token.Literal),
+	}
+	p.nextToken() // skip '(' token
+	if exp.Func != "cnt" {
+		if exp.Arg = p.parseExpression(lowest); exp.Arg == nil {
+			return nil
+		}
+	}
+	p.expect(token.Rparen, "")
+	return exp
+}
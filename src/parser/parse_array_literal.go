@@ -0,0 +1,26 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+)
+
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	exp := &ast.ArrayLiteral{ // [1, 2, 3]
+		Token:    p.curToken,
+		Elements: []ast.Expression{},
+	}
+	p.nextToken() // skip '[' token
+
+	if !p.match(token.Rbracket) {
+		exp.Elements = append(exp.Elements, p.parseExpression(lowest))
+
+		for !p.eof() && p.match(token.Comma) {
+			p.nextToken() // skip ',' token
+			exp.Elements = append(exp.Elements, p.parseExpression(lowest))
+		}
+	}
+	p.expect(token.Rbracket, "expecting `]` after array elements")
+
+	return exp
+}
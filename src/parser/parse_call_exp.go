@@ -6,6 +6,12 @@ import (
 )
 
 func (p *Parser) parseCallExp(caller ast.Expression) ast.Expression {
+	if p.sql > 0 && isSqlAggregate(caller) {
+		return p.parseSqlAggregateExp(caller)
+	}
+	if p.calc && isCalcAggregate(caller) {
+		return p.parseCalcAggregateExp(caller)
+	}
 	exp := &ast.CallExp{ // foo(x, y)
 		Token:  p.curToken,
 		Caller: caller,
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+	"fmt"
+)
+
+// parseCreateDatabaseStmt => Create Database ventas
+func (p *Parser) parseCreateDatabaseStmt(tok token.Token) ast.Statement {
+	stmt := &ast.CreateDatabaseStmt{Token: tok}
+	if stmt.Name = p.parseFileName(); stmt.Name == nil {
+		return nil
+	}
+	return stmt
+}
+
+// parseOpenStmt => Open Database ventas [Exclusive | Shared]
+func (p *Parser) parseOpenStmt() ast.Statement {
+	stmt := &ast.OpenDatabaseStmt{Token: p.curToken}
+	p.nextToken() // skip 'Open' token
+	if !p.expectWord("database") {
+		return nil
+	}
+	clauses := []string{"exclusive", "shared"}
+	if stmt.Name = p.parseFileName(clauses...); stmt.Name == nil {
+		return nil
+	}
+	for !p.eof() && !p.match(token.NewLine) {
+		switch {
+		case p.matchWord("exclusive"):
+			stmt.Exclusive = true
+		case p.matchWord("shared"):
+			stmt.Shared = true
+		default:
+			p.newError(fmt.Sprintf("unexpected token `%s` in OPEN DATABASE command", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+		p.nextToken() // skip clause
+	}
+	return stmt
+}
+
+// parseSetDatabaseStmt => Set Database To [ventas]
+func (p *Parser) parseSetDatabaseStmt(set *ast.SetStmt) ast.Statement {
+	stmt := &ast.SetDatabaseStmt{Token: set.Token}
+	if !p.expectWord("to") {
+		return nil
+	}
+	if !p.eof() && !p.match(token.NewLine) {
+		stmt.Name = p.parseFileName()
+	}
+	return stmt
+}
+
+// parseAddStmt => Add Table clientes [Name clientes_activos]
+func (p *Parser) parseAddStmt() ast.Statement {
+	stmt := &ast.AddTableStmt{Token: p.curToken}
+	p.nextToken() // skip 'Add' token
+	if !p.expectWord("table") {
+		return nil
+	}
+	if stmt.File = p.parseFileName("name"); stmt.File == nil {
+		return nil
+	}
+	if p.matchWord("name") {
+		p.nextToken() // skip 'Name' token
+		stmt.Name = p.parseFileName()
+	}
+	return stmt
+}
@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+	"fmt"
+)
+
+// parseDoStmt => Do Calcular [With arg1, arg2, ...] | Do otro.flp [With ...]
+// Las variables se pasan por referencia salvo que se encierren entre
+// paréntesis: Do Calcular With lnTotal, (lnTasa)
+func (p *Parser) parseDoStmt() ast.Statement {
+	stmt := &ast.DoStmt{
+		Token: p.curToken,
+		Args:  []ast.Expression{},
+	}
+	p.nextToken() // skip 'Do' token
+	if p.match(token.String, token.Lparen) || (p.match(token.Ident) && p.peek(token.Dot)) {
+		// Do otro.flp | Do "c:\programas\otro.flp" | Do (lcPrograma)
+		stmt.File = p.parseFileName("with")
+	} else if !p.match(token.Ident) {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting a routine name", p.curToken.Literal))
+		p.recovery()
+		return nil
+	} else {
+		stmt.Name = p.parseLiteral().(*ast.Literal)
+	}
+
+	if p.matchWord("with") {
+		p.nextToken() // skip 'With' token
+		stmt.Args = append(stmt.Args, p.parseDoArgument())
+		for !p.eof() && p.match(token.Comma) {
+			p.nextToken() // skip ',' token
+			stmt.Args = append(stmt.Args, p.parseDoArgument())
+		}
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseDoArgument() ast.Expression {
+	if p.match(token.Ident) && (p.peek(token.Comma) || p.peek(token.NewLine) || p.peek(token.Eof)) {
+		tok := p.curToken
+		return &ast.ReferenceExp{
+			Token: tok,
+			Name:  p.parseLiteral().(*ast.Literal),
+		}
+	}
+	return p.parseExpression(lowest)
+}
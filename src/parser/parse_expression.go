@@ -13,8 +13,12 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 		return nil
 	}
 	// parse left right expresion
-	leftExp := prefixFns()
+	return p.parseInfixExpressions(prefixFns(), precedence)
+}
 
+// parseInfixExpressions continúa una expresión a partir de su operando
+// izquierdo ya analizado mientras los operadores tengan mayor precedencia.
+func (p *Parser) parseInfixExpressions(leftExp ast.Expression, precedence int) ast.Expression {
 	for precedence < p.curPrecedence() {
 		infixFns := p.infixParseFns[p.curToken.Type]
 		if infixFns == nil {
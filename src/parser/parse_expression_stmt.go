@@ -1,14 +1,39 @@
 package parser
 
-import "FoxLite/src/ast"
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+)
 
-func (p *Parser) parseExpressionStmt() *ast.ExpressionStmt {
+func (p *Parser) parseExpressionStmt() ast.Statement {
 	stmt := &ast.ExpressionStmt{
 		Token: p.curToken,
 	}
-	exp := p.parseExpression(lowest)
+	// Analizamos hasta antes del '=' para saber si es una asignación
+	// a un elemento: laFrutas[0] = "Manzana" o a una variable: m.lnTotal = 0
+	exp := p.parseExpression(equality)
+	if isAssignTarget(exp) && p.match(token.Assign) {
+		assign := &ast.AssignStmt{
+			Token:  p.curToken,
+			Target: exp,
+		}
+		p.nextToken() // skip '=' token
+		assign.Value = p.parseExpression(lowest)
+		return assign
+	}
 	if exp != nil {
-		stmt.Expression = exp
+		stmt.Expression = p.parseInfixExpressions(exp, lowest)
 	}
 	return stmt
 }
+
+// isAssignTarget indica si la expresión puede recibir una asignación.
+func isAssignTarget(exp ast.Expression) bool {
+	switch exp := exp.(type) {
+	case *ast.IndexExp:
+		return true
+	case *ast.InfixExp:
+		return exp.Op == token.Dot
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+	"strings"
+)
+
+// parseFileName analiza el nombre de fichero de un comando, que puede
+// escribirse sin comillas (SAVE TO vars.mem, USE data/clientes), como un
+// string ("c:\datos\vars.mem") o como una expresión entre paréntesis
+// (SAVE TO (lcFichero)). El nombre sin comillas termina al final de la línea
+// o al encontrar alguna de las palabras de la cláusula siguiente, salvo
+// en la extensión (COPY TO clientes.csv TYPE CSV).
+func (p *Parser) parseFileName(stopWords ...string) ast.Expression {
+	if p.match(token.Lparen) {
+		return p.parseGroupedExp()
+	}
+	if p.match(token.String) {
+		return p.parseLiteral()
+	}
+	tok := p.curToken
+	var name strings.Builder
+	for !p.eof() && !p.match(token.NewLine, token.Comma) &&
+		(!p.matchWord(stopWords...) || strings.HasSuffix(name.String(), ".")) {
+		name.WriteString(p.curToken.Literal)
+		p.nextToken()
+	}
+	if name.Len() == 0 {
+		p.newError("expecting a file name")
+	}
+	tok.Type = token.String
+	tok.Literal = name.String()
+	return &ast.Literal{Token: tok, Value: tok.Literal}
+}
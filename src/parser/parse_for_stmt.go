@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+	"fmt"
+)
+
+func (p *Parser) parseForStmt() ast.Statement {
+	stmt := &ast.For{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'For' token
+	if !p.match(token.Ident) {
+		p.newError(fmt.Sprintf("unexpected token `%s` for loop variable", p.curToken.Literal))
+	}
+	stmt.Value = p.parseLiteral().(*ast.Literal)
+
+	if p.match(token.Comma) { // For i, v in laFrutas
+		p.nextToken() // skip ',' token
+		if !p.match(token.Ident) {
+			p.newError(fmt.Sprintf("unexpected token `%s` for loop variable", p.curToken.Literal))
+		}
+		stmt.Key = stmt.Value
+		stmt.Value = p.parseLiteral().(*ast.Literal)
+	}
+	p.expect(token.In, "expecting `in` after loop variables")
+	stmt.Iterable = p.parseExpression(lowest)
+	stmt.Body = p.parseBlockStmt()
+
+	return stmt
+}
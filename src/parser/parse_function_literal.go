@@ -9,22 +9,64 @@ import (
 func (p *Parser) parseFunctionLiteral() ast.Statement {
 	exp := &ast.FunctionLiteral{
 		Token:      p.curToken,
-		Parameters: []*ast.Literal{},
+		Parameters: []*ast.Parameter{},
 	}
 	p.nextToken() // skip 'Func' token
 	exp.Name = p.parseLiteral().(*ast.Literal)
+	exp.Parameters = p.parseFunctionParameters()
+	exp.Body = p.parseBlockStmt()
+
+	return exp
+}
+
+// parseAnonymousFunction => func(x, y) + bloque indentado, o bien una única
+// expresión en la misma línea cuyo valor se retorna: func(x) x * 2
+func (p *Parser) parseAnonymousFunction() ast.Expression {
+	exp := &ast.FunctionLiteral{
+		Token:      p.curToken,
+		Parameters: []*ast.Parameter{},
+	}
+	p.nextToken() // skip 'Func' token
+	exp.Parameters = p.parseFunctionParameters()
+
+	if p.match(token.NewLine) {
+		exp.Body = p.parseBlockStmt()
+	} else {
+		exp.Body = &ast.BlockStmt{
+			Statements: []ast.Statement{p.parseExpressionStmt()},
+		}
+	}
+
+	return exp
+}
+
+func (p *Parser) parseFunctionParameters() []*ast.Parameter {
+	var params = []*ast.Parameter{}
 	p.expect(token.Lparen, fmt.Sprintf("unexpected token `%s`, expecting `(`", p.curToken.Literal))
 
 	if !p.match(token.Rparen) { // hay parámetros definidos?
-		exp.Parameters = append(exp.Parameters, p.parseLiteral().(*ast.Literal))
+		params = append(params, p.parseParameter())
 		for !p.eof() && p.match(token.Comma) {
 			p.nextToken() // skip ',' token
-			exp.Parameters = append(exp.Parameters, p.parseLiteral().(*ast.Literal))
+			params = append(params, p.parseParameter())
 		}
 	}
 	p.expect(token.Rparen, "expecting `)`")
 
-	exp.Body = p.parseBlockStmt()
+	return params
+}
 
-	return exp
+// parseParameter => tcNombre | tcSaludo = "Hola"
+func (p *Parser) parseParameter() *ast.Parameter {
+	if !p.match(token.Ident) {
+		p.newError(fmt.Sprintf("unexpected token `%s` for parameter name", p.curToken.Literal))
+	}
+	param := &ast.Parameter{
+		Name: p.parseLiteral().(*ast.Literal),
+	}
+	if p.match(token.Assign) { // valor por defecto
+		p.nextToken() // skip '=' token
+		param.Default = p.parseExpression(lowest)
+	}
+	return param
 }
@@ -6,6 +6,9 @@ import (
 )
 
 func (p *Parser) parseGroupedExp() ast.Expression {
+	if p.sql > 0 && p.peekWord("select") {
+		return p.parseSqlSubqueryExp()
+	}
 	p.nextToken() // skip '('
 	exp := p.parseExpression(lowest)
 	p.expect(token.Rparen, "expected `)` after grouped expresion")
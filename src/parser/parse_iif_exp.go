@@ -0,0 +1,38 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+)
+
+// parseIifExp => Iif(condition, consequence, alternative)
+func (p *Parser) parseIifExp() ast.Expression {
+	exp := &ast.IifExp{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Iif' token
+	p.expect(token.Lparen, "expecting `(` after Iif")
+	exp.Condition = p.parseExpression(lowest)
+	p.expect(token.Comma, "Iif() expects 3 arguments: condition, true value and false value")
+	exp.Consequence = p.parseExpression(lowest)
+	p.expect(token.Comma, "Iif() expects 3 arguments: condition, true value and false value")
+	exp.Alternative = p.parseExpression(lowest)
+	p.expect(token.Rparen, "Iif() expects 3 arguments: condition, true value and false value")
+
+	return exp
+}
+
+// parseConditionalExp => consequence if condition else alternative
+func (p *Parser) parseConditionalExp(consequence ast.Expression) ast.Expression {
+	exp := &ast.IifExp{
+		Token:       p.curToken,
+		Consequence: consequence,
+	}
+	p.nextToken() // skip 'If' token
+	exp.Condition = p.parseExpression(conditional)
+	p.expect(token.Else, "expecting `else` in conditional expression")
+	// la alternativa se asocia a la derecha: a if x else b if y else c
+	exp.Alternative = p.parseExpression(lowest)
+
+	return exp
+}
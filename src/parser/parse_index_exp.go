@@ -0,0 +1,18 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+)
+
+func (p *Parser) parseIndexExp(left ast.Expression) ast.Expression {
+	exp := &ast.IndexExp{ // laFrutas[0]
+		Token: p.curToken,
+		Left:  left,
+	}
+	p.nextToken() // skip '[' token
+	exp.Index = p.parseExpression(lowest)
+	p.expect(token.Rbracket, "expecting `]` after index expression")
+
+	return exp
+}
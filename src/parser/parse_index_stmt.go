@@ -0,0 +1,176 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+	"fmt"
+)
+
+// parseIndexStmt => Index On Upper(nombre) Tag nombre [For cond] [Ascending | Descending] [Unique | Candidate] [Additive]
+func (p *Parser) parseIndexStmt() ast.Statement {
+	stmt := &ast.IndexStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Index' token
+	if !p.expectWord("on") {
+		return nil
+	}
+	start := p.curToken
+	if stmt.Expr = p.parseExpression(lowest); stmt.Expr == nil {
+		return nil
+	}
+	stmt.Source = p.sourceFrom(start)
+	if p.matchWord("to") {
+		p.newError("standalone .idx indexes are not supported, use TAG")
+		p.recovery()
+		return nil
+	}
+	if !p.expectWord("tag") {
+		return nil
+	}
+	if !p.match(token.Ident) {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting a tag name", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	stmt.Tag = p.curToken.Literal
+	p.nextToken() // skip tag name
+	for !p.eof() && !p.match(token.NewLine) {
+		switch {
+		case p.match(token.For):
+			p.nextToken() // skip 'For' token
+			start := p.curToken
+			if stmt.For = p.parseExpression(lowest); stmt.For == nil {
+				return nil
+			}
+			stmt.ForSource = p.sourceFrom(start)
+			continue
+		case p.matchWord("descending"):
+			stmt.Descending = true
+		case p.matchWord("ascending", "additive"):
+		case p.matchWord("unique"):
+			stmt.Unique = true
+		case p.matchWord("candidate"):
+			stmt.Candidate = true
+		default:
+			p.newError(fmt.Sprintf("unexpected token `%s` in INDEX command", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+		p.nextToken() // skip clause
+	}
+	return stmt
+}
+
+// sourceFrom devuelve el texto del programa desde el token start hasta el
+// token actual (sin incluirlo).
+func (p *Parser) sourceFrom(start token.Token) string {
+	return p.l.Source(start.Offset, p.curToken.Offset)
+}
+
+// parseReindexStmt => Reindex
+func (p *Parser) parseReindexStmt() ast.Statement {
+	stmt := &ast.ReindexStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Reindex' token
+	return stmt
+}
+
+// parseDeleteTagStmt => Delete Tag nombre, ciudad | Delete Tag All
+func (p *Parser) parseDeleteTagStmt(tok token.Token) ast.Statement {
+	stmt := &ast.DeleteTagStmt{
+		Token: tok,
+	}
+	p.nextToken() // skip 'Tag' token
+	if p.matchWord("all") {
+		p.nextToken() // skip 'All' token
+		stmt.All = true
+		return stmt
+	}
+	for {
+		if !p.match(token.Ident) {
+			p.newError(fmt.Sprintf("unexpected token `%s`, expecting a tag name", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+		stmt.Names = append(stmt.Names, p.curToken.Literal)
+		p.nextToken() // skip tag name
+		if !p.match(token.Comma) {
+			break
+		}
+		p.nextToken() // skip ',' token
+	}
+	return stmt
+}
+
+// parseSetOrderStmt => Set Order To [Tag] nombre | 2 | 0 [In cli] [Ascending | Descending]
+func (p *Parser) parseSetOrderStmt(set *ast.SetStmt) ast.Statement {
+	stmt := &ast.SetOrderStmt{
+		Token: set.Token,
+	}
+	if !p.expectWord("to") {
+		return nil
+	}
+	if p.matchWord("tag") {
+		p.nextToken() // skip 'Tag' token
+	}
+	if !p.eof() && !p.match(token.NewLine) && !p.match(token.In) {
+		if stmt.Order = p.parseWorkArea(); stmt.Order == nil {
+			return nil
+		}
+	}
+	for !p.eof() && !p.match(token.NewLine) {
+		switch {
+		case p.match(token.In):
+			p.nextToken() // skip 'In' token
+			if stmt.In = p.parseWorkArea(); stmt.In == nil {
+				return nil
+			}
+			continue
+		case p.matchWord("descending"):
+			stmt.Descending = true
+		case p.matchWord("ascending"):
+			stmt.Ascending = true
+		default:
+			p.newError(fmt.Sprintf("unexpected token `%s` in SET ORDER command", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+		p.nextToken() // skip clause
+	}
+	return stmt
+}
+
+// parseSeekStmt => Seek "GARCIA" [Order [Tag] nombre] [In cli]
+func (p *Parser) parseSeekStmt() ast.Statement {
+	stmt := &ast.SeekStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Seek' token
+	if stmt.Value = p.parseExpression(lowest); stmt.Value == nil {
+		return nil
+	}
+	for !p.eof() && !p.match(token.NewLine) {
+		switch {
+		case p.matchWord("order"):
+			p.nextToken() // skip 'Order' token
+			if p.matchWord("tag") {
+				p.nextToken() // skip 'Tag' token
+			}
+			if stmt.Order = p.parseWorkArea(); stmt.Order == nil {
+				return nil
+			}
+		case p.match(token.In):
+			p.nextToken() // skip 'In' token
+			if stmt.In = p.parseWorkArea(); stmt.In == nil {
+				return nil
+			}
+		default:
+			p.newError(fmt.Sprintf("unexpected token `%s` in SEEK command", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+	}
+	return stmt
+}
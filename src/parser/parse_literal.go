@@ -29,3 +29,13 @@ func (p *Parser) parseLiteral() ast.Expression {
 	p.nextToken()
 	return exp
 }
+
+// parseKeywordCall => CreateObject("Empty")
+// Palabras reservadas que se invocan como funciones nativas: se tratan como
+// el nombre de la función.
+func (p *Parser) parseKeywordCall() ast.Expression {
+	tok := p.curToken
+	tok.Type = token.Ident
+	p.nextToken()
+	return &ast.Literal{Token: tok, Value: tok.Literal}
+}
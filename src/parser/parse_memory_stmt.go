@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+	"fmt"
+)
+
+// parseReleaseStmt => Release lnTotal, lcNombre | Release All [Like l* | Except g*]
+func (p *Parser) parseReleaseStmt() ast.Statement {
+	stmt := &ast.ReleaseStmt{
+		Token:    p.curToken,
+		Skeleton: "*",
+	}
+	p.nextToken() // skip 'Release' token
+
+	if p.matchWord("all") {
+		p.nextToken() // skip 'All' token
+		stmt.All = true
+		stmt.Skeleton, stmt.Except = p.parseLikeClause("*")
+		return stmt
+	}
+	for !p.eof() {
+		if !p.match(token.Ident) {
+			p.newError(fmt.Sprintf("unexpected token `%s` for variable name", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+		stmt.Names = append(stmt.Names, p.curToken.Literal)
+		p.nextToken() // skip variable name
+		if !p.match(token.Comma) {
+			break
+		}
+		p.nextToken() // skip ',' token
+	}
+	return stmt
+}
+
+// parseClearStmt => Clear Memory
+func (p *Parser) parseClearStmt() ast.Statement {
+	stmt := &ast.ClearMemoryStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Clear' token
+	if !p.matchWord("memory") {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting `MEMORY`", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	p.nextToken() // skip 'Memory' token
+	return stmt
+}
+
+// parseListMemoryStmt => List Memory [Like l* | Except g*] | Display Memory
+func (p *Parser) parseListMemoryStmt() ast.Statement {
+	stmt := &ast.ListMemoryStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'List' | 'Display' token
+	if !p.matchWord("memory") {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting `MEMORY`", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	p.nextToken() // skip 'Memory' token
+	stmt.Skeleton, stmt.Except = p.parseLikeClause("*")
+	return stmt
+}
+
+// parseSaveStmt => Save To vars.mem [All Like l* | All Except g*]
+func (p *Parser) parseSaveStmt() ast.Statement {
+	stmt := &ast.SaveStmt{
+		Token:    p.curToken,
+		Skeleton: "*",
+	}
+	p.nextToken() // skip 'Save' token
+	if !p.matchWord("to") {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting `TO`", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	p.nextToken() // skip 'To' token
+	stmt.File = p.parseFileName("all")
+	if p.matchWord("all") {
+		p.nextToken() // skip 'All' token
+		stmt.Skeleton, stmt.Except = p.parseLikeClause("*")
+	}
+	return stmt
+}
+
+// parseRestoreStmt => Restore From vars.mem [Additive]
+func (p *Parser) parseRestoreStmt() ast.Statement {
+	stmt := &ast.RestoreStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Restore' token
+	if !p.matchWord("from") {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting `FROM`", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	p.nextToken() // skip 'From' token
+	stmt.File = p.parseFileName("additive")
+	if p.matchWord("additive") {
+		p.nextToken() // skip 'Additive' token
+		stmt.Additive = true
+	}
+	return stmt
+}
+
+// parseLikeClause => [Like skeleton | Except skeleton]
+// Devuelve el patrón (o def si no hay cláusula) e indica si es Except.
+func (p *Parser) parseLikeClause(def string) (string, bool) {
+	if !p.matchWord("like", "except") {
+		return def, false
+	}
+	except := p.matchWord("except")
+	p.nextToken() // skip 'Like' | 'Except' token
+	return p.parseSkeleton(), except
+}
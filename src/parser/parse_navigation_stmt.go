@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+	"fmt"
+)
+
+// parseScanStmt => Scan [For cond] [While cond] + bloque [EndScan]
+func (p *Parser) parseScanStmt() ast.Statement {
+	stmt := &ast.ScanStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Scan' token
+	var ok bool
+	if stmt.For, stmt.While, ok = p.parseConditions("SCAN"); !ok {
+		return nil
+	}
+	stmt.Body = p.parseBlockStmt()
+	if p.matchWord("endscan") && p.curToken.Col == stmt.Token.Col {
+		p.nextToken() // skip 'EndScan' token
+	}
+	return stmt
+}
+
+// parseLocateStmt => Locate [For cond] [While cond]
+func (p *Parser) parseLocateStmt() ast.Statement {
+	stmt := &ast.LocateStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Locate' token
+	var ok bool
+	if stmt.For, stmt.While, ok = p.parseConditions("LOCATE"); !ok {
+		return nil
+	}
+	if !p.eof() && !p.match(token.NewLine) {
+		p.newError(fmt.Sprintf("unexpected token `%s` in LOCATE command", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	return stmt
+}
+
+// parseConditions analiza las cláusulas For y While de un comando, en
+// cualquier orden.
+func (p *Parser) parseConditions(command string) (ast.Expression, ast.Expression, bool) {
+	var forCond, whileCond ast.Expression
+	for p.match(token.For, token.While) {
+		isFor := p.match(token.For)
+		if (isFor && forCond != nil) || (!isFor && whileCond != nil) {
+			p.newError(fmt.Sprintf("duplicated `%s` clause in %s command", p.curToken.Literal, command))
+			p.recovery()
+			return nil, nil, false
+		}
+		p.nextToken() // skip 'For' | 'While' token
+		cond := p.parseExpression(lowest)
+		if cond == nil {
+			return nil, nil, false
+		}
+		if isFor {
+			forCond = cond
+		} else {
+			whileCond = cond
+		}
+	}
+	return forCond, whileCond, true
+}
+
+// parseContinueStmt => Continue
+func (p *Parser) parseContinueStmt() ast.Statement {
+	stmt := &ast.ContinueStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Continue' token
+	return stmt
+}
+
+// parseSetFilterStmt => Set Filter To [cond] [In cli]
+func (p *Parser) parseSetFilterStmt(set *ast.SetStmt) ast.Statement {
+	stmt := &ast.SetFilterStmt{
+		Token: set.Token,
+	}
+	if !p.expectWord("to") {
+		return nil
+	}
+	if !p.eof() && !p.match(token.NewLine) && !p.match(token.In) {
+		stmt.Cond = p.parseExpression(lowest)
+	}
+	if p.match(token.In) {
+		p.nextToken() // skip 'In' token
+		if stmt.In = p.parseWorkArea(); stmt.In == nil {
+			return nil
+		}
+	}
+	return stmt
+}
+
+// parseSetRelationStmt => Set Relation To [id Into pedidos, ...] [Additive] | Set Relation Off Into pedidos
+func (p *Parser) parseSetRelationStmt(set *ast.SetStmt) ast.Statement {
+	stmt := &ast.SetRelationStmt{
+		Token: set.Token,
+	}
+	if p.matchWord("off") {
+		p.nextToken() // skip 'Off' token
+		if !p.expectWord("into") {
+			return nil
+		}
+		into := p.parseWorkArea()
+		if into == nil {
+			return nil
+		}
+		stmt.Off = true
+		stmt.Relations = []*ast.RelationDef{{Into: into}}
+		return stmt
+	}
+	if !p.expectWord("to") {
+		return nil
+	}
+	for !p.eof() && !p.match(token.NewLine) && !p.matchWord("additive") {
+		rel := &ast.RelationDef{Expr: p.parseExpression(lowest)}
+		if rel.Expr == nil || !p.expectWord("into") {
+			return nil
+		}
+		if rel.Into = p.parseWorkArea(); rel.Into == nil {
+			return nil
+		}
+		stmt.Relations = append(stmt.Relations, rel)
+		if !p.match(token.Comma) {
+			break
+		}
+		p.nextToken() // skip ',' token
+	}
+	if p.matchWord("additive") {
+		p.nextToken() // skip 'Additive' token
+		stmt.Additive = true
+	}
+	return stmt
+}
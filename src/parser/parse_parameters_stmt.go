@@ -0,0 +1,34 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+	"fmt"
+)
+
+func (p *Parser) parseParametersStmt() ast.Statement {
+	stmt := &ast.ParametersStmt{
+		Token: p.curToken,
+		Scope: 'p', // Parameters => private
+		Names: []*ast.Literal{},
+	}
+	if p.match(token.LParameters) {
+		stmt.Scope = 'l' // LParameters => local
+	}
+	p.nextToken() // skip 'Parameters' | 'LParameters' token
+
+	for !p.eof() {
+		if !p.match(token.Ident) {
+			p.newError(fmt.Sprintf("unexpected token `%s` for parameter name", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+		stmt.Names = append(stmt.Names, p.parseLiteral().(*ast.Literal))
+		if !p.match(token.Comma) {
+			break
+		}
+		p.nextToken() // skip ',' token
+	}
+
+	return stmt
+}
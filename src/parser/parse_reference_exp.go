@@ -0,0 +1,22 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+	"fmt"
+)
+
+func (p *Parser) parseReferenceExp() ast.Expression {
+	exp := &ast.ReferenceExp{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip '@' token
+	if !p.match(token.Ident) {
+		p.newError(fmt.Sprintf("unexpected token `%s`, only variables can be passed by reference", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	exp.Name = p.parseLiteral().(*ast.Literal)
+
+	return exp
+}
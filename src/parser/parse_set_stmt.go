@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+	"fmt"
+	"strings"
+)
+
+func (p *Parser) parseSetStmt() ast.Statement {
+	stmt := &ast.SetStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Set' token
+	if !p.match(token.Ident) {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting a setting name", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	stmt.Name = strings.ToLower(p.curToken.Literal)
+	p.nextToken() // skip setting name
+	if fileSettings[stmt.Name] && p.matchWord("to") {
+		return p.parseSetFileStmt(stmt)
+	}
+	switch stmt.Name {
+	case "filter":
+		return p.parseSetFilterStmt(stmt)
+	case "relation":
+		return p.parseSetRelationStmt(stmt)
+	case "order":
+		return p.parseSetOrderStmt(stmt)
+	case "database":
+		return p.parseSetDatabaseStmt(stmt)
+	}
+
+	switch {
+	case p.matchWord("on", "off"): // SET EXACT ON | OFF
+		stmt.Value = &ast.Literal{
+			Token: p.curToken,
+			Value: strings.EqualFold(p.curToken.Literal, "on"),
+		}
+		p.nextToken() // skip 'On' | 'Off'
+	case p.matchWord("to"): // SET COLLATE TO "GENERAL"
+		p.nextToken()                          // skip 'To'
+		if p.eof() || p.match(token.NewLine) { // SET PATH TO: valor por defecto
+			return stmt
+		}
+		stmt.Value = p.parseExpression(lowest)
+		if stmt.Name == "reprocess" && p.matchWord("seconds") { // SET REPROCESS TO 5 SECONDS
+			p.nextToken() // skip 'Seconds'
+			stmt.Seconds = true
+		}
+	case p.match(token.Ident): // SET DATE BRITISH
+		stmt.Value = p.parseLiteral()
+	default:
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting `ON`, `OFF` or `TO`", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+
+	return stmt
+}
+
+// fileSettings son los comandos SET cuyo valor es una lista de archivos o
+// carpetas escritos sin comillas.
+var fileSettings = map[string]bool{
+	"procedure": true,
+	"path":      true,
+}
+
+// parseSetFileStmt => Set Procedure To lib.flp, util.flp [Additive] | Set Path To datos, libs
+func (p *Parser) parseSetFileStmt(set *ast.SetStmt) ast.Statement {
+	stmt := &ast.SetFileStmt{
+		Token: set.Token,
+		Name:  set.Name,
+	}
+	p.nextToken() // skip 'To' token
+	for !p.eof() && !p.match(token.NewLine) && !p.matchWord("additive") {
+		stmt.Files = append(stmt.Files, p.parseFileName("additive"))
+		if !p.match(token.Comma) {
+			break
+		}
+		p.nextToken() // skip ',' token
+	}
+	if p.matchWord("additive") {
+		p.nextToken() // skip 'Additive' token
+		stmt.Additive = true
+	}
+	return stmt
+}
+
+// parsePushSetStmt => Push Set | Pop Set
+func (p *Parser) parsePushSetStmt() ast.Statement {
+	stmt := &ast.PushSetStmt{
+		Token: p.curToken,
+		Pop:   strings.EqualFold(p.curToken.Literal, "pop"),
+	}
+	p.nextToken() // skip 'Push' | 'Pop' token
+	if !p.matchWord("set") {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting `SET`", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	p.nextToken() // skip 'Set' token
+	return stmt
+}
@@ -0,0 +1,701 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+	"fmt"
+	"strings"
+)
+
+// sqlKeywords son las palabras que terminan una columna o una tabla de la
+// consulta, por lo que no pueden usarse como alias sin As.
+var sqlKeywords = []string{
+	"from", "into", "to", "where", "group", "having", "order",
+	"join", "inner", "left", "right", "full", "on", "union",
+}
+
+// parseSqlSelectStmt => Select ... From ... [Into Cursor nombre [ReadWrite] |
+// Into Array nombre | Into Table nombre | To Screen]
+func (p *Parser) parseSqlSelectStmt(tok token.Token) ast.Statement {
+	stmt := &ast.SqlSelectStmt{Token: tok}
+	if stmt.Query = p.parseSqlQuery(tok); stmt.Query == nil {
+		return nil
+	}
+	switch {
+	case p.matchWord("into"):
+		p.nextToken() // skip 'Into' token
+		if !p.matchWord("cursor", "array", "table", "dbf") {
+			p.newError(fmt.Sprintf("unexpected token `%s`, expecting CURSOR, ARRAY or TABLE", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+		into := &ast.SqlInto{Kind: strings.ToLower(p.curToken.Literal)}
+		if into.Kind == "dbf" {
+			into.Kind = "table"
+		}
+		p.nextToken() // skip 'Cursor' | 'Array' | 'Table' token
+		if into.Kind == "table" {
+			into.Name = p.parseFileName("readwrite", "nofilter")
+		} else {
+			into.Name = p.parseTableName()
+		}
+		if into.Name == nil {
+			return nil
+		}
+		for p.matchWord("readwrite", "nofilter") {
+			into.ReadWrite = into.ReadWrite || p.matchWord("readwrite")
+			p.nextToken() // skip 'ReadWrite' | 'NoFilter' token
+		}
+		stmt.Into = into
+	case p.matchWord("to"):
+		p.nextToken() // skip 'To' token
+		if !p.expectWord("screen") {
+			return nil
+		}
+	}
+	if !p.eof() && !p.match(token.NewLine) {
+		p.newError(fmt.Sprintf("unexpected token `%s` in SELECT command", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	return stmt
+}
+
+// parseSqlQuery analiza una consulta a partir de la lista de columnas (el
+// token Select ya se ha saltado).
+func (p *Parser) parseSqlQuery(tok token.Token) *ast.SqlQuery {
+	p.sql++
+	defer func() { p.sql-- }()
+
+	query := &ast.SqlQuery{Token: tok}
+	if p.matchWord("all") {
+		p.nextToken() // skip 'All' token
+	} else if p.matchWord("distinct") {
+		query.Distinct = true
+		p.nextToken() // skip 'Distinct' token
+	}
+	if p.matchWord("top") {
+		p.nextToken() // skip 'Top' token
+		switch {
+		case p.match(token.Number):
+			query.Top = p.parseLiteral()
+		case p.match(token.Lparen):
+			query.Top = p.parseGroupedExp()
+		default:
+			p.newError(fmt.Sprintf("unexpected token `%s`, expecting the number of rows of TOP", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+		if p.matchWord("percent") {
+			query.Percent = true
+			p.nextToken() // skip 'Percent' token
+		}
+	}
+	for {
+		column := p.parseSqlColumn()
+		if column == nil {
+			return nil
+		}
+		query.Columns = append(query.Columns, column)
+		if !p.match(token.Comma) {
+			break
+		}
+		p.nextToken() // skip ',' token
+	}
+	if !p.expectWord("from") {
+		return nil
+	}
+	if !p.parseSqlFrom(query) {
+		return nil
+	}
+	if p.matchWord("where") {
+		p.nextToken() // skip 'Where' token
+		if query.Where = p.parseSqlCondition(); query.Where == nil {
+			return nil
+		}
+	}
+	if p.matchWord("group") {
+		p.nextToken() // skip 'Group' token
+		if !p.expectWord("by") {
+			return nil
+		}
+		if query.GroupBy = p.parseSqlExpressionList(); query.GroupBy == nil {
+			return nil
+		}
+	}
+	if p.matchWord("having") {
+		p.nextToken() // skip 'Having' token
+		if query.Having = p.parseSqlCondition(); query.Having == nil {
+			return nil
+		}
+	}
+	if p.matchWord("order") {
+		p.nextToken() // skip 'Order' token
+		if !p.expectWord("by") {
+			return nil
+		}
+		for {
+			order := &ast.SqlOrder{}
+			if order.Expr = p.parseExpression(lowest); order.Expr == nil {
+				return nil
+			}
+			if p.matchWord("asc", "desc") {
+				order.Descending = p.matchWord("desc")
+				p.nextToken() // skip 'Asc' | 'Desc' token
+			}
+			query.OrderBy = append(query.OrderBy, order)
+			if !p.match(token.Comma) {
+				break
+			}
+			p.nextToken() // skip ',' token
+		}
+	}
+	return query
+}
+
+// parseSqlColumn => * | alias.* | expr [[As] nombre]
+func (p *Parser) parseSqlColumn() *ast.SqlColumn {
+	column := &ast.SqlColumn{}
+	switch {
+	case p.match(token.Mul):
+		column.Star = true
+		p.nextToken() // skip '*' token
+		return column
+	case p.match(token.Ident) && p.peek(token.Dot):
+		left := p.parseLiteral()
+		if p.peek(token.Mul) {
+			column.Star = true
+			column.Source = left.(*ast.Literal).Value.(string)
+			p.nextToken() // skip '.' token
+			p.nextToken() // skip '*' token
+			return column
+		}
+		column.Expr = p.parseInfixExpressions(left, lowest)
+	default:
+		column.Expr = p.parseExpression(lowest)
+	}
+	if column.Expr == nil {
+		return nil
+	}
+	if p.matchWord("as") {
+		p.nextToken() // skip 'As' token
+		if !p.match(token.Ident) {
+			p.newError(fmt.Sprintf("unexpected token `%s`, expecting a column name", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+	}
+	if p.match(token.Ident) && !p.matchWord(sqlKeywords...) {
+		column.Alias = p.curToken.Literal
+		p.nextToken() // skip column name
+	}
+	return column
+}
+
+// parseSqlFrom => tabla [[As] alias] {, tabla [alias] |
+// [Inner | Left [Outer] | Right [Outer] | Full [Outer]] Join tabla [alias] On cond}
+func (p *Parser) parseSqlFrom(query *ast.SqlQuery) bool {
+	join := ""
+	for {
+		table := p.parseSqlTable()
+		if table == nil {
+			return false
+		}
+		table.Join = join
+		if join != "" {
+			if !p.expectWord("on") {
+				return false
+			}
+			if table.On = p.parseSqlCondition(); table.On == nil {
+				return false
+			}
+		}
+		query.From = append(query.From, table)
+
+		switch {
+		case p.match(token.Comma):
+			p.nextToken() // skip ',' token
+			join = ""
+		case p.matchWord("join", "inner", "left", "right", "full"):
+			join = strings.ToLower(p.curToken.Literal)
+			if join == "join" {
+				join = "inner"
+			} else {
+				p.nextToken() // skip 'Inner' | 'Left' | 'Right' | 'Full' token
+				if join != "inner" && p.matchWord("outer") {
+					p.nextToken() // skip 'Outer' token
+				}
+			}
+			if !p.expectWord("join") {
+				return false
+			}
+		default:
+			return true
+		}
+	}
+}
+
+// parseSqlTable => clientes [[As] c]
+func (p *Parser) parseSqlTable() *ast.SqlTable {
+	table := &ast.SqlTable{}
+	if table.Name = p.parseSqlTableName(); table.Name == nil {
+		return nil
+	}
+	if p.matchWord("as") {
+		p.nextToken() // skip 'As' token
+		if !p.match(token.Ident) {
+			p.newError(fmt.Sprintf("unexpected token `%s`, expecting an alias", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+	}
+	if p.match(token.Ident) && !p.matchWord(sqlKeywords...) {
+		table.Alias = p.curToken.Literal
+		p.nextToken() // skip alias
+	}
+	return table
+}
+
+// parseSqlTableName => clientes | clientes.dbf | ventas!clientes |
+// "c:\datos\clientes" | (lcTabla)
+func (p *Parser) parseSqlTableName() ast.Expression {
+	switch {
+	case p.match(token.Lparen):
+		return p.parseGroupedExp()
+	case p.match(token.String):
+		return p.parseLiteral()
+	case p.match(token.Ident):
+		tok := p.curToken
+		tok.Type = token.String
+		name := p.curToken.Literal
+		p.nextToken() // skip table name
+		if p.match(token.Not) && p.peek(token.Ident) {
+			p.nextToken() // skip '!' token
+			name += "!" + p.curToken.Literal
+			p.nextToken() // skip table name
+		}
+		for p.match(token.Dot) && p.peek(token.Ident) {
+			p.nextToken() // skip '.' token
+			name += "." + p.curToken.Literal
+			p.nextToken() // skip extension
+		}
+		tok.Literal = name
+		return &ast.Literal{Token: tok, Value: name}
+	}
+	p.newError(fmt.Sprintf("unexpected token `%s`, expecting a table name", p.curToken.Literal))
+	p.recovery()
+	return nil
+}
+
+// parseSqlExpressionList => expr {, expr}
+func (p *Parser) parseSqlExpressionList() []ast.Expression {
+	var list []ast.Expression
+	for {
+		exp := p.parseExpression(lowest)
+		if exp == nil {
+			return nil
+		}
+		list = append(list, exp)
+		if !p.match(token.Comma) {
+			return list
+		}
+		p.nextToken() // skip ',' token
+	}
+}
+
+// parseSqlCondition => cond {Or cond}
+// Las condiciones de SQL admiten además de las expresiones de FoxLite los
+// operadores Not, In, Between, Like, Is Null y Exists.
+func (p *Parser) parseSqlCondition() ast.Expression {
+	left := p.parseSqlAnd()
+	for left != nil && p.match(token.Or) {
+		exp := &ast.InfixExp{Token: p.curToken, Left: left, Op: token.Or}
+		p.nextToken() // skip 'Or' token
+		if exp.Right = p.parseSqlAnd(); exp.Right == nil {
+			return nil
+		}
+		left = exp
+	}
+	return left
+}
+
+// parseSqlAnd => cond {And cond}
+func (p *Parser) parseSqlAnd() ast.Expression {
+	left := p.parseSqlNot()
+	for left != nil && p.match(token.And) {
+		exp := &ast.InfixExp{Token: p.curToken, Left: left, Op: token.And}
+		p.nextToken() // skip 'And' token
+		if exp.Right = p.parseSqlNot(); exp.Right == nil {
+			return nil
+		}
+		left = exp
+	}
+	return left
+}
+
+// parseSqlNot => Not cond | Exists (Select ...) | predicado
+func (p *Parser) parseSqlNot() ast.Expression {
+	switch {
+	case p.matchWord("not"):
+		exp := &ast.SqlNotExp{Token: p.curToken}
+		p.nextToken() // skip 'Not' token
+		if exp.Right = p.parseSqlNot(); exp.Right == nil {
+			return nil
+		}
+		return exp
+	case p.matchWord("exists") && p.peek(token.Lparen):
+		exp := &ast.SqlExistsExp{Token: p.curToken}
+		p.nextToken() // skip 'Exists' token
+		subquery, ok := p.parseSqlSubqueryExp().(*ast.SqlSubqueryExp)
+		if !ok {
+			return nil
+		}
+		exp.Query = subquery.Query
+		return exp
+	}
+	return p.parseSqlPredicate()
+}
+
+// parseSqlPredicate => expr [[Not] In (...) | [Not] Between a And b |
+// [Not] Like patrón | Is [Not] Null]
+func (p *Parser) parseSqlPredicate() ast.Expression {
+	var left ast.Expression
+	if p.match(token.Lparen) && !p.peekWord("select") {
+		// los paréntesis agrupan condiciones SQL, que pueden seguir con
+		// otros operadores: (a + b) * 2 > 10
+		p.nextToken() // skip '(' token
+		if left = p.parseSqlCondition(); left == nil {
+			return nil
+		}
+		p.expect(token.Rparen, "expected `)` after grouped expresion")
+		left = p.parseInfixExpressions(left, logicAnd)
+	} else if left = p.parseExpression(logicAnd); left == nil {
+		return nil
+	}
+	tok := p.curToken
+	negated := false
+	if p.matchWord("not") && (p.peek(token.In) || p.peekWord("between") || p.peekWord("like")) {
+		negated = true
+		p.nextToken() // skip 'Not' token
+	}
+	switch {
+	case p.match(token.In):
+		return p.parseSqlInExp(tok, left, negated)
+	case p.matchWord("between"):
+		exp := &ast.SqlBetweenExp{Token: tok, Left: left, Not: negated}
+		p.nextToken() // skip 'Between' token
+		if exp.Low = p.parseExpression(logicAnd); exp.Low == nil {
+			return nil
+		}
+		if !p.match(token.And) {
+			p.newError(fmt.Sprintf("unexpected token `%s`, expecting `AND`", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+		p.nextToken() // skip 'And' token
+		if exp.High = p.parseExpression(logicAnd); exp.High == nil {
+			return nil
+		}
+		return exp
+	case p.matchWord("like"):
+		exp := &ast.SqlLikeExp{Token: tok, Left: left, Not: negated}
+		p.nextToken() // skip 'Like' token
+		if exp.Pattern = p.parseExpression(logicAnd); exp.Pattern == nil {
+			return nil
+		}
+		return exp
+	case p.matchWord("is"):
+		exp := &ast.SqlIsNullExp{Token: tok, Left: left}
+		p.nextToken() // skip 'Is' token
+		if p.matchWord("not") {
+			exp.Not = true
+			p.nextToken() // skip 'Not' token
+		}
+		if !p.match(token.Null) {
+			p.newError(fmt.Sprintf("unexpected token `%s`, expecting `NULL`", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+		p.nextToken() // skip 'Null' token
+		return exp
+	}
+	return left
+}
+
+// parseSqlInExp => expr [Not] In (a, b, c) | expr [Not] In (Select ...)
+func (p *Parser) parseSqlInExp(tok token.Token, left ast.Expression, negated bool) ast.Expression {
+	exp := &ast.SqlInExp{Token: tok, Left: left, Not: negated}
+	p.nextToken() // skip 'In' token
+	if !p.match(token.Lparen) {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting `(`", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	if p.peekWord("select") {
+		subquery, ok := p.parseSqlSubqueryExp().(*ast.SqlSubqueryExp)
+		if !ok {
+			return nil
+		}
+		exp.Query = subquery.Query
+		return exp
+	}
+	p.nextToken() // skip '(' token
+	if exp.List = p.parseSqlExpressionList(); exp.List == nil {
+		return nil
+	}
+	p.expect(token.Rparen, "")
+	return exp
+}
+
+// parseSqlSubqueryExp => (Select ...)
+func (p *Parser) parseSqlSubqueryExp() ast.Expression {
+	exp := &ast.SqlSubqueryExp{Token: p.curToken}
+	p.nextToken() // skip '(' token
+	tok := p.curToken
+	p.nextToken() // skip 'Select' token
+	if exp.Query = p.parseSqlQuery(tok); exp.Query == nil {
+		return nil
+	}
+	if !p.match(token.Rparen) {
+		p.newError(fmt.Sprintf("unexpected token `%s` in subquery, expecting `)`", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	p.nextToken() // skip ')' token
+	return exp
+}
+
+// sqlAggregates son las funciones de agregado de SQL.
+var sqlAggregates = map[string]bool{"count": true, "sum": true, "avg": true, "min": true, "max": true}
+
+func isSqlAggregate(caller ast.Expression) bool {
+	ident, ok := caller.(*ast.Literal)
+	return ok && ident.Token.Type == token.Ident && sqlAggregates[strings.ToLower(ident.Token.Literal)]
+}
+
+// parseSqlAggregateExp => Count(*) | Count([Distinct] expr) | Sum(expr) | ...
+// Min y Max con varios argumentos son las funciones escalares de FoxLite.
+func (p *Parser) parseSqlAggregateExp(caller ast.Expression) ast.Expression {
+	exp := &ast.SqlAggregateExp{
+		Token: p.curToken,
+		Func:  strings.ToLower(caller.(*ast.Literal).Token.Literal),
+	}
+	p.nextToken() // skip '(' token
+	if p.match(token.Mul) && exp.Func == "count" {
+		p.nextToken() // skip '*' token
+		p.expect(token.Rparen, "")
+		return exp
+	}
+	if p.matchWord("distinct") {
+		exp.Distinct = true
+		p.nextToken() // skip 'Distinct' token
+	}
+	args := p.parseSqlExpressionList()
+	if args == nil {
+		return nil
+	}
+	p.expect(token.Rparen, "")
+	if len(args) > 1 {
+		if exp.Distinct || (exp.Func != "min" && exp.Func != "max") {
+			p.newError(fmt.Sprintf("%s() expects one argument", strings.ToUpper(exp.Func)))
+			return nil
+		}
+		return &ast.CallExp{Token: exp.Token, Caller: caller, Args: args}
+	}
+	exp.Arg = args[0]
+	return exp
+}
+
+// parseInsertStmt => Insert Into t [(campos)] Values (valores) |
+// Insert Into t From Array arr | From Memvar | From Name obj
+func (p *Parser) parseInsertStmt() ast.Statement {
+	p.sql++
+	defer func() { p.sql-- }()
+
+	stmt := &ast.SqlInsertStmt{Token: p.curToken}
+	p.nextToken() // skip 'Insert' token
+	if !p.expectWord("into") {
+		return nil
+	}
+	if stmt.Table = p.parseSqlTableName(); stmt.Table == nil {
+		return nil
+	}
+	if p.match(token.Lparen) {
+		for {
+			p.nextToken() // skip '(' | ',' token
+			if !p.match(token.Ident) {
+				p.newError(fmt.Sprintf("unexpected token `%s`, expecting a field name", p.curToken.Literal))
+				p.recovery()
+				return nil
+			}
+			stmt.Columns = append(stmt.Columns, p.curToken.Literal)
+			p.nextToken() // skip field name
+			if !p.match(token.Comma) {
+				break
+			}
+		}
+		p.expect(token.Rparen, "")
+	}
+	switch {
+	case p.matchWord("values"):
+		p.nextToken() // skip 'Values' token
+		if !p.match(token.Lparen) {
+			p.newError(fmt.Sprintf("unexpected token `%s`, expecting `(`", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+		p.nextToken() // skip '(' token
+		if stmt.Values = p.parseSqlExpressionList(); stmt.Values == nil {
+			return nil
+		}
+		p.expect(token.Rparen, "")
+		if len(stmt.Columns) > 0 && len(stmt.Columns) != len(stmt.Values) {
+			p.newError(fmt.Sprintf("INSERT: %d fields and %d values", len(stmt.Columns), len(stmt.Values)))
+			return nil
+		}
+	case p.matchWord("from"):
+		p.nextToken() // skip 'From' token
+		if !p.matchWord("array", "memvar", "name") {
+			p.newError(fmt.Sprintf("unexpected token `%s`, expecting ARRAY, MEMVAR or NAME", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+		stmt.From = strings.ToLower(p.curToken.Literal)
+		p.nextToken() // skip 'Array' | 'Memvar' | 'Name' token
+		if stmt.From != "memvar" {
+			if stmt.Source = p.parseExpression(lowest); stmt.Source == nil {
+				return nil
+			}
+		}
+	default:
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting VALUES or FROM", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	return stmt
+}
+
+// parseUpdateStmt => Update t Set campo = expr [, campo = expr] [Where cond]
+func (p *Parser) parseUpdateStmt() ast.Statement {
+	p.sql++
+	defer func() { p.sql-- }()
+
+	stmt := &ast.SqlUpdateStmt{Token: p.curToken}
+	p.nextToken() // skip 'Update' token
+	if stmt.Table = p.parseSqlTableName(); stmt.Table == nil {
+		return nil
+	}
+	if !p.expectWord("set") {
+		return nil
+	}
+	for {
+		column, ok := p.parseFieldName()
+		if !ok {
+			return nil
+		}
+		if !p.match(token.Assign, token.Equal) {
+			p.newError(fmt.Sprintf("unexpected token `%s`, expecting `=`", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+		p.nextToken() // skip '=' token
+		assign := &ast.SqlAssign{Column: column}
+		if assign.Value = p.parseExpression(lowest); assign.Value == nil {
+			return nil
+		}
+		stmt.Set = append(stmt.Set, assign)
+		if !p.match(token.Comma) {
+			break
+		}
+		p.nextToken() // skip ',' token
+	}
+	if p.matchWord("where") {
+		p.nextToken() // skip 'Where' token
+		if stmt.Where = p.parseSqlCondition(); stmt.Where == nil {
+			return nil
+		}
+	}
+	return stmt
+}
+
+// parseSqlDeleteStmt => Delete From t [Where cond]
+func (p *Parser) parseSqlDeleteStmt(tok token.Token) ast.Statement {
+	p.sql++
+	defer func() { p.sql-- }()
+
+	stmt := &ast.SqlDeleteStmt{Token: tok}
+	p.nextToken() // skip 'From' token
+	if stmt.Table = p.parseSqlTableName(); stmt.Table == nil {
+		return nil
+	}
+	if p.matchWord("where") {
+		p.nextToken() // skip 'Where' token
+		if stmt.Where = p.parseSqlCondition(); stmt.Where == nil {
+			return nil
+		}
+	}
+	return stmt
+}
+
+// parseCreateTableStmt => Create Table clientes [Free] (id I, nombre C(20) Null)
+func (p *Parser) parseCreateTableStmt(tok token.Token) ast.Statement {
+	stmt := &ast.CreateTableStmt{Token: tok}
+	if stmt.Name = p.parseSqlTableName(); stmt.Name == nil {
+		return nil
+	}
+	if p.matchWord("free") {
+		p.nextToken() // skip 'Free' token
+		stmt.Free = true
+	}
+	fields, ok := p.parseFieldDefs()
+	if !ok {
+		return nil
+	}
+	stmt.Fields = fields
+	return stmt
+}
+
+// parseAlterStmt => Alter Table t Add [Column] def | Alter [Column] def |
+// Drop [Column] campo | Rename [Column] campo To nuevo
+func (p *Parser) parseAlterStmt() ast.Statement {
+	stmt := &ast.AlterTableStmt{Token: p.curToken}
+	p.nextToken() // skip 'Alter' token
+	if !p.expectWord("table") {
+		return nil
+	}
+	if stmt.Table = p.parseSqlTableName(); stmt.Table == nil {
+		return nil
+	}
+	if !p.matchWord("add", "alter", "drop", "rename") {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting ADD, ALTER, DROP or RENAME", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	stmt.Action = strings.ToLower(p.curToken.Literal)
+	p.nextToken() // skip 'Add' | 'Alter' | 'Drop' | 'Rename' token
+	if p.matchWord("column") {
+		p.nextToken() // skip 'Column' token
+	}
+	switch stmt.Action {
+	case "add", "alter":
+		field, ok := p.parseFieldDef()
+		if !ok {
+			return nil
+		}
+		stmt.Field = field
+	default:
+		var ok bool
+		if stmt.Column, ok = p.parseFieldName(); !ok {
+			return nil
+		}
+		if stmt.Action == "rename" {
+			if !p.expectWord("to") {
+				return nil
+			}
+			if stmt.NewName, ok = p.parseFieldName(); !ok {
+				return nil
+			}
+		}
+	}
+	return stmt
+}
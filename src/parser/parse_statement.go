@@ -3,6 +3,7 @@ package parser
 import (
 	"FoxLite/src/ast"
 	"FoxLite/src/token"
+	"strings"
 )
 
 func (p *Parser) parseStatement() ast.Statement {
@@ -26,7 +27,14 @@ func (p *Parser) parseStatement() ast.Statement {
 	case token.Class:
 		return p.parseClassStmt()
 	case token.Function:
+		if p.peek(token.Lparen) { // función anónima
+			return p.parseExpressionStmt()
+		}
 		return p.parseFunctionLiteral()
+	case token.For:
+		return p.parseForStmt()
+	case token.Parameters, token.LParameters:
+		return p.parseParametersStmt()
 	case token.If:
 		return p.parseIfStmt()
 	default:
@@ -36,6 +44,12 @@ func (p *Parser) parseStatement() ast.Statement {
 		if p.match(token.Do) && p.peekToken.Type == token.Case {
 			return p.parseDoCaseStmt()
 		}
+		if p.match(token.Do) {
+			return p.parseDoStmt()
+		}
+		if fn, ok := p.commandParseFns[strings.ToLower(p.curToken.Literal)]; ok && p.isCommand() {
+			return fn()
+		}
 		return p.parseExpressionStmt()
 	}
 }
@@ -46,3 +60,16 @@ func (p *Parser) isVarStmt() bool {
 	}
 	return p.match(token.Local, token.Private, token.Public)
 }
+
+// isCommand indica si el identificador actual inicia un comando xBase y no
+// una expresión: `release` sí, pero `release(x)`, `release.x` o `release[0]` no.
+// Un paréntesis separado por espacios es el argumento del comando: USE (lcTabla)
+func (p *Parser) isCommand() bool {
+	if !p.match(token.Ident) {
+		return false
+	}
+	if p.peek(token.Lparen) {
+		return p.peekToken.Line == p.curToken.Line && p.peekToken.Col > p.curToken.Col+len([]rune(p.curToken.Literal))
+	}
+	return !p.peek(token.Dot) && !p.peek(token.Lbracket) && !p.peek(token.Assign)
+}
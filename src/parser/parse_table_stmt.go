@@ -0,0 +1,560 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+	"fmt"
+	"strings"
+)
+
+// parseUseStmt => Use clientes [In 0] [Alias cli] [Exclusive | Shared] | Use [In cli]
+func (p *Parser) parseUseStmt() ast.Statement {
+	stmt := &ast.UseStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Use' token
+	clauses := []string{"in", "alias", "exclusive", "shared"}
+	if !p.eof() && !p.match(token.NewLine) && !p.matchWord(clauses...) {
+		stmt.File = p.parseFileName(clauses...)
+	}
+	for !p.eof() && !p.match(token.NewLine) {
+		switch {
+		case p.matchWord("in"):
+			p.nextToken() // skip 'In' token
+			if stmt.In = p.parseWorkArea(); stmt.In == nil {
+				return nil
+			}
+			continue
+		case p.matchWord("alias"):
+			p.nextToken() // skip 'Alias' token
+			if !p.match(token.Ident) {
+				p.newError(fmt.Sprintf("unexpected token `%s`, expecting an alias name", p.curToken.Literal))
+				p.recovery()
+				return nil
+			}
+			stmt.Alias = p.curToken.Literal
+		case p.matchWord("exclusive"):
+			stmt.Exclusive = true
+		case p.matchWord("shared"):
+			stmt.Shared = true
+		default:
+			p.newError(fmt.Sprintf("unexpected token `%s` in USE command", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+		p.nextToken() // skip clause
+	}
+	return stmt
+}
+
+// parseAppendStmt => Append Blank | Append Memo notas From notas.txt [Overwrite]
+// | Append From archivo ...
+func (p *Parser) parseAppendStmt() ast.Statement {
+	tok := p.curToken
+	p.nextToken() // skip 'Append' token
+	if p.matchWord("from") {
+		return p.parseAppendFromStmt(tok)
+	}
+	if p.matchWord("memo") {
+		p.nextToken() // skip 'Memo' token
+		stmt := &ast.AppendMemoStmt{Token: tok}
+		field, ok := p.parseFieldName()
+		if !ok || !p.expectWord("from") {
+			return nil
+		}
+		stmt.Field = field
+		stmt.File = p.parseFileName("overwrite")
+		if p.matchWord("overwrite") {
+			p.nextToken() // skip 'Overwrite' token
+			stmt.Overwrite = true
+		}
+		return stmt
+	}
+	if !p.expectWord("blank") {
+		return nil
+	}
+	return &ast.AppendBlankStmt{Token: tok}
+}
+
+// parseCopyStmt => Copy Memo notas To notas.txt [Additive] | Copy To archivo ...
+func (p *Parser) parseCopyStmt() ast.Statement {
+	stmt := &ast.CopyMemoStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Copy' token
+	if p.matchWord("to") {
+		return p.parseCopyToStmt(stmt.Token)
+	}
+	if !p.expectWord("memo") {
+		return nil
+	}
+	field, ok := p.parseFieldName()
+	if !ok || !p.expectWord("to") {
+		return nil
+	}
+	stmt.Field = field
+	stmt.File = p.parseFileName("additive")
+	if p.matchWord("additive") {
+		p.nextToken() // skip 'Additive' token
+		stmt.Additive = true
+	}
+	return stmt
+}
+
+// textFileWords son las palabras que terminan el nombre del archivo de
+// Copy To y Append From.
+var textFileWords = append([]string{"fields", "for", "while", "type", "csv", "sdf", "delimited", "as"}, scopeWords...)
+
+// parseCopyToStmt => Copy To archivo [Fields campos | Fields Like patrón |
+// Fields Except patrón] [alcance] [For cond] [While cond] [Type] Csv | Sdf |
+// Delimited [With ...] [As nCodePage]
+func (p *Parser) parseCopyToStmt(tok token.Token) ast.Statement {
+	stmt := &ast.CopyToStmt{Token: tok}
+	p.nextToken() // skip 'To' token
+	stmt.File = p.parseFileName(textFileWords...)
+	for !p.eof() && !p.match(token.NewLine) {
+		switch {
+		case p.matchWord("fields"):
+			if len(stmt.Filter.Fields) > 0 || stmt.Filter.Like != "" || stmt.Filter.Except != "" {
+				p.newError("duplicated FIELDS clause in COPY TO command")
+				p.recovery()
+				return nil
+			}
+			if !p.parseFieldFilter(&stmt.Filter) {
+				return nil
+			}
+		case p.matchWord(scopeWords...) || p.match(token.For, token.While):
+			if stmt.Scope != nil || stmt.For != nil || stmt.While != nil {
+				p.newError("duplicated scope clause in COPY TO command")
+				p.recovery()
+				return nil
+			}
+			var ok bool
+			if stmt.Scope, stmt.For, stmt.While, ok = p.parseScopeClauses("COPY TO"); !ok {
+				return nil
+			}
+		case p.matchWord("type", "csv", "sdf", "delimited"):
+			if stmt.Format.Type != "" {
+				p.newError("duplicated TYPE clause in COPY TO command")
+				p.recovery()
+				return nil
+			}
+			if !p.parseTextFormat(&stmt.Format) {
+				return nil
+			}
+		default:
+			p.newError(fmt.Sprintf("unexpected token `%s` in COPY TO command", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+	}
+	if stmt.Format.Type == "" {
+		p.newError("COPY TO: expecting TYPE CSV, SDF or DELIMITED")
+		p.recovery()
+		return nil
+	}
+	return stmt
+}
+
+// parseAppendFromStmt => Append From archivo [Fields campos] [For cond]
+// [Type] Csv | Sdf | Delimited [With ...] [As nCodePage]
+func (p *Parser) parseAppendFromStmt(tok token.Token) ast.Statement {
+	stmt := &ast.AppendFromStmt{Token: tok}
+	p.nextToken() // skip 'From' token
+	stmt.File = p.parseFileName(textFileWords...)
+	for !p.eof() && !p.match(token.NewLine) {
+		switch {
+		case p.matchWord("fields") && stmt.Fields == nil:
+			p.nextToken() // skip 'Fields' token
+			for {
+				name, ok := p.parseFieldName()
+				if !ok {
+					return nil
+				}
+				stmt.Fields = append(stmt.Fields, name)
+				if !p.match(token.Comma) {
+					break
+				}
+				p.nextToken() // skip ',' token
+			}
+		case p.match(token.For) && stmt.For == nil:
+			p.nextToken() // skip 'For' token
+			if stmt.For = p.parseExpression(lowest); stmt.For == nil {
+				return nil
+			}
+		case p.matchWord("type", "csv", "sdf", "delimited") && stmt.Format.Type == "":
+			if !p.parseTextFormat(&stmt.Format) {
+				return nil
+			}
+		default:
+			p.newError(fmt.Sprintf("unexpected token `%s` in APPEND FROM command", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+	}
+	if stmt.Format.Type == "" {
+		p.newError("APPEND FROM: expecting TYPE CSV, SDF or DELIMITED")
+		p.recovery()
+		return nil
+	}
+	return stmt
+}
+
+// parseTextFormat => [Type] Csv | Sdf | Delimited [With delimitador |
+// With Blank | With Tab | With Character separador] [As nCodePage]
+// El delimitador y el separador se escriben entre comillas o como un solo
+// carácter: Delimited With _ | Delimited With Character ";".
+func (p *Parser) parseTextFormat(format *ast.TextFormat) bool {
+	if p.matchWord("type") {
+		p.nextToken() // skip 'Type' token
+	}
+	if !p.matchWord("csv", "sdf", "delimited") {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting CSV, SDF or DELIMITED", p.curToken.Literal))
+		p.recovery()
+		return false
+	}
+	format.Type = strings.ToLower(p.curToken.Literal)
+	p.nextToken() // skip 'Csv' | 'Sdf' | 'Delimited' token
+	for format.Type == "delimited" && p.matchWord("with") {
+		p.nextToken() // skip 'With' token
+		switch {
+		case p.matchWord("blank"):
+			format.Separator = " "
+			p.nextToken() // skip 'Blank' token
+		case p.matchWord("tab"):
+			format.Separator = "\t"
+			p.nextToken() // skip 'Tab' token
+		case p.matchWord("character"):
+			p.nextToken() // skip 'Character' token
+			sep, ok := p.parseTextChar()
+			if !ok {
+				return false
+			}
+			format.Separator = sep
+		default:
+			delim, ok := p.parseTextChar()
+			if !ok {
+				return false
+			}
+			format.Delimiter = &delim
+		}
+	}
+	if p.matchWord("as") {
+		p.nextToken() // skip 'As' token
+		if format.CodePage = p.parseExpression(lowest); format.CodePage == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTextChar => "c" | c
+func (p *Parser) parseTextChar() (string, bool) {
+	if p.eof() || p.match(token.NewLine) {
+		p.newError("expecting a delimiter character")
+		p.recovery()
+		return "", false
+	}
+	text := p.curToken.Literal
+	p.nextToken() // skip delimiter
+	if len([]rune(text)) > 1 {
+		p.newError(fmt.Sprintf("invalid delimiter `%s`, expecting a single character", text))
+		p.recovery()
+		return "", false
+	}
+	return text, true
+}
+
+// parseReplaceStmt => Replace nombre With "Ana" [Additive], saldo With saldo + 10
+// [alcance] [For cond] [While cond]
+// El alcance también puede ir antes de los campos: Replace All saldo With 0.
+func (p *Parser) parseReplaceStmt() ast.Statement {
+	stmt := &ast.ReplaceStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Replace' token
+	var ok bool
+	if p.matchWord(scopeWords...) && !p.peekWord("with") {
+		if stmt.Scope, stmt.For, stmt.While, ok = p.parseScopeClauses("REPLACE"); !ok {
+			return nil
+		}
+	}
+	for {
+		field, ok := p.parseFieldName()
+		if !ok {
+			return nil
+		}
+		if !p.expectWord("with") {
+			return nil
+		}
+		item := &ast.Replacement{Field: field, Value: p.parseExpression(lowest)}
+		if p.matchWord("additive") {
+			p.nextToken() // skip 'Additive' token
+			item.Additive = true
+		}
+		stmt.Replacements = append(stmt.Replacements, item)
+		if !p.match(token.Comma) {
+			break
+		}
+		p.nextToken() // skip ',' token
+	}
+	if stmt.Scope == nil && stmt.For == nil && stmt.While == nil {
+		if stmt.Scope, stmt.For, stmt.While, ok = p.parseScopeClauses("REPLACE"); !ok {
+			return nil
+		}
+	}
+	return stmt
+}
+
+// parseFieldName => nombre | cli.nombre
+func (p *Parser) parseFieldName() (string, bool) {
+	if !p.match(token.Ident) {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting a field name", p.curToken.Literal))
+		p.recovery()
+		return "", false
+	}
+	name := p.curToken.Literal
+	p.nextToken() // skip field name
+	if p.match(token.Dot) && p.peek(token.Ident) {
+		p.nextToken() // skip '.' token
+		name += "." + p.curToken.Literal
+		p.nextToken() // skip field name
+	}
+	return name, true
+}
+
+// parseDeleteStmt => Delete | Recall [alcance] [For cond] [While cond] |
+// Delete Tag nombre | Delete From t
+func (p *Parser) parseDeleteStmt() ast.Statement {
+	stmt := &ast.DeleteStmt{
+		Token:  p.curToken,
+		Recall: strings.EqualFold(p.curToken.Literal, "recall"),
+	}
+	p.nextToken() // skip 'Delete' | 'Recall' token
+	if !stmt.Recall && p.matchWord("tag") {
+		return p.parseDeleteTagStmt(stmt.Token)
+	}
+	if !stmt.Recall && p.matchWord("from") {
+		return p.parseSqlDeleteStmt(stmt.Token)
+	}
+	command := strings.ToUpper(stmt.Token.Literal)
+	var ok bool
+	if stmt.Scope, stmt.For, stmt.While, ok = p.parseScopeClauses(command); !ok {
+		return nil
+	}
+	return stmt
+}
+
+// parsePackStmt => Pack | Zap
+func (p *Parser) parsePackStmt() ast.Statement {
+	stmt := &ast.PackStmt{
+		Token: p.curToken,
+		Zap:   strings.EqualFold(p.curToken.Literal, "zap"),
+	}
+	p.nextToken() // skip 'Pack' | 'Zap' token
+	return stmt
+}
+
+// parseGoStmt => Go Top | Go Bottom | Go [Record] 10 | Goto lnRegistro
+func (p *Parser) parseGoStmt() ast.Statement {
+	stmt := &ast.GoStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Go' | 'Goto' token
+	switch {
+	case p.matchWord("top", "bottom"):
+		stmt.Where = strings.ToLower(p.curToken.Literal)
+		p.nextToken() // skip 'Top' | 'Bottom' token
+	case p.matchWord("record"):
+		p.nextToken() // skip 'Record' token
+		stmt.Record = p.parseExpression(lowest)
+	default:
+		stmt.Record = p.parseExpression(lowest)
+	}
+	return stmt
+}
+
+// parseSkipStmt => Skip [n]
+func (p *Parser) parseSkipStmt() ast.Statement {
+	stmt := &ast.SkipStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Skip' token
+	if !p.eof() && !p.match(token.NewLine) {
+		stmt.Count = p.parseExpression(lowest)
+	}
+	return stmt
+}
+
+// parseScatterStmt => Scatter [filtro] [Blank] To arr | Memvar | Name obj [Additive]
+func (p *Parser) parseScatterStmt() ast.Statement {
+	stmt := &ast.ScatterStmt{Token: p.curToken}
+	p.nextToken() // skip 'Scatter' token
+	for !p.eof() && !p.match(token.NewLine) && stmt.Target == "" {
+		switch {
+		case p.matchWord("fields", "memo"):
+			if !p.parseFieldFilter(&stmt.Filter) {
+				return nil
+			}
+		case p.matchWord("blank"):
+			stmt.Blank = true
+			p.nextToken() // skip 'Blank' token
+		case p.matchWord("to"):
+			p.nextToken() // skip 'To' token
+			stmt.Target = "array"
+		case p.matchWord("memvar"):
+			p.nextToken() // skip 'Memvar' token
+			stmt.Target = "memvar"
+		case p.matchWord("name"):
+			p.nextToken() // skip 'Name' token
+			stmt.Target = "name"
+		default:
+			p.newError(fmt.Sprintf("unexpected token `%s` in SCATTER command", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+	}
+	if stmt.Target == "" {
+		p.newError("SCATTER: expecting TO, MEMVAR or NAME")
+		p.recovery()
+		return nil
+	}
+	if stmt.Target != "memvar" {
+		if !p.match(token.Ident) {
+			p.newError(fmt.Sprintf("unexpected token `%s`, expecting a name", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+		stmt.Name = p.curToken.Literal
+		p.nextToken() // skip name
+	}
+	// las demás cláusulas también se admiten después del destino
+	for p.matchWord("blank", "additive", "memo", "fields") {
+		switch {
+		case p.matchWord("fields", "memo"):
+			if !p.parseFieldFilter(&stmt.Filter) {
+				return nil
+			}
+			continue
+		case p.matchWord("blank"):
+			stmt.Blank = true
+		default:
+			if stmt.Target != "name" {
+				p.newError("SCATTER: ADDITIVE is only allowed with NAME")
+				p.recovery()
+				return nil
+			}
+			stmt.Additive = true
+		}
+		p.nextToken() // skip 'Blank' | 'Additive' token
+	}
+	return stmt
+}
+
+// parseGatherStmt => Gather From arr | Memvar | Name obj [filtro]
+func (p *Parser) parseGatherStmt() ast.Statement {
+	stmt := &ast.GatherStmt{Token: p.curToken}
+	p.nextToken() // skip 'Gather' token
+	switch {
+	case p.matchWord("from"):
+		stmt.Source = "array"
+	case p.matchWord("memvar"):
+		stmt.Source = "memvar"
+	case p.matchWord("name"):
+		stmt.Source = "name"
+	default:
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting FROM, MEMVAR or NAME", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	p.nextToken() // skip 'From' | 'Memvar' | 'Name' token
+	if stmt.Source != "memvar" {
+		if !p.match(token.Ident) {
+			p.newError(fmt.Sprintf("unexpected token `%s`, expecting a name", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+		stmt.Name = p.curToken.Literal
+		p.nextToken() // skip name
+	}
+	for p.matchWord("fields", "memo") {
+		if !p.parseFieldFilter(&stmt.Filter) {
+			return nil
+		}
+	}
+	return stmt
+}
+
+// parseFieldFilter => Fields campos | Fields [Like patrón] [Except patrón] | Memo
+func (p *Parser) parseFieldFilter(filter *ast.FieldFilter) bool {
+	if p.matchWord("memo") {
+		filter.Memo = true
+		p.nextToken() // skip 'Memo' token
+		return true
+	}
+	p.nextToken() // skip 'Fields' token
+	stops := append([]string{"memo", "blank", "to", "memvar", "name", "except"}, textFileWords...)
+	if !p.matchWord("like", "except") {
+		for {
+			name, ok := p.parseFieldName()
+			if !ok {
+				return false
+			}
+			filter.Fields = append(filter.Fields, name)
+			if !p.match(token.Comma) {
+				return true
+			}
+			p.nextToken() // skip ',' token
+		}
+	}
+	if p.matchWord("like") {
+		p.nextToken() // skip 'Like' token
+		filter.Like = p.parseSkeleton(stops...)
+	}
+	if p.matchWord("except") {
+		p.nextToken() // skip 'Except' token
+		filter.Except = p.parseSkeleton(stops...)
+	}
+	return true
+}
+
+// parseTransactionStmt => Begin Transaction | End Transaction | Rollback
+func (p *Parser) parseTransactionStmt() ast.Statement {
+	stmt := &ast.TransactionStmt{
+		Token:  p.curToken,
+		Action: strings.ToLower(p.curToken.Literal),
+	}
+	p.nextToken() // skip 'Begin' | 'End' | 'Rollback' token
+	if stmt.Action != "rollback" && !p.expectWord("transaction") {
+		return nil
+	}
+	return stmt
+}
+
+// parseUnlockStmt => Unlock [Record n] [In cli] [All]
+func (p *Parser) parseUnlockStmt() ast.Statement {
+	stmt := &ast.UnlockStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Unlock' token
+	for !p.eof() && !p.match(token.NewLine) {
+		switch {
+		case p.matchWord("record"):
+			p.nextToken() // skip 'Record' token
+			stmt.Record = p.parseExpression(lowest)
+		case p.matchWord("in"):
+			p.nextToken() // skip 'In' token
+			if stmt.In = p.parseWorkArea(); stmt.In == nil {
+				return nil
+			}
+		case p.matchWord("all"):
+			p.nextToken() // skip 'All' token
+			stmt.All = true
+		default:
+			p.newError(fmt.Sprintf("unexpected token `%s`, expecting `RECORD`, `IN` or `ALL`", p.curToken.Literal))
+			p.recovery()
+			return nil
+		}
+	}
+	return stmt
+}
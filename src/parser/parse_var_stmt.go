@@ -4,19 +4,18 @@ import (
 	"FoxLite/src/ast"
 	"FoxLite/src/token"
 	"fmt"
+	"strings"
 )
 
-func (p *Parser) parseVarStmt() *ast.VarStmt {
+func (p *Parser) parseVarStmt() ast.Statement {
+	if p.match(token.Private) && p.peekToken.Type == token.Ident && strings.EqualFold(p.peekToken.Literal, "all") {
+		return p.parsePrivateAllStmt()
+	}
+	// Value queda en nil cuando la variable se declara sin valor
 	stmt := &ast.VarStmt{
 		Token: p.curToken,
 		Scope: 'p', // private
 		Type:  'b', // boolean
-		Value: &ast.Literal{
-			Token: token.Token{
-				Type:    token.False,
-				Literal: "false",
-			},
-			Value: false},
 	}
 
 	if p.match(token.Local, token.Private, token.Public) {
@@ -47,3 +46,30 @@ func (p *Parser) parseVarStmt() *ast.VarStmt {
 
 	return stmt
 }
+
+// parsePrivateAllStmt => Private All [Like skeleton | Except skeleton]
+func (p *Parser) parsePrivateAllStmt() ast.Statement {
+	stmt := &ast.PrivateAllStmt{
+		Token:    p.curToken,
+		Skeleton: "*",
+	}
+	p.nextToken() // skip 'Private' token
+	p.nextToken() // skip 'All' token
+	stmt.Skeleton, stmt.Except = p.parseLikeClause("*")
+	return stmt
+}
+
+// parseSkeleton lee un patrón de nombres como l*, lc??? o *
+// uniendo los tokens que lo forman hasta el final de la cláusula o hasta
+// alguna de las palabras stopWords.
+func (p *Parser) parseSkeleton(stopWords ...string) string {
+	var skeleton strings.Builder
+	for !p.eof() && !p.match(token.NewLine, token.Comma) && !p.matchWord(stopWords...) {
+		skeleton.WriteString(p.curToken.Literal)
+		p.nextToken()
+	}
+	if skeleton.Len() == 0 {
+		p.newError("expecting a name skeleton, e.g. `l*`")
+	}
+	return skeleton.String()
+}
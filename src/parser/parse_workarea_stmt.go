@@ -0,0 +1,228 @@
+package parser
+
+import (
+	"FoxLite/src/ast"
+	"FoxLite/src/token"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseSelectStmt => Select 2 | Select cli | Select 0 | Select (lcAlias)
+// Cualquier otra forma es una consulta SQL: Select ... From ...
+func (p *Parser) parseSelectStmt() ast.Statement {
+	stmt := &ast.SelectStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Select' token
+	if !p.match(token.Lparen) && !(p.match(token.Number, token.Ident) && (p.peek(token.NewLine) || p.peek(token.Eof))) {
+		return p.parseSqlSelectStmt(stmt.Token)
+	}
+	if stmt.Area = p.parseWorkArea(); stmt.Area == nil {
+		return nil
+	}
+	if !p.eof() && !p.match(token.NewLine) {
+		p.newError(fmt.Sprintf("unexpected token `%s` in SELECT command", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	return stmt
+}
+
+// parseWorkArea analiza el área de trabajo de un comando: un número, un
+// alias sin comillas o una expresión entre paréntesis.
+func (p *Parser) parseWorkArea() ast.Expression {
+	switch {
+	case p.match(token.Number):
+		return p.parseLiteral()
+	case p.match(token.Lparen):
+		return p.parseGroupedExp()
+	case p.match(token.Ident):
+		tok := p.curToken
+		tok.Type = token.String
+		p.nextToken() // skip alias
+		return &ast.Literal{Token: tok, Value: tok.Literal}
+	}
+	p.newError(fmt.Sprintf("unexpected token `%s`, expecting a work area or an alias", p.curToken.Literal))
+	p.recovery()
+	return nil
+}
+
+// parseTableName analiza el nombre de un cursor o tabla nueva: un
+// identificador o una expresión entre paréntesis.
+func (p *Parser) parseTableName() ast.Expression {
+	switch {
+	case p.match(token.Lparen):
+		return p.parseGroupedExp()
+	case p.match(token.Ident):
+		tok := p.curToken
+		tok.Type = token.String
+		p.nextToken() // skip name
+		return &ast.Literal{Token: tok, Value: tok.Literal}
+	}
+	p.newError(fmt.Sprintf("unexpected token `%s`, expecting a name", p.curToken.Literal))
+	p.recovery()
+	return nil
+}
+
+// parseCreateStmt => Create Cursor tmp (id I, nombre C(20) Null) |
+// Create Table clientes (...) | Create Database ventas
+func (p *Parser) parseCreateStmt() ast.Statement {
+	tok := p.curToken
+	p.nextToken() // skip 'Create' token
+	if p.matchWord("table", "dbf") {
+		p.nextToken() // skip 'Table' token
+		return p.parseCreateTableStmt(tok)
+	}
+	if p.matchWord("database") {
+		p.nextToken() // skip 'Database' token
+		return p.parseCreateDatabaseStmt(tok)
+	}
+	if !p.expectWord("cursor") {
+		return nil
+	}
+	stmt := &ast.CreateCursorStmt{Token: tok}
+	if stmt.Name = p.parseTableName(); stmt.Name == nil {
+		return nil
+	}
+	fields, ok := p.parseFieldDefs()
+	if !ok {
+		return nil
+	}
+	stmt.Fields = fields
+	return stmt
+}
+
+// fieldTypes traduce los nombres largos de los tipos de campo.
+var fieldTypes = map[string]byte{
+	"character": 'C',
+	"char":      'C',
+	"varchar":   'C',
+	"numeric":   'N',
+	"float":     'F',
+	"logical":   'L',
+	"date":      'D',
+	"datetime":  'T',
+	"integer":   'I',
+	"int":       'I',
+	"currency":  'Y',
+	"double":    'B',
+	"memo":      'M',
+}
+
+// parseFieldDefs => (id I, nombre C(20) [Null | Not Null], saldo N(10, 2))
+func (p *Parser) parseFieldDefs() ([]*ast.FieldDef, bool) {
+	if !p.match(token.Lparen) {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting `(`", p.curToken.Literal))
+		p.recovery()
+		return nil, false
+	}
+	var fields []*ast.FieldDef
+	for {
+		p.nextToken() // skip '(' | ',' token
+		field, ok := p.parseFieldDef()
+		if !ok {
+			return nil, false
+		}
+		fields = append(fields, field)
+		if !p.match(token.Comma) {
+			break
+		}
+	}
+	if !p.match(token.Rparen) {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting `)`", p.curToken.Literal))
+		p.recovery()
+		return nil, false
+	}
+	p.nextToken() // skip ')' token
+	return fields, true
+}
+
+// parseFieldDef => nombre C(20) [Null | Not Null]
+func (p *Parser) parseFieldDef() (*ast.FieldDef, bool) {
+	if !p.match(token.Ident) {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting a field name", p.curToken.Literal))
+		p.recovery()
+		return nil, false
+	}
+	field := &ast.FieldDef{Name: p.curToken.Literal}
+	p.nextToken() // skip field name
+	typeName := strings.ToLower(p.curToken.Literal)
+	if t, ok := fieldTypes[typeName]; ok {
+		field.Type = t
+	} else if len(typeName) == 1 && p.match(token.Ident) {
+		field.Type = strings.ToUpper(typeName)[0]
+	} else {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting a field type", p.curToken.Literal))
+		p.recovery()
+		return nil, false
+	}
+	p.nextToken() // skip field type
+	if p.match(token.Lparen) {
+		p.nextToken() // skip '(' token
+		var ok bool
+		if field.Length, ok = p.parseFieldSize(); !ok {
+			return nil, false
+		}
+		if p.match(token.Comma) {
+			p.nextToken() // skip ',' token
+			if field.Decimals, ok = p.parseFieldSize(); !ok {
+				return nil, false
+			}
+		}
+		if !p.match(token.Rparen) {
+			p.newError(fmt.Sprintf("unexpected token `%s`, expecting `)`", p.curToken.Literal))
+			p.recovery()
+			return nil, false
+		}
+		p.nextToken() // skip ')' token
+	}
+	switch {
+	case p.match(token.Null):
+		field.Null = true
+		p.nextToken() // skip 'Null' token
+	case p.matchWord("not"):
+		p.nextToken() // skip 'Not' token
+		if !p.match(token.Null) {
+			p.newError(fmt.Sprintf("unexpected token `%s`, expecting `NULL`", p.curToken.Literal))
+			p.recovery()
+			return nil, false
+		}
+		field.NotNull = true
+		p.nextToken() // skip 'Null' token
+	}
+	return field, true
+}
+
+// parseFieldSize analiza el ancho o los decimales de un campo.
+func (p *Parser) parseFieldSize() (int, bool) {
+	size, err := strconv.Atoi(p.curToken.Literal)
+	if !p.match(token.Number) || err != nil || size < 0 {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting the field size", p.curToken.Literal))
+		p.recovery()
+		return 0, false
+	}
+	p.nextToken() // skip size
+	return size, true
+}
+
+// parseCloseStmt => Close Tables [All] | Close Databases [All] | Close All
+func (p *Parser) parseCloseStmt() ast.Statement {
+	stmt := &ast.CloseStmt{
+		Token: p.curToken,
+	}
+	p.nextToken() // skip 'Close' token
+	if !p.matchWord("tables", "databases", "all") {
+		p.newError(fmt.Sprintf("unexpected token `%s` in CLOSE command", p.curToken.Literal))
+		p.recovery()
+		return nil
+	}
+	stmt.What = strings.ToLower(p.curToken.Literal)
+	stmt.All = stmt.What == "all"
+	p.nextToken() // skip 'Tables' | 'Databases' | 'All' token
+	if p.matchWord("all") {
+		stmt.All = true
+		p.nextToken() // skip 'All' token
+	}
+	return stmt
+}
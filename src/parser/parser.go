@@ -5,11 +5,13 @@ import (
 	"FoxLite/src/lexer"
 	"FoxLite/src/token"
 	"fmt"
+	"strings"
 )
 
 // Constantes con las precedencias
 const (
 	lowest = iota
+	conditional
 	assignment
 	logicOr
 	logicAnd
@@ -24,7 +26,8 @@ const (
 
 // Tabla de precedencias
 var precedenceTable = map[token.TokenType]int{
-	token.Assign:    assignment,
+	token.If:        conditional,
+	token.Assign:    equality,
 	token.Or:        logicOr,
 	token.And:       logicAnd,
 	token.Equal:     equality,
@@ -33,6 +36,7 @@ var precedenceTable = map[token.TokenType]int{
 	token.LessEq:    comparison,
 	token.Greater:   comparison,
 	token.GreaterEq: comparison,
+	token.Contains:  comparison,
 	token.Plus:      term,
 	token.Minus:     term,
 	token.Mul:       factor,
@@ -46,6 +50,7 @@ var precedenceTable = map[token.TokenType]int{
 
 type prefixFns = func() ast.Expression
 type infixFns = func(left ast.Expression) ast.Expression
+type commandFns = func() ast.Statement
 
 type Parser struct {
 	l         *lexer.Lexer
@@ -54,19 +59,28 @@ type Parser struct {
 	// Diccionarios para las funciones
 	prefixParseFns map[token.TokenType]prefixFns
 	infixParseFns  map[token.TokenType]infixFns
+	// Comandos xBase indexados por su palabra inicial en minúsculas
+	commandParseFns map[string]commandFns
+	// Profundidad de las consultas SQL en análisis: dentro de ellas se
+	// admiten las funciones de agregado y las subconsultas
+	sql int
+	// Dentro de CALCULATE se admiten sus funciones de agregado
+	calc bool
 	// Informe de errores
 	errors []string
 }
 
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
-		l:              l,
-		prefixParseFns: map[token.TokenType]prefixFns{},
-		infixParseFns:  map[token.TokenType]infixFns{},
-		errors:         []string{},
+		l:               l,
+		prefixParseFns:  map[token.TokenType]prefixFns{},
+		infixParseFns:   map[token.TokenType]infixFns{},
+		commandParseFns: map[string]commandFns{},
+		errors:          []string{},
 	}
 	p.registerPrefixFns()
 	p.registerInfixFns()
+	p.registerCommandFns()
 	p.nextToken()
 	p.nextToken()
 	return p
@@ -94,8 +108,18 @@ func (p *Parser) registerPrefixFns() {
 	p.prefixParseFns[token.Ident] = p.parseLiteral  // foo, bar
 	// Expresiones agrupadas
 	p.prefixParseFns[token.Lparen] = p.parseGroupedExp // (1 + 2) * (3 + 4)
+	// Arrays
+	p.prefixParseFns[token.Lbracket] = p.parseArrayLiteral // [1, 2, 3]
 	// Expresiones unarias
 	p.prefixParseFns[token.Minus] = p.parsePrefixExp // -5, -foo()
+	// Argumentos por referencia
+	p.prefixParseFns[token.At] = p.parseReferenceExp // foo(@lnTotal)
+	// Funciones anónimas
+	p.prefixParseFns[token.Function] = p.parseAnonymousFunction // func(x) x * 2
+	// Expresiones condicionales
+	p.prefixParseFns[token.Iif] = p.parseIifExp // Iif(lnNum > 0, "positivo", "negativo")
+
+	p.prefixParseFns[token.CreateObject] = p.parseKeywordCall // CreateObject("Empty")
 }
 
 func (p *Parser) registerInfixFns() {
@@ -116,12 +140,77 @@ func (p *Parser) registerInfixFns() {
 	p.infixParseFns[token.GreaterEq] = p.parseInfixExp // 1 >= 2
 	p.infixParseFns[token.Equal] = p.parseInfixExp     // 1 == 2
 	p.infixParseFns[token.NotEq] = p.parseInfixExp     // 1 != 2
+	p.infixParseFns[token.Contains] = p.parseInfixExp  // "ox" $ "FoxLite"
 	// Operador de resolución de nombres
 	p.infixParseFns[token.Dot] = p.parseInfixExp // foo.bar
 	// Asignaciones
-	p.infixParseFns[token.Assign] = p.parseInfixExp // foo = bar | foo.bar = 20
+	p.infixParseFns[token.Assign] = p.parseInfixExp // foo = bar (comparación en una expresión)
+	// Expresión condicional en línea
+	p.infixParseFns[token.If] = p.parseConditionalExp // "par" if lnNum % 2 == 0 else "impar"
 	// llamadas a funciones
 	p.infixParseFns[token.Lparen] = p.parseCallExp // foo()
+	// acceso a los elementos de un array
+	p.infixParseFns[token.Lbracket] = p.parseIndexExp // foo[0]
+}
+
+// Los comandos no son palabras reservadas: solo se reconocen al comienzo de
+// una sentencia, por lo que siguen pudiendo usarse como nombres de variables.
+func (p *Parser) registerCommandFns() {
+	// Configuración
+	p.commandParseFns["set"] = p.parseSetStmt      // SET EXACT ON
+	p.commandParseFns["push"] = p.parsePushSetStmt // PUSH SET
+	p.commandParseFns["pop"] = p.parsePushSetStmt  // POP SET
+	// Variables de memoria
+	p.commandParseFns["release"] = p.parseReleaseStmt    // RELEASE ALL LIKE l*
+	p.commandParseFns["clear"] = p.parseClearStmt        // CLEAR MEMORY
+	p.commandParseFns["list"] = p.parseListMemoryStmt    // LIST MEMORY
+	p.commandParseFns["display"] = p.parseListMemoryStmt // DISPLAY MEMORY
+	p.commandParseFns["save"] = p.parseSaveStmt          // SAVE TO vars.mem
+	p.commandParseFns["restore"] = p.parseRestoreStmt    // RESTORE FROM vars.mem
+	// Tablas
+	p.commandParseFns["use"] = p.parseUseStmt         // USE clientes ALIAS cli
+	p.commandParseFns["append"] = p.parseAppendStmt   // APPEND BLANK
+	p.commandParseFns["replace"] = p.parseReplaceStmt // REPLACE nombre WITH "Ana"
+	p.commandParseFns["copy"] = p.parseCopyStmt       // COPY MEMO notas TO notas.txt
+	p.commandParseFns["delete"] = p.parseDeleteStmt   // DELETE
+	p.commandParseFns["recall"] = p.parseDeleteStmt   // RECALL
+	p.commandParseFns["pack"] = p.parsePackStmt       // PACK
+	p.commandParseFns["zap"] = p.parsePackStmt        // ZAP
+	p.commandParseFns["go"] = p.parseGoStmt           // GO TOP
+	p.commandParseFns["goto"] = p.parseGoStmt         // GOTO 10
+	p.commandParseFns["skip"] = p.parseSkipStmt       // SKIP -1
+	p.commandParseFns["scatter"] = p.parseScatterStmt // SCATTER MEMVAR
+	p.commandParseFns["gather"] = p.parseGatherStmt   // GATHER MEMVAR
+	// Transacciones y bloqueos
+	p.commandParseFns["begin"] = p.parseTransactionStmt    // BEGIN TRANSACTION
+	p.commandParseFns["end"] = p.parseTransactionStmt      // END TRANSACTION
+	p.commandParseFns["rollback"] = p.parseTransactionStmt // ROLLBACK
+	p.commandParseFns["unlock"] = p.parseUnlockStmt        // UNLOCK ALL
+	// Bases de datos
+	p.commandParseFns["open"] = p.parseOpenStmt // OPEN DATABASE ventas
+	p.commandParseFns["add"] = p.parseAddStmt   // ADD TABLE clientes
+	// Áreas de trabajo
+	p.commandParseFns["select"] = p.parseSelectStmt // SELECT cli
+	p.commandParseFns["create"] = p.parseCreateStmt // CREATE CURSOR tmp (id I)
+	p.commandParseFns["close"] = p.parseCloseStmt   // CLOSE TABLES ALL
+	// Recorrido de registros
+	p.commandParseFns["scan"] = p.parseScanStmt         // SCAN FOR saldo > 0
+	p.commandParseFns["locate"] = p.parseLocateStmt     // LOCATE FOR nombre = "Ana"
+	p.commandParseFns["continue"] = p.parseContinueStmt // CONTINUE
+	// Índices
+	p.commandParseFns["index"] = p.parseIndexStmt     // INDEX ON UPPER(nombre) TAG nombre
+	p.commandParseFns["reindex"] = p.parseReindexStmt // REINDEX
+	p.commandParseFns["seek"] = p.parseSeekStmt       // SEEK "GARCIA"
+	// Resúmenes
+	p.commandParseFns["count"] = p.parseAggregateStmt     // COUNT FOR saldo > 0 TO lnCant
+	p.commandParseFns["sum"] = p.parseAggregateStmt       // SUM saldo TO lnTotal
+	p.commandParseFns["average"] = p.parseAggregateStmt   // AVERAGE saldo TO lnMedia
+	p.commandParseFns["calculate"] = p.parseAggregateStmt // CALCULATE MAX(saldo) TO lnMax
+	p.commandParseFns["total"] = p.parseTotalStmt         // TOTAL ON cliente TO totales
+	// SQL
+	p.commandParseFns["insert"] = p.parseInsertStmt // INSERT INTO t VALUES (1, "Ana")
+	p.commandParseFns["update"] = p.parseUpdateStmt // UPDATE t SET saldo = 0
+	p.commandParseFns["alter"] = p.parseAlterStmt   // ALTER TABLE t ADD COLUMN email C(40)
 }
 
 func (p *Parser) curPrecedence() int {
@@ -155,10 +244,44 @@ func (p *Parser) expect(t token.TokenType, msg string) {
 	}
 }
 
+// matchWord comprueba si el token actual es alguna de las palabras indicadas
+// (sin distinguir mayúsculas), útil para las cláusulas de los comandos xBase,
+// que no son palabras reservadas (SET EXACT ON) salvo In, For y While.
+func (p *Parser) matchWord(words ...string) bool {
+	if p.curToken.Type != token.Ident && p.curToken.Type != token.LookupIdent(p.curToken.Literal) {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(p.curToken.Literal, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// expectWord avanza si el token actual es la palabra indicada; si no lo es
+// registra el error y descarta el resto de la sentencia.
+func (p *Parser) expectWord(word string) bool {
+	if !p.matchWord(word) {
+		p.newError(fmt.Sprintf("unexpected token `%s`, expecting `%s`", p.curToken.Literal, strings.ToUpper(word)))
+		p.recovery()
+		return false
+	}
+	p.nextToken() // skip word
+	return true
+}
+
 func (p *Parser) peek(t token.TokenType) bool {
 	return p.peekToken.Type == t
 }
 
+// peekWord comprueba si el token siguiente es la palabra indicada (sin
+// distinguir mayúsculas).
+func (p *Parser) peekWord(word string) bool {
+	return (p.peekToken.Type == token.Ident || p.peekToken.Type == token.LookupIdent(p.peekToken.Literal)) &&
+		strings.EqualFold(p.peekToken.Literal, word)
+}
+
 func (p *Parser) eof() bool {
 	return p.curToken.Type == token.Eof
 }
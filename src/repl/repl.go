@@ -18,9 +18,13 @@ const VERSION = "1.0.1"
 
 func RunFile(fileName string) { // Ejecuta el código de un fichero.
 	env := createEnvironment()
+	env.EnterFile(fileName)
 	l := lexer.New()
 	l.ScanFile(fileName)
 	Execute(l, os.Stdout, env)
+	env.CloseAreas() // borra los cursores temporales
+	env.CloseDatabases()
+	env.CloseConnections()
 }
 
 func RunPrompt(in io.Reader, out io.Writer) {
@@ -32,7 +36,7 @@ func RunPrompt(in io.Reader, out io.Writer) {
 		fmt.Print(PROMPT)
 		scanned := scanner.Scan()
 		if !scanned {
-			return
+			break
 		}
 		input := scanner.Text()
 		if len(input) == 0 {
@@ -45,6 +49,9 @@ func RunPrompt(in io.Reader, out io.Writer) {
 		l.ScanText([]rune(input))
 		Execute(l, out, env)
 	}
+	env.CloseAreas() // borra los cursores temporales
+	env.CloseDatabases()
+	env.CloseConnections()
 }
 
 func Execute(l *lexer.Lexer, out io.Writer, env *object.Environment) {
@@ -65,12 +72,17 @@ func Execute(l *lexer.Lexer, out io.Writer, env *object.Environment) {
 			return
 		}
 		evaluated := evaluator.Eval(program, env)
-		if evaluated.Type() == object.ErrorObj {
-			msg := fmt.Sprintf("%s %s\n", l.GetFileName(), evaluated.Inspect())
+		if err, ok := evaluated.(*object.Error); ok {
+			// el error puede venir de un programa o librería incluidos
+			fileName := l.GetFileName()
+			if err.File != "" {
+				fileName = err.File
+			}
+			msg := fmt.Sprintf("%s %s\n", fileName, err.Inspect())
 			fmt.Println(msg)
 			return
 		} else {
-			fmt.Println(evaluated.Inspect())
+			fmt.Println(env.Format().Inspect(evaluated))
 		}
 	}
 	//fmt.Println(color.Green + "policia execute 2!" + color.Reset)
@@ -0,0 +1,7 @@
+//go:build sqlite
+
+package main
+
+// El driver de SQLite para SQL pass-through (SQLSTRINGCONNECT y demás) solo
+// se incluye al compilar con -tags sqlite.
+import _ "modernc.org/sqlite"
@@ -36,6 +36,7 @@ const (
 	GreaterEq // >=
 	Equal     // =
 	NotEq     // !=
+	Contains  // $
 
 	// Caracteres especiales
 	Lbracket // [
@@ -46,6 +47,7 @@ const (
 	OpenQM   // ¿
 	CloseQM  // ?
 	Dot      // .
+	At       // @
 
 	// Palabras reservadas
 	Function
@@ -68,6 +70,9 @@ const (
 	Exit
 	Loop
 	Class
+	Iif
+	Parameters
+	LParameters
 	// Variables
 	Private // Private
 	Local   // Local
@@ -104,6 +109,7 @@ var tokenDesc = []string{
 	"GreaterEq", // >=
 	"Equal",     // =
 	"NotEq",     // !=
+	"Contains",  // $
 
 	// Caracteres especiales
 	"Lbracket", // [
@@ -114,6 +120,7 @@ var tokenDesc = []string{
 	"OpenQM",   // ¿
 	"CloseQM",  // ?
 	"Dot",      // .
+	"At",       // @
 
 	// Palabras reservadas
 	"Function",
@@ -136,6 +143,9 @@ var tokenDesc = []string{
 	"Exit",
 	"Loop",
 	"Class",
+	"Iif",
+	"Parameters",
+	"LParameters",
 	"Private",
 	"Local",
 	"Public",
@@ -163,6 +173,9 @@ var keywords = map[string]TokenType{
 	"exit":         Exit,
 	"loop":         Loop,
 	"class":        Class,
+	"iif":          Iif,
+	"parameters":   Parameters,
+	"lparameters":  LParameters,
 	"prv":          Private,
 	"loc":          Local,
 	"pub":          Public,
@@ -176,6 +189,7 @@ type Token struct {
 	Literal string
 	Line    int
 	Col     int
+	Offset  int // posición en el texto del programa
 }
 
 func (t *Token) Str() string {